@@ -0,0 +1,19 @@
+-- Migration 034: Per-token confirmation and emission policies
+-- Read by the event indexer (TOKEN_POLICIES=true) and refreshed periodically
+-- Created: 2026-10-17
+
+CREATE TABLE IF NOT EXISTS token_policies (
+  chain_id BIGINT NOT NULL,
+  -- Contract address (EVM hex, TRON Base58), or asset ID for TRC-10
+  token_address TEXT NOT NULL,
+  -- Smallest transfer emitted, in the token's base units; NULL = any amount
+  min_amount NUMERIC(78, 0),
+  -- Confirmations required before a transfer is confirmed; 0 = chain default
+  confirmations INTEGER NOT NULL DEFAULT 0 CHECK (confirmations >= 0),
+  -- Disabled tokens' transfers are dropped
+  enabled BOOLEAN NOT NULL DEFAULT true,
+  -- Once any token on a chain is allowlisted, only allowlisted tokens are emitted
+  allowlisted BOOLEAN NOT NULL DEFAULT false,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (chain_id, token_address)
+);
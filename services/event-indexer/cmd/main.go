@@ -4,11 +4,16 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/protocol-bank/event-indexer/internal/config"
+	"github.com/protocol-bank/event-indexer/internal/handler"
+	"github.com/protocol-bank/event-indexer/internal/store"
 	"github.com/protocol-bank/event-indexer/internal/watcher"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
@@ -16,6 +21,9 @@ import (
 	"google.golang.org/grpc/reflection"
 )
 
+// grpcStopTimeout 优雅关闭 gRPC 服务的最长等待时间，超时后强制关闭
+const grpcStopTimeout = 10 * time.Second
+
 func main() {
 	// 初始化日志
 	zerolog.TimeFieldFormat = zerolog.TimeFormatUnix
@@ -38,6 +46,56 @@ func main() {
 		log.Fatal().Err(err).Msg("Failed to create multi-chain watcher")
 	}
 
+	// Redis 状态存储 (检查点/运行余额/监听地址，按需连接)
+	var redisStore *store.Redis
+	if cfg.PersistCheckpoints || cfg.EmitBalanceDeltas || cfg.PersistWatchedAddresses {
+		redisStore, err = store.NewRedis(ctx, cfg.Redis)
+		if err != nil {
+			log.Fatal().Err(err).Msg("Failed to connect state store")
+		}
+		defer redisStore.Close()
+	}
+
+	// 持久化检查点 (可选): 重启后从已确认的检查点恢复
+	if cfg.PersistCheckpoints {
+		chainIDs := make([]uint64, 0, len(cfg.Chains))
+		for chainID := range cfg.Chains {
+			chainIDs = append(chainIDs, chainID)
+		}
+		if err := redisStore.MigrateCheckpoints(ctx, chainIDs); err != nil {
+			log.Fatal().Err(err).Msg("Failed to migrate checkpoints")
+		}
+		multiChainWatcher.SetCheckpointStore(redisStore)
+	}
+
+	// 持久化监听地址 (可选): 运行时新增的地址重启后仍然生效
+	if cfg.PersistWatchedAddresses {
+		if err := multiChainWatcher.SetWatchSetStore(ctx, redisStore); err != nil {
+			log.Fatal().Err(err).Msg("Failed to load persisted watched addresses")
+		}
+	}
+
+	// 代币策略 (可选): 从 Postgres token_policies 表加载并定期刷新
+	if cfg.TokenPolicies {
+		db, err := store.NewPostgres(ctx, cfg.Database.URL)
+		if err != nil {
+			log.Fatal().Err(err).Msg("Failed to connect policy database")
+		}
+		defer db.Close()
+		if err := multiChainWatcher.SetTokenPolicyStore(ctx, db, cfg.TokenPolicyRefreshInterval); err != nil {
+			log.Fatal().Err(err).Msg("Failed to load token policies")
+		}
+	}
+
+	// 运行余额 (可选): 监听地址每次余额变动发出 balance_delta 事件
+	if cfg.EmitBalanceDeltas {
+		multiChainWatcher.SetBalanceStore(redisStore)
+	}
+
+	// 实时事件流: 分发的事件推送给 SubscribeEvents 订阅者
+	broker := handler.NewEventBroker(cfg.StreamBufferSize)
+	multiChainWatcher.AddHandler(broker.Publish)
+
 	// 启动监听
 	go multiChainWatcher.Start(ctx)
 
@@ -48,6 +106,7 @@ func main() {
 	}
 
 	grpcServer := grpc.NewServer()
+	handler.RegisterIndexerServer(grpcServer, broker)
 	if cfg.Environment == "development" || cfg.Environment == "" {
 		reflection.Register(grpcServer) // Only enable gRPC reflection in development
 	}
@@ -59,13 +118,70 @@ func main() {
 		}
 	}()
 
+	// 管理端 HTTP 接口 (状态快照、Prometheus 指标、历史区块回填)，ADMIN_PORT=0 时关闭
+	var adminServer *http.Server
+	if cfg.AdminPort > 0 {
+		metrics := prometheus.NewRegistry()
+		if err := multiChainWatcher.RegisterMetrics(metrics); err != nil {
+			log.Fatal().Err(err).Msg("Failed to register metrics")
+		}
+		backfill := func(chainID, from, to uint64) error {
+			return multiChainWatcher.StartBackfill(ctx, chainID, from, to)
+		}
+		if cfg.AdminToken == "" {
+			log.Warn().Msg("ADMIN_TOKEN not set, admin backfill endpoint disabled")
+		}
+		adminServer = &http.Server{
+			Addr:              fmt.Sprintf(":%d", cfg.AdminPort),
+			Handler:           handler.NewAdminHandler(multiChainWatcher.Snapshot, metrics, backfill, cfg.AdminToken),
+			ReadHeaderTimeout: 5 * time.Second,
+		}
+		go func() {
+			log.Info().Int("port", cfg.AdminPort).Msg("Admin HTTP server listening")
+			if err := adminServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				log.Error().Err(err).Msg("Admin HTTP server failed")
+			}
+		}()
+	}
+
 	// 优雅关闭
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
 	log.Info().Msg("Shutting down...")
-	grpcServer.GracefulStop()
+
+	// Lame duck: 停止接收新区块，等待当前区块与在途事件处理器完成
+	drained := make(chan struct{})
+	go func() {
+		multiChainWatcher.BeginShutdown()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+	case <-time.After(cfg.ShutdownDrainTimeout):
+		log.Warn().Dur("timeout", cfg.ShutdownDrainTimeout).Msg("Drain timed out, forcing shutdown")
+	}
+
+	// 先结束事件订阅流，GracefulStop 不会取消流的 context；超时后强制关闭
+	broker.Close()
+	stopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(grpcStopTimeout):
+		log.Warn().Dur("timeout", grpcStopTimeout).Msg("gRPC graceful stop timed out, forcing stop")
+		grpcServer.Stop()
+	}
+	if adminServer != nil {
+		adminServer.Close()
+	}
 	cancel()
+	if err := multiChainWatcher.Close(); err != nil {
+		log.Warn().Err(err).Msg("Failed to close chain watchers")
+	}
 	log.Info().Msg("Event Indexer stopped")
 }
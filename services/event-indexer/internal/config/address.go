@@ -0,0 +1,136 @@
+package config
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// TronMainnetPrefix TRON 主网 (及当前测试网) 的地址前缀字节，
+// 其他网络通过 ChainConfig.AddressPrefix 覆盖
+const TronMainnetPrefix byte = 0x41
+
+// ValidateAddress 校验地址格式: EVM ("evm") 为 0x 开头的 20 字节十六进制地址，
+// 大小写混合时必须符合 EIP-55 校验和 (全小写/全大写视为未带校验和)；
+// TRON ("tron") 为 Base58Check 校验和正确、前缀为 tronPrefix 的地址
+// (0 = 主网 0x41，主网地址还须为 T 开头的 34 个字符)
+func ValidateAddress(addr string, chainType string, tronPrefix byte) error {
+	switch chainType {
+	case "evm":
+		if !common.IsHexAddress(addr) || !strings.HasPrefix(addr, "0x") {
+			return fmt.Errorf("not a 0x-prefixed 20-byte hex address")
+		}
+		hexPart := addr[2:]
+		if hexPart != strings.ToLower(hexPart) && hexPart != strings.ToUpper(hexPart) {
+			if checksummed := common.HexToAddress(addr).Hex(); checksummed != addr {
+				return fmt.Errorf("EIP-55 checksum mismatch, expected %s", checksummed)
+			}
+		}
+		return nil
+	case "tron":
+		if tronPrefix == 0 {
+			tronPrefix = TronMainnetPrefix
+		}
+		if tronPrefix == TronMainnetPrefix && (len(addr) != 34 || addr[0] != 'T') {
+			return fmt.Errorf("not a TRON address (want 34 characters starting with T)")
+		}
+		if _, err := DecodeTronAddress(addr, tronPrefix); err != nil {
+			return fmt.Errorf("invalid Base58Check address: %w", err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown chain type %q", chainType)
+	}
+}
+
+// addressChainType 根据地址格式推断链类型 (0x 开头为 EVM，否则按 TRON 处理)
+func addressChainType(addr string) string {
+	if strings.HasPrefix(addr, "0x") || strings.HasPrefix(addr, "0X") {
+		return "evm"
+	}
+	return "tron"
+}
+
+// validateWatchedAddress 校验 WATCHED_ADDRESSES 条目。TRON 地址只要属于任一
+// 已配置 TRON 链的网络 (按其 AddressPrefix) 即有效，未配置 TRON 链时按主网校验
+func validateWatchedAddress(addr string, chains map[uint64]ChainConfig) error {
+	chainType := addressChainType(addr)
+	if chainType != "tron" {
+		return ValidateAddress(addr, chainType, 0)
+	}
+	var err error
+	checked := false
+	for _, chain := range chains {
+		if chain.Type != "tron" {
+			continue
+		}
+		checked = true
+		if err = ValidateAddress(addr, chainType, chain.AddressPrefix); err == nil {
+			return nil
+		}
+	}
+	if !checked {
+		return ValidateAddress(addr, chainType, 0)
+	}
+	return err
+}
+
+// DecodeTronAddress 返回的错误 (经 %w 包装)
+var (
+	ErrInvalidBase58     = errors.New("invalid base58 character")
+	ErrBase58Checksum    = errors.New("base58check checksum mismatch")
+	ErrTronAddressLength = errors.New("invalid TRON address length")
+	ErrTronAddressPrefix = errors.New("unexpected TRON address prefix")
+)
+
+// DecodeTronAddress 解码 Base58Check 格式的 TRON 地址，返回 21 字节
+// (前缀 + 20 字节地址)。校验和错误或前缀不是 prefix 时报错
+func DecodeTronAddress(s string, prefix byte) ([]byte, error) {
+	decoded, err := base58Decode(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(decoded) != 25 {
+		return nil, fmt.Errorf("%w: %d bytes, want 25", ErrTronAddressLength, len(decoded))
+	}
+	payload, checksum := decoded[:21], decoded[21:]
+	first := sha256.Sum256(payload)
+	second := sha256.Sum256(first[:])
+	if !bytes.Equal(second[:4], checksum) {
+		return nil, ErrBase58Checksum
+	}
+	if payload[0] != prefix {
+		return nil, fmt.Errorf("%w 0x%02x, want 0x%02x", ErrTronAddressPrefix, payload[0], prefix)
+	}
+	return payload, nil
+}
+
+// Base58Alphabet Bitcoin/TRON 使用的 Base58 字母表，去掉了易混淆的 0、O、I、l
+const Base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// base58Decode 解码 Base58 字符串。遇到字母表外的字符立即报错，
+// 而不是把损坏的地址解码成错误的字节
+func base58Decode(input string) ([]byte, error) {
+	x := new(big.Int)
+	base := big.NewInt(58)
+	for i, c := range input {
+		digit := strings.IndexRune(Base58Alphabet, c)
+		if digit < 0 {
+			return nil, fmt.Errorf("%w %q at position %d", ErrInvalidBase58, c, i)
+		}
+		x.Mul(x, base)
+		x.Add(x, big.NewInt(int64(digit)))
+	}
+
+	// 每个前导 '1' 对应一个前导零字节
+	zeros := 0
+	for zeros < len(input) && input[zeros] == Base58Alphabet[0] {
+		zeros++
+	}
+	return append(make([]byte, zeros), x.Bytes()...), nil
+}
@@ -0,0 +1,79 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	usdtAddress = "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t"
+	// usdtAddress re-encoded with the 0xa0 prefix of a private network
+	usdtPrefixA0 = "27eDfqA41mYyW9NxN9zCp8jBsbovcENGQMP"
+)
+
+func TestBase58Decode(t *testing.T) {
+	for input, want := range map[string][]byte{
+		"":   {},
+		"1":  {0x00},
+		"2":  {0x01},
+		"11": {0x00, 0x00},
+	} {
+		decoded, err := base58Decode(input)
+		require.NoError(t, err)
+		assert.Equal(t, want, decoded, input)
+	}
+
+	// Look-alikes of valid characters are the usual copy-paste corruptions
+	for _, bad := range []string{"0", "O", "I", "l", "+", "é"} {
+		t.Run(bad, func(t *testing.T) {
+			_, err := base58Decode(usdtAddress[:5] + bad + usdtAddress[6:])
+			require.ErrorIs(t, err, ErrInvalidBase58)
+			assert.Contains(t, err.Error(), "at position 5")
+		})
+	}
+}
+
+func TestDecodeTronAddress(t *testing.T) {
+	raw, err := DecodeTronAddress(usdtAddress, TronMainnetPrefix)
+	require.NoError(t, err)
+	assert.Len(t, raw, 21)
+	assert.Equal(t, TronMainnetPrefix, raw[0])
+
+	// A single mistyped character breaks the checksum
+	_, err = DecodeTronAddress("TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6u", TronMainnetPrefix)
+	assert.ErrorIs(t, err, ErrBase58Checksum)
+
+	_, err = DecodeTronAddress(usdtAddress[:20], TronMainnetPrefix)
+	assert.ErrorIs(t, err, ErrTronAddressLength)
+
+	_, err = DecodeTronAddress(usdtPrefixA0, TronMainnetPrefix)
+	assert.ErrorIs(t, err, ErrTronAddressPrefix)
+	other, err := DecodeTronAddress(usdtPrefixA0, 0xa0)
+	require.NoError(t, err)
+	assert.Equal(t, raw[1:], other[1:])
+}
+
+func TestValidateAddress_TronPrefix(t *testing.T) {
+	assert.NoError(t, ValidateAddress(usdtAddress, "tron", 0), "0 is mainnet")
+	assert.NoError(t, ValidateAddress(usdtAddress, "tron", TronMainnetPrefix))
+	assert.Error(t, ValidateAddress(usdtPrefixA0, "tron", 0))
+	assert.NoError(t, ValidateAddress(usdtPrefixA0, "tron", 0xa0))
+	assert.Error(t, ValidateAddress(usdtAddress, "tron", 0xa0))
+}
+
+func TestValidateWatchedAddress(t *testing.T) {
+	mainnet := map[uint64]ChainConfig{728126428: {Type: "tron"}}
+	private := map[uint64]ChainConfig{
+		1:   {Type: "evm"},
+		999: {Type: "tron", AddressPrefix: 0xa0},
+	}
+
+	assert.NoError(t, validateWatchedAddress(usdtAddress, nil), "mainnet without TRON chains")
+	assert.NoError(t, validateWatchedAddress(usdtAddress, mainnet))
+	assert.Error(t, validateWatchedAddress(usdtPrefixA0, mainnet))
+	assert.NoError(t, validateWatchedAddress(usdtPrefixA0, private))
+	assert.Error(t, validateWatchedAddress(usdtAddress, private), "no chain on mainnet")
+	assert.NoError(t, validateWatchedAddress("0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed", private))
+}
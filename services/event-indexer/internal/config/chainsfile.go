@@ -0,0 +1,110 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// chainsFile 是 CHAINS_CONFIG_FILE 的格式 (YAML，JSON 作为其子集同样适用):
+//
+//	chains:
+//	  - chain_id: 11155111
+//	    name: Sepolia
+//	    type: evm
+//	    rpc_url: https://rpc.sepolia.org
+//	    confirmations: 3
+//
+// 字段名为 ChainConfig 的字段名，不区分大小写，可用 "_"/"-" 分隔
+// (RPCURL、rpcUrl、rpc_url 等价)；duration 写作 "3s"，大整数写作十进制字符串
+type chainsFile struct {
+	Chains []yaml.Node `yaml:"chains"`
+}
+
+// loadChainsFile 读取链配置文件，返回按 chainID 索引的原始条目，
+// 由调用方解码到已有配置上 (只覆盖文件中出现的字段)
+func loadChainsFile(path string) (map[uint64]*yaml.Node, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read chains config file: %w", err)
+	}
+
+	var file chainsFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("parse chains config file %s: %w", path, err)
+	}
+
+	nodes := make(map[uint64]*yaml.Node, len(file.Chains))
+	for i := range file.Chains {
+		node := &file.Chains[i]
+		if node.Kind != yaml.MappingNode {
+			return nil, fmt.Errorf("chains config file %s: entry %d is not a mapping", path, i)
+		}
+		normalizeChainKeys(node)
+
+		var entry struct {
+			ChainID uint64 `yaml:"chainid"`
+		}
+		if err := node.Decode(&entry); err != nil {
+			return nil, fmt.Errorf("chains config file %s: entry %d: %w", path, i, err)
+		}
+		if entry.ChainID == 0 {
+			return nil, fmt.Errorf("chains config file %s: entry %d has no chain_id", path, i)
+		}
+		if _, dup := nodes[entry.ChainID]; dup {
+			return nil, fmt.Errorf("chains config file %s: chain %d defined twice", path, entry.ChainID)
+		}
+		nodes[entry.ChainID] = node
+	}
+	return nodes, nil
+}
+
+// normalizeChainKeys 将条目的字段名统一为 yaml 默认的小写字段名
+// (去掉 "_"/"-")。只处理顶层字段: TokenRegistry 等 map 的键是合约地址，
+// TRON Base58 地址区分大小写
+func normalizeChainKeys(node *yaml.Node) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key := node.Content[i]
+		key.Value = strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(key.Value))
+	}
+}
+
+// decodeChain 将文件条目覆盖到 chain 上，未出现的字段保持原值
+func decodeChain(node *yaml.Node, chain *ChainConfig) error {
+	if err := node.Decode(chain); err != nil {
+		return fmt.Errorf("chain %d: %w", chain.ChainID, err)
+	}
+	return nil
+}
+
+// validateChain 校验链配置的必填字段: 类型为 evm/tron，RPC 地址非空
+// (TRON 为 gRPC 地址或 HTTP API 地址，取决于 RPCProtocol)，
+// EVM 开启 UseWebSocket 时需要 WSURL
+func validateChain(chainID uint64, chain ChainConfig) error {
+	if chain.ChainID != chainID {
+		return fmt.Errorf("chain %d: chain_id is %d", chainID, chain.ChainID)
+	}
+	switch chain.Type {
+	case "evm":
+		if chain.RPCURL == "" {
+			return fmt.Errorf("chain %d (%s): rpc_url is required", chainID, chain.Name)
+		}
+		if chain.UseWebSocket && chain.WSURL == "" {
+			return fmt.Errorf("chain %d (%s): ws_url is required with use_websocket", chainID, chain.Name)
+		}
+	case "tron":
+		if chain.RPCURL == "" {
+			return fmt.Errorf("chain %d (%s): rpc_url (gRPC host:port or HTTP API URL) is required", chainID, chain.Name)
+		}
+		switch chain.RPCProtocol {
+		case "", RPCProtocolGRPC, RPCProtocolHTTP:
+		default:
+			return fmt.Errorf("chain %d (%s): unknown rpc_protocol %q, expected grpc or http", chainID, chain.Name, chain.RPCProtocol)
+		}
+	default:
+		return fmt.Errorf("chain %d (%s): unknown type %q, expected evm or tron", chainID, chain.Name, chain.Type)
+	}
+	return nil
+}
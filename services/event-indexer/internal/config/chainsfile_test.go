@@ -0,0 +1,82 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// loadWithChainsFile runs Load with CHAINS_CONFIG_FILE pointing at contents.
+func loadWithChainsFile(t *testing.T, name, contents string) (*Config, error) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(contents), 0o600))
+	t.Setenv("CHAINS_CONFIG_FILE", path)
+	return Load()
+}
+
+func TestLoad_ChainsFile(t *testing.T) {
+	cfg, err := loadWithChainsFile(t, "chains.yaml", `
+chains:
+  - chain_id: 1
+    rpc_url: https://eth.example.com
+    confirmations: 20
+    checkpoint_confirmations: 5
+  - chainId: 10
+    name: Optimism
+    type: evm
+    rpcUrl: https://op.example.com
+    POLL_INTERVAL: 2s
+`)
+	require.NoError(t, err)
+
+	// Built-in chain: only the fields in the file are overridden
+	eth := cfg.Chains[1]
+	assert.Equal(t, "https://eth.example.com", eth.RPCURL)
+	assert.Equal(t, uint64(20), eth.Confirmations)
+	assert.Equal(t, "Ethereum", eth.Name)
+	assert.Equal(t, uint64(5), eth.CheckpointConfirmations, "set in the file, not derived")
+
+	// New chain, with rpc_url/rpcUrl/POLL_INTERVAL style keys normalized
+	op, ok := cfg.Chains[10]
+	require.True(t, ok)
+	assert.Equal(t, "Optimism", op.Name)
+	assert.Equal(t, "https://op.example.com", op.RPCURL)
+	assert.Equal(t, "2s", op.PollInterval.String())
+	assert.Equal(t, op.Confirmations, op.CheckpointConfirmations, "derived when unset")
+}
+
+func TestLoad_ChainsFileJSON(t *testing.T) {
+	cfg, err := loadWithChainsFile(t, "chains.json", `{"chains": [{"chain_id": 10, "name": "Optimism", "type": "evm", "rpc_url": "https://op.example.com"}]}`)
+	require.NoError(t, err)
+	assert.Equal(t, "https://op.example.com", cfg.Chains[10].RPCURL)
+}
+
+func TestLoad_ChainsFileRejected(t *testing.T) {
+	for want, contents := range map[string]string{
+		"chain 10 defined twice": `
+chains:
+  - chain_id: 10
+    type: evm
+    rpc_url: https://a.example.com
+  - chainId: 10
+    type: evm
+    rpc_url: https://b.example.com
+`,
+		"ws_url is required with use_websocket": `
+chains:
+  - chain_id: 10
+    type: evm
+    rpc_url: https://op.example.com
+    use_websocket: true
+`,
+	} {
+		t.Run(want, func(t *testing.T) {
+			_, err := loadWithChainsFile(t, "chains.yaml", contents)
+			assert.ErrorContains(t, err, want)
+		})
+	}
+}
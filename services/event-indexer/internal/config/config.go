@@ -1,15 +1,60 @@
 package config
 
 import (
+	"fmt"
+	"math/big"
 	"os"
 	"strconv"
 	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
 )
 
 type Config struct {
 	Environment string
 	GRPCPort    int
 
+	// Port of the admin HTTP endpoint (state snapshots, /metrics),
+	// 9090 unless ADMIN_PORT overrides it; 0 = disabled
+	AdminPort int
+
+	// Bearer token required by the admin endpoint's write operations
+	// (backfill); empty leaves them disabled
+	AdminToken string
+
+	// Persist per-chain checkpoints to Redis and resume from them on restart
+	PersistCheckpoints bool
+
+	// Keep running per-address, per-token net balances in Redis and emit a
+	// balance_delta event for every change
+	EmitBalanceDeltas bool
+
+	// Emit the confirmed phase of a transfer only once per EventID, even if
+	// a reorg re-mines it at another height; ConfirmedDedupeSize bounds the
+	// EventIDs remembered (0 = default)
+	DedupeConfirmedEvents bool
+	ConfirmedDedupeSize   int
+
+	// Emit a detected event only once per EventID and block, so block
+	// ranges scanned twice (backfill overlapping live polling, catch-up
+	// after a restart) don't deliver it again; EventDedupeSize bounds the
+	// EventIDs remembered (0 = default)
+	DedupeEvents    bool
+	EventDedupeSize int
+
+	// Persist addresses added at runtime to Redis (a set per chain) and
+	// reload them on startup, merged with WatchedAddresses
+	PersistWatchedAddresses bool
+
+	// Load per-token policies from the token_policies table at DATABASE_URL,
+	// refreshed every TokenPolicyRefreshInterval
+	TokenPolicies              bool
+	TokenPolicyRefreshInterval time.Duration
+
+	// How long lame duck mode waits for in-flight blocks/handlers on shutdown
+	ShutdownDrainTimeout time.Duration
+
 	// Database
 	Database DatabaseConfig
 
@@ -21,6 +66,91 @@ type Config struct {
 
 	// Watched addresses (comma-separated in env)
 	WatchedAddresses []string
+
+	// Chains each watched address applies to ("addr=1|137,..." in env);
+	// addresses not listed are watched on every chain of their format
+	WatchedAddressChains map[string][]uint64
+
+	// Routing tags per address ("addr=tag1|tag2,..." in env)
+	AddressTags map[string][]string
+
+	// Handler retry policies keyed by event type, with a fallback default
+	EventRetryPolicies map[string]RetryPolicy
+	DefaultRetryPolicy RetryPolicy
+
+	// Interval of per-address address_summary rollups (0 = disabled)
+	AddressSummaryInterval time.Duration
+
+	// Per-address event cap: at most AddressEventLimit events per watched
+	// address per AddressEventWindow (0 = off). Excess events are dropped,
+	// or every AddressThrottleSampleEvery-th one is kept
+	AddressEventLimit          int
+	AddressEventWindow         time.Duration
+	AddressThrottleSampleEvery int
+
+	// Global cap on concurrent handler executions across chains (0 = unlimited)
+	HandlerConcurrency int
+
+	// Bounded handler dispatch: deliveries are queued for a fixed pool of
+	// workers (0 workers = HandlerConcurrency workers if set, otherwise a
+	// goroutine per delivery). A full queue blocks for
+	// DispatchEnqueueTimeout, then drops the delivery.
+	DispatchWorkers        int
+	DispatchQueueSize      int
+	DispatchEnqueueTimeout time.Duration
+
+	// What ChainEvent.PartitionKey orders events by: the watched address
+	// (default), the token contract, or the chain
+	PartitionBy string
+
+	// Per-subscriber event buffer of SubscribeEvents; slower subscribers are dropped
+	StreamBufferSize int
+
+	// Deliver phases of the same event (pending, confirmed, milestones) to
+	// each handler in dispatch order
+	OrderedPhases bool
+
+	// Re-check confirmation milestones of all chains on this interval,
+	// concurrently across chains, instead of inline with each chain's
+	// polling (0 = inline)
+	ConfirmationCheckInterval time.Duration
+	// Chains re-checked at once (0 = 4)
+	ConfirmationCheckConcurrency int
+
+	// Chain watchers are constructed concurrently, each within
+	// ChainStartupTimeout; chains that fail are retried in the background
+	// every ChainStartRetryInterval (0 = not retried)
+	ChainStartupTimeout     time.Duration
+	ChainStartRetryInterval time.Duration
+}
+
+// Partition key modes (Config.PartitionBy)
+const (
+	PartitionByAddress = "address"
+	PartitionByToken   = "token"
+	PartitionByChain   = "chain"
+)
+
+// RetryPolicy 事件处理器失败时的重试策略
+type RetryPolicy struct {
+	MaxAttempts int           // total attempts including the first one
+	Backoff     time.Duration // initial backoff, doubled after each failed attempt
+}
+
+// TokenInfo 代币注册表条目 (TOKEN_REGISTRY)
+type TokenInfo struct {
+	Symbol   string
+	Decimals uint8
+}
+
+// TokenPolicy 单个代币的确认与发出策略 (token_policies 表)
+type TokenPolicy struct {
+	ChainID       uint64
+	Token         string   // contract address, or asset ID for TRC-10
+	MinAmount     *big.Int // smallest transfer emitted in base units, nil = any
+	Confirmations uint64   // confirmations required, 0 = the chain's
+	Enabled       bool     // false drops the token's transfers
+	Allowlisted   bool     // once any token on a chain is, only those are emitted
 }
 
 type DatabaseConfig struct {
@@ -43,21 +173,309 @@ type ChainConfig struct {
 	StartBlock    uint64
 	Confirmations uint64
 	Type          string // "evm" or "tron"
+
+	// ResolveTokenDecimals looks up token decimals (TRON constant call) to
+	// populate ChainEvent.NormalizedValue
+	ResolveTokenDecimals bool
+
+	// TokenRegistry maps token contracts (TRC-10: asset IDs) to their symbol
+	// and decimals, populating ChainEvent.TokenSymbol and NormalizedValue
+	// without any lookup; it takes precedence over ResolveTokenDecimals
+	TokenRegistry map[string]TokenInfo
+
+	// TransferEventSigs overrides the Transfer topic0 signatures to match
+	// (hex, empty = standard Transfer(address,address,uint256))
+	TransferEventSigs []string
+
+	// IncludeFeeInfo attaches the transaction fee and payer to transfer
+	// events (EVM costs one extra receipt and transaction fetch per tx)
+	IncludeFeeInfo bool
+
+	// ConfirmationMilestones re-emits tracked events as they reach each of
+	// these confirmation depths (e.g. 1,6,12,64; empty = emit once)
+	ConfirmationMilestones []uint64
+
+	// EmitConfirmedEvents re-emits events detected below the required
+	// confirmations once they reach it, with Confirmed set
+	EmitConfirmedEvents bool
+
+	// ScopedTokens enables token-scoped mode: every transfer of these token
+	// contracts is emitted, tagged with whether it touches a watched address
+	ScopedTokens []string
+
+	// WatchedTokens, when non-empty, limits transfers to these token
+	// contracts: logs emitted by any other contract are skipped. Entries
+	// apply to the chains of their address format (empty = all tokens)
+	WatchedTokens []string
+
+	// DropUnwatchedTransfers drops token-scoped transfers that don't touch a
+	// watched address, leaving only deposit detection
+	DropUnwatchedTransfers bool
+
+	// AddressFormat is the form of the addresses in emitted EVM events:
+	// AddressFormatChecksum (default) or AddressFormatLowercase. TRON
+	// addresses are always Base58
+	AddressFormat string
+
+	// EmitZeroAddressTransfers emits transfers whose from and to are both the
+	// zero address (neither a mint nor a burn) flagged, instead of dropping
+	// them; either way they are counted as anomalies
+	EmitZeroAddressTransfers bool
+
+	// StartupTimeout bounds how long the watcher waits, with backoff, for
+	// the RPC endpoint to become reachable at startup
+	StartupTimeout time.Duration
+
+	// PollInterval is how often the watcher polls for new blocks (TRON, and
+	// EVM chains without a WebSocket subscription); zero = the chain's block
+	// time
+	PollInterval time.Duration
+
+	// UseWebSocket subscribes to Transfer logs over WSURL instead of polling
+	// (EVM only); polling takes over while the subscription is down
+	UseWebSocket bool
+
+	// LogRangeMin, LogRangeMax and LogRangeInitial bound how many blocks one
+	// eth_getLogs call covers while catching up: the range starts at
+	// LogRangeInitial, grows on fast responses and shrinks on errors (EVM
+	// only; LogRangeMax <= 1 = one call per block)
+	LogRangeMin     uint64
+	LogRangeMax     uint64
+	LogRangeInitial uint64
+
+	// BackfillRange is how many blocks one backfill step covers: one
+	// eth_getLogs call on EVM chains, so keep it within the provider's log
+	// range limit; on TRON only the progress reporting interval
+	BackfillRange uint64
+
+	// MinTransferValue skips fungible transfers below this many raw token
+	// units before they are dispatched, to cut dust (nil = emit all)
+	MinTransferValue *big.Int
+
+	// MinEventTimestamp drops events from blocks older than this cutoff,
+	// e.g. to skip stale deposits during a long backfill (zero = emit all)
+	MinEventTimestamp time.Time
+
+	// HaltBlocks flags the chain as halted when the head hasn't advanced for
+	// this many block times (0 = disabled)
+	HaltBlocks uint64
+
+	// ReorgHistorySize bounds the per-chain history of detected reorgs
+	ReorgHistorySize int
+
+	// RPCURLs are additional RPC endpoints; calls go to the healthiest of
+	// RPCURL and these, by recent error rate and latency
+	RPCURLs []string
+
+	// RPCEndpointCooldown is how long an endpoint's errors count against it;
+	// one benched by repeated failures is retried after it (0 = 30s)
+	RPCEndpointCooldown time.Duration
+
+	// RPCRetry retries block and transaction info fetches that fail with a
+	// transient error (timeout, 5xx, gRPC Unavailable); a block still failing
+	// after the last attempt is retried on the next tick (TRON only)
+	RPCRetry RetryPolicy
+
+	// RPCHealthCheckInterval is how often endpoints benched by repeated
+	// failures are probed; one that answers is used again at once, so a
+	// recovered primary takes back over (0 = wait out the cooldown)
+	RPCHealthCheckInterval time.Duration
+
+	// MinHandlerSlots reserves part of HandlerConcurrency for this chain so
+	// busy chains can't starve it
+	MinHandlerSlots int
+
+	// BadHeadAlertAfter escalates to an alert after this many consecutive
+	// head blocks with a zero or regressed timestamp (TRON)
+	BadHeadAlertAfter int
+
+	// CaptureUnknownLogs emits "unknown" events carrying the raw topics and
+	// data of unparseable logs that involve a watched address
+	CaptureUnknownLogs bool
+
+	// MaxMessageSize caps RPC response sizes in bytes (TRON gRPC receive
+	// limit, EVM WebSocket message limit)
+	MaxMessageSize int
+
+	// IndexFailedTxs emits "failed_tx" events for reverted transactions sent
+	// by a watched address, carrying the gas fee they were still charged (EVM)
+	IndexFailedTxs bool
+
+	// EmitBlockEvents emits a "block_processed" event per processed block
+	// with its hash, transaction count and number of matched events, a
+	// progress signal consumers can check for skipped blocks. Per chain,
+	// since it's noisy on fast chains
+	EmitBlockEvents bool
+
+	// ConfirmationMode decides when TRON events count as Confirmed: by block
+	// count, by the solidified block, or only when both agree (strictest)
+	ConfirmationMode string
+
+	// RPCProtocol is how TRON nodes are reached: RPCProtocolGRPC (default,
+	// RPCURL is host:port) or RPCProtocolHTTP (RPCURL is the HTTP API base
+	// URL, e.g. https://api.trongrid.io)
+	RPCProtocol string
+
+	// EmitMalformedTokenAddress emits TRON transfers whose token contract
+	// address has a malformed length, flagged and without TokenAddress,
+	// instead of dropping them
+	EmitMalformedTokenAddress bool
+
+	// MaxTxConcurrency caps the transaction infos fetched concurrently per
+	// TRON block (0 = default of 8)
+	MaxTxConcurrency int
+
+	// CallTimeout bounds every TRON node call; a call exceeding it fails
+	// with a timeout and is retried per RPCRetry (0 = SDK default)
+	CallTimeout time.Duration
+
+	// DecodeTransferCalldata decodes transfer/transferFrom call data of
+	// successful TRON TriggerSmartContract transactions that emitted no
+	// Transfer log. Expensive: every such transaction is decoded
+	DecodeTransferCalldata bool
+
+	// ConfirmedOnly only processes blocks that are already Confirmations
+	// deep, trading latency for events that are always final
+	ConfirmedOnly bool
+
+	// MaxPendingConfirmations caps the events tracked for confirmation
+	// milestones; block processing pauses while the cap is reached (0 = unbounded)
+	MaxPendingConfirmations int
+
+	// AddressPrefix is the TRON address prefix byte (0 = mainnet 0x41)
+	AddressPrefix byte
+
+	// RecoverPanics isolates the chain's loop: a panic while processing a
+	// block or polling is logged and counted, and the loop carries on with
+	// the next block instead of crashing the process
+	RecoverPanics bool
+
+	// TxInfoCacheSize bounds the LRU of TRON transaction infos reused when
+	// blocks are re-scanned within the confirmation window (0 = no cache)
+	TxInfoCacheSize int
+
+	// CheckTotalSupply flags transfers whose value exceeds the token's
+	// totalSupply() (cached with the other token metadata) as suspicious
+	CheckTotalSupply bool
+
+	// CheckpointConfirmations is how far the persisted checkpoint trails the
+	// processed head, so it only ever covers blocks a reorg can't replace
+	// (defaults to the larger of Confirmations and MaxConfirmations)
+	CheckpointConfirmations uint64
+
+	// MaxConfirmations bounds how far reorgs may raise the confirmation
+	// requirement: each reorg within AdaptiveConfirmationWindow adds its
+	// depth to Confirmations, up to this value (<= Confirmations = fixed)
+	MaxConfirmations           uint64
+	AdaptiveConfirmationWindow time.Duration
 }
 
+// Emitted EVM address forms (ChainConfig.AddressFormat)
+const (
+	AddressFormatChecksum  = "checksum"  // EIP-55 mixed case
+	AddressFormatLowercase = "lowercase" // all lowercase
+)
+
+// TRON node protocols (ChainConfig.RPCProtocol)
+const (
+	RPCProtocolGRPC = "grpc"
+	RPCProtocolHTTP = "http" // /wallet/* HTTP API, for providers without gRPC
+)
+
+// TRON confirmation modes (ChainConfig.ConfirmationMode)
+const (
+	ConfirmationModeBlocks     = "blocks"     // currentBlock - blockNum >= Confirmations
+	ConfirmationModeSolidified = "solidified" // blockNum <= solidified block
+	ConfirmationModeBoth       = "both"       // both of the above
+)
+
+// defaultAdminPort 管理端 HTTP 接口 (状态快照、/metrics) 默认端口，ADMIN_PORT=0 关闭
+const defaultAdminPort = 9090
+
 func Load() (*Config, error) {
 	port, _ := strconv.Atoi(getEnv("GRPC_PORT", "50052"))
+	adminPort, _ := strconv.Atoi(getEnv("ADMIN_PORT", strconv.Itoa(defaultAdminPort)))
+	confirmedDedupeSize, _ := strconv.Atoi(getEnv("CONFIRMED_DEDUPE_SIZE", "0"))
+	eventDedupeSize, _ := strconv.Atoi(getEnv("EVENT_DEDUPE_SIZE", "0"))
+	tronResolveDecimals := getEnv("TRON_RESOLVE_DECIMALS", "true") == "true"
+	includeFeeInfo := getEnv("INCLUDE_FEE_INFO", "false") == "true"
+	dropUnwatched := getEnv("DROP_UNWATCHED_TRANSFERS", "false") == "true"
+	emitZeroAddress := getEnv("EMIT_ZERO_ADDRESS_TRANSFERS", "false") == "true"
+	evmAddressFormat := getEnv("EVM_ADDRESS_FORMAT", AddressFormatChecksum)
+	startupTimeout := getEnvDuration("RPC_STARTUP_TIMEOUT", 30*time.Second)
+	minEventTimestamp := getEnvTime("MIN_EVENT_TIMESTAMP")
+	minTransferValue := getEnvBigInt("MIN_TRANSFER_VALUE")
+	tokenRegistry := parseTokenRegistry(getEnv("TOKEN_REGISTRY", ""))
+	var watchedTokens []string
+	if tokens := getEnv("WATCHED_TOKENS", ""); tokens != "" {
+		watchedTokens = strings.Split(tokens, ",")
+	}
+	haltBlocks, _ := strconv.ParseUint(getEnv("CHAIN_HALT_BLOCKS", "10"), 10, 64)
+	reorgHistorySize, _ := strconv.Atoi(getEnv("REORG_HISTORY_SIZE", "50"))
+	handlerConcurrency, _ := strconv.Atoi(getEnv("HANDLER_CONCURRENCY", "0"))
+	dispatchWorkers, _ := strconv.Atoi(getEnv("DISPATCH_WORKERS", "64"))
+	dispatchQueueSize, _ := strconv.Atoi(getEnv("DISPATCH_QUEUE_SIZE", "10000"))
+	addressEventLimit, _ := strconv.Atoi(getEnv("ADDRESS_EVENT_LIMIT", "0"))
+	addressThrottleSample, _ := strconv.Atoi(getEnv("ADDRESS_THROTTLE_SAMPLE_EVERY", "0"))
+	badHeadAlertAfter, _ := strconv.Atoi(getEnv("BAD_HEAD_ALERT_AFTER", "3"))
+	endpointCooldown := getEnvDuration("RPC_ENDPOINT_COOLDOWN", 30*time.Second)
+	endpointHealthCheck := getEnvDuration("RPC_HEALTH_CHECK_INTERVAL", 10*time.Second)
+	captureUnknown := getEnv("CAPTURE_UNKNOWN_LOGS", "false") == "true"
+	emitConfirmed := getEnv("EMIT_CONFIRMED_EVENTS", "false") == "true"
+	rpcRetry := parseRetryPolicy(getEnv("RPC_RETRY", "3:500ms"), RetryPolicy{MaxAttempts: 3, Backoff: 500 * time.Millisecond})
+	logRangeMin, _ := strconv.ParseUint(getEnv("LOG_RANGE_MIN", "1"), 10, 64)
+	logRangeMax, _ := strconv.ParseUint(getEnv("LOG_RANGE_MAX", "1"), 10, 64)
+	backfillRange, _ := strconv.ParseUint(getEnv("BACKFILL_RANGE", "1000"), 10, 64)
+	logRangeInitial, _ := strconv.ParseUint(getEnv("LOG_RANGE_INITIAL", "0"), 10, 64)
+	adaptiveWindow := getEnvDuration("ADAPTIVE_CONFIRMATION_WINDOW", time.Hour)
+	maxMessageSize, _ := strconv.Atoi(getEnv("RPC_MAX_MESSAGE_SIZE", "33554432"))
+	indexFailedTxs := getEnv("INDEX_FAILED_TXS", "false") == "true"
+	emitBlockEvents := getEnv("EMIT_BLOCK_EVENTS", "false") == "true"
+	recoverPanics := getEnv("RECOVER_PANICS", "true") == "true"
+	checkTotalSupply := getEnv("CHECK_TOTAL_SUPPLY", "false") == "true"
+	txInfoCacheSize, _ := strconv.Atoi(getEnv("TX_INFO_CACHE_SIZE", "10000"))
+	tronConfirmationMode := getEnv("TRON_CONFIRMATION_MODE", ConfirmationModeBlocks)
+	tronRPCProtocol := getEnv("TRON_RPC_PROTOCOL", RPCProtocolGRPC)
+	tronPollInterval := getEnvMillis("TRON_POLL_INTERVAL_MS", 0)
+	tronCallTimeout := getEnvDuration("TRON_CALL_TIMEOUT", 10*time.Second)
+	tronMaxTxConcurrency, _ := strconv.Atoi(getEnv("TRON_MAX_TX_CONCURRENCY", "8"))
+	emitMalformedToken := getEnv("EMIT_MALFORMED_TOKEN_ADDRESS", "false") == "true"
+	decodeTransferCalldata := getEnv("TRON_DECODE_TRANSFER_CALLDATA", "false") == "true"
+	confirmedOnly := getEnv("CONFIRMED_ONLY", "false") == "true"
+	maxPendingConfirmations, _ := strconv.Atoi(getEnv("MAX_PENDING_CONFIRMATIONS", "10000"))
+	streamBufferSize, _ := strconv.Atoi(getEnv("STREAM_BUFFER_SIZE", "256"))
+	confirmationCheckConcurrency, _ := strconv.Atoi(getEnv("CONFIRMATION_CHECK_CONCURRENCY", "4"))
 	redisDB, _ := strconv.Atoi(getEnv("REDIS_DB", "0"))
 
-	// Parse watched addresses
+	// Parse watched addresses (格式在链配置加载后校验)
 	watchedAddrs := []string{}
 	if addrs := getEnv("WATCHED_ADDRESSES", ""); addrs != "" {
-		watchedAddrs = strings.Split(addrs, ",")
+		for _, addr := range strings.Split(addrs, ",") {
+			addr = strings.TrimSpace(addr)
+			if addr == "" {
+				continue
+			}
+			watchedAddrs = append(watchedAddrs, addr)
+		}
 	}
 
+	defaultRetry := parseRetryPolicy(getEnv("EVENT_RETRY_DEFAULT", "3:1s"), RetryPolicy{MaxAttempts: 3, Backoff: time.Second})
+
 	cfg := &Config{
-		Environment: getEnv("ENVIRONMENT", "development"),
-		GRPCPort:    port,
+		Environment:                getEnv("ENVIRONMENT", "development"),
+		GRPCPort:                   port,
+		AdminPort:                  adminPort,
+		AdminToken:                 getEnv("ADMIN_TOKEN", ""),
+		PersistCheckpoints:         getEnv("PERSIST_CHECKPOINTS", "false") == "true",
+		EmitBalanceDeltas:          getEnv("EMIT_BALANCE_DELTAS", "false") == "true",
+		PersistWatchedAddresses:    getEnv("PERSIST_WATCHED_ADDRESSES", "false") == "true",
+		DedupeConfirmedEvents:      getEnv("DEDUPE_CONFIRMED_EVENTS", "false") == "true",
+		ConfirmedDedupeSize:        confirmedDedupeSize,
+		DedupeEvents:               getEnv("DEDUPE_EVENTS", "false") == "true",
+		EventDedupeSize:            eventDedupeSize,
+		TokenPolicies:              getEnv("TOKEN_POLICIES", "false") == "true",
+		TokenPolicyRefreshInterval: getEnvDuration("TOKEN_POLICY_REFRESH_INTERVAL", time.Minute),
+		ShutdownDrainTimeout:       getEnvDuration("SHUTDOWN_DRAIN_TIMEOUT", 10*time.Second),
 		Database: DatabaseConfig{
 			URL: getEnv("DATABASE_URL", ""),
 		},
@@ -67,7 +485,30 @@ func Load() (*Config, error) {
 			DB:         redisDB,
 			TLSEnabled: getEnv("REDIS_TLS_ENABLED", "false") == "true",
 		},
-		WatchedAddresses: watchedAddrs,
+		WatchedAddresses:     watchedAddrs,
+		WatchedAddressChains: parseAddressChains(getEnv("WATCHED_ADDRESS_CHAINS", "")),
+		AddressTags:          parseAddressTags(getEnv("ADDRESS_TAGS", "")),
+		EventRetryPolicies:   parseRetryPolicies(getEnv("EVENT_RETRY_POLICIES", ""), defaultRetry),
+		DefaultRetryPolicy:   defaultRetry,
+
+		AddressSummaryInterval: getEnvDuration("ADDRESS_SUMMARY_INTERVAL", 0),
+		HandlerConcurrency:     handlerConcurrency,
+		DispatchWorkers:        dispatchWorkers,
+		DispatchQueueSize:      dispatchQueueSize,
+		DispatchEnqueueTimeout: getEnvDuration("DISPATCH_ENQUEUE_TIMEOUT", time.Second),
+		PartitionBy:            getEnv("PARTITION_KEY", PartitionByAddress),
+		StreamBufferSize:       streamBufferSize,
+		OrderedPhases:          getEnv("ORDERED_PHASES", "true") == "true",
+
+		AddressEventLimit:          addressEventLimit,
+		AddressEventWindow:         getEnvDuration("ADDRESS_EVENT_WINDOW", time.Second),
+		AddressThrottleSampleEvery: addressThrottleSample,
+
+		ConfirmationCheckInterval:    getEnvDuration("CONFIRMATION_CHECK_INTERVAL", 0),
+		ConfirmationCheckConcurrency: confirmationCheckConcurrency,
+
+		ChainStartupTimeout:     getEnvDuration("CHAIN_STARTUP_TIMEOUT", time.Minute),
+		ChainStartRetryInterval: getEnvDuration("CHAIN_START_RETRY_INTERVAL", 30*time.Second),
 		Chains: map[uint64]ChainConfig{
 			// ——— EVM Chains ———
 			1: {
@@ -110,6 +551,27 @@ func Load() (*Config, error) {
 				Confirmations: 12,
 				Type:          "evm",
 			},
+			// ——— EVM Testnets ———
+			11155111: {
+				ChainID:       11155111,
+				Name:          "Sepolia",
+				RPCURL:        getEnv("SEPOLIA_RPC_URL", "https://ethereum-sepolia-rpc.publicnode.com"),
+				WSURL:         getEnv("SEPOLIA_WS_URL", "wss://ethereum-sepolia-rpc.publicnode.com"),
+				ExplorerURL:   "https://sepolia.etherscan.io",
+				StartBlock:    0,
+				Confirmations: 3,
+				Type:          "evm",
+			},
+			17000: {
+				ChainID:       17000,
+				Name:          "Holesky",
+				RPCURL:        getEnv("HOLESKY_RPC_URL", "https://ethereum-holesky-rpc.publicnode.com"),
+				WSURL:         getEnv("HOLESKY_WS_URL", "wss://ethereum-holesky-rpc.publicnode.com"),
+				ExplorerURL:   "https://holesky.etherscan.io",
+				StartBlock:    0,
+				Confirmations: 3,
+				Type:          "evm",
+			},
 			// ——— TRON Chains ———
 			728126428: {
 				ChainID:       728126428,
@@ -119,6 +581,16 @@ func Load() (*Config, error) {
 				StartBlock:    0,
 				Confirmations: 19, // ~57 seconds (3s blocks)
 				Type:          "tron",
+
+				ResolveTokenDecimals: tronResolveDecimals,
+				ConfirmationMode:     tronConfirmationMode,
+				RPCProtocol:          tronRPCProtocol,
+				PollInterval:         tronPollInterval,
+				CallTimeout:          tronCallTimeout,
+				MaxTxConcurrency:     tronMaxTxConcurrency,
+
+				EmitMalformedTokenAddress: emitMalformedToken,
+				DecodeTransferCalldata:    decodeTransferCalldata,
 			},
 			3448148188: {
 				ChainID:       3448148188,
@@ -128,10 +600,190 @@ func Load() (*Config, error) {
 				StartBlock:    0,
 				Confirmations: 19,
 				Type:          "tron",
+
+				ResolveTokenDecimals: tronResolveDecimals,
+				ConfirmationMode:     tronConfirmationMode,
+				RPCProtocol:          tronRPCProtocol,
+				PollInterval:         tronPollInterval,
+				CallTimeout:          tronCallTimeout,
+				MaxTxConcurrency:     tronMaxTxConcurrency,
+
+				EmitMalformedTokenAddress: emitMalformedToken,
+				DecodeTransferCalldata:    decodeTransferCalldata,
 			},
 		},
 	}
 
+	// 链配置文件 (可选): CHAINS_CONFIG_FILE 中的链覆盖内置配置的同名字段，
+	// 新链直接加入。文件优先于全局开关，按链环境变量 (X_<chainID>) 优先于文件
+	var chainNodes map[uint64]*yaml.Node
+	if path := getEnv("CHAINS_CONFIG_FILE", ""); path != "" {
+		nodes, err := loadChainsFile(path)
+		if err != nil {
+			return nil, err
+		}
+		chainNodes = nodes
+		for chainID := range nodes {
+			if _, ok := cfg.Chains[chainID]; !ok {
+				cfg.Chains[chainID] = ChainConfig{ChainID: chainID}
+			}
+		}
+	}
+
+	// 应用全局开关 (INCLUDE_FEE_INFO, DROP_UNWATCHED_TRANSFERS, RPC_STARTUP_TIMEOUT,
+	// MIN_EVENT_TIMESTAMP, CHAIN_HALT_BLOCKS, REORG_HISTORY_SIZE, BAD_HEAD_ALERT_AFTER,
+	// CAPTURE_UNKNOWN_LOGS, RPC_MAX_MESSAGE_SIZE, INDEX_FAILED_TXS, CONFIRMED_ONLY,
+	// MAX_PENDING_CONFIRMATIONS, EMIT_BLOCK_EVENTS, RPC_ENDPOINT_COOLDOWN, RECOVER_PANICS,
+	// TX_INFO_CACHE_SIZE, ADAPTIVE_CONFIRMATION_WINDOW, BACKFILL_RANGE)，
+	// 并按链覆盖 Transfer 事件签名:
+	// TRANSFER_EVENT_SIGS_<chainID>=sig1,sig2
+	for chainID, chain := range cfg.Chains {
+		chain.IncludeFeeInfo = includeFeeInfo
+		chain.CheckTotalSupply = checkTotalSupply
+		chain.DropUnwatchedTransfers = dropUnwatched
+		chain.EmitZeroAddressTransfers = emitZeroAddress
+		chain.AddressFormat = evmAddressFormat
+		chain.StartupTimeout = startupTimeout
+		chain.MinEventTimestamp = minEventTimestamp
+		chain.MinTransferValue = minTransferValue
+		chain.TokenRegistry = tokenRegistry[chainID]
+		chain.WatchedTokens = watchedTokens
+		chain.HaltBlocks = haltBlocks
+		chain.ReorgHistorySize = reorgHistorySize
+		chain.BadHeadAlertAfter = badHeadAlertAfter
+		chain.CaptureUnknownLogs = captureUnknown
+		chain.EmitConfirmedEvents = emitConfirmed
+		chain.LogRangeMin = logRangeMin
+		chain.LogRangeMax = logRangeMax
+		chain.LogRangeInitial = logRangeInitial
+		chain.BackfillRange = backfillRange
+		chain.MaxMessageSize = maxMessageSize
+		chain.IndexFailedTxs = indexFailedTxs
+		chain.EmitBlockEvents = emitBlockEvents
+		chain.ConfirmedOnly = confirmedOnly
+		chain.MaxPendingConfirmations = maxPendingConfirmations
+		chain.RPCEndpointCooldown = endpointCooldown
+		chain.RPCHealthCheckInterval = endpointHealthCheck
+		chain.RPCRetry = rpcRetry
+		chain.RecoverPanics = recoverPanics
+		chain.TxInfoCacheSize = txInfoCacheSize
+		chain.AdaptiveConfirmationWindow = adaptiveWindow
+		if node, ok := chainNodes[chainID]; ok {
+			if err := decodeChain(node, &chain); err != nil {
+				return nil, fmt.Errorf("chains config file: %w", err)
+			}
+		}
+		if sigs := getEnv(fmt.Sprintf("TRANSFER_EVENT_SIGS_%d", chainID), ""); sigs != "" {
+			chain.TransferEventSigs = strings.Split(sigs, ",")
+		}
+		// 确认里程碑: CONFIRMATION_MILESTONES_<chainID>=1,6,12,64
+		if milestones := getEnv(fmt.Sprintf("CONFIRMATION_MILESTONES_%d", chainID), ""); milestones != "" {
+			chain.ConfirmationMilestones = parseUint64List(milestones)
+		}
+		// 按链保留的处理器并发槽位: MIN_HANDLER_SLOTS_<chainID>=n
+		if slots, err := strconv.Atoi(getEnv(fmt.Sprintf("MIN_HANDLER_SLOTS_%d", chainID), "0")); err == nil {
+			chain.MinHandlerSlots = slots
+		}
+		// TRON 地址前缀 (hex): ADDRESS_PREFIX_<chainID>=a0
+		if prefix, err := strconv.ParseUint(getEnv(fmt.Sprintf("ADDRESS_PREFIX_%d", chainID), "0"), 16, 8); err == nil {
+			chain.AddressPrefix = byte(prefix)
+		}
+		// 备用 RPC 端点: RPC_URLS_<chainID>=url1,url2
+		if urls := getEnv(fmt.Sprintf("RPC_URLS_%d", chainID), ""); urls != "" {
+			chain.RPCURLs = strings.Split(urls, ",")
+		}
+		// RPC 地址也可直接配置为逗号分隔列表 (如 TRON_RPC_URL=a,b)，首个为主端点
+		if primary, rest, ok := strings.Cut(chain.RPCURL, ","); ok {
+			chain.RPCURL = strings.TrimSpace(primary)
+			chain.RPCURLs = append(strings.Split(rest, ","), chain.RPCURLs...)
+		}
+		// 按链覆盖瞬时 RPC 错误重试策略: RPC_RETRY_<chainID>=attempts:backoff
+		if spec := getEnv(fmt.Sprintf("RPC_RETRY_%d", chainID), ""); spec != "" {
+			chain.RPCRetry = parseRetryPolicy(spec, rpcRetry)
+		}
+		// 按链覆盖代币白名单: WATCHED_TOKENS_<chainID>=token1,token2
+		if tokens := getEnv(fmt.Sprintf("WATCHED_TOKENS_%d", chainID), ""); tokens != "" {
+			chain.WatchedTokens = strings.Split(tokens, ",")
+		}
+		// 按代币监听模式: SCOPED_TOKENS_<chainID>=token1,token2
+		if tokens := getEnv(fmt.Sprintf("SCOPED_TOKENS_%d", chainID), ""); tokens != "" {
+			chain.ScopedTokens = strings.Split(tokens, ",")
+		}
+		// 重组频繁时确认数自动上调的上限: MAX_CONFIRMATIONS_<chainID>=n
+		if ceiling, err := strconv.ParseUint(getEnv(fmt.Sprintf("MAX_CONFIRMATIONS_%d", chainID), ""), 10, 64); err == nil {
+			chain.MaxConfirmations = ceiling
+		}
+		// 按节点能力覆盖 TRON 交易信息并发数: MAX_TX_CONCURRENCY_<chainID>=n
+		if concurrency, err := strconv.Atoi(getEnv(fmt.Sprintf("MAX_TX_CONCURRENCY_%d", chainID), "")); err == nil {
+			chain.MaxTxConcurrency = concurrency
+		}
+		// 无检查点时的起始区块 (0 = 链头): START_BLOCK_<chainID>=n
+		if start, err := strconv.ParseUint(getEnv(fmt.Sprintf("START_BLOCK_%d", chainID), ""), 10, 64); err == nil {
+			chain.StartBlock = start
+		}
+		// WebSocket 日志订阅模式 (仅 EVM): USE_WEBSOCKET_<chainID>=true
+		if getEnv(fmt.Sprintf("USE_WEBSOCKET_%d", chainID), "false") == "true" {
+			chain.UseWebSocket = true
+		}
+		// 按节点限制覆盖 eth_getLogs 自适应区块范围:
+		// LOG_RANGE_MIN_<chainID>=n, LOG_RANGE_MAX_<chainID>=n, LOG_RANGE_INITIAL_<chainID>=n
+		if n, err := strconv.ParseUint(getEnv(fmt.Sprintf("LOG_RANGE_MIN_%d", chainID), ""), 10, 64); err == nil {
+			chain.LogRangeMin = n
+		}
+		if n, err := strconv.ParseUint(getEnv(fmt.Sprintf("LOG_RANGE_MAX_%d", chainID), ""), 10, 64); err == nil {
+			chain.LogRangeMax = n
+		}
+		if n, err := strconv.ParseUint(getEnv(fmt.Sprintf("LOG_RANGE_INITIAL_%d", chainID), ""), 10, 64); err == nil {
+			chain.LogRangeInitial = n
+		}
+		// 按节点日志范围限制覆盖回填步长: BACKFILL_RANGE_<chainID>=n
+		if n, err := strconv.ParseUint(getEnv(fmt.Sprintf("BACKFILL_RANGE_%d", chainID), ""), 10, 64); err == nil {
+			chain.BackfillRange = n
+		}
+		// 按链覆盖小额转账过滤阈值 (原始单位): MIN_TRANSFER_VALUE_<chainID>=n
+		if value := getEnvBigInt(fmt.Sprintf("MIN_TRANSFER_VALUE_%d", chainID)); value != nil {
+			chain.MinTransferValue = value
+		}
+		// 按链覆盖轮询间隔: POLL_INTERVAL_MS_<chainID>=ms
+		if interval := getEnvMillis(fmt.Sprintf("POLL_INTERVAL_MS_%d", chainID), 0); interval > 0 {
+			chain.PollInterval = interval
+		}
+		// 持久化检查点落后链头的区块数 (未在链配置文件中设置时默认等于可能的最大确认数):
+		// CHECKPOINT_CONFIRMATIONS_<chainID>=n
+		if chain.CheckpointConfirmations == 0 {
+			chain.CheckpointConfirmations = max(chain.Confirmations, chain.MaxConfirmations)
+		}
+		if lag, err := strconv.ParseUint(getEnv(fmt.Sprintf("CHECKPOINT_CONFIRMATIONS_%d", chainID), ""), 10, 64); err == nil {
+			chain.CheckpointConfirmations = lag
+		}
+		// 按链覆盖 TRON 节点协议: RPC_PROTOCOL_<chainID>=grpc|http
+		if protocol := getEnv(fmt.Sprintf("RPC_PROTOCOL_%d", chainID), ""); protocol != "" {
+			chain.RPCProtocol = protocol
+		}
+		// 按链开关区块进度事件 (出块快的链上较多): EMIT_BLOCK_EVENTS_<chainID>=true|false
+		if emit := getEnv(fmt.Sprintf("EMIT_BLOCK_EVENTS_%d", chainID), ""); emit != "" {
+			chain.EmitBlockEvents = emit == "true"
+		}
+		// 按链覆盖 panic 隔离: RECOVER_PANICS_<chainID>=false
+		if isolate := getEnv(fmt.Sprintf("RECOVER_PANICS_%d", chainID), ""); isolate != "" {
+			chain.RecoverPanics = isolate == "true"
+		}
+		cfg.Chains[chainID] = chain
+	}
+
+	for chainID, chain := range cfg.Chains {
+		if err := validateChain(chainID, chain); err != nil {
+			return nil, fmt.Errorf("invalid chain config: %w", err)
+		}
+	}
+
+	// 格式错误的监控地址直接启动失败，并指出具体条目 (TRON 地址按链的 AddressPrefix 校验)
+	for _, addr := range cfg.WatchedAddresses {
+		if err := validateWatchedAddress(addr, cfg.Chains); err != nil {
+			return nil, fmt.Errorf("invalid WATCHED_ADDRESSES entry %q: %w", addr, err)
+		}
+	}
+
 	return cfg, nil
 }
 
@@ -141,3 +793,151 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// getEnvDuration 解析 Go duration 格式的环境变量 (如 "10s", "500ms")，无效时使用默认值
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if d, err := time.ParseDuration(value); err == nil && d > 0 {
+			return d
+		}
+	}
+	return defaultValue
+}
+
+// getEnvMillis 解析以毫秒为单位的整数环境变量 (如 "1500")，无效时使用默认值
+func getEnvMillis(key string, defaultValue time.Duration) time.Duration {
+	if ms, err := strconv.ParseInt(os.Getenv(key), 10, 64); err == nil && ms > 0 {
+		return time.Duration(ms) * time.Millisecond
+	}
+	return defaultValue
+}
+
+// getEnvTime 解析 RFC3339 时间 (如 "2024-01-01T00:00:00Z")，未设置或格式错误时为零值
+func getEnvTime(key string) time.Time {
+	if value := os.Getenv(key); value != "" {
+		if t, err := time.Parse(time.RFC3339, value); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// parseTokenRegistry 解析 "chainID:token=symbol:decimals,..." 格式的代币注册表
+// (如 "1:0xdAC17F958D2ee523a2206206994597C13D831ec7=USDT:6")，格式错误的条目被忽略
+func parseTokenRegistry(value string) map[uint64]map[string]TokenInfo {
+	registry := make(map[uint64]map[string]TokenInfo)
+	for _, entry := range strings.Split(value, ",") {
+		key, info, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok {
+			continue
+		}
+		chainStr, token, ok := strings.Cut(key, ":")
+		if !ok || token == "" {
+			continue
+		}
+		chainID, err := strconv.ParseUint(chainStr, 10, 64)
+		if err != nil {
+			continue
+		}
+		symbol, decimalsStr, ok := strings.Cut(info, ":")
+		if !ok {
+			continue
+		}
+		decimals, err := strconv.ParseUint(decimalsStr, 10, 8)
+		if err != nil {
+			continue
+		}
+		if registry[chainID] == nil {
+			registry[chainID] = make(map[string]TokenInfo)
+		}
+		registry[chainID][token] = TokenInfo{Symbol: symbol, Decimals: uint8(decimals)}
+	}
+	return registry
+}
+
+// getEnvBigInt 解析十进制整数 (如代币原始单位)，未设置或格式错误时为 nil
+func getEnvBigInt(key string) *big.Int {
+	if value := os.Getenv(key); value != "" {
+		if n, ok := new(big.Int).SetString(strings.TrimSpace(value), 10); ok && n.Sign() >= 0 {
+			return n
+		}
+	}
+	return nil
+}
+
+// parseAddressTags 解析 "addr=tag1|tag2,addr2=tag3" 格式的地址路由标签
+func parseAddressTags(value string) map[string][]string {
+	tags := make(map[string][]string)
+	for _, entry := range strings.Split(value, ",") {
+		addr, list, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok || addr == "" {
+			continue
+		}
+		for _, tag := range strings.Split(list, "|") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				tags[addr] = append(tags[addr], tag)
+			}
+		}
+	}
+	return tags
+}
+
+// parseAddressChains 解析 "addr=1|137,addr2=8453" 格式的地址生效链
+func parseAddressChains(value string) map[string][]uint64 {
+	chains := make(map[string][]uint64)
+	for _, entry := range strings.Split(value, ",") {
+		addr, list, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok || addr == "" {
+			continue
+		}
+		if ids := parseUint64List(strings.ReplaceAll(list, "|", ",")); len(ids) > 0 {
+			chains[addr] = append(chains[addr], ids...)
+		}
+	}
+	return chains
+}
+
+// parseUint64List 解析逗号分隔的正整数列表，忽略无法解析的条目
+func parseUint64List(value string) []uint64 {
+	var out []uint64
+	for _, entry := range strings.Split(value, ",") {
+		n, err := strconv.ParseUint(strings.TrimSpace(entry), 10, 64)
+		if err != nil || n == 0 {
+			continue
+		}
+		out = append(out, n)
+	}
+	return out
+}
+
+// parseRetryPolicies 解析 "event_type=attempts:backoff,..." 格式的重试策略
+// (如 "trc20_transfer=8:2s,heartbeat=1:0s")，格式错误的条目使用默认策略
+func parseRetryPolicies(value string, fallback RetryPolicy) map[string]RetryPolicy {
+	policies := make(map[string]RetryPolicy)
+	for _, entry := range strings.Split(value, ",") {
+		eventType, spec, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok || eventType == "" {
+			continue
+		}
+		policies[eventType] = parseRetryPolicy(spec, fallback)
+	}
+	return policies
+}
+
+// parseRetryPolicy 解析 "attempts:backoff" 格式 (如 "3:1s")
+func parseRetryPolicy(spec string, fallback RetryPolicy) RetryPolicy {
+	attemptsStr, backoffStr, _ := strings.Cut(spec, ":")
+	attempts, err := strconv.Atoi(attemptsStr)
+	if err != nil || attempts < 1 {
+		return fallback
+	}
+	policy := RetryPolicy{MaxAttempts: attempts}
+	if backoffStr != "" {
+		backoff, err := time.ParseDuration(backoffStr)
+		if err != nil || backoff < 0 {
+			return fallback
+		}
+		policy.Backoff = backoff
+	}
+	return policy
+}
@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/protocol-bank/event-indexer/internal/watcher"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdminHandler_Snapshot(t *testing.T) {
+	admin := NewAdminHandler(func() watcher.IndexerSnapshot {
+		return watcher.IndexerSnapshot{
+			Chains: map[uint64]watcher.ChainSnapshot{
+				1: {ChainID: 1, Name: "Ethereum", Checkpoint: 100, WatchedAddresses: 3},
+			},
+			RecoveredPanics: 2,
+		}
+	}, prometheus.NewRegistry(), nil, "")
+
+	rec := httptest.NewRecorder()
+	admin.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/snapshot", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	var got watcher.IndexerSnapshot
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+	assert.Equal(t, uint64(100), got.Chains[1].Checkpoint)
+	assert.Equal(t, 3, got.Chains[1].WatchedAddresses)
+	assert.Equal(t, uint64(2), got.RecoveredPanics)
+
+	rec = httptest.NewRecorder()
+	admin.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/debug/snapshot", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
+
+func TestAdminHandler_Metrics(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	counter := prometheus.NewCounter(prometheus.CounterOpts{Name: "indexer_test_total", Help: "Test counter."})
+	reg.MustRegister(counter)
+	counter.Add(3)
+
+	rec := httptest.NewRecorder()
+	NewAdminHandler(func() watcher.IndexerSnapshot { return watcher.IndexerSnapshot{} }, reg, nil, "").
+		ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "indexer_test_total 3")
+}
+
+func TestAdminHandler_Backfill(t *testing.T) {
+	var started [][3]uint64
+	admin := NewAdminHandler(func() watcher.IndexerSnapshot { return watcher.IndexerSnapshot{} }, prometheus.NewRegistry(),
+		func(chainID, from, to uint64) error {
+			if chainID != 1 {
+				return fmt.Errorf("unknown chain %d", chainID)
+			}
+			started = append(started, [3]uint64{chainID, from, to})
+			return nil
+		}, "s3cret")
+
+	post := func(target string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, target, nil)
+		req.Header.Set("Authorization", "Bearer s3cret")
+		admin.ServeHTTP(rec, req)
+		return rec
+	}
+	assert.Equal(t, http.StatusAccepted, post("/admin/backfill?chain_id=1&from=100&to=200").Code)
+	assert.Equal(t, [][3]uint64{{1, 100, 200}}, started)
+
+	rec := post("/admin/backfill?chain_id=56&from=100&to=200")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "unknown chain 56")
+	assert.Equal(t, http.StatusBadRequest, post("/admin/backfill?chain_id=1&from=100").Code)
+	assert.Len(t, started, 1)
+
+	// A missing or wrong token is rejected
+	for _, auth := range []string{"", "Bearer wrong", "s3cret"} {
+		rec = httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/admin/backfill?chain_id=1&from=100&to=200", nil)
+		req.Header.Set("Authorization", auth)
+		admin.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code, auth)
+	}
+	assert.Len(t, started, 1)
+
+	// Not registered without a backfill function or a token
+	for _, admin := range []http.Handler{
+		NewAdminHandler(func() watcher.IndexerSnapshot { return watcher.IndexerSnapshot{} }, prometheus.NewRegistry(), nil, "s3cret"),
+		NewAdminHandler(func() watcher.IndexerSnapshot { return watcher.IndexerSnapshot{} }, prometheus.NewRegistry(),
+			func(chainID, from, to uint64) error { return nil }, ""),
+	} {
+		rec = httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/admin/backfill?chain_id=1&from=100&to=200", nil)
+		req.Header.Set("Authorization", "Bearer s3cret")
+		admin.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	}
+}
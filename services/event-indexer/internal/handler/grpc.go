@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"github.com/protocol-bank/event-indexer/internal/pb"
+	"github.com/protocol-bank/event-indexer/internal/watcher"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// IndexerServer gRPC 服务实现，未实现的方法返回 Unimplemented
+type IndexerServer struct {
+	pb.UnimplementedIndexerServiceServer
+	broker *EventBroker
+}
+
+// RegisterIndexerServer 注册 gRPC 服务
+func RegisterIndexerServer(s *grpc.Server, broker *EventBroker) {
+	pb.RegisterIndexerServiceServer(s, &IndexerServer{broker: broker})
+	log.Info().Msg("Indexer gRPC server registered")
+}
+
+// SubscribeEvents 订阅实时事件流，按链/事件类型/地址在服务端过滤。
+// 订阅期间 broker 为该流注册一个事件处理器，客户端断开后自动注销。
+// 跟不上的订阅者会被断开 (ResourceExhausted)，不会阻塞事件分发。
+func (s *IndexerServer) SubscribeEvents(req *pb.StreamEventsRequest, stream grpc.ServerStreamingServer[pb.ChainEvent]) error {
+	return s.subscribe(StreamFilter{
+		ChainIDs:   req.GetChainIds(),
+		EventTypes: req.GetEventTypes(),
+		Addresses:  req.GetAddresses(),
+	}, protoEventStream{stream})
+}
+
+// StreamEvents 是 SubscribeEvents 的旧名称，保留给已有客户端。
+func (s *IndexerServer) StreamEvents(req *pb.StreamEventsRequest, stream grpc.ServerStreamingServer[pb.ChainEvent]) error {
+	return s.SubscribeEvents(req, stream)
+}
+
+func (s *IndexerServer) subscribe(filter StreamFilter, stream EventStream) error {
+	log.Info().
+		Interface("chain_ids", filter.ChainIDs).
+		Strs("event_types", filter.EventTypes).
+		Int("addresses", len(filter.Addresses)).
+		Msg("Event stream subscriber connected")
+	err := s.broker.Subscribe(filter, stream)
+	log.Info().Err(err).Msg("Event stream subscriber disconnected")
+	return err
+}
+
+// protoEventStream 将 watcher 事件转换为 pb.ChainEvent 后发送
+type protoEventStream struct {
+	grpc.ServerStreamingServer[pb.ChainEvent]
+}
+
+func (s protoEventStream) Send(event *watcher.ChainEvent) error {
+	return s.ServerStreamingServer.Send(toProtoEvent(event))
+}
+
+// protoEventTypes 索引器事件类型到 pb.EventType 的映射，其余为 UNSPECIFIED (见 event_kind)
+var protoEventTypes = map[string]pb.EventType{
+	"transfer":                      pb.EventType_EVENT_TYPE_TRANSFER,
+	"trc20_transfer":                pb.EventType_EVENT_TYPE_TRANSFER,
+	watcher.EventTypeTRXTransfer:    pb.EventType_EVENT_TYPE_TRANSFER,
+	watcher.EventTypeTRC10Transfer:  pb.EventType_EVENT_TYPE_TRANSFER,
+	watcher.EventTypeERC721Transfer: pb.EventType_EVENT_TYPE_TRANSFER,
+	watcher.EventTypeERC1155Single:  pb.EventType_EVENT_TYPE_TRANSFER,
+	watcher.EventTypeERC1155Batch:   pb.EventType_EVENT_TYPE_TRANSFER,
+}
+
+// toProtoEvent 将 watcher 事件转换为 gRPC 消息
+func toProtoEvent(event *watcher.ChainEvent) *pb.ChainEvent {
+	return &pb.ChainEvent{
+		EventId:       event.EventID,
+		ChainId:       event.ChainID,
+		ChainName:     event.ChainName,
+		EventType:     protoEventTypes[event.EventType],
+		EventKind:     event.EventType,
+		TxHash:        event.TxHash,
+		BlockNumber:   event.BlockNumber,
+		LogIndex:      uint32(event.LogIndex),
+		FromAddress:   event.FromAddress,
+		ToAddress:     event.ToAddress,
+		Value:         event.Value,
+		TokenAddress:  event.TokenAddress,
+		TokenSymbol:   event.TokenSymbol,
+		TokenAmount:   event.NormalizedValue,
+		Confirmations: event.Confirmations,
+		IsConfirmed:   event.Confirmed,
+		Timestamp:     timestamppb.New(event.Timestamp),
+		SchemaVersion: uint32(event.SchemaVersion),
+	}
+}
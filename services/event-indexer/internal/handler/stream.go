@@ -0,0 +1,208 @@
+package handler
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/protocol-bank/event-indexer/internal/watcher"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// defaultStreamBuffer is the per-subscriber queue length when none is configured.
+const defaultStreamBuffer = 256
+
+// StreamFilter selects the events a subscriber receives. Empty fields match
+// everything; addresses match either side of a transfer.
+type StreamFilter struct {
+	ChainIDs   []uint64
+	EventTypes []string
+	Addresses  []string
+}
+
+// EventStream is the server side of a SubscribeEvents call: the generated
+// IndexerService_SubscribeEventsServer, adapted to send watcher events.
+type EventStream interface {
+	Send(event *watcher.ChainEvent) error
+	Context() context.Context
+}
+
+// subscriber is one SubscribeEvents call. Events are queued on a bounded
+// channel; a subscriber that lets it fill up is dropped.
+type subscriber struct {
+	chainIDs   map[uint64]bool
+	eventTypes map[string]bool
+	addresses  map[string]bool
+
+	events  chan *watcher.ChainEvent
+	dropped chan struct{} // closed when the broker drops this subscriber
+}
+
+// EventBroker fans watcher events out to live stream subscribers. Publishing
+// never blocks the watcher: a subscriber too slow to keep up with its buffer
+// is disconnected and counted, instead of stalling dispatch for everyone.
+type EventBroker struct {
+	mu         sync.RWMutex
+	subs       map[*subscriber]struct{}
+	bufferSize int
+	done       chan struct{} // closed by Close to end every subscription
+	closeOnce  sync.Once
+
+	droppedSubs atomic.Uint64
+}
+
+// NewEventBroker creates a broker with the given per-subscriber buffer.
+func NewEventBroker(bufferSize int) *EventBroker {
+	if bufferSize <= 0 {
+		bufferSize = defaultStreamBuffer
+	}
+	return &EventBroker{
+		subs:       make(map[*subscriber]struct{}),
+		bufferSize: bufferSize,
+		done:       make(chan struct{}),
+	}
+}
+
+// Close ends every subscription, current and future, with Unavailable so
+// the gRPC server can stop gracefully instead of waiting on open streams.
+// Later calls are no-ops.
+func (b *EventBroker) Close() {
+	b.closeOnce.Do(func() { close(b.done) })
+}
+
+// Publish queues an event for every matching subscriber. It has the
+// watcher.EventHandler signature so it can be registered with AddHandler.
+func (b *EventBroker) Publish(event *watcher.ChainEvent) error {
+	b.mu.RLock()
+	var slow []*subscriber
+	for sub := range b.subs {
+		if !sub.matches(event) {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+			slow = append(slow, sub)
+		}
+	}
+	b.mu.RUnlock()
+
+	for _, sub := range slow {
+		b.drop(sub)
+	}
+	return nil
+}
+
+// Subscribe streams matching events to stream until the client goes away
+// or falls too far behind, in which case ResourceExhausted is returned, or
+// the broker is closed, in which case Unavailable is returned.
+func (b *EventBroker) Subscribe(filter StreamFilter, stream EventStream) error {
+	sub := b.add(filter)
+	defer b.remove(sub)
+
+	ctx := stream.Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-sub.dropped:
+			return status.Error(codes.ResourceExhausted, "subscriber too slow, events dropped")
+		case <-b.done:
+			return status.Error(codes.Unavailable, "server shutting down")
+		case event := <-sub.events:
+			if err := stream.Send(event); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// DroppedSubscribers returns how many subscribers were disconnected for
+// falling behind.
+func (b *EventBroker) DroppedSubscribers() uint64 {
+	return b.droppedSubs.Load()
+}
+
+// Subscribers returns the number of connected subscribers.
+func (b *EventBroker) Subscribers() int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return len(b.subs)
+}
+
+func (b *EventBroker) add(filter StreamFilter) *subscriber {
+	sub := &subscriber{
+		events:  make(chan *watcher.ChainEvent, b.bufferSize),
+		dropped: make(chan struct{}),
+	}
+	if len(filter.ChainIDs) > 0 {
+		sub.chainIDs = make(map[uint64]bool, len(filter.ChainIDs))
+		for _, id := range filter.ChainIDs {
+			sub.chainIDs[id] = true
+		}
+	}
+	if len(filter.EventTypes) > 0 {
+		sub.eventTypes = make(map[string]bool, len(filter.EventTypes))
+		for _, t := range filter.EventTypes {
+			sub.eventTypes[t] = true
+		}
+	}
+	if len(filter.Addresses) > 0 {
+		sub.addresses = make(map[string]bool, len(filter.Addresses))
+		for _, addr := range filter.Addresses {
+			sub.addresses[addressKey(addr)] = true
+		}
+	}
+
+	b.mu.Lock()
+	b.subs[sub] = struct{}{}
+	b.mu.Unlock()
+	return sub
+}
+
+func (b *EventBroker) remove(sub *subscriber) {
+	b.mu.Lock()
+	delete(b.subs, sub)
+	b.mu.Unlock()
+}
+
+// drop disconnects a subscriber whose buffer is full. Publish calls may
+// race to drop the same subscriber; only the first counts.
+func (b *EventBroker) drop(sub *subscriber) {
+	b.mu.Lock()
+	_, ok := b.subs[sub]
+	delete(b.subs, sub)
+	b.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	close(sub.dropped)
+	b.droppedSubs.Add(1)
+	log.Warn().Int("buffer", b.bufferSize).Msg("Dropping slow event stream subscriber")
+}
+
+func (s *subscriber) matches(event *watcher.ChainEvent) bool {
+	if s.chainIDs != nil && !s.chainIDs[event.ChainID] {
+		return false
+	}
+	if s.eventTypes != nil && !s.eventTypes[event.EventType] {
+		return false
+	}
+	if s.addresses != nil && !s.addresses[addressKey(event.FromAddress)] && !s.addresses[addressKey(event.ToAddress)] {
+		return false
+	}
+	return true
+}
+
+// addressKey normalizes EVM hex addresses to lowercase; TRON Base58 is
+// case-sensitive and kept as-is.
+func addressKey(addr string) string {
+	if strings.HasPrefix(addr, "0x") || strings.HasPrefix(addr, "0X") {
+		return strings.ToLower(addr)
+	}
+	return addr
+}
@@ -0,0 +1,185 @@
+package handler
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/protocol-bank/event-indexer/internal/pb"
+	"github.com/protocol-bank/event-indexer/internal/watcher"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// mockStream records sent events; block, if set, stalls Send until closed.
+type mockStream struct {
+	ctx   context.Context
+	block chan struct{}
+
+	mu   sync.Mutex
+	sent []*watcher.ChainEvent
+}
+
+func (m *mockStream) Send(event *watcher.ChainEvent) error {
+	if m.block != nil {
+		<-m.block
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent = append(m.sent, event)
+	return nil
+}
+
+func (m *mockStream) Context() context.Context { return m.ctx }
+
+func (m *mockStream) txs() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	txs := make([]string, 0, len(m.sent))
+	for _, event := range m.sent {
+		txs = append(txs, event.TxHash)
+	}
+	return txs
+}
+
+func subscribe(t *testing.T, broker *EventBroker, filter StreamFilter, stream *mockStream) <-chan error {
+	t.Helper()
+	done := make(chan error, 1)
+	before := broker.Subscribers()
+	go func() { done <- (&IndexerServer{broker: broker}).subscribe(filter, stream) }()
+	require.Eventually(t, func() bool { return broker.Subscribers() == before+1 }, time.Second, time.Millisecond)
+	return done
+}
+
+func TestEventBroker_FiltersServerSide(t *testing.T) {
+	broker := NewEventBroker(16)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	stream := &mockStream{ctx: ctx}
+	done := subscribe(t, broker, StreamFilter{
+		ChainIDs:   []uint64{1},
+		EventTypes: []string{"transfer"},
+		Addresses:  []string{"0xABCDEF0000000000000000000000000000000001"},
+	}, stream)
+
+	events := []*watcher.ChainEvent{
+		{ChainID: 1, EventType: "transfer", TxHash: "in", ToAddress: "0xabcdef0000000000000000000000000000000001"},
+		{ChainID: 1, EventType: "transfer", TxHash: "out", FromAddress: "0xAbCdEf0000000000000000000000000000000001"},
+		{ChainID: 137, EventType: "transfer", TxHash: "other-chain", ToAddress: "0xabcdef0000000000000000000000000000000001"},
+		{ChainID: 1, EventType: "unknown", TxHash: "other-type", ToAddress: "0xabcdef0000000000000000000000000000000001"},
+		{ChainID: 1, EventType: "transfer", TxHash: "other-address", ToAddress: "0x0000000000000000000000000000000000000002"},
+	}
+	for _, event := range events {
+		require.NoError(t, broker.Publish(event))
+	}
+
+	assert.Eventually(t, func() bool { return len(stream.txs()) == 2 }, time.Second, time.Millisecond)
+	assert.Equal(t, []string{"in", "out"}, stream.txs())
+
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+	assert.Zero(t, broker.Subscribers())
+}
+
+func TestEventBroker_DropsSlowSubscriber(t *testing.T) {
+	broker := NewEventBroker(2)
+
+	fast := &mockStream{ctx: context.Background()}
+	fastDone := subscribe(t, broker, StreamFilter{}, fast)
+
+	slow := &mockStream{ctx: context.Background(), block: make(chan struct{})}
+	slowDone := subscribe(t, broker, StreamFilter{}, slow)
+
+	// The slow subscriber holds one event in Send and two in its buffer;
+	// publishing never blocks on it
+	for i := 0; i < 10; i++ {
+		require.NoError(t, broker.Publish(&watcher.ChainEvent{ChainID: 1, TxHash: "tx"}))
+		time.Sleep(time.Millisecond)
+	}
+	close(slow.block)
+
+	err := <-slowDone
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	assert.Equal(t, uint64(1), broker.DroppedSubscribers())
+
+	assert.Eventually(t, func() bool { return len(fast.txs()) == 10 }, time.Second, time.Millisecond, "fast subscriber unaffected")
+	assert.Equal(t, 1, broker.Subscribers())
+
+	select {
+	case err := <-fastDone:
+		t.Fatalf("fast subscriber ended: %v", err)
+	default:
+	}
+}
+
+func TestEventBroker_CloseEndsSubscriptions(t *testing.T) {
+	broker := NewEventBroker(16)
+	done := subscribe(t, broker, StreamFilter{}, &mockStream{ctx: context.Background()})
+
+	broker.Close()
+	select {
+	case err := <-done:
+		assert.Equal(t, codes.Unavailable, status.Code(err))
+	case <-time.After(time.Second):
+		t.Fatal("subscriber still connected after Close")
+	}
+	assert.Zero(t, broker.Subscribers())
+
+	// Subscriptions after Close end at once; Close is idempotent
+	broker.Close()
+	err := (&IndexerServer{broker: broker}).subscribe(StreamFilter{}, &mockStream{ctx: context.Background()})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+}
+
+func TestSubscribeEvents_ReceivesDispatchedEvents(t *testing.T) {
+	broker := NewEventBroker(16)
+	var handler watcher.EventHandler = broker.Publish
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream := &mockStream{ctx: ctx}
+	done := subscribe(t, broker, StreamFilter{ChainIDs: []uint64{728126428}}, stream)
+
+	require.NoError(t, handler(&watcher.ChainEvent{ChainID: 728126428, TxHash: "tron"}))
+	require.NoError(t, handler(&watcher.ChainEvent{ChainID: 1, TxHash: "eth"}))
+	assert.Eventually(t, func() bool { return len(stream.txs()) == 1 }, time.Second, time.Millisecond)
+	assert.Equal(t, []string{"tron"}, stream.txs())
+
+	// Dropping the client deregisters it; later events go nowhere
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+	assert.Zero(t, broker.Subscribers())
+	require.NoError(t, handler(&watcher.ChainEvent{ChainID: 728126428, TxHash: "late"}))
+	assert.Equal(t, []string{"tron"}, stream.txs())
+}
+
+func TestToProtoEvent(t *testing.T) {
+	ts := time.Unix(1700000000, 0)
+	msg := toProtoEvent(&watcher.ChainEvent{
+		EventID:         "evt",
+		ChainID:         1,
+		EventType:       watcher.EventTypeERC721Transfer,
+		TxHash:          "0xabc",
+		BlockNumber:     42,
+		LogIndex:        3,
+		NormalizedValue: "1.5",
+		Confirmations:   12,
+		Confirmed:       true,
+		Timestamp:       ts,
+		SchemaVersion:   watcher.EventSchemaVersion,
+	})
+	assert.Equal(t, pb.EventType_EVENT_TYPE_TRANSFER, msg.GetEventType())
+	assert.Equal(t, watcher.EventTypeERC721Transfer, msg.GetEventKind())
+	assert.Equal(t, uint32(3), msg.GetLogIndex())
+	assert.Equal(t, "1.5", msg.GetTokenAmount())
+	assert.True(t, msg.GetIsConfirmed())
+	assert.True(t, msg.GetTimestamp().AsTime().Equal(ts))
+	assert.Equal(t, uint32(watcher.EventSchemaVersion), msg.GetSchemaVersion())
+
+	// Indexer-only kinds have no pb.EventType and keep their name in event_kind
+	msg = toProtoEvent(&watcher.ChainEvent{EventType: watcher.EventTypeBalanceDelta})
+	assert.Equal(t, pb.EventType_EVENT_TYPE_UNSPECIFIED, msg.GetEventType())
+	assert.Equal(t, watcher.EventTypeBalanceDelta, msg.GetEventKind())
+}
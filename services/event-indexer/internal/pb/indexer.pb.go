@@ -0,0 +1,1322 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: indexer.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// 链上事件类型
+type EventType int32
+
+const (
+	EventType_EVENT_TYPE_UNSPECIFIED           EventType = 0
+	EventType_EVENT_TYPE_TRANSFER              EventType = 1 // 转账
+	EventType_EVENT_TYPE_APPROVAL              EventType = 2 // 授权
+	EventType_EVENT_TYPE_SWAP                  EventType = 3 // Swap
+	EventType_EVENT_TYPE_BRIDGE                EventType = 4 // 跨链桥
+	EventType_EVENT_TYPE_CONTRACT_DEPLOY       EventType = 5 // 合约部署
+	EventType_EVENT_TYPE_MULTISIG_SUBMISSION   EventType = 6 // 多签提交
+	EventType_EVENT_TYPE_MULTISIG_CONFIRMATION EventType = 7 // 多签确认
+	EventType_EVENT_TYPE_MULTISIG_EXECUTION    EventType = 8 // 多签执行
+)
+
+// Enum value maps for EventType.
+var (
+	EventType_name = map[int32]string{
+		0: "EVENT_TYPE_UNSPECIFIED",
+		1: "EVENT_TYPE_TRANSFER",
+		2: "EVENT_TYPE_APPROVAL",
+		3: "EVENT_TYPE_SWAP",
+		4: "EVENT_TYPE_BRIDGE",
+		5: "EVENT_TYPE_CONTRACT_DEPLOY",
+		6: "EVENT_TYPE_MULTISIG_SUBMISSION",
+		7: "EVENT_TYPE_MULTISIG_CONFIRMATION",
+		8: "EVENT_TYPE_MULTISIG_EXECUTION",
+	}
+	EventType_value = map[string]int32{
+		"EVENT_TYPE_UNSPECIFIED":           0,
+		"EVENT_TYPE_TRANSFER":              1,
+		"EVENT_TYPE_APPROVAL":              2,
+		"EVENT_TYPE_SWAP":                  3,
+		"EVENT_TYPE_BRIDGE":                4,
+		"EVENT_TYPE_CONTRACT_DEPLOY":       5,
+		"EVENT_TYPE_MULTISIG_SUBMISSION":   6,
+		"EVENT_TYPE_MULTISIG_CONFIRMATION": 7,
+		"EVENT_TYPE_MULTISIG_EXECUTION":    8,
+	}
+)
+
+func (x EventType) Enum() *EventType {
+	p := new(EventType)
+	*p = x
+	return p
+}
+
+func (x EventType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (EventType) Descriptor() protoreflect.EnumDescriptor {
+	return file_indexer_proto_enumTypes[0].Descriptor()
+}
+
+func (EventType) Type() protoreflect.EnumType {
+	return &file_indexer_proto_enumTypes[0]
+}
+
+func (x EventType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use EventType.Descriptor instead.
+func (EventType) EnumDescriptor() ([]byte, []int) {
+	return file_indexer_proto_rawDescGZIP(), []int{0}
+}
+
+// 风险等级
+type RiskLevel int32
+
+const (
+	RiskLevel_RISK_LEVEL_UNSPECIFIED RiskLevel = 0
+	RiskLevel_RISK_LEVEL_LOW         RiskLevel = 1
+	RiskLevel_RISK_LEVEL_MEDIUM      RiskLevel = 2
+	RiskLevel_RISK_LEVEL_HIGH        RiskLevel = 3
+	RiskLevel_RISK_LEVEL_CRITICAL    RiskLevel = 4
+)
+
+// Enum value maps for RiskLevel.
+var (
+	RiskLevel_name = map[int32]string{
+		0: "RISK_LEVEL_UNSPECIFIED",
+		1: "RISK_LEVEL_LOW",
+		2: "RISK_LEVEL_MEDIUM",
+		3: "RISK_LEVEL_HIGH",
+		4: "RISK_LEVEL_CRITICAL",
+	}
+	RiskLevel_value = map[string]int32{
+		"RISK_LEVEL_UNSPECIFIED": 0,
+		"RISK_LEVEL_LOW":         1,
+		"RISK_LEVEL_MEDIUM":      2,
+		"RISK_LEVEL_HIGH":        3,
+		"RISK_LEVEL_CRITICAL":    4,
+	}
+)
+
+func (x RiskLevel) Enum() *RiskLevel {
+	p := new(RiskLevel)
+	*p = x
+	return p
+}
+
+func (x RiskLevel) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (RiskLevel) Descriptor() protoreflect.EnumDescriptor {
+	return file_indexer_proto_enumTypes[1].Descriptor()
+}
+
+func (RiskLevel) Type() protoreflect.EnumType {
+	return &file_indexer_proto_enumTypes[1]
+}
+
+func (x RiskLevel) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use RiskLevel.Descriptor instead.
+func (RiskLevel) EnumDescriptor() ([]byte, []int) {
+	return file_indexer_proto_rawDescGZIP(), []int{1}
+}
+
+// 订阅请求
+type SubscribeRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Addresses      []string               `protobuf:"bytes,1,rep,name=addresses,proto3" json:"addresses,omitempty"`                                                    // 要监听��地址
+	ChainIds       []uint64               `protobuf:"varint,2,rep,packed,name=chain_ids,json=chainIds,proto3" json:"chain_ids,omitempty"`                              // 链ID列表
+	EventTypes     []EventType            `protobuf:"varint,3,rep,packed,name=event_types,json=eventTypes,proto3,enum=indexer.EventType" json:"event_types,omitempty"` // 事件类型过滤
+	IncludePending bool                   `protobuf:"varint,4,opt,name=include_pending,json=includePending,proto3" json:"include_pending,omitempty"`                   // 是否包含待确认交易
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *SubscribeRequest) Reset() {
+	*x = SubscribeRequest{}
+	mi := &file_indexer_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubscribeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubscribeRequest) ProtoMessage() {}
+
+func (x *SubscribeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_indexer_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubscribeRequest.ProtoReflect.Descriptor instead.
+func (*SubscribeRequest) Descriptor() ([]byte, []int) {
+	return file_indexer_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *SubscribeRequest) GetAddresses() []string {
+	if x != nil {
+		return x.Addresses
+	}
+	return nil
+}
+
+func (x *SubscribeRequest) GetChainIds() []uint64 {
+	if x != nil {
+		return x.ChainIds
+	}
+	return nil
+}
+
+func (x *SubscribeRequest) GetEventTypes() []EventType {
+	if x != nil {
+		return x.EventTypes
+	}
+	return nil
+}
+
+func (x *SubscribeRequest) GetIncludePending() bool {
+	if x != nil {
+		return x.IncludePending
+	}
+	return false
+}
+
+// 实时事件流请求 (各字段为空表示不过滤)
+type StreamEventsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ChainIds      []uint64               `protobuf:"varint,1,rep,packed,name=chain_ids,json=chainIds,proto3" json:"chain_ids,omitempty"` // 链ID列表
+	EventTypes    []string               `protobuf:"bytes,2,rep,name=event_types,json=eventTypes,proto3" json:"event_types,omitempty"`   // 事件类型 (transfer, trc20_transfer, ...)
+	Addresses     []string               `protobuf:"bytes,3,rep,name=addresses,proto3" json:"addresses,omitempty"`                       // from/to 任一匹配即发送
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamEventsRequest) Reset() {
+	*x = StreamEventsRequest{}
+	mi := &file_indexer_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamEventsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamEventsRequest) ProtoMessage() {}
+
+func (x *StreamEventsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_indexer_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamEventsRequest.ProtoReflect.Descriptor instead.
+func (*StreamEventsRequest) Descriptor() ([]byte, []int) {
+	return file_indexer_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *StreamEventsRequest) GetChainIds() []uint64 {
+	if x != nil {
+		return x.ChainIds
+	}
+	return nil
+}
+
+func (x *StreamEventsRequest) GetEventTypes() []string {
+	if x != nil {
+		return x.EventTypes
+	}
+	return nil
+}
+
+func (x *StreamEventsRequest) GetAddresses() []string {
+	if x != nil {
+		return x.Addresses
+	}
+	return nil
+}
+
+// 链上事件
+type ChainEvent struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	EventId   string                 `protobuf:"bytes,1,opt,name=event_id,json=eventId,proto3" json:"event_id,omitempty"`
+	ChainId   uint64                 `protobuf:"varint,2,opt,name=chain_id,json=chainId,proto3" json:"chain_id,omitempty"`
+	ChainName string                 `protobuf:"bytes,3,opt,name=chain_name,json=chainName,proto3" json:"chain_name,omitempty"`
+	EventType EventType              `protobuf:"varint,4,opt,name=event_type,json=eventType,proto3,enum=indexer.EventType" json:"event_type,omitempty"`
+	// 交易信息
+	TxHash      string `protobuf:"bytes,5,opt,name=tx_hash,json=txHash,proto3" json:"tx_hash,omitempty"`
+	BlockNumber uint64 `protobuf:"varint,6,opt,name=block_number,json=blockNumber,proto3" json:"block_number,omitempty"`
+	TxIndex     uint32 `protobuf:"varint,7,opt,name=tx_index,json=txIndex,proto3" json:"tx_index,omitempty"`
+	LogIndex    uint32 `protobuf:"varint,8,opt,name=log_index,json=logIndex,proto3" json:"log_index,omitempty"`
+	// 参与方
+	FromAddress string `protobuf:"bytes,9,opt,name=from_address,json=fromAddress,proto3" json:"from_address,omitempty"`
+	ToAddress   string `protobuf:"bytes,10,opt,name=to_address,json=toAddress,proto3" json:"to_address,omitempty"`
+	// 金额信息
+	Value         string `protobuf:"bytes,11,opt,name=value,proto3" json:"value,omitempty"`                                   // 原生代币金额
+	TokenAddress  string `protobuf:"bytes,12,opt,name=token_address,json=tokenAddress,proto3" json:"token_address,omitempty"` // 代币地址 (如果是代币转账)
+	TokenSymbol   string `protobuf:"bytes,13,opt,name=token_symbol,json=tokenSymbol,proto3" json:"token_symbol,omitempty"`
+	TokenDecimals uint32 `protobuf:"varint,14,opt,name=token_decimals,json=tokenDecimals,proto3" json:"token_decimals,omitempty"`
+	TokenAmount   string `protobuf:"bytes,15,opt,name=token_amount,json=tokenAmount,proto3" json:"token_amount,omitempty"`
+	// 状态
+	Confirmations uint64                 `protobuf:"varint,16,opt,name=confirmations,proto3" json:"confirmations,omitempty"`
+	IsConfirmed   bool                   `protobuf:"varint,17,opt,name=is_confirmed,json=isConfirmed,proto3" json:"is_confirmed,omitempty"`
+	Timestamp     *timestamppb.Timestamp `protobuf:"bytes,18,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	// 索引器事件类型 (transfer, trc20_transfer, balance_delta, ...)，event_type 无对应值时为 UNSPECIFIED
+	EventKind string `protobuf:"bytes,19,opt,name=event_kind,json=eventKind,proto3" json:"event_kind,omitempty"`
+	// 事件结构版本 (与索引器 EventSchemaVersion 一致)，消费方据此兼容旧字段
+	SchemaVersion uint32 `protobuf:"varint,20,opt,name=schema_version,json=schemaVersion,proto3" json:"schema_version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChainEvent) Reset() {
+	*x = ChainEvent{}
+	mi := &file_indexer_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChainEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChainEvent) ProtoMessage() {}
+
+func (x *ChainEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_indexer_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChainEvent.ProtoReflect.Descriptor instead.
+func (*ChainEvent) Descriptor() ([]byte, []int) {
+	return file_indexer_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ChainEvent) GetEventId() string {
+	if x != nil {
+		return x.EventId
+	}
+	return ""
+}
+
+func (x *ChainEvent) GetChainId() uint64 {
+	if x != nil {
+		return x.ChainId
+	}
+	return 0
+}
+
+func (x *ChainEvent) GetChainName() string {
+	if x != nil {
+		return x.ChainName
+	}
+	return ""
+}
+
+func (x *ChainEvent) GetEventType() EventType {
+	if x != nil {
+		return x.EventType
+	}
+	return EventType_EVENT_TYPE_UNSPECIFIED
+}
+
+func (x *ChainEvent) GetTxHash() string {
+	if x != nil {
+		return x.TxHash
+	}
+	return ""
+}
+
+func (x *ChainEvent) GetBlockNumber() uint64 {
+	if x != nil {
+		return x.BlockNumber
+	}
+	return 0
+}
+
+func (x *ChainEvent) GetTxIndex() uint32 {
+	if x != nil {
+		return x.TxIndex
+	}
+	return 0
+}
+
+func (x *ChainEvent) GetLogIndex() uint32 {
+	if x != nil {
+		return x.LogIndex
+	}
+	return 0
+}
+
+func (x *ChainEvent) GetFromAddress() string {
+	if x != nil {
+		return x.FromAddress
+	}
+	return ""
+}
+
+func (x *ChainEvent) GetToAddress() string {
+	if x != nil {
+		return x.ToAddress
+	}
+	return ""
+}
+
+func (x *ChainEvent) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+func (x *ChainEvent) GetTokenAddress() string {
+	if x != nil {
+		return x.TokenAddress
+	}
+	return ""
+}
+
+func (x *ChainEvent) GetTokenSymbol() string {
+	if x != nil {
+		return x.TokenSymbol
+	}
+	return ""
+}
+
+func (x *ChainEvent) GetTokenDecimals() uint32 {
+	if x != nil {
+		return x.TokenDecimals
+	}
+	return 0
+}
+
+func (x *ChainEvent) GetTokenAmount() string {
+	if x != nil {
+		return x.TokenAmount
+	}
+	return ""
+}
+
+func (x *ChainEvent) GetConfirmations() uint64 {
+	if x != nil {
+		return x.Confirmations
+	}
+	return 0
+}
+
+func (x *ChainEvent) GetIsConfirmed() bool {
+	if x != nil {
+		return x.IsConfirmed
+	}
+	return false
+}
+
+func (x *ChainEvent) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+func (x *ChainEvent) GetEventKind() string {
+	if x != nil {
+		return x.EventKind
+	}
+	return ""
+}
+
+func (x *ChainEvent) GetSchemaVersion() uint32 {
+	if x != nil {
+		return x.SchemaVersion
+	}
+	return 0
+}
+
+// 历史记录请求
+type HistoryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Address       string                 `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	ChainId       uint64                 `protobuf:"varint,2,opt,name=chain_id,json=chainId,proto3" json:"chain_id,omitempty"`
+	FromTimestamp int64                  `protobuf:"varint,3,opt,name=from_timestamp,json=fromTimestamp,proto3" json:"from_timestamp,omitempty"`
+	ToTimestamp   int64                  `protobuf:"varint,4,opt,name=to_timestamp,json=toTimestamp,proto3" json:"to_timestamp,omitempty"`
+	Limit         int32                  `protobuf:"varint,5,opt,name=limit,proto3" json:"limit,omitempty"`
+	Offset        int32                  `protobuf:"varint,6,opt,name=offset,proto3" json:"offset,omitempty"`
+	EventTypes    []EventType            `protobuf:"varint,7,rep,packed,name=event_types,json=eventTypes,proto3,enum=indexer.EventType" json:"event_types,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HistoryRequest) Reset() {
+	*x = HistoryRequest{}
+	mi := &file_indexer_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HistoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HistoryRequest) ProtoMessage() {}
+
+func (x *HistoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_indexer_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HistoryRequest.ProtoReflect.Descriptor instead.
+func (*HistoryRequest) Descriptor() ([]byte, []int) {
+	return file_indexer_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *HistoryRequest) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *HistoryRequest) GetChainId() uint64 {
+	if x != nil {
+		return x.ChainId
+	}
+	return 0
+}
+
+func (x *HistoryRequest) GetFromTimestamp() int64 {
+	if x != nil {
+		return x.FromTimestamp
+	}
+	return 0
+}
+
+func (x *HistoryRequest) GetToTimestamp() int64 {
+	if x != nil {
+		return x.ToTimestamp
+	}
+	return 0
+}
+
+func (x *HistoryRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *HistoryRequest) GetOffset() int32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *HistoryRequest) GetEventTypes() []EventType {
+	if x != nil {
+		return x.EventTypes
+	}
+	return nil
+}
+
+// 历史记录响应
+type HistoryResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Events        []*ChainEvent          `protobuf:"bytes,1,rep,name=events,proto3" json:"events,omitempty"`
+	TotalCount    int32                  `protobuf:"varint,2,opt,name=total_count,json=totalCount,proto3" json:"total_count,omitempty"`
+	HasMore       bool                   `protobuf:"varint,3,opt,name=has_more,json=hasMore,proto3" json:"has_more,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HistoryResponse) Reset() {
+	*x = HistoryResponse{}
+	mi := &file_indexer_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HistoryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HistoryResponse) ProtoMessage() {}
+
+func (x *HistoryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_indexer_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HistoryResponse.ProtoReflect.Descriptor instead.
+func (*HistoryResponse) Descriptor() ([]byte, []int) {
+	return file_indexer_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *HistoryResponse) GetEvents() []*ChainEvent {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
+func (x *HistoryResponse) GetTotalCount() int32 {
+	if x != nil {
+		return x.TotalCount
+	}
+	return 0
+}
+
+func (x *HistoryResponse) GetHasMore() bool {
+	if x != nil {
+		return x.HasMore
+	}
+	return false
+}
+
+// 余额请求
+type BalanceRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Address        string                 `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	ChainIds       []uint64               `protobuf:"varint,2,rep,packed,name=chain_ids,json=chainIds,proto3" json:"chain_ids,omitempty"`
+	TokenAddresses []string               `protobuf:"bytes,3,rep,name=token_addresses,json=tokenAddresses,proto3" json:"token_addresses,omitempty"` // 空=只查原生代币
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *BalanceRequest) Reset() {
+	*x = BalanceRequest{}
+	mi := &file_indexer_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BalanceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BalanceRequest) ProtoMessage() {}
+
+func (x *BalanceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_indexer_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BalanceRequest.ProtoReflect.Descriptor instead.
+func (*BalanceRequest) Descriptor() ([]byte, []int) {
+	return file_indexer_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *BalanceRequest) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *BalanceRequest) GetChainIds() []uint64 {
+	if x != nil {
+		return x.ChainIds
+	}
+	return nil
+}
+
+func (x *BalanceRequest) GetTokenAddresses() []string {
+	if x != nil {
+		return x.TokenAddresses
+	}
+	return nil
+}
+
+// 余额响应
+type BalanceResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Balances      []*ChainBalance        `protobuf:"bytes,1,rep,name=balances,proto3" json:"balances,omitempty"`
+	TotalUsdValue string                 `protobuf:"bytes,2,opt,name=total_usd_value,json=totalUsdValue,proto3" json:"total_usd_value,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BalanceResponse) Reset() {
+	*x = BalanceResponse{}
+	mi := &file_indexer_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BalanceResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BalanceResponse) ProtoMessage() {}
+
+func (x *BalanceResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_indexer_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BalanceResponse.ProtoReflect.Descriptor instead.
+func (*BalanceResponse) Descriptor() ([]byte, []int) {
+	return file_indexer_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *BalanceResponse) GetBalances() []*ChainBalance {
+	if x != nil {
+		return x.Balances
+	}
+	return nil
+}
+
+func (x *BalanceResponse) GetTotalUsdValue() string {
+	if x != nil {
+		return x.TotalUsdValue
+	}
+	return ""
+}
+
+// 单链余额
+type ChainBalance struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	ChainId        uint64                 `protobuf:"varint,1,opt,name=chain_id,json=chainId,proto3" json:"chain_id,omitempty"`
+	ChainName      string                 `protobuf:"bytes,2,opt,name=chain_name,json=chainName,proto3" json:"chain_name,omitempty"`
+	NativeBalance  string                 `protobuf:"bytes,3,opt,name=native_balance,json=nativeBalance,proto3" json:"native_balance,omitempty"`
+	NativeSymbol   string                 `protobuf:"bytes,4,opt,name=native_symbol,json=nativeSymbol,proto3" json:"native_symbol,omitempty"`
+	NativeUsdValue string                 `protobuf:"bytes,5,opt,name=native_usd_value,json=nativeUsdValue,proto3" json:"native_usd_value,omitempty"`
+	Tokens         []*TokenBalance        `protobuf:"bytes,6,rep,name=tokens,proto3" json:"tokens,omitempty"`
+	ChainTotalUsd  string                 `protobuf:"bytes,7,opt,name=chain_total_usd,json=chainTotalUsd,proto3" json:"chain_total_usd,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ChainBalance) Reset() {
+	*x = ChainBalance{}
+	mi := &file_indexer_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChainBalance) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChainBalance) ProtoMessage() {}
+
+func (x *ChainBalance) ProtoReflect() protoreflect.Message {
+	mi := &file_indexer_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChainBalance.ProtoReflect.Descriptor instead.
+func (*ChainBalance) Descriptor() ([]byte, []int) {
+	return file_indexer_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ChainBalance) GetChainId() uint64 {
+	if x != nil {
+		return x.ChainId
+	}
+	return 0
+}
+
+func (x *ChainBalance) GetChainName() string {
+	if x != nil {
+		return x.ChainName
+	}
+	return ""
+}
+
+func (x *ChainBalance) GetNativeBalance() string {
+	if x != nil {
+		return x.NativeBalance
+	}
+	return ""
+}
+
+func (x *ChainBalance) GetNativeSymbol() string {
+	if x != nil {
+		return x.NativeSymbol
+	}
+	return ""
+}
+
+func (x *ChainBalance) GetNativeUsdValue() string {
+	if x != nil {
+		return x.NativeUsdValue
+	}
+	return ""
+}
+
+func (x *ChainBalance) GetTokens() []*TokenBalance {
+	if x != nil {
+		return x.Tokens
+	}
+	return nil
+}
+
+func (x *ChainBalance) GetChainTotalUsd() string {
+	if x != nil {
+		return x.ChainTotalUsd
+	}
+	return ""
+}
+
+// 代币余额
+type TokenBalance struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TokenAddress  string                 `protobuf:"bytes,1,opt,name=token_address,json=tokenAddress,proto3" json:"token_address,omitempty"`
+	Symbol        string                 `protobuf:"bytes,2,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	Decimals      uint32                 `protobuf:"varint,3,opt,name=decimals,proto3" json:"decimals,omitempty"`
+	Balance       string                 `protobuf:"bytes,4,opt,name=balance,proto3" json:"balance,omitempty"`
+	UsdValue      string                 `protobuf:"bytes,5,opt,name=usd_value,json=usdValue,proto3" json:"usd_value,omitempty"`
+	LogoUrl       string                 `protobuf:"bytes,6,opt,name=logo_url,json=logoUrl,proto3" json:"logo_url,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TokenBalance) Reset() {
+	*x = TokenBalance{}
+	mi := &file_indexer_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TokenBalance) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TokenBalance) ProtoMessage() {}
+
+func (x *TokenBalance) ProtoReflect() protoreflect.Message {
+	mi := &file_indexer_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TokenBalance.ProtoReflect.Descriptor instead.
+func (*TokenBalance) Descriptor() ([]byte, []int) {
+	return file_indexer_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *TokenBalance) GetTokenAddress() string {
+	if x != nil {
+		return x.TokenAddress
+	}
+	return ""
+}
+
+func (x *TokenBalance) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+func (x *TokenBalance) GetDecimals() uint32 {
+	if x != nil {
+		return x.Decimals
+	}
+	return 0
+}
+
+func (x *TokenBalance) GetBalance() string {
+	if x != nil {
+		return x.Balance
+	}
+	return ""
+}
+
+func (x *TokenBalance) GetUsdValue() string {
+	if x != nil {
+		return x.UsdValue
+	}
+	return ""
+}
+
+func (x *TokenBalance) GetLogoUrl() string {
+	if x != nil {
+		return x.LogoUrl
+	}
+	return ""
+}
+
+// 交易分析请求
+type AnalyzeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TxHash        string                 `protobuf:"bytes,1,opt,name=tx_hash,json=txHash,proto3" json:"tx_hash,omitempty"`
+	ChainId       uint64                 `protobuf:"varint,2,opt,name=chain_id,json=chainId,proto3" json:"chain_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AnalyzeRequest) Reset() {
+	*x = AnalyzeRequest{}
+	mi := &file_indexer_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AnalyzeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AnalyzeRequest) ProtoMessage() {}
+
+func (x *AnalyzeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_indexer_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AnalyzeRequest.ProtoReflect.Descriptor instead.
+func (*AnalyzeRequest) Descriptor() ([]byte, []int) {
+	return file_indexer_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *AnalyzeRequest) GetTxHash() string {
+	if x != nil {
+		return x.TxHash
+	}
+	return ""
+}
+
+func (x *AnalyzeRequest) GetChainId() uint64 {
+	if x != nil {
+		return x.ChainId
+	}
+	return 0
+}
+
+// 交易分析响应
+type AnalyzeResponse struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	TxHash          string                 `protobuf:"bytes,1,opt,name=tx_hash,json=txHash,proto3" json:"tx_hash,omitempty"`
+	RiskLevel       RiskLevel              `protobuf:"varint,2,opt,name=risk_level,json=riskLevel,proto3,enum=indexer.RiskLevel" json:"risk_level,omitempty"`
+	RiskFlags       []*RiskFlag            `protobuf:"bytes,3,rep,name=risk_flags,json=riskFlags,proto3" json:"risk_flags,omitempty"`
+	AnalysisSummary string                 `protobuf:"bytes,4,opt,name=analysis_summary,json=analysisSummary,proto3" json:"analysis_summary,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *AnalyzeResponse) Reset() {
+	*x = AnalyzeResponse{}
+	mi := &file_indexer_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AnalyzeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AnalyzeResponse) ProtoMessage() {}
+
+func (x *AnalyzeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_indexer_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AnalyzeResponse.ProtoReflect.Descriptor instead.
+func (*AnalyzeResponse) Descriptor() ([]byte, []int) {
+	return file_indexer_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *AnalyzeResponse) GetTxHash() string {
+	if x != nil {
+		return x.TxHash
+	}
+	return ""
+}
+
+func (x *AnalyzeResponse) GetRiskLevel() RiskLevel {
+	if x != nil {
+		return x.RiskLevel
+	}
+	return RiskLevel_RISK_LEVEL_UNSPECIFIED
+}
+
+func (x *AnalyzeResponse) GetRiskFlags() []*RiskFlag {
+	if x != nil {
+		return x.RiskFlags
+	}
+	return nil
+}
+
+func (x *AnalyzeResponse) GetAnalysisSummary() string {
+	if x != nil {
+		return x.AnalysisSummary
+	}
+	return ""
+}
+
+// 风险标记
+type RiskFlag struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	FlagType       string                 `protobuf:"bytes,1,opt,name=flag_type,json=flagType,proto3" json:"flag_type,omitempty"` // sanctioned_address, large_amount, etc.
+	Description    string                 `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	RelatedAddress string                 `protobuf:"bytes,3,opt,name=related_address,json=relatedAddress,proto3" json:"related_address,omitempty"`
+	Severity       RiskLevel              `protobuf:"varint,4,opt,name=severity,proto3,enum=indexer.RiskLevel" json:"severity,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *RiskFlag) Reset() {
+	*x = RiskFlag{}
+	mi := &file_indexer_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RiskFlag) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RiskFlag) ProtoMessage() {}
+
+func (x *RiskFlag) ProtoReflect() protoreflect.Message {
+	mi := &file_indexer_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RiskFlag.ProtoReflect.Descriptor instead.
+func (*RiskFlag) Descriptor() ([]byte, []int) {
+	return file_indexer_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *RiskFlag) GetFlagType() string {
+	if x != nil {
+		return x.FlagType
+	}
+	return ""
+}
+
+func (x *RiskFlag) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *RiskFlag) GetRelatedAddress() string {
+	if x != nil {
+		return x.RelatedAddress
+	}
+	return ""
+}
+
+func (x *RiskFlag) GetSeverity() RiskLevel {
+	if x != nil {
+		return x.Severity
+	}
+	return RiskLevel_RISK_LEVEL_UNSPECIFIED
+}
+
+var File_indexer_proto protoreflect.FileDescriptor
+
+const file_indexer_proto_rawDesc = "" +
+	"\n" +
+	"\rindexer.proto\x12\aindexer\x1a\x1fgoogle/protobuf/timestamp.proto\"\xab\x01\n" +
+	"\x10SubscribeRequest\x12\x1c\n" +
+	"\taddresses\x18\x01 \x03(\tR\taddresses\x12\x1b\n" +
+	"\tchain_ids\x18\x02 \x03(\x04R\bchainIds\x123\n" +
+	"\vevent_types\x18\x03 \x03(\x0e2\x12.indexer.EventTypeR\n" +
+	"eventTypes\x12'\n" +
+	"\x0finclude_pending\x18\x04 \x01(\bR\x0eincludePending\"q\n" +
+	"\x13StreamEventsRequest\x12\x1b\n" +
+	"\tchain_ids\x18\x01 \x03(\x04R\bchainIds\x12\x1f\n" +
+	"\vevent_types\x18\x02 \x03(\tR\n" +
+	"eventTypes\x12\x1c\n" +
+	"\taddresses\x18\x03 \x03(\tR\taddresses\"\xbb\x05\n" +
+	"\n" +
+	"ChainEvent\x12\x19\n" +
+	"\bevent_id\x18\x01 \x01(\tR\aeventId\x12\x19\n" +
+	"\bchain_id\x18\x02 \x01(\x04R\achainId\x12\x1d\n" +
+	"\n" +
+	"chain_name\x18\x03 \x01(\tR\tchainName\x121\n" +
+	"\n" +
+	"event_type\x18\x04 \x01(\x0e2\x12.indexer.EventTypeR\teventType\x12\x17\n" +
+	"\atx_hash\x18\x05 \x01(\tR\x06txHash\x12!\n" +
+	"\fblock_number\x18\x06 \x01(\x04R\vblockNumber\x12\x19\n" +
+	"\btx_index\x18\a \x01(\rR\atxIndex\x12\x1b\n" +
+	"\tlog_index\x18\b \x01(\rR\blogIndex\x12!\n" +
+	"\ffrom_address\x18\t \x01(\tR\vfromAddress\x12\x1d\n" +
+	"\n" +
+	"to_address\x18\n" +
+	" \x01(\tR\ttoAddress\x12\x14\n" +
+	"\x05value\x18\v \x01(\tR\x05value\x12#\n" +
+	"\rtoken_address\x18\f \x01(\tR\ftokenAddress\x12!\n" +
+	"\ftoken_symbol\x18\r \x01(\tR\vtokenSymbol\x12%\n" +
+	"\x0etoken_decimals\x18\x0e \x01(\rR\rtokenDecimals\x12!\n" +
+	"\ftoken_amount\x18\x0f \x01(\tR\vtokenAmount\x12$\n" +
+	"\rconfirmations\x18\x10 \x01(\x04R\rconfirmations\x12!\n" +
+	"\fis_confirmed\x18\x11 \x01(\bR\visConfirmed\x128\n" +
+	"\ttimestamp\x18\x12 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\x12\x1d\n" +
+	"\n" +
+	"event_kind\x18\x13 \x01(\tR\teventKind\x12%\n" +
+	"\x0eschema_version\x18\x14 \x01(\rR\rschemaVersion\"\xf2\x01\n" +
+	"\x0eHistoryRequest\x12\x18\n" +
+	"\aaddress\x18\x01 \x01(\tR\aaddress\x12\x19\n" +
+	"\bchain_id\x18\x02 \x01(\x04R\achainId\x12%\n" +
+	"\x0efrom_timestamp\x18\x03 \x01(\x03R\rfromTimestamp\x12!\n" +
+	"\fto_timestamp\x18\x04 \x01(\x03R\vtoTimestamp\x12\x14\n" +
+	"\x05limit\x18\x05 \x01(\x05R\x05limit\x12\x16\n" +
+	"\x06offset\x18\x06 \x01(\x05R\x06offset\x123\n" +
+	"\vevent_types\x18\a \x03(\x0e2\x12.indexer.EventTypeR\n" +
+	"eventTypes\"z\n" +
+	"\x0fHistoryResponse\x12+\n" +
+	"\x06events\x18\x01 \x03(\v2\x13.indexer.ChainEventR\x06events\x12\x1f\n" +
+	"\vtotal_count\x18\x02 \x01(\x05R\n" +
+	"totalCount\x12\x19\n" +
+	"\bhas_more\x18\x03 \x01(\bR\ahasMore\"p\n" +
+	"\x0eBalanceRequest\x12\x18\n" +
+	"\aaddress\x18\x01 \x01(\tR\aaddress\x12\x1b\n" +
+	"\tchain_ids\x18\x02 \x03(\x04R\bchainIds\x12'\n" +
+	"\x0ftoken_addresses\x18\x03 \x03(\tR\x0etokenAddresses\"l\n" +
+	"\x0fBalanceResponse\x121\n" +
+	"\bbalances\x18\x01 \x03(\v2\x15.indexer.ChainBalanceR\bbalances\x12&\n" +
+	"\x0ftotal_usd_value\x18\x02 \x01(\tR\rtotalUsdValue\"\x95\x02\n" +
+	"\fChainBalance\x12\x19\n" +
+	"\bchain_id\x18\x01 \x01(\x04R\achainId\x12\x1d\n" +
+	"\n" +
+	"chain_name\x18\x02 \x01(\tR\tchainName\x12%\n" +
+	"\x0enative_balance\x18\x03 \x01(\tR\rnativeBalance\x12#\n" +
+	"\rnative_symbol\x18\x04 \x01(\tR\fnativeSymbol\x12(\n" +
+	"\x10native_usd_value\x18\x05 \x01(\tR\x0enativeUsdValue\x12-\n" +
+	"\x06tokens\x18\x06 \x03(\v2\x15.indexer.TokenBalanceR\x06tokens\x12&\n" +
+	"\x0fchain_total_usd\x18\a \x01(\tR\rchainTotalUsd\"\xb9\x01\n" +
+	"\fTokenBalance\x12#\n" +
+	"\rtoken_address\x18\x01 \x01(\tR\ftokenAddress\x12\x16\n" +
+	"\x06symbol\x18\x02 \x01(\tR\x06symbol\x12\x1a\n" +
+	"\bdecimals\x18\x03 \x01(\rR\bdecimals\x12\x18\n" +
+	"\abalance\x18\x04 \x01(\tR\abalance\x12\x1b\n" +
+	"\tusd_value\x18\x05 \x01(\tR\busdValue\x12\x19\n" +
+	"\blogo_url\x18\x06 \x01(\tR\alogoUrl\"D\n" +
+	"\x0eAnalyzeRequest\x12\x17\n" +
+	"\atx_hash\x18\x01 \x01(\tR\x06txHash\x12\x19\n" +
+	"\bchain_id\x18\x02 \x01(\x04R\achainId\"\xba\x01\n" +
+	"\x0fAnalyzeResponse\x12\x17\n" +
+	"\atx_hash\x18\x01 \x01(\tR\x06txHash\x121\n" +
+	"\n" +
+	"risk_level\x18\x02 \x01(\x0e2\x12.indexer.RiskLevelR\triskLevel\x120\n" +
+	"\n" +
+	"risk_flags\x18\x03 \x03(\v2\x11.indexer.RiskFlagR\triskFlags\x12)\n" +
+	"\x10analysis_summary\x18\x04 \x01(\tR\x0fanalysisSummary\"\xa2\x01\n" +
+	"\bRiskFlag\x12\x1b\n" +
+	"\tflag_type\x18\x01 \x01(\tR\bflagType\x12 \n" +
+	"\vdescription\x18\x02 \x01(\tR\vdescription\x12'\n" +
+	"\x0frelated_address\x18\x03 \x01(\tR\x0erelatedAddress\x12.\n" +
+	"\bseverity\x18\x04 \x01(\x0e2\x12.indexer.RiskLevelR\bseverity*\x92\x02\n" +
+	"\tEventType\x12\x1a\n" +
+	"\x16EVENT_TYPE_UNSPECIFIED\x10\x00\x12\x17\n" +
+	"\x13EVENT_TYPE_TRANSFER\x10\x01\x12\x17\n" +
+	"\x13EVENT_TYPE_APPROVAL\x10\x02\x12\x13\n" +
+	"\x0fEVENT_TYPE_SWAP\x10\x03\x12\x15\n" +
+	"\x11EVENT_TYPE_BRIDGE\x10\x04\x12\x1e\n" +
+	"\x1aEVENT_TYPE_CONTRACT_DEPLOY\x10\x05\x12\"\n" +
+	"\x1eEVENT_TYPE_MULTISIG_SUBMISSION\x10\x06\x12$\n" +
+	" EVENT_TYPE_MULTISIG_CONFIRMATION\x10\a\x12!\n" +
+	"\x1dEVENT_TYPE_MULTISIG_EXECUTION\x10\b*\x80\x01\n" +
+	"\tRiskLevel\x12\x1a\n" +
+	"\x16RISK_LEVEL_UNSPECIFIED\x10\x00\x12\x12\n" +
+	"\x0eRISK_LEVEL_LOW\x10\x01\x12\x15\n" +
+	"\x11RISK_LEVEL_MEDIUM\x10\x02\x12\x13\n" +
+	"\x0fRISK_LEVEL_HIGH\x10\x03\x12\x17\n" +
+	"\x13RISK_LEVEL_CRITICAL\x10\x042\xba\x03\n" +
+	"\x0eIndexerService\x12D\n" +
+	"\x10SubscribeAddress\x12\x19.indexer.SubscribeRequest\x1a\x13.indexer.ChainEvent0\x01\x12F\n" +
+	"\x0fSubscribeEvents\x12\x1c.indexer.StreamEventsRequest\x1a\x13.indexer.ChainEvent0\x01\x12C\n" +
+	"\fStreamEvents\x12\x1c.indexer.StreamEventsRequest\x1a\x13.indexer.ChainEvent0\x01\x12J\n" +
+	"\x15GetTransactionHistory\x12\x17.indexer.HistoryRequest\x1a\x18.indexer.HistoryResponse\x12@\n" +
+	"\vGetBalances\x12\x17.indexer.BalanceRequest\x1a\x18.indexer.BalanceResponse\x12G\n" +
+	"\x12AnalyzeTransaction\x12\x17.indexer.AnalyzeRequest\x1a\x18.indexer.AnalyzeResponseB1Z/github.com/protocol-bank/services/proto/indexerb\x06proto3"
+
+var (
+	file_indexer_proto_rawDescOnce sync.Once
+	file_indexer_proto_rawDescData []byte
+)
+
+func file_indexer_proto_rawDescGZIP() []byte {
+	file_indexer_proto_rawDescOnce.Do(func() {
+		file_indexer_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_indexer_proto_rawDesc), len(file_indexer_proto_rawDesc)))
+	})
+	return file_indexer_proto_rawDescData
+}
+
+var file_indexer_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_indexer_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
+var file_indexer_proto_goTypes = []any{
+	(EventType)(0),                // 0: indexer.EventType
+	(RiskLevel)(0),                // 1: indexer.RiskLevel
+	(*SubscribeRequest)(nil),      // 2: indexer.SubscribeRequest
+	(*StreamEventsRequest)(nil),   // 3: indexer.StreamEventsRequest
+	(*ChainEvent)(nil),            // 4: indexer.ChainEvent
+	(*HistoryRequest)(nil),        // 5: indexer.HistoryRequest
+	(*HistoryResponse)(nil),       // 6: indexer.HistoryResponse
+	(*BalanceRequest)(nil),        // 7: indexer.BalanceRequest
+	(*BalanceResponse)(nil),       // 8: indexer.BalanceResponse
+	(*ChainBalance)(nil),          // 9: indexer.ChainBalance
+	(*TokenBalance)(nil),          // 10: indexer.TokenBalance
+	(*AnalyzeRequest)(nil),        // 11: indexer.AnalyzeRequest
+	(*AnalyzeResponse)(nil),       // 12: indexer.AnalyzeResponse
+	(*RiskFlag)(nil),              // 13: indexer.RiskFlag
+	(*timestamppb.Timestamp)(nil), // 14: google.protobuf.Timestamp
+}
+var file_indexer_proto_depIdxs = []int32{
+	0,  // 0: indexer.SubscribeRequest.event_types:type_name -> indexer.EventType
+	0,  // 1: indexer.ChainEvent.event_type:type_name -> indexer.EventType
+	14, // 2: indexer.ChainEvent.timestamp:type_name -> google.protobuf.Timestamp
+	0,  // 3: indexer.HistoryRequest.event_types:type_name -> indexer.EventType
+	4,  // 4: indexer.HistoryResponse.events:type_name -> indexer.ChainEvent
+	9,  // 5: indexer.BalanceResponse.balances:type_name -> indexer.ChainBalance
+	10, // 6: indexer.ChainBalance.tokens:type_name -> indexer.TokenBalance
+	1,  // 7: indexer.AnalyzeResponse.risk_level:type_name -> indexer.RiskLevel
+	13, // 8: indexer.AnalyzeResponse.risk_flags:type_name -> indexer.RiskFlag
+	1,  // 9: indexer.RiskFlag.severity:type_name -> indexer.RiskLevel
+	2,  // 10: indexer.IndexerService.SubscribeAddress:input_type -> indexer.SubscribeRequest
+	3,  // 11: indexer.IndexerService.SubscribeEvents:input_type -> indexer.StreamEventsRequest
+	3,  // 12: indexer.IndexerService.StreamEvents:input_type -> indexer.StreamEventsRequest
+	5,  // 13: indexer.IndexerService.GetTransactionHistory:input_type -> indexer.HistoryRequest
+	7,  // 14: indexer.IndexerService.GetBalances:input_type -> indexer.BalanceRequest
+	11, // 15: indexer.IndexerService.AnalyzeTransaction:input_type -> indexer.AnalyzeRequest
+	4,  // 16: indexer.IndexerService.SubscribeAddress:output_type -> indexer.ChainEvent
+	4,  // 17: indexer.IndexerService.SubscribeEvents:output_type -> indexer.ChainEvent
+	4,  // 18: indexer.IndexerService.StreamEvents:output_type -> indexer.ChainEvent
+	6,  // 19: indexer.IndexerService.GetTransactionHistory:output_type -> indexer.HistoryResponse
+	8,  // 20: indexer.IndexerService.GetBalances:output_type -> indexer.BalanceResponse
+	12, // 21: indexer.IndexerService.AnalyzeTransaction:output_type -> indexer.AnalyzeResponse
+	16, // [16:22] is the sub-list for method output_type
+	10, // [10:16] is the sub-list for method input_type
+	10, // [10:10] is the sub-list for extension type_name
+	10, // [10:10] is the sub-list for extension extendee
+	0,  // [0:10] is the sub-list for field type_name
+}
+
+func init() { file_indexer_proto_init() }
+func file_indexer_proto_init() {
+	if File_indexer_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_indexer_proto_rawDesc), len(file_indexer_proto_rawDesc)),
+			NumEnums:      2,
+			NumMessages:   12,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_indexer_proto_goTypes,
+		DependencyIndexes: file_indexer_proto_depIdxs,
+		EnumInfos:         file_indexer_proto_enumTypes,
+		MessageInfos:      file_indexer_proto_msgTypes,
+	}.Build()
+	File_indexer_proto = out.File
+	file_indexer_proto_goTypes = nil
+	file_indexer_proto_depIdxs = nil
+}
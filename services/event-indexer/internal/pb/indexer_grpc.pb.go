@@ -0,0 +1,337 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: indexer.proto
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	IndexerService_SubscribeAddress_FullMethodName      = "/indexer.IndexerService/SubscribeAddress"
+	IndexerService_SubscribeEvents_FullMethodName       = "/indexer.IndexerService/SubscribeEvents"
+	IndexerService_StreamEvents_FullMethodName          = "/indexer.IndexerService/StreamEvents"
+	IndexerService_GetTransactionHistory_FullMethodName = "/indexer.IndexerService/GetTransactionHistory"
+	IndexerService_GetBalances_FullMethodName           = "/indexer.IndexerService/GetBalances"
+	IndexerService_AnalyzeTransaction_FullMethodName    = "/indexer.IndexerService/AnalyzeTransaction"
+)
+
+// IndexerServiceClient is the client API for IndexerService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Event Indexer Service - 链上事件索引
+type IndexerServiceClient interface {
+	// 订阅地址事件
+	SubscribeAddress(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ChainEvent], error)
+	// 订阅实时事件流 (服务端按链/事件类型/地址过滤)，客户端断开后自动注销
+	SubscribeEvents(ctx context.Context, in *StreamEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ChainEvent], error)
+	// SubscribeEvents 的旧名称，保留给已有客户端
+	StreamEvents(ctx context.Context, in *StreamEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ChainEvent], error)
+	// 获取地址交易历史
+	GetTransactionHistory(ctx context.Context, in *HistoryRequest, opts ...grpc.CallOption) (*HistoryResponse, error)
+	// 获取地址余额
+	GetBalances(ctx context.Context, in *BalanceRequest, opts ...grpc.CallOption) (*BalanceResponse, error)
+	// 检测异常交易
+	AnalyzeTransaction(ctx context.Context, in *AnalyzeRequest, opts ...grpc.CallOption) (*AnalyzeResponse, error)
+}
+
+type indexerServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewIndexerServiceClient(cc grpc.ClientConnInterface) IndexerServiceClient {
+	return &indexerServiceClient{cc}
+}
+
+func (c *indexerServiceClient) SubscribeAddress(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ChainEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &IndexerService_ServiceDesc.Streams[0], IndexerService_SubscribeAddress_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[SubscribeRequest, ChainEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type IndexerService_SubscribeAddressClient = grpc.ServerStreamingClient[ChainEvent]
+
+func (c *indexerServiceClient) SubscribeEvents(ctx context.Context, in *StreamEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ChainEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &IndexerService_ServiceDesc.Streams[1], IndexerService_SubscribeEvents_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamEventsRequest, ChainEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type IndexerService_SubscribeEventsClient = grpc.ServerStreamingClient[ChainEvent]
+
+func (c *indexerServiceClient) StreamEvents(ctx context.Context, in *StreamEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ChainEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &IndexerService_ServiceDesc.Streams[2], IndexerService_StreamEvents_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamEventsRequest, ChainEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type IndexerService_StreamEventsClient = grpc.ServerStreamingClient[ChainEvent]
+
+func (c *indexerServiceClient) GetTransactionHistory(ctx context.Context, in *HistoryRequest, opts ...grpc.CallOption) (*HistoryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(HistoryResponse)
+	err := c.cc.Invoke(ctx, IndexerService_GetTransactionHistory_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *indexerServiceClient) GetBalances(ctx context.Context, in *BalanceRequest, opts ...grpc.CallOption) (*BalanceResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BalanceResponse)
+	err := c.cc.Invoke(ctx, IndexerService_GetBalances_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *indexerServiceClient) AnalyzeTransaction(ctx context.Context, in *AnalyzeRequest, opts ...grpc.CallOption) (*AnalyzeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AnalyzeResponse)
+	err := c.cc.Invoke(ctx, IndexerService_AnalyzeTransaction_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// IndexerServiceServer is the server API for IndexerService service.
+// All implementations must embed UnimplementedIndexerServiceServer
+// for forward compatibility.
+//
+// Event Indexer Service - 链上事件索引
+type IndexerServiceServer interface {
+	// 订阅地址事件
+	SubscribeAddress(*SubscribeRequest, grpc.ServerStreamingServer[ChainEvent]) error
+	// 订阅实时事件流 (服务端按链/事件类型/地址过滤)，客户端断开后自动注销
+	SubscribeEvents(*StreamEventsRequest, grpc.ServerStreamingServer[ChainEvent]) error
+	// SubscribeEvents 的旧名称，保留给已有客户端
+	StreamEvents(*StreamEventsRequest, grpc.ServerStreamingServer[ChainEvent]) error
+	// 获取地址交易历史
+	GetTransactionHistory(context.Context, *HistoryRequest) (*HistoryResponse, error)
+	// 获取地址余额
+	GetBalances(context.Context, *BalanceRequest) (*BalanceResponse, error)
+	// 检测异常交易
+	AnalyzeTransaction(context.Context, *AnalyzeRequest) (*AnalyzeResponse, error)
+	mustEmbedUnimplementedIndexerServiceServer()
+}
+
+// UnimplementedIndexerServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedIndexerServiceServer struct{}
+
+func (UnimplementedIndexerServiceServer) SubscribeAddress(*SubscribeRequest, grpc.ServerStreamingServer[ChainEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method SubscribeAddress not implemented")
+}
+func (UnimplementedIndexerServiceServer) SubscribeEvents(*StreamEventsRequest, grpc.ServerStreamingServer[ChainEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method SubscribeEvents not implemented")
+}
+func (UnimplementedIndexerServiceServer) StreamEvents(*StreamEventsRequest, grpc.ServerStreamingServer[ChainEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamEvents not implemented")
+}
+func (UnimplementedIndexerServiceServer) GetTransactionHistory(context.Context, *HistoryRequest) (*HistoryResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTransactionHistory not implemented")
+}
+func (UnimplementedIndexerServiceServer) GetBalances(context.Context, *BalanceRequest) (*BalanceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBalances not implemented")
+}
+func (UnimplementedIndexerServiceServer) AnalyzeTransaction(context.Context, *AnalyzeRequest) (*AnalyzeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AnalyzeTransaction not implemented")
+}
+func (UnimplementedIndexerServiceServer) mustEmbedUnimplementedIndexerServiceServer() {}
+func (UnimplementedIndexerServiceServer) testEmbeddedByValue()                        {}
+
+// UnsafeIndexerServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to IndexerServiceServer will
+// result in compilation errors.
+type UnsafeIndexerServiceServer interface {
+	mustEmbedUnimplementedIndexerServiceServer()
+}
+
+func RegisterIndexerServiceServer(s grpc.ServiceRegistrar, srv IndexerServiceServer) {
+	// If the following call pancis, it indicates UnimplementedIndexerServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&IndexerService_ServiceDesc, srv)
+}
+
+func _IndexerService_SubscribeAddress_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(IndexerServiceServer).SubscribeAddress(m, &grpc.GenericServerStream[SubscribeRequest, ChainEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type IndexerService_SubscribeAddressServer = grpc.ServerStreamingServer[ChainEvent]
+
+func _IndexerService_SubscribeEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamEventsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(IndexerServiceServer).SubscribeEvents(m, &grpc.GenericServerStream[StreamEventsRequest, ChainEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type IndexerService_SubscribeEventsServer = grpc.ServerStreamingServer[ChainEvent]
+
+func _IndexerService_StreamEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamEventsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(IndexerServiceServer).StreamEvents(m, &grpc.GenericServerStream[StreamEventsRequest, ChainEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type IndexerService_StreamEventsServer = grpc.ServerStreamingServer[ChainEvent]
+
+func _IndexerService_GetTransactionHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IndexerServiceServer).GetTransactionHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IndexerService_GetTransactionHistory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IndexerServiceServer).GetTransactionHistory(ctx, req.(*HistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IndexerService_GetBalances_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BalanceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IndexerServiceServer).GetBalances(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IndexerService_GetBalances_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IndexerServiceServer).GetBalances(ctx, req.(*BalanceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IndexerService_AnalyzeTransaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AnalyzeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IndexerServiceServer).AnalyzeTransaction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: IndexerService_AnalyzeTransaction_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IndexerServiceServer).AnalyzeTransaction(ctx, req.(*AnalyzeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// IndexerService_ServiceDesc is the grpc.ServiceDesc for IndexerService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var IndexerService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "indexer.IndexerService",
+	HandlerType: (*IndexerServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetTransactionHistory",
+			Handler:    _IndexerService_GetTransactionHistory_Handler,
+		},
+		{
+			MethodName: "GetBalances",
+			Handler:    _IndexerService_GetBalances_Handler,
+		},
+		{
+			MethodName: "AnalyzeTransaction",
+			Handler:    _IndexerService_AnalyzeTransaction_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "SubscribeAddress",
+			Handler:       _IndexerService_SubscribeAddress_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "SubscribeEvents",
+			Handler:       _IndexerService_SubscribeEvents_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "StreamEvents",
+			Handler:       _IndexerService_StreamEvents_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "indexer.proto",
+}
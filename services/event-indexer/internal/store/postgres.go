@@ -0,0 +1,73 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math/big"
+
+	_ "github.com/lib/pq"
+	"github.com/protocol-bank/event-indexer/internal/config"
+)
+
+// Postgres 基于 Postgres 的代币策略表
+type Postgres struct {
+	db *sql.DB
+}
+
+// NewPostgres 连接 Postgres
+func NewPostgres(ctx context.Context, url string) (*Postgres, error) {
+	db, err := sql.Open("postgres", url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+	return &Postgres{db: db}, nil
+}
+
+// NewPostgresFromDB 包装已打开的数据库连接
+func NewPostgresFromDB(db *sql.DB) *Postgres {
+	return &Postgres{db: db}
+}
+
+// Close 关闭数据库连接
+func (p *Postgres) Close() error {
+	return p.db.Close()
+}
+
+// LoadTokenPolicies 读取全部代币策略 (min_amount 为 NULL 表示不限金额)
+func (p *Postgres) LoadTokenPolicies(ctx context.Context) ([]config.TokenPolicy, error) {
+	query := `
+		SELECT chain_id, token_address, min_amount::text, confirmations, enabled, allowlisted
+		FROM token_policies
+	`
+	rows, err := p.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query token policies: %w", err)
+	}
+	defer rows.Close()
+
+	var policies []config.TokenPolicy
+	for rows.Next() {
+		var policy config.TokenPolicy
+		var minAmount sql.NullString
+		if err := rows.Scan(&policy.ChainID, &policy.Token, &minAmount, &policy.Confirmations, &policy.Enabled, &policy.Allowlisted); err != nil {
+			return nil, fmt.Errorf("failed to scan token policy: %w", err)
+		}
+		if minAmount.Valid {
+			amount, ok := new(big.Int).SetString(minAmount.String, 10)
+			if !ok {
+				return nil, fmt.Errorf("token policy %d/%s: invalid min_amount %q", policy.ChainID, policy.Token, minAmount.String)
+			}
+			policy.MinAmount = amount
+		}
+		policies = append(policies, policy)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read token policies: %w", err)
+	}
+	return policies, nil
+}
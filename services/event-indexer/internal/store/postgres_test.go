@@ -0,0 +1,39 @@
+package store
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/protocol-bank/event-indexer/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var tokenPolicyColumns = []string{"chain_id", "token_address", "min_amount", "confirmations", "enabled", "allowlisted"}
+
+func TestPostgres_LoadTokenPolicies(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+	p := NewPostgresFromDB(db)
+
+	mock.ExpectQuery("SELECT (.+) FROM token_policies").WillReturnRows(sqlmock.NewRows(tokenPolicyColumns).
+		AddRow(1, "0xdAC17F958D2ee523a2206206994597C13D831ec7", "1000000", 30, true, true).
+		AddRow(728126428, "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t", nil, 0, false, false))
+
+	policies, err := p.LoadTokenPolicies(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []config.TokenPolicy{
+		{ChainID: 1, Token: "0xdAC17F958D2ee523a2206206994597C13D831ec7", MinAmount: big.NewInt(1_000_000), Confirmations: 30, Enabled: true, Allowlisted: true},
+		{ChainID: 728126428, Token: "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t"},
+	}, policies)
+
+	mock.ExpectQuery("SELECT (.+) FROM token_policies").WillReturnRows(sqlmock.NewRows(tokenPolicyColumns).
+		AddRow(1, "0xdAC17F958D2ee523a2206206994597C13D831ec7", "1e6", 0, true, false))
+	_, err = p.LoadTokenPolicies(context.Background())
+	assert.ErrorContains(t, err, "invalid min_amount")
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,255 @@
+package store
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/protocol-bank/event-indexer/internal/config"
+	"github.com/rs/zerolog/log"
+)
+
+// Redis 基于 Redis 的索引器持久化状态 (检查点、运行余额)
+type Redis struct {
+	client *redis.Client
+}
+
+// NewRedis 连接 Redis，支持 redis:// URL 或 host:port 地址
+func NewRedis(ctx context.Context, cfg config.RedisConfig) (*Redis, error) {
+	var rdb *redis.Client
+	if strings.HasPrefix(cfg.URL, "redis://") || strings.HasPrefix(cfg.URL, "rediss://") {
+		opt, err := redis.ParseURL(cfg.URL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse redis url: %w", err)
+		}
+		if cfg.TLSEnabled && opt.TLSConfig == nil {
+			opt.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+		}
+		rdb = redis.NewClient(opt)
+	} else {
+		opts := &redis.Options{
+			Addr:     cfg.URL,
+			Password: cfg.Password,
+			DB:       cfg.DB,
+		}
+		if cfg.TLSEnabled {
+			opts.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+		}
+		rdb = redis.NewClient(opts)
+	}
+
+	if err := rdb.Ping(ctx).Err(); err != nil {
+		return nil, fmt.Errorf("redis connection failed: %w", err)
+	}
+	return &Redis{client: rdb}, nil
+}
+
+// Close 关闭 Redis 连接
+func (r *Redis) Close() error {
+	return r.client.Close()
+}
+
+// checkpointKey 每条链的持久化检查点 key (带格式版本，便于后续演进)
+func checkpointKey(chainID uint64) string {
+	return fmt.Sprintf("indexer:v2:lastblock:%d", chainID)
+}
+
+// legacyCheckpointKeys 旧版本使用过的检查点 key 格式，按从新到旧排列
+var legacyCheckpointKeys = []func(chainID uint64) string{
+	func(chainID uint64) string { return fmt.Sprintf("indexer:lastblock:%d", chainID) },
+}
+
+// MigrateCheckpoints 启动时一次性迁移: 新格式 key 不存在而旧格式存在时，
+// 将旧值复制到新 key (旧 key 保留以便回滚)，避免升级后从头重新扫描
+func (r *Redis) MigrateCheckpoints(ctx context.Context, chainIDs []uint64) error {
+	for _, chainID := range chainIDs {
+		newKey := checkpointKey(chainID)
+		exists, err := r.client.Exists(ctx, newKey).Result()
+		if err != nil {
+			return fmt.Errorf("check checkpoint of chain %d: %w", chainID, err)
+		}
+		if exists > 0 {
+			continue
+		}
+
+		for _, legacy := range legacyCheckpointKeys {
+			oldKey := legacy(chainID)
+			value, err := r.client.Get(ctx, oldKey).Result()
+			if errors.Is(err, redis.Nil) {
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("read legacy checkpoint %s: %w", oldKey, err)
+			}
+			// SETNX: 不覆盖迁移期间已写入的新检查点
+			if err := r.client.SetNX(ctx, newKey, value, 0).Err(); err != nil {
+				return fmt.Errorf("migrate checkpoint %s: %w", oldKey, err)
+			}
+			log.Info().Uint64("chain_id", chainID).Str("from", oldKey).Str("to", newKey).Str("block", value).Msg("Migrated checkpoint to new key format")
+			break
+		}
+	}
+	return nil
+}
+
+// LoadCheckpoint 读取链的持久化检查点，不存在时 ok 为 false
+func (r *Redis) LoadCheckpoint(ctx context.Context, chainID uint64) (block uint64, ok bool, err error) {
+	block, err = r.client.Get(ctx, checkpointKey(chainID)).Uint64()
+	if errors.Is(err, redis.Nil) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return block, true, nil
+}
+
+// SaveCheckpoint 写入链的持久化检查点
+func (r *Redis) SaveCheckpoint(ctx context.Context, chainID uint64, block uint64) error {
+	return r.client.Set(ctx, checkpointKey(chainID), block, 0).Err()
+}
+
+// watchSetKey 每条链运行时新增的监听地址集合
+func watchSetKey(chainID uint64) string {
+	return fmt.Sprintf("indexer:v2:watched:%d", chainID)
+}
+
+// LoadWatchedAddresses 读取链上持久化的监听地址
+func (r *Redis) LoadWatchedAddresses(ctx context.Context, chainID uint64) ([]string, error) {
+	return r.client.SMembers(ctx, watchSetKey(chainID)).Result()
+}
+
+// AddWatchedAddress 持久化监听地址 (重复添加无副作用)
+func (r *Redis) AddWatchedAddress(ctx context.Context, chainID uint64, address string) error {
+	return r.client.SAdd(ctx, watchSetKey(chainID), address).Err()
+}
+
+// RemoveWatchedAddress 删除持久化的监听地址
+func (r *Redis) RemoveWatchedAddress(ctx context.Context, chainID uint64, address string) error {
+	return r.client.SRem(ctx, watchSetKey(chainID), address).Err()
+}
+
+// balanceMarkerTTL 余额变动去重标记的保留时间 (远大于任何链的重组窗口)
+const balanceMarkerTTL = 7 * 24 * time.Hour
+
+// balanceKey 地址在某代币上的运行净余额 (十进制字符串，可超出 int64)
+func balanceKey(chainID uint64, address, token string) string {
+	return fmt.Sprintf("indexer:v2:balance:%d:%s:%s", chainID, token, address)
+}
+
+// balanceMarkerKey 已应用的余额变动标记，值为变动量，用于去重与重组冲回
+func balanceMarkerKey(chainID uint64, eventID, address string) string {
+	return fmt.Sprintf("indexer:v2:balance:applied:%d:%s:%s", chainID, eventID, address)
+}
+
+// ApplyBalanceDelta 将事件的余额变动累加到运行余额 (同一事件与地址只应用一次)
+func (r *Redis) ApplyBalanceDelta(ctx context.Context, chainID uint64, eventID, address, token string, delta *big.Int) (balance *big.Int, applied bool, err error) {
+	key := balanceKey(chainID, address, token)
+	marker := balanceMarkerKey(chainID, eventID, address)
+
+	err = r.transact(ctx, func(tx *redis.Tx) error {
+		exists, err := tx.Exists(ctx, marker).Result()
+		if err != nil {
+			return err
+		}
+		current, err := loadBigInt(ctx, tx, key)
+		if err != nil {
+			return err
+		}
+		if exists > 0 {
+			balance, applied = current, false
+			return nil
+		}
+
+		next := new(big.Int).Add(current, delta)
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Set(ctx, key, next.String(), 0)
+			pipe.Set(ctx, marker, delta.String(), balanceMarkerTTL)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		balance, applied = next, true
+		return nil
+	}, key, marker)
+	if err != nil {
+		return nil, false, fmt.Errorf("apply balance delta %s: %w", eventID, err)
+	}
+	return balance, applied, nil
+}
+
+// RevertBalanceDelta 冲回事件已应用的余额变动 (重组时调用)，返回冲回量与新余额
+func (r *Redis) RevertBalanceDelta(ctx context.Context, chainID uint64, eventID, address, token string) (delta, balance *big.Int, reverted bool, err error) {
+	key := balanceKey(chainID, address, token)
+	marker := balanceMarkerKey(chainID, eventID, address)
+
+	err = r.transact(ctx, func(tx *redis.Tx) error {
+		applied, err := tx.Get(ctx, marker).Result()
+		if errors.Is(err, redis.Nil) {
+			reverted = false
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		appliedDelta, ok := new(big.Int).SetString(applied, 10)
+		if !ok {
+			return fmt.Errorf("malformed balance marker %s: %q", marker, applied)
+		}
+		current, err := loadBigInt(ctx, tx, key)
+		if err != nil {
+			return err
+		}
+
+		reversal := new(big.Int).Neg(appliedDelta)
+		next := new(big.Int).Add(current, reversal)
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Set(ctx, key, next.String(), 0)
+			pipe.Del(ctx, marker)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		delta, balance, reverted = reversal, next, true
+		return nil
+	}, key, marker)
+	if err != nil {
+		return nil, nil, false, fmt.Errorf("revert balance delta %s: %w", eventID, err)
+	}
+	return delta, balance, reverted, nil
+}
+
+// transact 在 WATCH 事务中执行 fn，keys 被并发修改时重试
+func (r *Redis) transact(ctx context.Context, fn func(tx *redis.Tx) error, keys ...string) error {
+	const maxRetries = 10
+	for i := 0; i < maxRetries; i++ {
+		err := r.client.Watch(ctx, fn, keys...)
+		if !errors.Is(err, redis.TxFailedErr) {
+			return err
+		}
+	}
+	return redis.TxFailedErr
+}
+
+// loadBigInt 读取十进制大整数，key 不存在时为 0
+func loadBigInt(ctx context.Context, tx *redis.Tx, key string) (*big.Int, error) {
+	value, err := tx.Get(ctx, key).Result()
+	if errors.Is(err, redis.Nil) {
+		return new(big.Int), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	n, ok := new(big.Int).SetString(value, 10)
+	if !ok {
+		return nil, fmt.Errorf("malformed balance %s: %q", key, value)
+	}
+	return n, nil
+}
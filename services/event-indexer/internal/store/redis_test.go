@@ -0,0 +1,152 @@
+package store
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/protocol-bank/event-indexer/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRedis(t *testing.T) (*Redis, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	r, err := NewRedis(context.Background(), config.RedisConfig{URL: mr.Addr()})
+	require.NoError(t, err)
+	t.Cleanup(func() { r.Close() })
+	return r, mr
+}
+
+func TestRedis_Checkpoint(t *testing.T) {
+	ctx := context.Background()
+	r, mr := newTestRedis(t)
+
+	_, ok, err := r.LoadCheckpoint(ctx, 1)
+	require.NoError(t, err)
+	assert.False(t, ok, "no checkpoint yet")
+
+	require.NoError(t, r.SaveCheckpoint(ctx, 1, 19000000))
+	block, ok, err := r.LoadCheckpoint(ctx, 1)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(19000000), block)
+
+	stored, err := mr.Get("indexer:v2:lastblock:1")
+	require.NoError(t, err)
+	assert.Equal(t, "19000000", stored)
+
+	_, ok, err = r.LoadCheckpoint(ctx, 137)
+	require.NoError(t, err)
+	assert.False(t, ok, "checkpoints are per chain")
+}
+
+func TestRedis_MigrateCheckpoints(t *testing.T) {
+	ctx := context.Background()
+	r, mr := newTestRedis(t)
+
+	// Written by a release using the old key format
+	require.NoError(t, mr.Set("indexer:lastblock:1", "19000000"))
+	require.NoError(t, mr.Set("indexer:lastblock:137", "50000000"))
+	// Already on the new format; must not be overwritten
+	require.NoError(t, r.SaveCheckpoint(ctx, 137, 50000100))
+
+	require.NoError(t, r.MigrateCheckpoints(ctx, []uint64{1, 137, 8453}))
+
+	block, ok, err := r.LoadCheckpoint(ctx, 1)
+	require.NoError(t, err)
+	assert.True(t, ok, "old-format checkpoint migrated")
+	assert.Equal(t, uint64(19000000), block)
+	assert.True(t, mr.Exists("indexer:lastblock:1"), "old key kept for rollback")
+
+	block, _, err = r.LoadCheckpoint(ctx, 137)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(50000100), block)
+
+	_, ok, err = r.LoadCheckpoint(ctx, 8453)
+	require.NoError(t, err)
+	assert.False(t, ok, "nothing to migrate")
+
+	// Idempotent
+	require.NoError(t, r.MigrateCheckpoints(ctx, []uint64{1}))
+	block, _, _ = r.LoadCheckpoint(ctx, 1)
+	assert.Equal(t, uint64(19000000), block)
+}
+
+func TestRedis_BalanceDeltas(t *testing.T) {
+	ctx := context.Background()
+	r, mr := newTestRedis(t)
+
+	// Beyond int64
+	big1, _ := new(big.Int).SetString("100000000000000000000000", 10)
+
+	balance, applied, err := r.ApplyBalanceDelta(ctx, 1, "1:0xaa:0", "0xwatched", "0xtoken", big1)
+	require.NoError(t, err)
+	assert.True(t, applied)
+	assert.Equal(t, big1.String(), balance.String())
+
+	balance, applied, err = r.ApplyBalanceDelta(ctx, 1, "1:0xbb:0", "0xwatched", "0xtoken", big.NewInt(-40))
+	require.NoError(t, err)
+	assert.True(t, applied)
+	assert.Equal(t, "99999999999999999999960", balance.String())
+
+	// Redelivery of the same event is a no-op
+	balance, applied, err = r.ApplyBalanceDelta(ctx, 1, "1:0xbb:0", "0xwatched", "0xtoken", big.NewInt(-40))
+	require.NoError(t, err)
+	assert.False(t, applied)
+	assert.Equal(t, "99999999999999999999960", balance.String())
+
+	stored, err := mr.Get("indexer:v2:balance:1:0xtoken:0xwatched")
+	require.NoError(t, err)
+	assert.Equal(t, "99999999999999999999960", stored)
+
+	delta, balance, reverted, err := r.RevertBalanceDelta(ctx, 1, "1:0xbb:0", "0xwatched", "0xtoken")
+	require.NoError(t, err)
+	assert.True(t, reverted)
+	assert.Equal(t, "40", delta.String())
+	assert.Equal(t, big1.String(), balance.String())
+
+	// Only applied deltas are reverted, once
+	_, _, reverted, err = r.RevertBalanceDelta(ctx, 1, "1:0xbb:0", "0xwatched", "0xtoken")
+	require.NoError(t, err)
+	assert.False(t, reverted)
+	_, _, reverted, err = r.RevertBalanceDelta(ctx, 1, "1:0xcc:0", "0xwatched", "0xtoken")
+	require.NoError(t, err)
+	assert.False(t, reverted)
+
+	// Balances are per chain and token
+	balance, _, err = r.ApplyBalanceDelta(ctx, 137, "137:0xaa:0", "0xwatched", "0xtoken", big.NewInt(5))
+	require.NoError(t, err)
+	assert.Equal(t, "5", balance.String())
+}
+
+func TestRedis_WatchedAddresses(t *testing.T) {
+	ctx := context.Background()
+	r, mr := newTestRedis(t)
+
+	addresses, err := r.LoadWatchedAddresses(ctx, 1)
+	require.NoError(t, err)
+	assert.Empty(t, addresses)
+
+	addr := "0x2222222222222222222222222222222222222222"
+	require.NoError(t, r.AddWatchedAddress(ctx, 1, addr))
+	require.NoError(t, r.AddWatchedAddress(ctx, 1, addr))
+	addresses, err = r.LoadWatchedAddresses(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{addr}, addresses)
+
+	members, err := mr.Members("indexer:v2:watched:1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{addr}, members)
+
+	addresses, err = r.LoadWatchedAddresses(ctx, 137)
+	require.NoError(t, err)
+	assert.Empty(t, addresses, "sets are per chain")
+
+	require.NoError(t, r.RemoveWatchedAddress(ctx, 1, addr))
+	addresses, err = r.LoadWatchedAddresses(ctx, 1)
+	require.NoError(t, err)
+	assert.Empty(t, addresses)
+}
@@ -0,0 +1,101 @@
+package watcher
+
+import (
+	"sync"
+	"time"
+
+	"github.com/protocol-bank/event-indexer/internal/config"
+	"github.com/rs/zerolog/log"
+)
+
+// defaultAdaptiveWindow is how long a reorg raises the confirmation
+// requirement when no window is configured.
+const defaultAdaptiveWindow = time.Hour
+
+// adaptiveConfirmations raises a chain's confirmation requirement while it
+// is reorging. Each reorg seen within the window adds its depth to the base
+// requirement, capped at max; once the reorgs age out of the window the
+// requirement relaxes back to base. With max <= base it stays fixed.
+type adaptiveConfirmations struct {
+	mu        sync.Mutex
+	now       func() time.Time
+	chainName string
+	base      uint64
+	max       uint64
+	window    time.Duration
+
+	reorgs  []adaptiveReorg // within window, oldest first
+	current uint64
+}
+
+type adaptiveReorg struct {
+	at    time.Time
+	depth uint64
+}
+
+func newAdaptiveConfirmations(cfg config.ChainConfig) *adaptiveConfirmations {
+	window := cfg.AdaptiveConfirmationWindow
+	if window <= 0 {
+		window = defaultAdaptiveWindow
+	}
+	return &adaptiveConfirmations{
+		now:       time.Now,
+		chainName: cfg.Name,
+		base:      cfg.Confirmations,
+		max:       cfg.MaxConfirmations,
+		window:    window,
+		current:   cfg.Confirmations,
+	}
+}
+
+// maxConfirmations is the deepest confirmation requirement a chain can
+// reach, which bounds how far back reorg bookkeeping has to reach.
+func maxConfirmations(cfg config.ChainConfig) uint64 {
+	return max(cfg.Confirmations, cfg.MaxConfirmations)
+}
+
+// observe counts a detected reorg towards the requirement.
+func (a *adaptiveConfirmations) observe(reorg ReorgInfo) {
+	if a.max <= a.base {
+		return
+	}
+	a.mu.Lock()
+	a.reorgs = append(a.reorgs, adaptiveReorg{at: a.now(), depth: reorg.ToBlock - reorg.FromBlock + 1})
+	a.mu.Unlock()
+	a.required()
+}
+
+// required returns the current confirmation requirement, logging whenever
+// it changes.
+func (a *adaptiveConfirmations) required() uint64 {
+	if a.max <= a.base {
+		return a.base
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	cutoff := a.now().Add(-a.window)
+	expired := 0
+	for expired < len(a.reorgs) && !a.reorgs[expired].at.After(cutoff) {
+		expired++
+	}
+	a.reorgs = append(a.reorgs[:0], a.reorgs[expired:]...)
+
+	required := a.base
+	for _, reorg := range a.reorgs {
+		required = min(required+reorg.depth, a.max)
+	}
+	if required != a.current {
+		event := log.Info()
+		if required > a.current {
+			event = log.Warn()
+		}
+		event.Str("chain", a.chainName).
+			Uint64("from", a.current).
+			Uint64("to", required).
+			Int("recent_reorgs", len(a.reorgs)).
+			Msg("Adjusted required confirmations")
+		a.current = required
+	}
+	return required
+}
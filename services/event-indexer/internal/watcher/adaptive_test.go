@@ -0,0 +1,72 @@
+package watcher
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/protocol-bank/event-indexer/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdaptiveConfirmations_ReorgBurstAndRecovery(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	a := newAdaptiveConfirmations(config.ChainConfig{Name: "Test", Confirmations: 12, MaxConfirmations: 30, AdaptiveConfirmationWindow: time.Hour})
+	a.now = func() time.Time { return now }
+	assert.Equal(t, uint64(12), a.required())
+
+	// A burst of deep reorgs raises the requirement, bounded by the maximum
+	a.observe(ReorgInfo{FromBlock: 100, ToBlock: 104})
+	assert.Equal(t, uint64(17), a.required())
+	now = now.Add(10 * time.Minute)
+	a.observe(ReorgInfo{FromBlock: 200, ToBlock: 205})
+	assert.Equal(t, uint64(23), a.required())
+	now = now.Add(10 * time.Minute)
+	a.observe(ReorgInfo{FromBlock: 300, ToBlock: 309})
+	assert.Equal(t, uint64(30), a.required(), "capped at MaxConfirmations")
+
+	// Stability relaxes it as the reorgs age out of the window
+	now = now.Add(45 * time.Minute)
+	assert.Equal(t, uint64(28), a.required())
+	now = now.Add(10 * time.Minute)
+	assert.Equal(t, uint64(22), a.required())
+	now = now.Add(15 * time.Minute)
+	assert.Equal(t, uint64(12), a.required())
+}
+
+func TestAdaptiveConfirmations_FixedWithoutMax(t *testing.T) {
+	a := newAdaptiveConfirmations(config.ChainConfig{Name: "Test", Confirmations: 12})
+	a.observe(ReorgInfo{FromBlock: 100, ToBlock: 140})
+	assert.Equal(t, uint64(12), a.required())
+	assert.Empty(t, a.reorgs)
+}
+
+func TestChainWatcher_AdaptiveConfirmations(t *testing.T) {
+	client := newFakeEVMClient(1012)
+	watched := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	other := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	client.addLog(testTransferLog(1000, 0, other, watched, big.NewInt(5)))
+
+	w := newTestChainWatcher(t, client)
+	w.AddAddress(watched)
+	w.cfg.MaxConfirmations = 40
+	w.confirmations = newAdaptiveConfirmations(w.cfg)
+	now := time.Unix(1700000000, 0)
+	w.confirmations.now = func() time.Time { return now }
+
+	events := collectEVMEvents(t, w, 1000, 1012)
+	require.Len(t, events, 1)
+	assert.True(t, events[0].Confirmed, "12 confirmations suffice on a stable chain")
+
+	w.confirmations.observe(ReorgInfo{FromBlock: 990, ToBlock: 995})
+	events = collectEVMEvents(t, w, 1000, 1012)
+	require.Len(t, events, 1)
+	assert.False(t, events[0].Confirmed, "a recent reorg raises the requirement to 18")
+
+	now = now.Add(2 * time.Hour)
+	events = collectEVMEvents(t, w, 1000, 1012)
+	require.Len(t, events, 1)
+	assert.True(t, events[0].Confirmed)
+}
@@ -0,0 +1,43 @@
+package watcher
+
+import (
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/protocol-bank/event-indexer/internal/config"
+)
+
+// formatEVMAddress renders an EVM address in the configured form: EIP-55
+// checksummed by default, or all-lowercase. Anything that isn't a hex
+// address is returned unchanged.
+func formatEVMAddress(addr, format string) string {
+	if !common.IsHexAddress(addr) {
+		return addr
+	}
+	checksummed := common.HexToAddress(addr).Hex()
+	if format == config.AddressFormatLowercase {
+		return strings.ToLower(checksummed)
+	}
+	return checksummed
+}
+
+// formatEVMAddresses rewrites every address of an EVM event in the
+// configured form, whatever casing the raw log or transaction produced.
+func formatEVMAddresses(event *ChainEvent, format string) {
+	for _, addr := range []*string{&event.FromAddress, &event.ToAddress, &event.TokenAddress, &event.WatchedAddress, &event.Operator, &event.FeePayer} {
+		*addr = formatEVMAddress(*addr, format)
+	}
+}
+
+// emit formats a detected event's addresses as configured and dispatches
+// it, unless it was already detected in the same block. It reports whether
+// the event was dispatched.
+func (w *ChainWatcher) emit(event *ChainEvent) bool {
+	formatEVMAddresses(event, w.cfg.AddressFormat)
+	if w.dispatch.seen.repeat(event) {
+		return false
+	}
+	w.dispatch.tokenPolicies.holdConfirmed(event)
+	w.dispatch.dispatch(&w.gate, event)
+	return true
+}
@@ -0,0 +1,50 @@
+package watcher
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/protocol-bank/event-indexer/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatEVMAddress(t *testing.T) {
+	const checksummed = "0xdAC17F958D2ee523a2206206994597C13D831ec7"
+	for _, raw := range []string{checksummed, strings.ToLower(checksummed), "0x" + strings.ToUpper(checksummed[2:])} {
+		assert.Equal(t, checksummed, formatEVMAddress(raw, config.AddressFormatChecksum))
+		assert.Equal(t, checksummed, formatEVMAddress(raw, ""), "checksummed by default")
+		assert.Equal(t, strings.ToLower(checksummed), formatEVMAddress(raw, config.AddressFormatLowercase))
+	}
+	assert.Equal(t, "", formatEVMAddress("", config.AddressFormatLowercase))
+	assert.Equal(t, "TLa2f6VPqDgRE67v1736s7bJ8Ray5wYjU7", formatEVMAddress("TLa2f6VPqDgRE67v1736s7bJ8Ray5wYjU7", config.AddressFormatLowercase))
+}
+
+func TestChainWatcher_AddressFormat(t *testing.T) {
+	client := newFakeEVMClient(1000)
+	watched := common.HexToAddress("0xAb5801a7D398351b8bE11C439e05C5B3259aeC9B")
+	from := common.HexToAddress("0x52908400098527886E0F7030069857D2E4169EE7")
+	client.addLog(testTransferLog(990, 0, from, watched, big.NewInt(1)))
+
+	for _, format := range []string{config.AddressFormatChecksum, config.AddressFormatLowercase} {
+		t.Run(format, func(t *testing.T) {
+			w := newTestChainWatcher(t, client)
+			w.cfg.AddressFormat = format
+			w.AddAddress(watched)
+
+			events := collectEVMEvents(t, w, 990, 1000)
+			require.Len(t, events, 1)
+			event := events[0]
+			for _, addr := range []string{event.FromAddress, event.ToAddress, event.TokenAddress, event.WatchedAddress} {
+				if format == config.AddressFormatLowercase {
+					assert.Equal(t, strings.ToLower(addr), addr)
+				} else {
+					assert.Equal(t, common.HexToAddress(addr).Hex(), addr)
+				}
+			}
+			assert.Equal(t, formatEVMAddress(watched.Hex(), format), event.WatchedAddress)
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package watcher
+
+import (
+	"math/big"
+	"strings"
+)
+
+// maxTokenDecimals bounds accepted decimals; a uint256 has at most 78 digits,
+// so anything larger is contract garbage rather than a real scale.
+const maxTokenDecimals = 77
+
+// FormatAmount renders a raw integer token amount as a decimal string scaled
+// by decimals, using integer arithmetic only so no precision is lost.
+// Trailing fractional zeros are trimmed: 1500000 with 6 decimals → "1.5".
+func FormatAmount(raw *big.Int, decimals uint8) string {
+	if raw == nil {
+		return ""
+	}
+
+	neg := raw.Sign() < 0
+	digits := new(big.Int).Abs(raw).String()
+
+	if decimals > 0 {
+		d := int(decimals)
+		if len(digits) <= d {
+			digits = strings.Repeat("0", d-len(digits)+1) + digits
+		}
+		intPart := digits[:len(digits)-d]
+		fracPart := strings.TrimRight(digits[len(digits)-d:], "0")
+		digits = intPart
+		if fracPart != "" {
+			digits += "." + fracPart
+		}
+	}
+
+	if neg {
+		return "-" + digits
+	}
+	return digits
+}
@@ -0,0 +1,230 @@
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatAmount(t *testing.T) {
+	tests := []struct {
+		name     string
+		raw      string
+		decimals uint8
+		expected string
+	}{
+		{"USDT whole", "1000000", 6, "1"},
+		{"USDT fraction", "1234567", 6, "1.234567"},
+		{"trailing zeros trimmed", "1500000", 6, "1.5"},
+		{"below one", "42", 6, "0.000042"},
+		{"zero", "0", 6, "0"},
+		{"no decimals", "12345", 0, "12345"},
+		{"18 decimals beyond float64 precision", "123456789012345678901234567", 18, "123456789.012345678901234567"},
+		{"negative", "-1500000", 6, "-1.5"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw, ok := new(big.Int).SetString(tt.raw, 10)
+			require.True(t, ok)
+			assert.Equal(t, tt.expected, FormatAmount(raw, tt.decimals))
+		})
+	}
+}
+
+// fakeTRC20Metadata answers decimals() and totalSupply() for a fixed set of tokens.
+type fakeTRC20Metadata struct {
+	decimals map[string]int64
+	supplies map[string]*big.Int
+	calls    int
+}
+
+func (f *fakeTRC20Metadata) TRC20GetDecimals(contractAddress string) (*big.Int, error) {
+	f.calls++
+	d, ok := f.decimals[contractAddress]
+	if !ok {
+		return nil, fmt.Errorf("REVERT opcode executed")
+	}
+	return big.NewInt(d), nil
+}
+
+func (f *fakeTRC20Metadata) TRC20GetTotalSupply(contractAddress string) (*big.Int, error) {
+	f.calls++
+	supply, ok := f.supplies[contractAddress]
+	if !ok {
+		return nil, fmt.Errorf("REVERT opcode executed")
+	}
+	return supply, nil
+}
+
+func TestTronWatcher_NormalizedValue(t *testing.T) {
+	client := newFakeTronClient(200)
+	usdt, usdtAddr := testTronAddress(0xaa)
+	unknown, _ := testTronAddress(0xbb)
+	from, _ := testTronAddress(0x11)
+	to, toAddr := testTronAddress(0x22)
+	client.addTransfer(190, "b190", usdt, from, to, big.NewInt(12_345_678))
+	client.addTransfer(190, "c190", unknown, from, to, big.NewInt(12_345_678))
+
+	meta := &fakeTRC20Metadata{decimals: map[string]int64{usdtAddr: 6}}
+	w := newTestTronWatcher(client)
+	w.tokenMeta = newTokenMetadataCache(meta)
+	w.AddTronAddress(toAddr)
+
+	events := make(chan *ChainEvent, 2)
+	w.dispatch.addHandler(func(event *ChainEvent) error {
+		events <- event
+		return nil
+	})
+	w.processBlock(context.Background(), 190, 200)
+
+	got := map[string]*ChainEvent{}
+	for i := 0; i < 2; i++ {
+		e := <-events
+		got[e.TxHash] = e
+	}
+
+	require.Contains(t, got, "b190")
+	assert.Equal(t, "12345678", got["b190"].Value)
+	assert.Equal(t, "12.345678", got["b190"].NormalizedValue)
+
+	require.Contains(t, got, "c190")
+	assert.Equal(t, "12345678", got["c190"].Value)
+	assert.Empty(t, got["c190"].NormalizedValue)
+
+	// Cached: resolving the same token again does not re-query the node
+	calls := meta.calls
+	w.normalizeValue(usdtAddr, big.NewInt(1))
+	assert.Equal(t, calls, meta.calls)
+}
+
+func TestTokenMetadataCache_RetriesFailedDecimals(t *testing.T) {
+	_, token := testTronAddress(0xaa)
+	meta := &fakeTRC20Metadata{decimals: map[string]int64{}}
+	clock := &fakeClock{now: time.Unix(1_700_000_000, 0)}
+	cache := newTokenMetadataCache(meta)
+	cache.now = clock.Now
+
+	// The node fails the first lookup; the failure is cached briefly
+	_, ok := cache.decimals(token)
+	assert.False(t, ok)
+	_, ok = cache.decimals(token)
+	assert.False(t, ok)
+	assert.Equal(t, 1, meta.calls, "failure cached within the retry interval")
+
+	meta.decimals[token] = 6
+	clock.Advance(tokenMetadataRetry)
+	decimals, ok := cache.decimals(token)
+	assert.True(t, ok, "retried after the interval")
+	assert.Equal(t, uint8(6), decimals)
+
+	clock.Advance(2 * tokenMetadataRetry)
+	cache.decimals(token)
+	assert.Equal(t, 2, meta.calls, "successes are cached for good")
+}
+
+func TestTronWatcher_ExceedsTotalSupply(t *testing.T) {
+	client := newFakeTronClient(200)
+	token, tokenAddr := testTronAddress(0xaa)
+	noSupply, _ := testTronAddress(0xbb)
+	from, _ := testTronAddress(0x11)
+	to, toAddr := testTronAddress(0x22)
+	client.addTransfer(190, "a190", token, from, to, big.NewInt(1_000_000))
+	client.addTransfer(190, "b190", token, from, to, big.NewInt(1_000_001))
+	client.addTransfer(190, "c190", noSupply, from, to, big.NewInt(1_000_001))
+
+	meta := &fakeTRC20Metadata{supplies: map[string]*big.Int{tokenAddr: big.NewInt(1_000_000)}}
+	w := newTestTronWatcher(client)
+	w.tokenMeta = newTokenMetadataCache(meta)
+	w.tokenMeta.fetchDecimals = false
+	w.tokenMeta.fetchSupply = true
+	w.AddTronAddress(toAddr)
+
+	events := make(chan *ChainEvent, 3)
+	w.dispatch.addHandler(func(event *ChainEvent) error {
+		events <- event
+		return nil
+	})
+	w.processBlock(context.Background(), 190, 200)
+
+	flagged := map[string]bool{}
+	for i := 0; i < 3; i++ {
+		e := <-events
+		flagged[e.TxHash] = e.ExceedsTotalSupply
+		assert.Empty(t, e.NormalizedValue, "decimals resolution is off")
+	}
+	assert.False(t, flagged["a190"], "the whole supply is still plausible")
+	assert.True(t, flagged["b190"], "more than the total supply")
+	assert.False(t, flagged["c190"], "unknown supply never flags")
+	assert.Equal(t, 2, meta.calls, "supply is cached per token")
+}
+
+func TestTokenMetadataCache_RetriesFailedSupply(t *testing.T) {
+	_, token := testTronAddress(0xaa)
+	meta := &fakeTRC20Metadata{supplies: map[string]*big.Int{}}
+	clock := &fakeClock{now: time.Unix(1_700_000_000, 0)}
+	cache := newTokenMetadataCache(meta)
+	cache.now = clock.Now
+	cache.fetchDecimals = false
+	cache.fetchSupply = true
+
+	assert.False(t, cache.exceedsSupply(token, big.NewInt(1)), "unknown supply never flags")
+	meta.supplies[token] = big.NewInt(100)
+	assert.False(t, cache.exceedsSupply(token, big.NewInt(101)), "failure cached within the retry interval")
+	assert.Equal(t, 1, meta.calls)
+
+	clock.Advance(tokenMetadataRetry)
+	assert.True(t, cache.exceedsSupply(token, big.NewInt(101)), "retried after the interval")
+	clock.Advance(2 * tokenMetadataRetry)
+	assert.False(t, cache.exceedsSupply(token, big.NewInt(100)))
+	assert.Equal(t, 2, meta.calls, "successes are cached for good")
+}
+
+// fakeContractCaller answers eth_call with a fixed uint256 per contract and
+// reverts for any other.
+type fakeContractCaller struct {
+	results map[common.Address]*big.Int
+	calls   int
+}
+
+func (f *fakeContractCaller) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	f.calls++
+	v, ok := f.results[*msg.To]
+	if !ok {
+		return nil, fmt.Errorf("execution reverted")
+	}
+	return common.LeftPadBytes(v.Bytes(), 32), nil
+}
+
+func TestChainWatcher_ExceedsTotalSupply(t *testing.T) {
+	client := newFakeEVMClient(1020)
+	watched := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	from := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	client.addLog(testTransferLog(1000, 0, from, watched, big.NewInt(1_000_000)))
+	client.addLog(testTransferLog(1000, 1, from, watched, big.NewInt(1_000_001)))
+
+	usdt := common.HexToAddress("0xdAC17F958D2ee523a2206206994597C13D831ec7")
+	caller := &fakeContractCaller{results: map[common.Address]*big.Int{usdt: big.NewInt(1_000_000)}}
+	w := newTestChainWatcher(t, client)
+	w.tokenMeta = newTokenMetadataCache(evmMetadataClient{caller: caller})
+	w.tokenMeta.fetchDecimals = false
+	w.tokenMeta.fetchSupply = true
+	w.AddAddress(watched)
+
+	events := collectEVMEvents(t, w, 1000, 1020)
+	require.Len(t, events, 2)
+	flagged := map[uint]bool{}
+	for _, e := range events {
+		flagged[e.LogIndex] = e.ExceedsTotalSupply
+	}
+	assert.False(t, flagged[0], "the whole supply is still plausible")
+	assert.True(t, flagged[1], "more than the total supply")
+	assert.Equal(t, 1, caller.calls, "supply is cached per token")
+}
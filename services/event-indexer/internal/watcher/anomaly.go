@@ -0,0 +1,35 @@
+package watcher
+
+import (
+	"bytes"
+	"sync/atomic"
+
+	"github.com/rs/zerolog/log"
+)
+
+// zeroAddressTransfers counts transfers from and to the zero address, across
+// all watchers.
+var zeroAddressTransfers atomic.Uint64
+
+// ZeroAddressTransfers returns how many zero-to-zero transfers have been
+// seen since startup.
+func ZeroAddressTransfers() uint64 {
+	return zeroAddressTransfers.Load()
+}
+
+// zeroAddress is the 20-byte address zero-to-zero transfers use on both sides.
+var zeroAddress [20]byte
+
+// isZeroAddressTopic reports whether an address topic holds the zero address.
+func isZeroAddressTopic(topic []byte) bool {
+	return len(topic) >= len(zeroAddress) && bytes.Equal(topic[len(topic)-len(zeroAddress):], zeroAddress[:])
+}
+
+// dropZeroToZero counts a transfer from and to the zero address, which is
+// neither a mint nor a burn but a malformed or malicious log, and reports
+// whether it should be dropped rather than emitted flagged.
+func dropZeroToZero(chainName, txHash string, emit bool) bool {
+	zeroAddressTransfers.Add(1)
+	log.Warn().Str("chain", chainName).Str("tx", txHash).Bool("emitted", emit).Msg("Zero-to-zero address transfer")
+	return !emit
+}
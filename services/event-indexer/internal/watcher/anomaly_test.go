@@ -0,0 +1,66 @@
+package watcher
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChainWatcher_ZeroToZeroTransfer(t *testing.T) {
+	client := newFakeEVMClient(1000)
+	zero := common.Address{}
+	minted := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	client.addLog(testTransferLog(990, 0, zero, zero, big.NewInt(1)))
+	client.addLog(testTransferLog(990, 1, zero, minted, big.NewInt(2)))
+
+	run := func(emit bool) []*ChainEvent {
+		w := newTestChainWatcher(t, client)
+		w.cfg.EmitZeroAddressTransfers = emit
+		w.scopedTokens = scopedEVMTokens([]string{"0xdAC17F958D2ee523a2206206994597C13D831ec7"})
+		return collectEVMEvents(t, w, 990, 1000)
+	}
+
+	t.Run("suppressed by default", func(t *testing.T) {
+		before := ZeroAddressTransfers()
+		events := run(false)
+		require.Len(t, events, 1, "the mint still goes through")
+		assert.Equal(t, "2", events[0].Value)
+		assert.False(t, events[0].ZeroAddressTransfer)
+		assert.Equal(t, before+1, ZeroAddressTransfers(), "anomaly counted")
+	})
+
+	t.Run("emitted flagged", func(t *testing.T) {
+		before := ZeroAddressTransfers()
+		events := run(true)
+		require.Len(t, events, 2)
+		for _, event := range events {
+			assert.Equal(t, event.Value == "1", event.ZeroAddressTransfer)
+		}
+		assert.Equal(t, before+1, ZeroAddressTransfers())
+	})
+}
+
+func TestTronWatcher_ZeroToZeroTransfer(t *testing.T) {
+	client := newFakeTronClient(200)
+	usdt, usdtAddr := testTronAddress(0xaa)
+	zero := make([]byte, 20)
+	client.addTransfer(190, "e190", usdt, zero, zero, big.NewInt(1))
+
+	for _, emit := range []bool{false, true} {
+		w := newTestTronWatcher(client)
+		w.cfg.EmitZeroAddressTransfers = emit
+		w.scopedTokens = scopedTronTokens([]string{usdtAddr})
+
+		before := ZeroAddressTransfers()
+		txs := collectTronTxs(t, w, 190, 200)
+		assert.Equal(t, before+1, ZeroAddressTransfers())
+		if emit {
+			assert.Equal(t, []string{"e190"}, txs)
+		} else {
+			assert.Empty(t, txs)
+		}
+	}
+}
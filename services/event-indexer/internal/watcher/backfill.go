@@ -0,0 +1,242 @@
+package watcher
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/rs/zerolog/log"
+)
+
+// defaultBackfillRange is the backfill step when the chain sets none.
+const defaultBackfillRange = 1000
+
+// errShuttingDown is returned by work refused during lame duck shutdown.
+var errShuttingDown = errors.New("watcher is shutting down")
+
+// errBackfillNeedsDedupe refuses a backfill that would deliver every event
+// in its range again.
+var errBackfillNeedsDedupe = errors.New("backfill requires DEDUPE_EVENTS")
+
+// BackfillProgress reports a backfill after each completed step.
+type BackfillProgress struct {
+	ChainID uint64
+	From    uint64
+	To      uint64
+	Through uint64 // last block scanned
+	Events  int    // events emitted so far
+}
+
+// Backfill rescans the historical blocks [from, to] of a chain for events
+// of the currently watched addresses, e.g. prior deposits to an address
+// just added. Blocks are scanned in steps of the chain's BackfillRange
+// through the same event path as live processing, with progress reported
+// after each step. It runs beside the live loop without moving its
+// checkpoint or reorg tracking, and only with DedupeEvents on, so events
+// still in the seen set aren't emitted again. That set is in memory and
+// bounded: events detected before a restart or since evicted are emitted
+// again, under the same EventID. to is capped at the head.
+func (mcw *MultiChainWatcher) Backfill(ctx context.Context, chainID, from, to uint64, progress func(BackfillProgress)) error {
+	if err := mcw.checkBackfill(chainID, from, to); err != nil {
+		return err
+	}
+	watchers, tronWatchers := mcw.chainWatchers()
+	if w, ok := watchers[chainID]; ok {
+		return w.backfillRange(ctx, from, to, progress)
+	}
+	return tronWatchers[chainID].backfillRange(ctx, from, to, progress)
+}
+
+// StartBackfill checks the chain and range, then runs Backfill in the
+// background, logging its progress. The error only covers the start.
+func (mcw *MultiChainWatcher) StartBackfill(ctx context.Context, chainID, from, to uint64) error {
+	if err := mcw.checkBackfill(chainID, from, to); err != nil {
+		return err
+	}
+
+	go func() {
+		err := mcw.Backfill(ctx, chainID, from, to, func(p BackfillProgress) {
+			log.Info().Uint64("chain_id", p.ChainID).Uint64("through", p.Through).Uint64("to", p.To).Int("events", p.Events).Msg("Backfill progress")
+		})
+		if err != nil {
+			log.Error().Err(err).Uint64("chain_id", chainID).Uint64("from", from).Uint64("to", to).Msg("Backfill failed")
+			return
+		}
+		log.Info().Uint64("chain_id", chainID).Uint64("from", from).Uint64("to", to).Msg("Backfill complete")
+	}()
+	return nil
+}
+
+// checkBackfill reports why a backfill of [from, to] on chainID can't run.
+func (mcw *MultiChainWatcher) checkBackfill(chainID, from, to uint64) error {
+	if mcw.dispatch.seen == nil {
+		return errBackfillNeedsDedupe
+	}
+	if from > to {
+		return fmt.Errorf("invalid backfill range %d-%d", from, to)
+	}
+	watchers, tronWatchers := mcw.chainWatchers()
+	if watchers[chainID] == nil && tronWatchers[chainID] == nil {
+		return fmt.Errorf("unknown chain %d", chainID)
+	}
+	return nil
+}
+
+// backfillRange rescans [from, to] of an EVM chain, one eth_getLogs call
+// per step. A step the provider rejects as too wide or too large is halved
+// and retried, down to a single block, and the size that worked is kept
+// for the chain's next steps and backfills. Failed transactions aren't
+// rescanned: that takes every block's transactions rather than its logs.
+func (w *ChainWatcher) backfillRange(ctx context.Context, from, to uint64, progress func(BackfillProgress)) error {
+	if !w.gate.enter() {
+		return errShuttingDown
+	}
+	defer w.gate.leave()
+
+	head, err := w.client.BlockNumber(ctx)
+	if err != nil {
+		return fmt.Errorf("get block number: %w", err)
+	}
+	if from > head {
+		return fmt.Errorf("backfill from %d is past the head %d", from, head)
+	}
+	to = min(to, head)
+
+	w.mu.RLock()
+	addresses := make([]common.Address, 0, len(w.addresses))
+	for addr := range w.addresses {
+		addresses = append(addresses, addr)
+	}
+	w.mu.RUnlock()
+	if len(addresses) == 0 && len(w.scopedTokens) == 0 {
+		return nil
+	}
+
+	log.Info().Str("chain", w.chainName).Uint64("from", from).Uint64("to", to).Msg("Backfilling blocks")
+	events := 0
+	for start := from; start <= to; {
+		if w.gate.isDraining() {
+			return errShuttingDown
+		}
+		step := w.backfillStepSize()
+		r := BlockRange{From: start, To: min(start+step-1, to)}
+		logs, err := w.backfillLogs(ctx, r, addresses)
+		if err != nil {
+			if isLogRangeLimitError(err) && step > 1 {
+				w.backfillStep.Store(step / 2)
+				log.Warn().Err(err).Str("chain", w.chainName).Uint64("range", step/2).Msg("Shrinking backfill eth_getLogs block range")
+				continue
+			}
+			return fmt.Errorf("blocks %d-%d: %w", r.From, r.To, err)
+		}
+
+		events += w.emitBackfilledLogs(ctx, logs, addresses, head)
+		if progress != nil {
+			progress(BackfillProgress{ChainID: w.chainID, From: from, To: to, Through: r.To, Events: events})
+		}
+		if r.To == to {
+			break
+		}
+		start = r.To + 1
+	}
+	return nil
+}
+
+// backfillStepSize is the block range of the next backfill step: the last
+// one that worked, or the configured BackfillRange.
+func (w *ChainWatcher) backfillStepSize() uint64 {
+	if step := w.backfillStep.Load(); step > 0 {
+		return step
+	}
+	return cmp.Or(w.cfg.BackfillRange, defaultBackfillRange)
+}
+
+// backfillLogs fetches the logs of r. A single block still too large for
+// one call is fetched with the narrowed queries of live processing.
+func (w *ChainWatcher) backfillLogs(ctx context.Context, r BlockRange, addresses []common.Address) ([]types.Log, error) {
+	query := ethereum.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(r.From),
+		ToBlock:   new(big.Int).SetUint64(r.To),
+		Topics:    [][]common.Hash{w.logTopics()},
+	}
+	if w.cfg.CaptureUnknownLogs {
+		query.Topics = nil
+	}
+	logs, err := retryRPC(ctx, w.cfg.RPCRetry, w.chainName, "FilterLogs", func() ([]types.Log, error) {
+		return w.client.FilterLogs(ctx, query)
+	})
+	if r.From == r.To && isSizeLimitError(err) {
+		log.Warn().Err(err).Uint64("block", r.From).Str("chain", w.chainName).Msg("Logs response too large, retrying with narrowed queries")
+		return w.filterLogsNarrowed(ctx, r.From, addresses)
+	}
+	return logs, err
+}
+
+// emitBackfilledLogs emits the events in logs and returns their number.
+func (w *ChainWatcher) emitBackfilledLogs(ctx context.Context, logs []types.Log, addresses []common.Address, head uint64) int {
+	emitted := 0
+	timestamps := make(map[uint64]time.Time)
+	fees := make(map[common.Hash]*txFee)
+	for _, vLog := range logs {
+		timestamp, ok := timestamps[vLog.BlockNumber]
+		if !ok {
+			timestamp = time.Now()
+			if header, err := w.client.HeaderByNumber(ctx, new(big.Int).SetUint64(vLog.BlockNumber)); err == nil {
+				timestamp = time.Unix(int64(header.Time), 0)
+			} else {
+				log.Warn().Err(err).Uint64("block", vLog.BlockNumber).Str("chain", w.chainName).Msg("Failed to get block header")
+			}
+			timestamps[vLog.BlockNumber] = timestamp
+		}
+		if timestamp.Before(w.cfg.MinEventTimestamp) {
+			continue
+		}
+		if w.processLog(ctx, vLog, addresses, head, timestamp, fees) {
+			emitted++
+		}
+	}
+	return emitted
+}
+
+// backfillRange rescans [from, to] of a TRON chain block by block.
+func (w *TronWatcher) backfillRange(ctx context.Context, from, to uint64, progress func(BackfillProgress)) error {
+	if !w.gate.enter() {
+		return errShuttingDown
+	}
+	defer w.gate.leave()
+
+	block, err := tronCall(ctx, w, "GetNowBlock", w.client.GetNowBlock)
+	if err != nil {
+		return fmt.Errorf("get head block: %w", err)
+	}
+	head := uint64(block.GetBlockHeader().GetRawData().GetNumber())
+	if from > head {
+		return fmt.Errorf("backfill from %d is past the head %d", from, head)
+	}
+	to = min(to, head)
+
+	log.Info().Str("chain", w.chainName).Uint64("from", from).Uint64("to", to).Msg("Backfilling blocks")
+	events := 0
+	for _, r := range calculateBlockRanges(from, to, cmp.Or(w.cfg.BackfillRange, defaultBackfillRange)) {
+		for blockNum := r.From; blockNum <= r.To; blockNum++ {
+			if w.gate.isDraining() {
+				return errShuttingDown
+			}
+			n, err := w.scanBlock(ctx, int64(blockNum), int64(head), false)
+			if err != nil {
+				return fmt.Errorf("block %d: %w", blockNum, err)
+			}
+			events += n
+		}
+		if progress != nil {
+			progress(BackfillProgress{ChainID: w.chainID, From: from, To: to, Through: r.To, Events: events})
+		}
+	}
+	return nil
+}
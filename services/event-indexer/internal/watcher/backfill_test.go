@@ -0,0 +1,143 @@
+package watcher
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMultiChainWatcher_BackfillEVM(t *testing.T) {
+	ctx := context.Background()
+	client := newFakeEVMClient(1000)
+	watched := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	other := common.HexToAddress("0x3333333333333333333333333333333333333333")
+	client.addLog(testTransferLog(500, 0, other, watched, big.NewInt(1)))
+	client.addLog(testTransferLog(750, 0, watched, other, big.NewInt(2)))
+	client.addLog(testTransferLog(990, 0, other, other, big.NewInt(3)))
+
+	w := newTestChainWatcher(t, client)
+	w.cfg.BackfillRange = 200
+	w.AddAddress(watched)
+	w.dispatch.seen = newSeenEvents(0)
+	mcw := &MultiChainWatcher{watchers: map[uint64]*ChainWatcher{1: w}, dispatch: w.dispatch}
+
+	var mu sync.Mutex
+	var blocks []uint64
+	w.dispatch.addHandler(func(event *ChainEvent) error {
+		mu.Lock()
+		defer mu.Unlock()
+		blocks = append(blocks, event.BlockNumber)
+		return nil
+	})
+
+	var progress []BackfillProgress
+	require.NoError(t, mcw.Backfill(ctx, 1, 400, 5000, func(p BackfillProgress) {
+		progress = append(progress, p)
+	}))
+	w.gate.inflight.Wait()
+
+	mu.Lock()
+	assert.ElementsMatch(t, []uint64{500, 750}, blocks)
+	mu.Unlock()
+	require.Len(t, progress, 4, "400-599, 600-799, 800-999, 1000")
+	assert.Equal(t, uint64(599), progress[0].Through)
+	assert.Equal(t, 1, progress[0].Events)
+	assert.Equal(t, BackfillProgress{ChainID: 1, From: 400, To: 1000, Through: 1000, Events: 2}, progress[3])
+	assert.Zero(t, w.checkpoint.Load(), "the live checkpoint doesn't move")
+
+	// The live loop reaching a backfilled block doesn't emit it again
+	assert.Empty(t, collectEVMEvents(t, w, 500, 1000))
+
+	assert.ErrorContains(t, mcw.Backfill(ctx, 56, 1, 2, nil), "unknown chain 56")
+	assert.ErrorContains(t, mcw.Backfill(ctx, 1, 2, 1, nil), "invalid backfill range")
+	assert.ErrorContains(t, mcw.Backfill(ctx, 1, 1001, 1002, nil), "past the head")
+	assert.ErrorContains(t, mcw.StartBackfill(ctx, 56, 1, 2), "unknown chain 56")
+
+	// Without detection dedupe a backfill would deliver every event again
+	mcw.dispatch.seen = nil
+	assert.ErrorIs(t, mcw.Backfill(ctx, 1, 400, 500, nil), errBackfillNeedsDedupe)
+	assert.ErrorIs(t, mcw.StartBackfill(ctx, 1, 400, 500), errBackfillNeedsDedupe)
+}
+
+func TestMultiChainWatcher_BackfillTron(t *testing.T) {
+	client := newFakeTronClient(200)
+	token, _ := testTronAddress(0xaa)
+	from, _ := testTronAddress(0x11)
+	to, toAddr := testTronAddress(0x22)
+	client.addTransfer(150, "a150", token, from, to, big.NewInt(1))
+	client.addTransfer(152, "a152", token, from, to, big.NewInt(2))
+
+	w := newTestTronWatcher(client)
+	w.cfg.BackfillRange = 2
+	w.cfg.EmitBlockEvents = true
+	w.AddTronAddress(toAddr)
+	w.dispatch.seen = newSeenEvents(0)
+	mcw := &MultiChainWatcher{tronWatchers: map[uint64]*TronWatcher{w.chainID: w}, dispatch: w.dispatch}
+
+	var mu sync.Mutex
+	var txs []string
+	w.dispatch.addHandler(func(event *ChainEvent) error {
+		mu.Lock()
+		defer mu.Unlock()
+		txs = append(txs, event.TxHash)
+		return nil
+	})
+
+	var progress []BackfillProgress
+	require.NoError(t, mcw.Backfill(context.Background(), w.chainID, 150, 152, func(p BackfillProgress) {
+		progress = append(progress, p)
+	}))
+	w.gate.inflight.Wait()
+
+	mu.Lock()
+	assert.ElementsMatch(t, []string{"a150", "a152"}, txs, "no block events for backfilled blocks")
+	mu.Unlock()
+	require.Len(t, progress, 2)
+	assert.Equal(t, uint64(151), progress[0].Through)
+	assert.Equal(t, BackfillProgress{ChainID: w.chainID, From: 150, To: 152, Through: 152, Events: 2}, progress[1])
+	assert.Empty(t, w.reorgs.recent())
+}
+
+func TestChainWatcher_BackfillShrinksRange(t *testing.T) {
+	client := newFakeEVMClient(1000)
+	watched := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	other := common.HexToAddress("0x3333333333333333333333333333333333333333")
+	for block := uint64(900); block < 904; block++ {
+		client.addLog(testTransferLog(block, 0, other, watched, big.NewInt(1)))
+	}
+	client.maxLogs = 2 // the provider rejects responses of more than 2 logs
+
+	w := newTestChainWatcher(t, client)
+	w.cfg.BackfillRange = 50
+	w.AddAddress(watched)
+	var progress []BackfillProgress
+	require.NoError(t, w.backfillRange(context.Background(), 800, 1000, func(p BackfillProgress) {
+		progress = append(progress, p)
+	}))
+	w.gate.inflight.Wait()
+
+	assert.Equal(t, 4, progress[len(progress)-1].Events, "no range lost")
+	assert.Equal(t, uint64(1000), progress[len(progress)-1].Through)
+	// 50 → 25 → 12 → 6 → 3 → 1 covers 900-903 two logs at a time at most
+	assert.Equal(t, uint64(1), w.backfillStep.Load(), "the working range is remembered")
+	assert.Equal(t, uint64(1), w.backfillStepSize())
+}
+
+func TestIsLogRangeLimitError(t *testing.T) {
+	for _, msg := range []string{
+		"query returned more than 10000 results",
+		"Log response size exceeded. You can make eth_getLogs requests with up to a 2K block range",
+		"eth_getLogs is limited to a 10,000 range",
+		"exceed maximum block range: 5000",
+	} {
+		assert.True(t, isLogRangeLimitError(errors.New(msg)), msg)
+	}
+	assert.False(t, isLogRangeLimitError(errors.New("connection refused")))
+	assert.False(t, isLogRangeLimitError(nil))
+}
@@ -0,0 +1,245 @@
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// EventTypeBalanceDelta is the event type of running balance updates.
+const EventTypeBalanceDelta = "balance_delta"
+
+// balanceStoreTimeout bounds each balance store call made while a block is
+// being processed.
+const balanceStoreTimeout = 5 * time.Second
+
+// BalanceDelta is a change to a watched address's running net balance in
+// one token, as accumulated from the transfers the indexer has seen.
+type BalanceDelta struct {
+	Address string
+	Token   string   // token contract, empty for the native asset
+	Delta   *big.Int // signed change, in raw token units
+	Balance *big.Int // running net balance after the change
+}
+
+// BalanceStore persists running balances. Both operations are idempotent
+// per eventID and address: a delta is applied at most once, and only an
+// applied delta is reverted.
+type BalanceStore interface {
+	// ApplyBalanceDelta adds delta to the balance and returns the new one.
+	// applied is false if the delta was already applied.
+	ApplyBalanceDelta(ctx context.Context, chainID uint64, eventID, address, token string, delta *big.Int) (balance *big.Int, applied bool, err error)
+	// RevertBalanceDelta reverses a previously applied delta and returns the
+	// reversing change and the new balance. reverted is false if nothing
+	// was applied for eventID.
+	RevertBalanceDelta(ctx context.Context, chainID uint64, eventID, address, token string) (delta, balance *big.Int, reverted bool, err error)
+}
+
+// balanceEntry is a delta applied for a recent block, kept so a reorg of
+// that block can reverse it.
+type balanceEntry struct {
+	event   *ChainEvent // the transfer
+	address string
+}
+
+// balanceTracker maintains per-address running balances in a BalanceStore
+// and emits a balance_delta event for every change. Deltas of recent blocks
+// are journaled per chain so a reorg can reverse them; the journal keeps
+// each chain's confirmation depth of blocks, and is not persisted, so a
+// reorg of blocks processed before a restart isn't reversed.
+type balanceTracker struct {
+	store     BalanceStore
+	isWatched func(chainID uint64, addr string) bool
+
+	mu      sync.Mutex
+	depth   map[uint64]uint64 // chain → blocks journaled
+	latest  map[uint64]uint64 // chain → highest block seen
+	journal map[uint64][]balanceEntry
+}
+
+func newBalanceTracker(store BalanceStore, depth map[uint64]uint64, isWatched func(chainID uint64, addr string) bool) *balanceTracker {
+	return &balanceTracker{
+		store:     store,
+		isWatched: isWatched,
+		depth:     depth,
+		latest:    make(map[uint64]uint64),
+		journal:   make(map[uint64][]balanceEntry),
+	}
+}
+
+// isBalanceTransfer reports whether event moves a balance.
+func isBalanceTransfer(event *ChainEvent) bool {
+	switch event.EventType {
+	case "transfer", "trc20_transfer":
+	default:
+		return false
+	}
+	return event.FinalityStatus != FinalityReorged && event.FinalityStatus != FinalityFailed
+}
+
+// apply records the deltas of a transfer for each watched side, and
+// returns the balance_delta events of those not applied before. Repeated
+// deliveries of the same transfer (confirmation and milestone copies) are
+// no-ops in the store.
+func (b *balanceTracker) apply(event *ChainEvent) []*ChainEvent {
+	if b == nil || !isBalanceTransfer(event) || event.FromAddress == event.ToAddress {
+		return nil
+	}
+	amount, ok := new(big.Int).SetString(event.Value, 10)
+	if !ok || amount.Sign() == 0 {
+		return nil
+	}
+
+	var out []*ChainEvent
+	for _, side := range []struct {
+		address string
+		delta   *big.Int
+	}{
+		{event.FromAddress, new(big.Int).Neg(amount)},
+		{event.ToAddress, amount},
+	} {
+		if side.address == "" || !b.isWatched(event.ChainID, side.address) {
+			continue
+		}
+		b.record(event, side.address)
+
+		ctx, cancel := context.WithTimeout(context.Background(), balanceStoreTimeout)
+		balance, applied, err := b.store.ApplyBalanceDelta(ctx, event.ChainID, event.EventID, tagKey(side.address), tagKey(event.TokenAddress), side.delta)
+		cancel()
+		if err != nil {
+			log.Error().Err(err).Str("chain", event.ChainName).Str("event_id", event.EventID).Str("address", side.address).Msg("Failed to apply balance delta")
+			continue
+		}
+		if applied {
+			out = append(out, balanceDeltaEvent(event, side.address, side.delta, balance))
+		}
+	}
+	return out
+}
+
+// record journals an applied side of event and forgets entries that fell
+// below the chain's confirmation depth.
+func (b *balanceTracker) record(event *ChainEvent, address string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	chainID := event.ChainID
+	if event.BlockNumber > b.latest[chainID] {
+		b.latest[chainID] = event.BlockNumber
+	}
+	floor := confirmedFrontier(b.latest[chainID], b.depth[chainID])
+
+	journal := b.journal[chainID]
+	kept := journal[:0]
+	for _, entry := range journal {
+		if entry.event.BlockNumber > floor {
+			kept = append(kept, entry)
+		}
+	}
+	for i := len(kept); i < len(journal); i++ {
+		journal[i] = balanceEntry{}
+	}
+	for _, entry := range kept {
+		if entry.event.EventID == event.EventID && entry.address == address {
+			b.journal[chainID] = kept
+			return
+		}
+	}
+	b.journal[chainID] = append(kept, balanceEntry{event: event, address: address})
+}
+
+// revert reverses the deltas applied for blocks from fromBlock on, which a
+// reorg replaced, and returns the reversing balance_delta events marked
+// FinalityReorged.
+func (b *balanceTracker) revert(chainID, fromBlock uint64) []*ChainEvent {
+	if b == nil {
+		return nil
+	}
+
+	b.mu.Lock()
+	var orphaned []balanceEntry
+	journal := b.journal[chainID]
+	kept := journal[:0]
+	for _, entry := range journal {
+		if entry.event.BlockNumber < fromBlock {
+			kept = append(kept, entry)
+			continue
+		}
+		orphaned = append(orphaned, entry)
+	}
+	for i := len(kept); i < len(journal); i++ {
+		journal[i] = balanceEntry{}
+	}
+	b.journal[chainID] = kept
+	b.mu.Unlock()
+
+	var out []*ChainEvent
+	for _, entry := range orphaned {
+		event := entry.event
+		ctx, cancel := context.WithTimeout(context.Background(), balanceStoreTimeout)
+		delta, balance, reverted, err := b.store.RevertBalanceDelta(ctx, chainID, event.EventID, tagKey(entry.address), tagKey(event.TokenAddress))
+		cancel()
+		if err != nil {
+			log.Error().Err(err).Str("chain", event.ChainName).Str("event_id", event.EventID).Str("address", entry.address).Msg("Failed to revert balance delta")
+			continue
+		}
+		if !reverted {
+			continue
+		}
+		reversal := balanceDeltaEvent(event, entry.address, delta, balance)
+		reversal.Confirmed = false
+		reversal.FinalityStatus = FinalityReorged
+		out = append(out, reversal)
+	}
+	return out
+}
+
+// balanceDeltaEvent builds the balance_delta event of transfer for address.
+func balanceDeltaEvent(transfer *ChainEvent, address string, delta, balance *big.Int) *ChainEvent {
+	return &ChainEvent{
+		ChainID:        transfer.ChainID,
+		ChainName:      transfer.ChainName,
+		EventType:      EventTypeBalanceDelta,
+		TxHash:         transfer.TxHash,
+		BlockNumber:    transfer.BlockNumber,
+		FromAddress:    transfer.FromAddress,
+		ToAddress:      transfer.ToAddress,
+		Value:          delta.String(),
+		TokenAddress:   transfer.TokenAddress,
+		TokenSymbol:    transfer.TokenSymbol,
+		Timestamp:      transfer.Timestamp,
+		Confirmed:      transfer.Confirmed,
+		Confirmations:  transfer.Confirmations,
+		TouchesWatched: true,
+		WatchedAddress: address,
+		EventID:        fmt.Sprintf("%s:%s", transfer.EventID, tagKey(address)),
+		Balance: &BalanceDelta{
+			Address: address,
+			Token:   transfer.TokenAddress,
+			Delta:   delta,
+			Balance: balance,
+		},
+	}
+}
+
+// SetBalanceStore enables running balances: every transfer touching a
+// watched address also emits a balance_delta event carrying the address's
+// new cumulative balance, kept in store. Must be called before Start.
+func (mcw *MultiChainWatcher) SetBalanceStore(store BalanceStore) {
+	watchers, tronWatchers := mcw.chainWatchers()
+	depth := make(map[uint64]uint64, len(watchers)+len(tronWatchers))
+	for chainID, w := range watchers {
+		depth[chainID] = maxConfirmations(w.cfg)
+	}
+	for chainID, tw := range tronWatchers {
+		depth[chainID] = maxConfirmations(tw.cfg)
+	}
+	for _, cfg := range mcw.pendingChains() {
+		depth[cfg.ChainID] = maxConfirmations(cfg)
+	}
+	mcw.dispatch.balances = newBalanceTracker(store, depth, mcw.isWatched)
+}
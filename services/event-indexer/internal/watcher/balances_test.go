@@ -0,0 +1,143 @@
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memBalanceStore is an in-memory BalanceStore.
+type memBalanceStore struct {
+	mu       sync.Mutex
+	balances map[string]*big.Int
+	applied  map[string]*big.Int // eventID/address → delta
+}
+
+func newMemBalanceStore() *memBalanceStore {
+	return &memBalanceStore{balances: make(map[string]*big.Int), applied: make(map[string]*big.Int)}
+}
+
+func (m *memBalanceStore) balance(chainID uint64, address, token string) *big.Int {
+	key := fmt.Sprintf("%d/%s/%s", chainID, address, token)
+	if m.balances[key] == nil {
+		m.balances[key] = new(big.Int)
+	}
+	return m.balances[key]
+}
+
+func (m *memBalanceStore) ApplyBalanceDelta(ctx context.Context, chainID uint64, eventID, address, token string, delta *big.Int) (*big.Int, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	balance := m.balance(chainID, address, token)
+	if _, ok := m.applied[eventID+"/"+address]; ok {
+		return new(big.Int).Set(balance), false, nil
+	}
+	m.applied[eventID+"/"+address] = delta
+	balance.Add(balance, delta)
+	return new(big.Int).Set(balance), true, nil
+}
+
+func (m *memBalanceStore) RevertBalanceDelta(ctx context.Context, chainID uint64, eventID, address, token string) (*big.Int, *big.Int, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	applied, ok := m.applied[eventID+"/"+address]
+	if !ok {
+		return nil, nil, false, nil
+	}
+	delete(m.applied, eventID+"/"+address)
+	reversal := new(big.Int).Neg(applied)
+	balance := m.balance(chainID, address, token)
+	balance.Add(balance, reversal)
+	return reversal, new(big.Int).Set(balance), true, nil
+}
+
+func TestChainWatcher_BalanceDeltas(t *testing.T) {
+	ctx := context.Background()
+	client := newFakeEVMClient(1000)
+	watched := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	other := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	client.addLog(testTransferLog(1000, 0, other, watched, big.NewInt(100)))
+	client.addLog(testTransferLog(1000, 1, other, other, big.NewInt(999))) // not watched
+	client.addLog(testTransferLog(1001, 0, watched, other, big.NewInt(30)))
+	client.addLog(testTransferLog(1002, 0, other, watched, big.NewInt(5)))
+
+	w := newTestChainWatcher(t, client)
+	w.AddAddress(watched)
+	w.dispatch.balances = newBalanceTracker(newMemBalanceStore(), map[uint64]uint64{1: w.cfg.Confirmations},
+		func(chainID uint64, addr string) bool { return w.isWatched(addr) })
+
+	var mu sync.Mutex
+	var transfers, deltas []*ChainEvent
+	w.dispatch.addHandler(func(event *ChainEvent) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if event.EventType == EventTypeBalanceDelta {
+			deltas = append(deltas, event)
+		} else {
+			transfers = append(transfers, event)
+		}
+		return nil
+	})
+	type change struct {
+		block          uint64
+		delta, balance string
+		status         FinalityStatus
+	}
+	changes := func() []change {
+		mu.Lock()
+		defer mu.Unlock()
+		out := make([]change, 0, len(deltas))
+		for _, e := range deltas {
+			require.NotNil(t, e.Balance)
+			assert.Equal(t, watched.Hex(), e.Balance.Address)
+			assert.Equal(t, e.Balance.Delta.String(), e.Value)
+			out = append(out, change{e.BlockNumber, e.Balance.Delta.String(), e.Balance.Balance.String(), e.FinalityStatus})
+		}
+		return out
+	}
+
+	last := w.poll(ctx, 999)
+	client.head = 1001
+	last = w.poll(ctx, last)
+	w.gate.inflight.Wait()
+
+	// Deltas accumulate into the running balance
+	assert.ElementsMatch(t, []change{
+		{1000, "100", "100", FinalityPending},
+		{1001, "-30", "70", FinalityPending},
+	}, changes())
+
+	// Redelivering a transfer (e.g. its confirmed copy) doesn't apply it twice
+	var confirmed ChainEvent
+	for _, e := range transfers {
+		if e.BlockNumber == 1000 && e.ToAddress == watched.Hex() {
+			confirmed = *e
+		}
+	}
+	confirmed.Confirmed = true
+	confirmed.FinalityStatus = ""
+	w.gate.enter()
+	w.dispatch.dispatch(&w.gate, &confirmed)
+	w.gate.leave()
+	w.gate.inflight.Wait()
+	assert.Len(t, changes(), 2)
+
+	// The block holding the outgoing transfer is replaced: its delta is reversed
+	client.reorg(1001)
+	client.head = 1002
+	w.poll(ctx, last)
+	w.gate.drain()
+
+	assert.ElementsMatch(t, []change{
+		{1000, "100", "100", FinalityPending},
+		{1001, "-30", "70", FinalityPending},
+		{1001, "30", "100", FinalityReorged},
+		{1002, "5", "105", FinalityPending},
+	}, changes())
+}
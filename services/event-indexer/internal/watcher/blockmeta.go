@@ -0,0 +1,55 @@
+package watcher
+
+import (
+	"context"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/rs/zerolog/log"
+)
+
+// EventTypeBlockProcessed is emitted once per processed block when block
+// events are enabled, whether or not the block held a watched transfer.
+const EventTypeBlockProcessed = "block_processed"
+
+// BlockMeta describes a processed block.
+type BlockMeta struct {
+	Hash          string // empty if the header couldn't be fetched
+	TxCount       int    // -1 if unknown
+	MatchedEvents int    // events emitted for the block
+}
+
+// blockProcessedEvent builds a block_processed event. It's always final:
+// progress is reported once, and reorgs are reported separately.
+func blockProcessedEvent(chainID uint64, chainName string, blockNumber uint64, timestamp time.Time, meta BlockMeta) *ChainEvent {
+	return &ChainEvent{
+		ChainID:     chainID,
+		ChainName:   chainName,
+		EventType:   EventTypeBlockProcessed,
+		BlockNumber: blockNumber,
+		Timestamp:   timestamp,
+		Confirmed:   true,
+		Block:       &meta,
+	}
+}
+
+// emitBlockProcessed emits an EVM block's metadata event. Without a header
+// the hash is empty and the transaction count unknown.
+func (w *ChainWatcher) emitBlockProcessed(ctx context.Context, blockNumber uint64, header *types.Header, timestamp time.Time, matched int) {
+	meta := BlockMeta{TxCount: -1, MatchedEvents: matched}
+	if header != nil {
+		meta.Hash = header.Hash().Hex()
+		if count, err := w.client.TransactionCount(ctx, header.Hash()); err == nil {
+			meta.TxCount = int(count)
+		} else {
+			log.Warn().Err(err).Uint64("block", blockNumber).Str("chain", w.chainName).Msg("Failed to get block transaction count")
+		}
+	}
+	w.dispatch.dispatch(&w.gate, blockProcessedEvent(w.chainID, w.chainName, blockNumber, timestamp, meta))
+}
+
+// emitBlockProcessed emits a TRON block's metadata event.
+func (w *TronWatcher) emitBlockProcessed(blockNum int64, hash string, timestamp time.Time, txCount, matched int) {
+	meta := BlockMeta{Hash: hash, TxCount: txCount, MatchedEvents: matched}
+	w.dispatch.dispatch(&w.gate, blockProcessedEvent(w.chainID, w.chainName, uint64(blockNum), timestamp, meta))
+}
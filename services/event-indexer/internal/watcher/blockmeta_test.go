@@ -0,0 +1,104 @@
+package watcher
+
+import (
+	"context"
+	"encoding/hex"
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// blockCollector records block_processed events by block number.
+type blockCollector struct {
+	mu     sync.Mutex
+	blocks map[uint64]*ChainEvent
+	count  int
+}
+
+func (c *blockCollector) handle(event *ChainEvent) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if event.EventType == EventTypeBlockProcessed {
+		if c.blocks == nil {
+			c.blocks = make(map[uint64]*ChainEvent)
+		}
+		c.blocks[event.BlockNumber] = event
+		c.count++
+	}
+	return nil
+}
+
+func TestChainWatcher_BlockProcessedEvents(t *testing.T) {
+	client := newFakeEVMClient(1000)
+	watched := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	other := common.HexToAddress("0x3333333333333333333333333333333333333333")
+	client.addLog(testTransferLog(991, 0, other, watched, big.NewInt(5)))
+	client.addLog(testTransferLog(991, 1, other, other, big.NewInt(6)))
+	for nonce := uint64(0); nonce < 2; nonce++ {
+		tx := types.NewTx(&types.LegacyTx{Nonce: nonce, To: &other})
+		client.addTx(tx.Hash(), tx, &types.Receipt{BlockNumber: big.NewInt(991)}, other)
+	}
+
+	w := newTestChainWatcher(t, client)
+	w.AddAddress(watched)
+	assert.Len(t, collectEVMEvents(t, w, 991, 1000), 1, "disabled by default")
+
+	w = newTestChainWatcher(t, client)
+	w.cfg.EmitBlockEvents = true
+	w.AddAddress(watched)
+	var collected blockCollector
+	w.dispatch.addHandler(collected.handle)
+
+	w.gate.enter()
+	for block := uint64(990); block <= 992; block++ {
+		w.processBlock(context.Background(), block, 1000)
+	}
+	w.gate.leave()
+	w.gate.inflight.Wait()
+
+	assert.Equal(t, 3, collected.count, "one event per block")
+	require.Len(t, collected.blocks, 3)
+	meta := collected.blocks[991]
+	assert.Equal(t, client.headerAt(991).Hash().Hex(), meta.Block.Hash)
+	assert.Equal(t, 2, meta.Block.TxCount)
+	assert.Equal(t, 1, meta.Block.MatchedEvents)
+	assert.Equal(t, int64(991*12), meta.Timestamp.Unix())
+	assert.Equal(t, 0, collected.blocks[990].Block.MatchedEvents)
+	assert.Equal(t, 0, collected.blocks[992].Block.TxCount)
+}
+
+func TestTronWatcher_BlockProcessedEvents(t *testing.T) {
+	client := newFakeTronClient(200)
+	token, _ := testTronAddress(0xaa)
+	from, _ := testTronAddress(0x11)
+	to, toAddr := testTronAddress(0x22)
+	client.addTransfer(191, "a191", token, from, to, big.NewInt(1))
+	client.addTransfer(191, "b191", token, from, from, big.NewInt(2))
+
+	w := newTestTronWatcher(client)
+	w.cfg.EmitBlockEvents = true
+	w.AddTronAddress(toAddr)
+	var collected blockCollector
+	w.dispatch.addHandler(collected.handle)
+
+	w.gate.enter()
+	for block := int64(190); block <= 192; block++ {
+		w.processBlock(context.Background(), block, 200)
+	}
+	w.gate.leave()
+	w.gate.inflight.Wait()
+
+	assert.Equal(t, 3, collected.count, "one event per block")
+	require.Len(t, collected.blocks, 3)
+	meta := collected.blocks[191]
+	assert.Equal(t, hex.EncodeToString(client.blockID(191)), meta.Block.Hash)
+	assert.Equal(t, 2, meta.Block.TxCount)
+	assert.Equal(t, 1, meta.Block.MatchedEvents)
+	assert.True(t, meta.Confirmed)
+	assert.Equal(t, 0, collected.blocks[190].Block.TxCount)
+}
@@ -0,0 +1,176 @@
+package watcher
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/fbsobreira/gotron-sdk/pkg/proto/core"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/protobuf/proto"
+)
+
+// TRC-20 function selectors recognized in TriggerSmartContract calldata
+const (
+	selectorTransfer     = "a9059cbb" // transfer(address,uint256)
+	selectorTransferFrom = "23b872dd" // transferFrom(address,address,uint256)
+)
+
+// calldataTransfer is a token transfer decoded from call data. from is nil
+// for transfer(), where the sender is the transaction owner.
+type calldataTransfer struct {
+	from  []byte
+	to    []byte
+	value *big.Int
+}
+
+// decodeTransferCalldata decodes transfer or transferFrom call data. Any
+// other selector, a short payload or a malformed address word is rejected.
+func decodeTransferCalldata(data []byte) (calldataTransfer, bool) {
+	if len(data) < 4 {
+		return calldataTransfer{}, false
+	}
+
+	var words int
+	switch fmt.Sprintf("%x", data[:4]) {
+	case selectorTransfer:
+		words = 2
+	case selectorTransferFrom:
+		words = 3
+	default:
+		return calldataTransfer{}, false
+	}
+
+	args := data[4:]
+	if len(args) < words*32 {
+		return calldataTransfer{}, false
+	}
+	word := func(i int) []byte { return args[i*32 : (i+1)*32] }
+
+	addrs := make([][]byte, 0, words-1)
+	for i := 0; i < words-1; i++ {
+		w := word(i)
+		for _, b := range w[:12] {
+			if b != 0 {
+				return calldataTransfer{}, false
+			}
+		}
+		addrs = append(addrs, w[12:])
+	}
+
+	transfer := calldataTransfer{value: new(big.Int).SetBytes(word(words - 1))}
+	if words == 3 {
+		transfer.from, transfer.to = addrs[0], addrs[1]
+	} else {
+		transfer.to = addrs[0]
+	}
+	return transfer, true
+}
+
+// triggerSmartContract returns the transaction's TriggerSmartContract, or
+// nil if its first contract is of another type.
+func triggerSmartContract(tx *core.Transaction) *core.TriggerSmartContract {
+	contracts := tx.GetRawData().GetContract()
+	if len(contracts) == 0 || contracts[0].GetType() != core.Transaction_Contract_TriggerSmartContract {
+		return nil
+	}
+
+	trigger := &core.TriggerSmartContract{}
+	if err := proto.Unmarshal(contracts[0].GetParameter().GetValue(), trigger); err != nil {
+		return nil
+	}
+	return trigger
+}
+
+// processTransferCalldata emits a transfer decoded from the transaction's
+// call data. It's only consulted for transactions without a Transfer log,
+// which covers tokens that move balances without emitting one, and only if
+// the call succeeded. It reports whether an event was emitted.
+func (w *TronWatcher) processTransferCalldata(tx *core.Transaction, txID string, txInfo *core.TransactionInfo, fee *txFee, blockNum, currentBlock int64, timestamp time.Time) bool {
+	trigger := triggerSmartContract(tx)
+	if trigger == nil {
+		return false
+	}
+	transfer, ok := decodeTransferCalldata(trigger.GetData())
+	if !ok {
+		return false
+	}
+	if txInfo.GetResult() != core.TransactionInfo_SUCESS ||
+		txInfo.GetReceipt().GetResult() != core.Transaction_Result_SUCCESS {
+		return false
+	}
+
+	fromAddr := hexBytesToTronAddress(trigger.GetOwnerAddress(), w.addrPrefix)
+	if transfer.from != nil {
+		fromAddr = hexBytesToTronAddress(transfer.from, w.addrPrefix)
+	}
+	toAddr := hexBytesToTronAddress(transfer.to, w.addrPrefix)
+	tokenAddr := hexBytesToTronAddress(trigger.GetContractAddress(), w.addrPrefix)
+	if tokenAddr == "" || !w.tokenWatched(tokenAddr) {
+		return false
+	}
+
+	w.mu.RLock()
+	var watched string
+	switch {
+	case w.addresses[toAddr]:
+		watched = toAddr
+	case w.addresses[fromAddr]:
+		watched = fromAddr
+	}
+	w.mu.RUnlock()
+	isRelevant := watched != ""
+
+	if !isRelevant && (!w.scopedTokens[tokenAddr] || w.cfg.DropUnwatchedTransfers) {
+		return false
+	}
+
+	confirmations := confirmationsAt(uint64(currentBlock), uint64(blockNum))
+	confirmed := w.isFinal(confirmations >= w.confirmations.required(), blockNum)
+
+	event := &ChainEvent{
+		ChainID:         w.chainID,
+		ChainName:       w.chainName,
+		EventType:       "trc20_transfer",
+		TxHash:          txID,
+		BlockNumber:     uint64(blockNum),
+		FromAddress:     fromAddr,
+		ToAddress:       toAddr,
+		Value:           transfer.value.String(),
+		NormalizedValue: w.normalizeValue(tokenAddr, transfer.value),
+		TokenAddress:    tokenAddr,
+		Timestamp:       timestamp,
+		Confirmed:       confirmed,
+		Confirmations:   confirmations,
+		TouchesWatched:  isRelevant,
+		WatchedAddress:  watched,
+		Memo:            tronTxMemo(tx),
+		EventID:         fmt.Sprintf("%d:%s:call", w.chainID, txID),
+
+		DecodedFromCalldata: true,
+		ExceedsTotalSupply:  w.exceedsTotalSupply(tokenAddr, transfer.value),
+	}
+	w.tokens.annotate(event, transfer.value)
+	if belowMinTransferValue(w.cfg.MinTransferValue, event) {
+		return false
+	}
+	event.FinalityStatus = w.milestones.finality(event)
+	if fee != nil {
+		event.FeePaid = fee.paid
+		event.FeePayer = fee.payer
+	}
+
+	log.Info().
+		Str("chain", w.chainName).
+		Str("tx", txID).
+		Str("from", fromAddr).
+		Str("to", toAddr).
+		Str("value", event.Value).
+		Bool("confirmed", confirmed).
+		Msg("TRC20 transfer decoded from call data")
+
+	if w.emit(event) {
+		w.milestones.track(event)
+	}
+	return true
+}
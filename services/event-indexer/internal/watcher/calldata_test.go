@@ -0,0 +1,109 @@
+package watcher
+
+import (
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/fbsobreira/gotron-sdk/pkg/proto/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+func TestDecodeTransferCalldata(t *testing.T) {
+	to, _ := testTronAddress(0x22)
+	from, _ := testTronAddress(0x11)
+
+	// transfer(address,uint256) to 0x2222…22 of 1,000,000
+	data := mustHex(selectorTransfer +
+		"0000000000000000000000002222222222222222222222222222222222222222" +
+		"00000000000000000000000000000000000000000000000000000000000f4240")
+	transfer, ok := decodeTransferCalldata(data)
+	require.True(t, ok)
+	assert.Nil(t, transfer.from, "transfer() is sent by the transaction owner")
+	assert.Equal(t, to, transfer.to)
+	assert.Equal(t, big.NewInt(1_000_000), transfer.value)
+
+	data = append(mustHex(selectorTransferFrom), leftPad32(from)...)
+	data = append(data, leftPad32(to)...)
+	data = append(data, leftPad32(big.NewInt(7).Bytes())...)
+	transfer, ok = decodeTransferCalldata(data)
+	require.True(t, ok)
+	assert.Equal(t, from, transfer.from)
+	assert.Equal(t, to, transfer.to)
+	assert.Equal(t, big.NewInt(7), transfer.value)
+
+	for name, data := range map[string][]byte{
+		"empty":          nil,
+		"approve":        append(mustHex("095ea7b3"), make([]byte, 64)...),
+		"truncated":      append(mustHex(selectorTransfer), make([]byte, 63)...),
+		"dirty address":  append(mustHex(selectorTransfer), append(mustHex("ff"), make([]byte, 63)...)...),
+		"selector alone": mustHex(selectorTransferFrom),
+	} {
+		_, ok := decodeTransferCalldata(data)
+		assert.False(t, ok, name)
+	}
+}
+
+func TestTronWatcher_TransferCalldata(t *testing.T) {
+	client := newFakeTronClient(200)
+	token, tokenAddr := testTronAddress(0xaa)
+	owner, ownerAddr := testTronAddress(0x11)
+	to, toAddr := testTronAddress(0x22)
+
+	calldata := append(mustHex(selectorTransfer), leftPad32(to)...)
+	calldata = append(calldata, leftPad32(big.NewInt(500).Bytes())...)
+	trigger := func(txID string, result core.Transaction_ResultContractResult) {
+		client.addLogs(190, txID)
+		param, err := anypb.New(&core.TriggerSmartContract{
+			OwnerAddress:    append([]byte{tronMainnetPrefix}, owner...),
+			ContractAddress: append([]byte{tronMainnetPrefix}, token...),
+			Data:            calldata,
+		})
+		require.NoError(t, err)
+		txs := client.blocks[190].Transactions
+		txs[len(txs)-1].Transaction.RawData.Contract = []*core.Transaction_Contract{{
+			Type:      core.Transaction_Contract_TriggerSmartContract,
+			Parameter: param,
+		}}
+		client.txInfos[txID].Receipt = &core.ResourceReceipt{Result: result}
+	}
+	trigger("a190", core.Transaction_Result_SUCCESS)
+	trigger("b190", core.Transaction_Result_REVERT)
+	// A standard token: the Transfer log is authoritative, no second event
+	client.addTransfer(190, "c190", token, owner, to, big.NewInt(500))
+	client.blocks[190].Transactions[2].Transaction.RawData.Contract = client.blocks[190].Transactions[0].Transaction.RawData.Contract
+	client.txInfos["c190"].Receipt = &core.ResourceReceipt{Result: core.Transaction_Result_SUCCESS}
+
+	w := newTestTronWatcher(client)
+	w.AddTronAddress(toAddr)
+	assert.Equal(t, []string{"c190"}, collectTronTxs(t, w, 190, 200), "disabled by default")
+
+	w = newTestTronWatcher(client)
+	w.cfg.DecodeTransferCalldata = true
+	w.AddTronAddress(toAddr)
+
+	var mu sync.Mutex
+	var events []*ChainEvent
+	w.dispatch.addHandler(func(event *ChainEvent) error {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, event)
+		return nil
+	})
+	assert.Equal(t, []string{"a190", "c190"}, collectTronTxs(t, w, 190, 200), "reverted calls emit nothing")
+
+	for _, event := range events {
+		assert.Equal(t, event.TxHash == "a190", event.DecodedFromCalldata, event.TxHash)
+		if event.TxHash != "a190" {
+			continue
+		}
+		assert.Equal(t, "trc20_transfer", event.EventType)
+		assert.Equal(t, ownerAddr, event.FromAddress)
+		assert.Equal(t, toAddr, event.ToAddress)
+		assert.Equal(t, tokenAddr, event.TokenAddress)
+		assert.Equal(t, "500", event.Value)
+		assert.Equal(t, toAddr, event.WatchedAddress)
+	}
+}
@@ -0,0 +1,90 @@
+package watcher
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/protocol-bank/event-indexer/internal/config"
+)
+
+// defaultBlockTime is assumed for chains without a known block time.
+const defaultBlockTime = 12 * time.Second
+
+// ChainInfo holds static per-chain properties used to pace polling and to
+// label events.
+type ChainInfo struct {
+	Name      string
+	BlockTime time.Duration
+	// Symbol is the canonical network identifier consumers key on (e.g.
+	// "ETH", "TRON"), independent of the chain ID scheme
+	Symbol string
+}
+
+var (
+	chainInfoMu sync.RWMutex
+	chainInfos  = map[uint64]ChainInfo{
+		1:          {Name: "Ethereum Mainnet", BlockTime: 12 * time.Second, Symbol: "ETH"},
+		137:        {Name: "Polygon", BlockTime: 2 * time.Second, Symbol: "POLYGON"},
+		42161:      {Name: "Arbitrum One", BlockTime: 250 * time.Millisecond, Symbol: "ARBITRUM"},
+		8453:       {Name: "Base", BlockTime: 2 * time.Second, Symbol: "BASE"},
+		10:         {Name: "Optimism", BlockTime: 2 * time.Second, Symbol: "OPTIMISM"},
+		56:         {Name: "BNB Chain", BlockTime: 3 * time.Second, Symbol: "BSC"},
+		11155111:   {Name: "Sepolia Testnet", BlockTime: 12 * time.Second, Symbol: "ETH_SEPOLIA"},
+		17000:      {Name: "Holesky Testnet", BlockTime: 12 * time.Second, Symbol: "ETH_HOLESKY"},
+		728126428:  {Name: "TRON Mainnet", BlockTime: 3 * time.Second, Symbol: "TRON"},
+		3448148188: {Name: "TRON Nile Testnet", BlockTime: 3 * time.Second, Symbol: "TRON_NILE"},
+	}
+)
+
+// RegisterChainInfo registers or overrides the properties of a chain at
+// runtime, e.g. for chains the indexer doesn't know about. A zero BlockTime
+// falls back to the default.
+func RegisterChainInfo(chainID uint64, info ChainInfo) {
+	if info.BlockTime <= 0 {
+		info.BlockTime = defaultBlockTime
+	}
+	if info.Name == "" {
+		info.Name = fmt.Sprintf("Chain %d", chainID)
+	}
+	if info.Symbol == "" {
+		info.Symbol = fmt.Sprintf("CHAIN_%d", chainID)
+	}
+
+	chainInfoMu.Lock()
+	defer chainInfoMu.Unlock()
+	chainInfos[chainID] = info
+}
+
+// getChainConfig returns the chain's properties. Unknown chains get a
+// generated "Chain <id>" name and the default block time, so callers never
+// build a zero-interval ticker.
+func getChainConfig(chainID uint64) ChainInfo {
+	chainInfoMu.RLock()
+	info, ok := chainInfos[chainID]
+	chainInfoMu.RUnlock()
+	if ok {
+		return info
+	}
+	return ChainInfo{
+		Name:      fmt.Sprintf("Chain %d", chainID),
+		BlockTime: defaultBlockTime,
+		Symbol:    fmt.Sprintf("CHAIN_%d", chainID),
+	}
+}
+
+// pollInterval returns the chain's configured poll interval, defaulting to
+// its block time.
+func pollInterval(cfg config.ChainConfig) time.Duration {
+	if cfg.PollInterval > 0 {
+		return cfg.PollInterval
+	}
+	return getChainConfig(cfg.ChainID).BlockTime
+}
+
+// NetworkSymbol maps a chain ID to its canonical network symbol. TRON's
+// synthetic chain IDs and the small EVM ones both map to a stable,
+// human-friendly name; unknown chains get "CHAIN_<id>".
+func NetworkSymbol(chainID uint64) string {
+	return getChainConfig(chainID).Symbol
+}
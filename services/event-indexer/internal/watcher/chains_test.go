@@ -0,0 +1,20 @@
+package watcher
+
+import (
+	"testing"
+	"time"
+
+	"github.com/protocol-bank/event-indexer/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPollInterval(t *testing.T) {
+	assert.Equal(t, 3*time.Second, pollInterval(config.ChainConfig{ChainID: 3448148188}), "TRON Nile defaults to its block time")
+	assert.Equal(t, 2*time.Second, pollInterval(config.ChainConfig{ChainID: 137}))
+	assert.Equal(t, 12*time.Second, pollInterval(config.ChainConfig{ChainID: 11155111}), "Sepolia")
+	assert.Equal(t, defaultBlockTime, pollInterval(config.ChainConfig{ChainID: 999999}))
+	assert.Equal(t, 750*time.Millisecond, pollInterval(config.ChainConfig{ChainID: 3448148188, PollInterval: 750 * time.Millisecond}))
+
+	w := newTronWatcher(config.ChainConfig{ChainID: 728126428, Name: "TRON Mainnet", Type: "tron"}, newFakeTronClient(100))
+	assert.Equal(t, 3*time.Second, w.cfg.PollInterval)
+}
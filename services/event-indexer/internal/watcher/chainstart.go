@@ -0,0 +1,249 @@
+package watcher
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/protocol-bank/event-indexer/internal/config"
+	"github.com/rs/zerolog/log"
+)
+
+// ChainStartup is the outcome of starting one chain's watcher.
+type ChainStartup struct {
+	ChainID  uint64
+	Name     string
+	Started  bool
+	Error    string        // why the last attempt failed, empty once started
+	Attempts int           // including background retries
+	Duration time.Duration // of the last attempt
+}
+
+// pendingChain is a chain that failed to start and is retried in the
+// background. Address changes made meanwhile are applied when it joins.
+type pendingChain struct {
+	cfg       config.ChainConfig
+	addresses map[string]bool
+}
+
+// startChain runs start for one chain within timeout (0 = no limit).
+func startChain(ctx context.Context, cfg config.ChainConfig, timeout time.Duration, start func(ctx context.Context, cfg config.ChainConfig) error) ChainStartup {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	began := time.Now()
+	err := start(ctx, cfg)
+	result := ChainStartup{ChainID: cfg.ChainID, Name: cfg.Name, Started: err == nil, Attempts: 1, Duration: time.Since(began)}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// startChains starts every chain concurrently, each within timeout, so a
+// slow or unreachable node only delays its own chain. Outcomes are ordered
+// by chain ID.
+func startChains(ctx context.Context, chains map[uint64]config.ChainConfig, timeout time.Duration, start func(ctx context.Context, cfg config.ChainConfig) error) []ChainStartup {
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	results := make([]ChainStartup, 0, len(chains))
+	for chainID, cfg := range chains {
+		cfg.ChainID = chainID
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result := startChain(ctx, cfg, timeout, start)
+			mu.Lock()
+			defer mu.Unlock()
+			results = append(results, result)
+		}()
+	}
+	wg.Wait()
+
+	sort.Slice(results, func(i, j int) bool { return results[i].ChainID < results[j].ChainID })
+	return results
+}
+
+// connectChain constructs a chain's watcher and adds it to mcw.
+func (mcw *MultiChainWatcher) connectChain(ctx context.Context, cfg config.ChainConfig) error {
+	if isTronChain(cfg) {
+		tw, err := NewTronWatcher(ctx, cfg)
+		if err != nil {
+			return err
+		}
+		mcw.addTronWatcher(tw)
+		log.Info().Uint64("chain_id", cfg.ChainID).Str("name", cfg.Name).Msg("TRON watcher created")
+		return nil
+	}
+
+	w, err := newChainWatcher(ctx, cfg, mcw.parsedABI)
+	if err != nil {
+		return err
+	}
+	mcw.addEVMWatcher(w)
+	log.Info().Uint64("chain_id", cfg.ChainID).Str("name", cfg.Name).Msg("EVM watcher created")
+	return nil
+}
+
+// recordStartup reports the outcome of the initial startup and queues the
+// chains that failed for background retries.
+func (mcw *MultiChainWatcher) recordStartup(results []ChainStartup, chains map[uint64]config.ChainConfig) {
+	mcw.mu.Lock()
+	defer mcw.mu.Unlock()
+
+	if mcw.startup == nil {
+		mcw.startup = make(map[uint64]ChainStartup, len(results))
+	}
+	var started, failed int
+	for _, result := range results {
+		mcw.startup[result.ChainID] = result
+		if result.Started {
+			started++
+			continue
+		}
+		failed++
+		log.Warn().Uint64("chain_id", result.ChainID).Str("name", result.Name).Str("error", result.Error).Dur("took", result.Duration).Msg("Chain watcher failed to start")
+		if _, ok := mcw.pending[result.ChainID]; !ok {
+			if mcw.pending == nil {
+				mcw.pending = make(map[uint64]*pendingChain)
+			}
+			cfg := chains[result.ChainID]
+			cfg.ChainID = result.ChainID
+			mcw.pending[result.ChainID] = &pendingChain{cfg: cfg, addresses: make(map[string]bool)}
+		}
+	}
+	log.Info().Int("started", started).Int("failed", failed).Msg("Chain watcher startup complete")
+}
+
+// retryChain keeps starting a chain that failed to, every
+// startRetryInterval, and runs it once it connects.
+func (mcw *MultiChainWatcher) retryChain(ctx context.Context, cfg config.ChainConfig) {
+	if mcw.startRetryInterval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(mcw.startRetryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if mcw.closing.Load() {
+			return
+		}
+
+		result := startChain(ctx, cfg, mcw.startupTimeout, mcw.startChain)
+		mcw.mu.Lock()
+		result.Attempts += mcw.startup[cfg.ChainID].Attempts
+		mcw.startup[cfg.ChainID] = result
+		mcw.mu.Unlock()
+
+		if !result.Started {
+			log.Warn().Uint64("chain_id", cfg.ChainID).Str("name", cfg.Name).Str("error", result.Error).Int("attempts", result.Attempts).Msg("Chain watcher still failing to start")
+			continue
+		}
+		log.Info().Uint64("chain_id", cfg.ChainID).Str("name", cfg.Name).Int("attempts", result.Attempts).Msg("Chain watcher started after retrying")
+		mcw.runChain(ctx, cfg.ChainID)
+		return
+	}
+}
+
+// runChain runs a chain's watcher until ctx is cancelled.
+func (mcw *MultiChainWatcher) runChain(ctx context.Context, chainID uint64) {
+	if mcw.closing.Load() {
+		return
+	}
+	watchers, tronWatchers := mcw.chainWatchers()
+	if w, ok := watchers[chainID]; ok {
+		w.Start(ctx)
+	} else if tw, ok := tronWatchers[chainID]; ok {
+		tw.Start(ctx)
+	}
+}
+
+// addEVMWatcher wires w to mcw and adds it to the running chains.
+func (mcw *MultiChainWatcher) addEVMWatcher(w *ChainWatcher) {
+	w.dispatch = mcw.dispatch
+	w.separateConfirmationChecks = mcw.confirmationCheckInterval > 0
+
+	mcw.mu.Lock()
+	defer mcw.mu.Unlock()
+	w.checkpoints.store = mcw.checkpointStore
+	if p, ok := mcw.pending[w.chainID]; ok {
+		for addr := range p.addresses {
+			w.AddAddress(common.HexToAddress(addr))
+		}
+		delete(mcw.pending, w.chainID)
+	}
+	mcw.watchers = withChain(mcw.watchers, w.chainID, w)
+}
+
+// addTronWatcher wires tw to mcw and adds it to the running chains.
+func (mcw *MultiChainWatcher) addTronWatcher(tw *TronWatcher) {
+	tw.dispatch = mcw.dispatch
+	tw.separateConfirmationChecks = mcw.confirmationCheckInterval > 0
+
+	mcw.mu.Lock()
+	defer mcw.mu.Unlock()
+	tw.checkpoints.store = mcw.checkpointStore
+	if p, ok := mcw.pending[tw.chainID]; ok {
+		for addr := range p.addresses {
+			if _, err := tw.AddTronAddress(addr); err != nil {
+				log.Warn().Err(err).Uint64("chain_id", tw.chainID).Msg("Rejected watched address")
+			}
+		}
+		delete(mcw.pending, tw.chainID)
+	}
+	mcw.tronWatchers = withChain(mcw.tronWatchers, tw.chainID, tw)
+}
+
+// withChain returns a copy of m with w added. The watcher maps are
+// replaced rather than modified, so a map returned by chainWatchers stays
+// safe to iterate.
+func withChain[W any](m map[uint64]W, chainID uint64, w W) map[uint64]W {
+	out := make(map[uint64]W, len(m)+1)
+	for id, existing := range m {
+		out[id] = existing
+	}
+	out[chainID] = w
+	return out
+}
+
+// chainWatchers returns the running chain watchers. Chains retried in the
+// background join later; the returned maps must not be modified.
+func (mcw *MultiChainWatcher) chainWatchers() (map[uint64]*ChainWatcher, map[uint64]*TronWatcher) {
+	mcw.mu.RLock()
+	defer mcw.mu.RUnlock()
+	return mcw.watchers, mcw.tronWatchers
+}
+
+// pendingChains returns the configs of the chains awaiting a retry.
+func (mcw *MultiChainWatcher) pendingChains() []config.ChainConfig {
+	mcw.mu.RLock()
+	defer mcw.mu.RUnlock()
+	out := make([]config.ChainConfig, 0, len(mcw.pending))
+	for _, p := range mcw.pending {
+		out = append(out, p.cfg)
+	}
+	return out
+}
+
+// StartupReport returns the startup outcome of every configured chain,
+// ordered by chain ID.
+func (mcw *MultiChainWatcher) StartupReport() []ChainStartup {
+	mcw.mu.RLock()
+	defer mcw.mu.RUnlock()
+	out := make([]ChainStartup, 0, len(mcw.startup))
+	for _, result := range mcw.startup {
+		out = append(out, result)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ChainID < out[j].ChainID })
+	return out
+}
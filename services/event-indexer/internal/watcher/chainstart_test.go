@@ -0,0 +1,137 @@
+package watcher
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/protocol-bank/event-indexer/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStartChains_FailureDoesNotBlockOthers(t *testing.T) {
+	chains := map[uint64]config.ChainConfig{
+		1:   {Name: "Ethereum"},
+		56:  {Name: "BSC"},
+		137: {Name: "Polygon"},
+	}
+
+	var mu sync.Mutex
+	finished := make(map[uint64]time.Time)
+	start := func(ctx context.Context, cfg config.ChainConfig) error {
+		defer func() {
+			mu.Lock()
+			defer mu.Unlock()
+			finished[cfg.ChainID] = time.Now()
+		}()
+		switch cfg.ChainID {
+		case 1: // handshake never completes
+			<-ctx.Done()
+			return ctx.Err()
+		case 56:
+			return errors.New("connection refused")
+		default:
+			return nil
+		}
+	}
+
+	began := time.Now()
+	results := startChains(context.Background(), chains, 100*time.Millisecond, start)
+	assert.Less(t, time.Since(began), time.Second, "the hanging chain is bounded by its own timeout")
+
+	require.Len(t, results, 3)
+	assert.Equal(t, uint64(1), results[0].ChainID)
+	assert.False(t, results[0].Started)
+	assert.Contains(t, results[0].Error, context.DeadlineExceeded.Error())
+	assert.Equal(t, uint64(56), results[1].ChainID)
+	assert.False(t, results[1].Started)
+	assert.Equal(t, "connection refused", results[1].Error)
+	assert.Equal(t, uint64(137), results[2].ChainID)
+	assert.True(t, results[2].Started)
+	assert.Empty(t, results[2].Error)
+
+	// Chains start concurrently: the healthy ones don't wait for the hanging one
+	assert.True(t, finished[137].Before(finished[1]))
+	assert.True(t, finished[56].Before(finished[1]))
+}
+
+func TestMultiChainWatcher_RetriesFailedChain(t *testing.T) {
+	parsedABI, err := abi.JSON(strings.NewReader(erc20ABI))
+	require.NoError(t, err)
+	watched := "0x2222222222222222222222222222222222222222"
+	chains := map[uint64]config.ChainConfig{
+		1:   {Name: "Ethereum Test", Type: "evm", Confirmations: 12},
+		137: {Name: "Polygon Test", Type: "evm", Confirmations: 12},
+	}
+
+	mcw := &MultiChainWatcher{
+		dispatch:           newDispatcher(nil, config.RetryPolicy{MaxAttempts: 1}),
+		startupTimeout:     time.Second,
+		startRetryInterval: 5 * time.Millisecond,
+	}
+	var polygonAttempts atomic.Int32
+	mcw.startChain = func(ctx context.Context, cfg config.ChainConfig) error {
+		if cfg.ChainID == 137 && polygonAttempts.Add(1) <= 2 {
+			return errors.New("connection refused")
+		}
+		mcw.addEVMWatcher(newEVMWatcher(cfg, newFakeEVMClient(1000), parsedABI))
+		return nil
+	}
+
+	mcw.recordStartup(startChains(context.Background(), chains, mcw.startupTimeout, mcw.startChain), chains)
+	watchers, _ := mcw.chainWatchers()
+	assert.Contains(t, watchers, uint64(1), "healthy chain runs right away")
+	assert.NotContains(t, watchers, uint64(137))
+
+	// Added while the failed chain is still retrying
+	mcw.AddAddress(watched)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		mcw.Start(ctx)
+		close(done)
+	}()
+
+	require.Eventually(t, func() bool {
+		watchers, _ := mcw.chainWatchers()
+		return watchers[137] != nil
+	}, time.Second, time.Millisecond)
+	assert.True(t, mcw.isWatched(137, watched), "addresses added meanwhile apply once the chain joins")
+	assert.True(t, mcw.isWatched(1, watched))
+
+	report := mcw.StartupReport()
+	require.Len(t, report, 2)
+	assert.True(t, report[0].Started)
+	assert.Equal(t, 1, report[0].Attempts)
+	assert.True(t, report[1].Started)
+	assert.Equal(t, 3, report[1].Attempts)
+	assert.Empty(t, report[1].Error)
+
+	cancel()
+	<-done
+}
+
+func TestWatchSet_PendingChainFormat(t *testing.T) {
+	mcw := &MultiChainWatcher{pending: map[uint64]*pendingChain{
+		1:         {cfg: config.ChainConfig{ChainID: 1, Type: "evm"}, addresses: map[string]bool{}},
+		728126428: {cfg: config.ChainConfig{ChainID: 728126428, Type: "tron"}, addresses: map[string]bool{}},
+	}}
+	evm := common.HexToAddress("0x2222222222222222222222222222222222222222").Hex()
+	tron := "TLa2f6VPqDgRE67v1736s7bJ8Ray5wYjU7"
+
+	mcw.AddAddress(evm)
+	mcw.AddAddress(tron)
+	assert.Equal(t, map[string]bool{evm: true}, mcw.pending[1].addresses)
+	assert.Equal(t, map[string]bool{tron: true}, mcw.pending[728126428].addresses)
+
+	mcw.RemoveAddress(evm, 1)
+	assert.Empty(t, mcw.pending[1].addresses)
+}
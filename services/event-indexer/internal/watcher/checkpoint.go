@@ -0,0 +1,106 @@
+package watcher
+
+import (
+	"context"
+
+	"github.com/rs/zerolog/log"
+)
+
+// CheckpointStore persists each chain's durable checkpoint across restarts.
+type CheckpointStore interface {
+	LoadCheckpoint(ctx context.Context, chainID uint64) (block uint64, ok bool, err error)
+	SaveCheckpoint(ctx context.Context, chainID uint64, block uint64) error
+}
+
+// durableCheckpoint is the highest processed block that is also lag blocks
+// below head. Blocks are processed optimistically up to the head, but only
+// ones a reorg can no longer replace are recorded durably, so a restart
+// re-processes the optimistic tail instead of skipping a replaced block.
+func durableCheckpoint(processed, head, lag uint64) uint64 {
+	return min(processed, confirmedFrontier(head, lag))
+}
+
+// checkpointer writes a chain's durable checkpoint as processing advances.
+// It is owned by the chain's polling loop.
+type checkpointer struct {
+	store     CheckpointStore // nil = checkpoints aren't persisted
+	chainID   uint64
+	chainName string
+	lag       uint64
+	start     uint64 // first block to process without a checkpoint, 0 = head
+	saved     uint64
+	// positioned is set once resume returns a checkpoint or the configured
+	// start, so a resumed block 0 (start block 1) isn't taken for "at head"
+	positioned bool
+}
+
+// resume returns the persisted checkpoint to continue from. Without one it
+// falls back to the block before the configured start block, or 0 to start
+// at the head; atHead tells the two apart.
+func (c *checkpointer) resume(ctx context.Context) uint64 {
+	if c.store == nil {
+		return c.fromStart()
+	}
+	block, ok, err := c.store.LoadCheckpoint(ctx, c.chainID)
+	if err != nil {
+		log.Error().Err(err).Str("chain", c.chainName).Msg("Failed to load checkpoint")
+		return c.fromStart()
+	}
+	if !ok {
+		return c.fromStart()
+	}
+	c.saved = block
+	c.positioned = true
+	log.Info().Str("chain", c.chainName).Uint64("block", block).Msg("Resuming from checkpoint")
+	return block
+}
+
+// fromStart is where processing begins without a checkpoint.
+func (c *checkpointer) fromStart() uint64 {
+	if c.start == 0 {
+		log.Info().Str("chain", c.chainName).Msg("No checkpoint, starting at head")
+		return 0
+	}
+	log.Info().Str("chain", c.chainName).Uint64("block", c.start).Msg("No checkpoint, starting at the configured start block")
+	c.positioned = true
+	return c.start - 1
+}
+
+// atHead reports whether lastBlock means no position yet, so processing
+// starts at the head rather than after lastBlock.
+func (c *checkpointer) atHead(lastBlock uint64) bool {
+	return lastBlock == 0 && !c.positioned
+}
+
+// advance persists the durable checkpoint for processed blocks at head,
+// if it moved forward.
+func (c *checkpointer) advance(ctx context.Context, processed, head uint64) {
+	if c.store == nil {
+		return
+	}
+	block := durableCheckpoint(processed, head, c.lag)
+	if block <= c.saved {
+		return
+	}
+	if err := c.store.SaveCheckpoint(ctx, c.chainID, block); err != nil {
+		log.Warn().Err(err).Str("chain", c.chainName).Uint64("block", block).Msg("Failed to save checkpoint")
+		return
+	}
+	c.saved = block
+}
+
+// SetCheckpointStore persists every chain's checkpoint to store, and
+// resumes from it on Start. Must be called before Start.
+func (mcw *MultiChainWatcher) SetCheckpointStore(store CheckpointStore) {
+	mcw.mu.Lock()
+	defer mcw.mu.Unlock()
+
+	// Chains joining after a startup retry pick it up from mcw
+	mcw.checkpointStore = store
+	for _, w := range mcw.watchers {
+		w.checkpoints.store = store
+	}
+	for _, tw := range mcw.tronWatchers {
+		tw.checkpoints.store = store
+	}
+}
@@ -0,0 +1,123 @@
+package watcher
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memCheckpointStore is an in-memory CheckpointStore.
+type memCheckpointStore struct {
+	mu     sync.Mutex
+	blocks map[uint64]uint64
+}
+
+func (m *memCheckpointStore) LoadCheckpoint(ctx context.Context, chainID uint64) (uint64, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	block, ok := m.blocks[chainID]
+	return block, ok, nil
+}
+
+func (m *memCheckpointStore) SaveCheckpoint(ctx context.Context, chainID uint64, block uint64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.blocks[chainID] = block
+	return nil
+}
+
+func TestChainWatcher_CheckpointTrailsHead(t *testing.T) {
+	ctx := context.Background()
+	watched := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	other := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	store := &memCheckpointStore{blocks: make(map[uint64]uint64)}
+
+	client := newFakeEVMClient(120)
+	client.addLog(testTransferLog(118, 0, other, watched, big.NewInt(1)))
+
+	w := newTestChainWatcher(t, client)
+	w.checkpoints.store = store
+	w.checkpoints.lag = w.cfg.Confirmations
+	w.AddAddress(watched)
+
+	last := w.poll(ctx, 99)
+	w.checkpoints.advance(ctx, last, 120)
+	assert.Equal(t, uint64(120), last, "processed optimistically up to the head")
+	assert.Equal(t, uint64(108), store.blocks[1], "checkpoint trails the head by the confirmation depth")
+
+	client.head = 125
+	last = w.poll(ctx, last)
+	w.checkpoints.advance(ctx, last, 125)
+	assert.Equal(t, uint64(113), store.blocks[1])
+	w.gate.drain()
+
+	// After a restart, the unconfirmed tail is processed again
+	restarted := newTestChainWatcher(t, client)
+	restarted.checkpoints.store = store
+	restarted.AddAddress(watched)
+	resumed := restarted.checkpoints.resume(ctx)
+	require.Equal(t, uint64(113), resumed)
+
+	var blocks []uint64
+	restarted.dispatch.addHandler(func(event *ChainEvent) error {
+		blocks = append(blocks, event.BlockNumber)
+		return nil
+	})
+	assert.Equal(t, uint64(125), restarted.poll(ctx, resumed))
+	restarted.gate.drain()
+	assert.Equal(t, []uint64{118}, blocks)
+}
+
+func TestDurableCheckpoint(t *testing.T) {
+	assert.Equal(t, uint64(88), durableCheckpoint(100, 100, 12))
+	assert.Equal(t, uint64(80), durableCheckpoint(80, 100, 12), "never ahead of what was processed")
+	assert.Equal(t, uint64(0), durableCheckpoint(5, 10, 12), "nothing is confirmed yet")
+	assert.Equal(t, uint64(100), durableCheckpoint(100, 100, 0))
+}
+
+func TestCheckpointer_StartBlockFallback(t *testing.T) {
+	ctx := context.Background()
+	store := &memCheckpointStore{blocks: make(map[uint64]uint64)}
+
+	c := checkpointer{chainID: 1, chainName: "Test", start: 500}
+	assert.Equal(t, uint64(499), c.resume(ctx), "without a store, start at the start block")
+
+	c.store = store
+	assert.Equal(t, uint64(499), c.resume(ctx), "no checkpoint yet")
+
+	store.blocks[1] = 750
+	assert.Equal(t, uint64(750), c.resume(ctx), "a checkpoint wins over the start block")
+
+	atHead := checkpointer{chainID: 2, chainName: "Test", store: store}
+	assert.Zero(t, atHead.resume(ctx))
+}
+
+func TestChainWatcher_StartBlockOneIsNotHead(t *testing.T) {
+	ctx := context.Background()
+	watched := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	other := common.HexToAddress("0x1111111111111111111111111111111111111111")
+
+	client := newFakeEVMClient(5)
+	client.addLog(testTransferLog(1, 0, other, watched, big.NewInt(1)))
+
+	w := newTestChainWatcher(t, client)
+	w.checkpoints.start = 1
+	w.AddAddress(watched)
+	var blocks []uint64
+	w.dispatch.addHandler(func(event *ChainEvent) error {
+		blocks = append(blocks, event.BlockNumber)
+		return nil
+	})
+
+	resumed := w.checkpoints.resume(ctx)
+	require.Equal(t, uint64(0), resumed)
+	assert.False(t, w.checkpoints.atHead(resumed), "block 0 from start block 1 is a position, not the head")
+	assert.Equal(t, uint64(5), w.poll(ctx, resumed))
+	w.gate.drain()
+	assert.Equal(t, []uint64{1}, blocks)
+}
@@ -0,0 +1,43 @@
+package watcher
+
+import (
+	"context"
+	"sync"
+)
+
+// watcherCloser tears a chain watcher down once: it stops the loops started
+// by Start and releases the watcher's RPC connections.
+type watcherCloser struct {
+	once    sync.Once
+	closed  chan struct{}
+	release func() // closes the RPC connections, nil if there are none
+}
+
+func newWatcherCloser() *watcherCloser {
+	return &watcherCloser{closed: make(chan struct{})}
+}
+
+// bind returns a context that is cancelled when ctx is or the watcher is
+// closed, for Start to run its loops under.
+func (c *watcherCloser) bind(ctx context.Context) context.Context {
+	ctx, cancel := context.WithCancel(ctx)
+	go func() {
+		defer cancel()
+		select {
+		case <-c.closed:
+		case <-ctx.Done():
+		}
+	}()
+	return ctx
+}
+
+// close stops the loops and releases the connections; later calls are
+// no-ops.
+func (c *watcherCloser) close() {
+	c.once.Do(func() {
+		close(c.closed)
+		if c.release != nil {
+			c.release()
+		}
+	})
+}
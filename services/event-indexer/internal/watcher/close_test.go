@@ -0,0 +1,47 @@
+package watcher
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTronWatcher_Close(t *testing.T) {
+	w := newTestTronWatcher(newFakeTronClient(100))
+	released := 0
+	w.closer.release = func() { released++ }
+
+	stopped := make(chan struct{})
+	go func() {
+		w.Start(context.Background())
+		close(stopped)
+	}()
+
+	require.NoError(t, w.Close())
+	require.NoError(t, w.Close())
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("polling loop still running after Close")
+	}
+	assert.Equal(t, 1, released, "connections are released once")
+}
+
+func TestChainWatcher_CloseCancelsLoops(t *testing.T) {
+	w := newTestChainWatcher(t, newFakeEVMClient(1000))
+	released := 0
+	w.closer.release = func() { released++ }
+
+	ctx := w.closer.bind(context.Background())
+	require.NoError(t, w.Close())
+	require.NoError(t, w.Close())
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("loop context not cancelled by Close")
+	}
+	assert.Equal(t, 1, released)
+}
@@ -0,0 +1,231 @@
+package watcher
+
+import (
+	"sort"
+	"sync"
+)
+
+// confirmationTracker holds detected events until they have crossed every
+// configured confirmation milestone, re-emitting a copy of the event at each
+// one. With confirmedPhase it also holds events detected unconfirmed until
+// they reach the required confirmations, and re-emits them as Confirmed
+// then, unless a milestone already did. An event that has the chain's
+// required confirmations but not yet its own deeper requirement (a token
+// policy's depth) or finality is held the same way until it has. A nil
+// tracker (no milestones, no confirmed phase) tracks nothing.
+type confirmationTracker struct {
+	mu             sync.Mutex
+	milestones     []uint64 // ascending, deduplicated, > 0
+	required       uint64   // confirmations needed for Confirmed
+	confirmedPhase bool     // re-emit events once Confirmed
+	maxPending     int      // cap on tracked events, 0 = unbounded
+	pending        []*trackedEvent
+	final          finalitySource // nil = the block count alone
+	depth          eventDepth     // nil = the chain's required for every event
+}
+
+// finalitySource decides Confirmed from the block-count verdict and the
+// event's block, e.g. TRON's solidified block.
+type finalitySource func(countConfirmed bool, blockNum uint64) bool
+
+// eventDepth returns the confirmations an event itself requires, e.g. its
+// token policy's; 0 leaves it at the chain's.
+type eventDepth func(event *ChainEvent) uint64
+
+type trackedEvent struct {
+	event     *ChainEvent
+	required  uint64 // the event's own requirement, 0 = the chain's
+	next      int    // index of the next milestone to fire
+	confirmed bool   // a Confirmed copy has been emitted
+	held      bool   // has the chain's required confirmations, but isn't Confirmed yet
+}
+
+func newConfirmationTracker(milestones []uint64, required uint64, maxPending int, confirmedPhase bool) *confirmationTracker {
+	sorted := make([]uint64, 0, len(milestones))
+	seen := make(map[uint64]bool)
+	for _, m := range milestones {
+		if m == 0 || seen[m] {
+			continue
+		}
+		seen[m] = true
+		sorted = append(sorted, m)
+	}
+	if len(sorted) == 0 && !confirmedPhase {
+		return nil
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	return &confirmationTracker{milestones: sorted, required: required, confirmedPhase: confirmedPhase, maxPending: maxPending}
+}
+
+// setRequired updates the confirmations needed for Confirmed, which
+// follows the chain's adaptive requirement.
+func (t *confirmationTracker) setRequired(required uint64) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.required = required
+}
+
+// setFinality makes final part of the confirmation condition.
+func (t *confirmationTracker) setFinality(final finalitySource) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.final = final
+}
+
+// setDepth makes each event's own requirement part of the confirmation
+// condition.
+func (t *confirmationTracker) setDepth(depth eventDepth) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.depth = depth
+}
+
+// confirmedAt reports whether a tracked event has reached Confirmed at the
+// given depth, and whether it has the chain's required confirmations but is
+// still short of its own requirement or finality.
+func (t *confirmationTracker) confirmedAt(tracked *trackedEvent, confirmations uint64) (confirmed, held bool) {
+	confirmed = confirmations >= max(t.required, tracked.required)
+	if t.final != nil {
+		confirmed = t.final(confirmed, tracked.event.BlockNumber)
+	}
+	return confirmed, !confirmed && confirmations >= t.required
+}
+
+// full reports whether the tracker holds its maximum of pending events.
+// Watchers stop advancing while it is, so a chain stalled near the
+// confirmation boundary applies backpressure instead of growing the queue.
+func (t *confirmationTracker) full() bool {
+	if t == nil || t.maxPending <= 0 {
+		return false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.pending) >= t.maxPending
+}
+
+// track starts following an event that was just emitted. Milestones it had
+// already reached at detection are covered by that emission and don't fire.
+func (t *confirmationTracker) track(event *ChainEvent) {
+	if t == nil {
+		return
+	}
+
+	next := sort.Search(len(t.milestones), func(i int) bool {
+		return t.milestones[i] > event.Confirmations
+	})
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	tracked := &trackedEvent{event: event, next: next, confirmed: event.Confirmed}
+	if t.depth != nil {
+		tracked.required = t.depth(event)
+	}
+	if !tracked.confirmed {
+		_, tracked.held = t.confirmedAt(tracked, event.Confirmations)
+	}
+	if !t.tracking(tracked) {
+		return
+	}
+	t.pending = append(t.pending, tracked)
+}
+
+// tracking reports whether a tracked event has updates left to emit.
+func (t *confirmationTracker) tracking(tracked *trackedEvent) bool {
+	return tracked.next < len(t.milestones) || ((t.confirmedPhase || tracked.held) && !tracked.confirmed)
+}
+
+// advance returns one event per milestone crossed at the given head, with
+// Confirmations set to the milestone depth. Events that have crossed their
+// last milestone stop being tracked.
+func (t *confirmationTracker) advance(head uint64) []*ChainEvent {
+	if t == nil {
+		return nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var out []*ChainEvent
+	remaining := t.pending[:0]
+	for _, tracked := range t.pending {
+		confirmations := confirmationsAt(head, tracked.event.BlockNumber)
+		for tracked.next < len(t.milestones) && t.milestones[tracked.next] <= confirmations {
+			milestone := *tracked.event
+			milestone.Confirmations = t.milestones[tracked.next]
+			var held bool
+			milestone.Confirmed, held = t.confirmedAt(tracked, milestone.Confirmations)
+			milestone.FinalityStatus = t.finality(&milestone)
+			out = append(out, &milestone)
+			tracked.next++
+			tracked.confirmed = tracked.confirmed || milestone.Confirmed
+			tracked.held = tracked.held || held
+		}
+		if (t.confirmedPhase || tracked.held) && !tracked.confirmed {
+			if ok, _ := t.confirmedAt(tracked, confirmations); ok {
+				confirmed := *tracked.event
+				confirmed.Confirmations = confirmations
+				confirmed.Confirmed = true
+				confirmed.FinalityStatus = t.finality(&confirmed)
+				out = append(out, &confirmed)
+				tracked.confirmed = true
+			}
+		}
+		if t.tracking(tracked) {
+			remaining = append(remaining, tracked)
+		}
+	}
+	for i := len(remaining); i < len(t.pending); i++ {
+		t.pending[i] = nil
+	}
+	t.pending = remaining
+	return out
+}
+
+// orphan stops tracking events in blocks from fromBlock on, which a reorg
+// replaced, and returns a copy of each marked FinalityReorged.
+func (t *confirmationTracker) orphan(fromBlock uint64) []*ChainEvent {
+	if t == nil {
+		return nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var out []*ChainEvent
+	remaining := t.pending[:0]
+	for _, tracked := range t.pending {
+		if tracked.event.BlockNumber < fromBlock {
+			remaining = append(remaining, tracked)
+			continue
+		}
+		reorged := *tracked.event
+		reorged.Confirmed = false
+		reorged.FinalityStatus = FinalityReorged
+		out = append(out, &reorged)
+	}
+	for i := len(remaining); i < len(t.pending); i++ {
+		t.pending[i] = nil
+	}
+	t.pending = remaining
+	return out
+}
+
+// confirmedFrontier is the highest block that already has the required
+// confirmations at head. In confirmed-only mode nothing above it is
+// processed, so every emitted event is final and reorgs need no handling.
+func confirmedFrontier(head, confirmations uint64) uint64 {
+	if head < confirmations {
+		return 0
+	}
+	return head - confirmations
+}
@@ -0,0 +1,354 @@
+package watcher
+
+import (
+	"context"
+	"math/big"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChainWatcher_ConfirmationMilestones(t *testing.T) {
+	ctx := context.Background()
+	client := newFakeEVMClient(990)
+	watched := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	client.addLog(testTransferLog(990, 0, common.HexToAddress("0x1111111111111111111111111111111111111111"), watched, big.NewInt(7)))
+
+	w := newTestChainWatcher(t, client)
+	w.milestones = newConfirmationTracker([]uint64{64, 1, 12, 6, 6}, w.cfg.Confirmations, 0, false)
+	w.AddAddress(watched)
+
+	var mu sync.Mutex
+	var events []*ChainEvent
+	w.dispatch.addHandler(func(event *ChainEvent) error {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, event)
+		return nil
+	})
+
+	last := w.poll(ctx, 989) // detected at 0 confirmations
+	for _, head := range []uint64{991, 1002, 1002, 1053, 1054, 1100} {
+		client.head = head
+		last = w.poll(ctx, last)
+	}
+	w.gate.drain()
+
+	sort.Slice(events, func(i, j int) bool { return events[i].Confirmations < events[j].Confirmations })
+	var depths []uint64
+	var confirmed []bool
+	for _, event := range events {
+		assert.Equal(t, uint64(990), event.BlockNumber)
+		depths = append(depths, event.Confirmations)
+		confirmed = append(confirmed, event.Confirmed)
+	}
+	assert.Equal(t, []uint64{0, 1, 6, 12, 64}, depths, "each milestone fires exactly once")
+	assert.Equal(t, []bool{false, false, false, true, true}, confirmed)
+	assert.Empty(t, w.milestones.pending, "event released after its last milestone")
+}
+
+func TestConfirmationTracker_SkipsMilestonesReachedAtDetection(t *testing.T) {
+	tracker := newConfirmationTracker([]uint64{1, 6, 12}, 12, 0, false)
+	tracker.track(&ChainEvent{TxHash: "a", BlockNumber: 100, Confirmations: 8})
+
+	events := tracker.advance(108)
+	assert.Empty(t, events)
+
+	events = tracker.advance(115)
+	require.Len(t, events, 1)
+	assert.Equal(t, uint64(12), events[0].Confirmations)
+	assert.True(t, events[0].Confirmed)
+
+	// Past every milestone at detection: nothing to track
+	tracker.track(&ChainEvent{TxHash: "b", BlockNumber: 100, Confirmations: 20})
+	assert.Empty(t, tracker.pending)
+}
+
+func TestConfirmationTracker_Disabled(t *testing.T) {
+	tracker := newConfirmationTracker(nil, 12, 0, false)
+	assert.Nil(t, tracker)
+
+	tracker.track(&ChainEvent{BlockNumber: 1})
+	assert.Nil(t, tracker.advance(100))
+}
+
+func TestConfirmationTracker_ConfirmedPhase(t *testing.T) {
+	tracker := newConfirmationTracker(nil, 12, 0, true)
+	require.NotNil(t, tracker)
+	tracker.track(&ChainEvent{TxHash: "a", BlockNumber: 100, Confirmations: 3})
+	tracker.track(&ChainEvent{TxHash: "b", BlockNumber: 105, Confirmations: 0})
+	tracker.track(&ChainEvent{TxHash: "c", BlockNumber: 90, Confirmations: 13, Confirmed: true})
+	assert.Len(t, tracker.pending, 2, "confirmed at detection: nothing to follow up")
+
+	assert.Empty(t, tracker.advance(111))
+
+	events := tracker.advance(114)
+	require.Len(t, events, 1)
+	assert.Equal(t, "a", events[0].TxHash)
+	assert.True(t, events[0].Confirmed)
+	assert.Equal(t, uint64(14), events[0].Confirmations)
+	assert.Equal(t, FinalitySettled, events[0].FinalityStatus)
+
+	// A reorg drops the other before it confirms
+	orphaned := tracker.orphan(105)
+	require.Len(t, orphaned, 1)
+	assert.Equal(t, FinalityReorged, orphaned[0].FinalityStatus)
+	assert.Empty(t, tracker.advance(200))
+	assert.Empty(t, tracker.pending)
+}
+
+func TestConfirmationTracker_ConfirmedPhaseWithMilestones(t *testing.T) {
+	tracker := newConfirmationTracker([]uint64{1, 20}, 12, 0, true)
+	tracker.track(&ChainEvent{TxHash: "a", BlockNumber: 100})
+	assert.Equal(t, uint64(1), tracker.snapshot()[0].NextMilestone)
+
+	events := tracker.advance(101)
+	require.Len(t, events, 1)
+	assert.False(t, events[0].Confirmed)
+	assert.Equal(t, uint64(12), tracker.snapshot()[0].NextMilestone, "the confirmed phase comes before milestone 20")
+
+	events = tracker.advance(112)
+	require.Len(t, events, 1)
+	assert.True(t, events[0].Confirmed)
+	assert.Equal(t, FinalityCredited, events[0].FinalityStatus, "milestone 20 still follows")
+
+	events = tracker.advance(125)
+	require.Len(t, events, 1)
+	assert.Equal(t, uint64(20), events[0].Confirmations)
+	assert.Empty(t, tracker.pending)
+}
+
+func TestChainWatcher_ConfirmedOnly(t *testing.T) {
+	ctx := context.Background()
+	client := newFakeEVMClient(1000)
+	watched := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	from := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	for block := uint64(985); block <= 1005; block++ {
+		client.addLog(testTransferLog(block, 0, from, watched, big.NewInt(1)))
+	}
+
+	w := newTestChainWatcher(t, client)
+	w.cfg.ConfirmedOnly = true
+	w.AddAddress(watched)
+
+	var mu sync.Mutex
+	var events []*ChainEvent
+	w.dispatch.addHandler(func(event *ChainEvent) error {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, event)
+		return nil
+	})
+
+	lastBlock := w.poll(ctx, 0)
+	assert.Equal(t, uint64(988), lastBlock, "checkpoint starts at the confirmed frontier")
+
+	client.mu.Lock()
+	client.head = 1005
+	client.mu.Unlock()
+	lastBlock = w.poll(ctx, lastBlock)
+	w.gate.inflight.Wait()
+	assert.Equal(t, uint64(993), lastBlock)
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, events, 5)
+	for _, event := range events {
+		assert.LessOrEqual(t, event.BlockNumber, uint64(1005-12), "never within the confirmation window")
+		assert.True(t, event.Confirmed)
+	}
+}
+
+func TestTronWatcher_ConfirmedOnly(t *testing.T) {
+	ctx := context.Background()
+	client := newFakeTronClient(200)
+	_, toAddr := testTronAddress(0x22)
+
+	w := newTestTronWatcher(client)
+	w.cfg.ConfirmedOnly = true
+	w.AddTronAddress(toAddr)
+
+	w.poll(ctx)
+	assert.Equal(t, int64(200-19), w.lastBlock)
+
+	client.setHeadBlock(testTronHeader(210))
+	w.poll(ctx)
+	assert.Equal(t, int64(210-19), w.lastBlock)
+
+	fetched := client.fetchedBlocks()
+	require.NotEmpty(t, fetched)
+	for _, num := range fetched {
+		assert.LessOrEqual(t, num, int64(210-19), "never within the confirmation window")
+	}
+}
+
+func TestChainWatcher_PendingConfirmationCap(t *testing.T) {
+	ctx := context.Background()
+	client := newFakeEVMClient(1010)
+	watched := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	from := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	for block := uint64(1001); block <= 1010; block++ {
+		client.addLog(testTransferLog(block, 0, from, watched, big.NewInt(1)))
+	}
+
+	w := newTestChainWatcher(t, client)
+	w.milestones = newConfirmationTracker([]uint64{12}, w.cfg.Confirmations, 2, false)
+	w.AddAddress(watched)
+
+	// Two tracked events fill the queue; processing stops there
+	lastBlock := w.poll(ctx, 1000)
+	assert.Equal(t, uint64(1002), lastBlock)
+	assert.True(t, w.milestones.full())
+	assert.Len(t, w.milestones.pending, 2, "queue never exceeds the cap")
+
+	// Still full: the checkpoint holds
+	assert.Equal(t, uint64(1002), w.poll(ctx, lastBlock))
+
+	// Milestones release the queue and processing resumes
+	client.mu.Lock()
+	client.head = 1014
+	client.mu.Unlock()
+	lastBlock = w.poll(ctx, lastBlock)
+	assert.Equal(t, uint64(1002), lastBlock, "queue freed only at the end of this poll")
+	assert.False(t, w.milestones.full())
+
+	lastBlock = w.poll(ctx, lastBlock)
+	assert.Equal(t, uint64(1004), lastBlock)
+	w.gate.inflight.Wait()
+}
+
+func TestChainWatcher_PendingConfirmationCapOnSubscribedHeads(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	client := newFakeEVMClient(1010)
+	watched := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	from := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	for block := uint64(1001); block <= 1010; block++ {
+		client.addLog(testTransferLog(block, 0, from, watched, big.NewInt(1)))
+	}
+
+	w := newTestChainWatcher(t, client)
+	w.milestones = newConfirmationTracker([]uint64{12}, w.cfg.Confirmations, 2, false)
+	w.cfg.PollInterval = time.Hour
+	w.checkpoints.start = 1001
+	w.AddAddress(watched)
+
+	// Subscribed heads are processed under the same cap as polling
+	heads := make(chan struct{}, 1)
+	go w.pollBlocks(ctx, heads)
+	heads <- struct{}{}
+	assert.Eventually(t, func() bool { return w.checkpoint.Load() == 1002 }, time.Second, time.Millisecond)
+	assert.True(t, w.milestones.full())
+
+	heads <- struct{}{}
+	assert.Never(t, func() bool { return w.checkpoint.Load() > 1002 }, 100*time.Millisecond, time.Millisecond)
+}
+
+func TestChainWatcher_FinalityStatusTransitions(t *testing.T) {
+	ctx := context.Background()
+	client := newFakeEVMClient(990)
+	watched := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	other := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	client.addLog(testTransferLog(990, 0, other, watched, big.NewInt(7)))
+	client.addLog(testTransferLog(1011, 0, other, watched, big.NewInt(8)))
+
+	w := newTestChainWatcher(t, client)
+	w.milestones = newConfirmationTracker([]uint64{1, 12, 64}, w.cfg.Confirmations, 0, false)
+	w.AddAddress(watched)
+
+	var mu sync.Mutex
+	statuses := make(map[uint64][]FinalityStatus) // by block
+	w.dispatch.addHandler(func(event *ChainEvent) error {
+		mu.Lock()
+		defer mu.Unlock()
+		statuses[event.BlockNumber] = append(statuses[event.BlockNumber], event.FinalityStatus)
+		assert.Equal(t, event.FinalityStatus == FinalityCredited || event.FinalityStatus == FinalitySettled, event.Confirmed,
+			"Confirmed stays consistent with the status")
+		return nil
+	})
+
+	last := w.poll(ctx, 989)
+	for _, head := range []uint64{991, 1002, 1011} {
+		client.head = head
+		last = w.poll(ctx, last)
+		w.gate.inflight.Wait()
+	}
+
+	// The block holding the second transfer is replaced before it settles
+	client.reorg(1011)
+	client.head = 1054
+	w.poll(ctx, last)
+	w.gate.drain()
+
+	assert.Equal(t, []FinalityStatus{FinalityPending, FinalityPending, FinalityCredited, FinalitySettled}, statuses[990])
+	assert.Equal(t, []FinalityStatus{FinalityPending, FinalityReorged}, statuses[1011])
+	assert.Empty(t, w.milestones.pending)
+}
+
+func TestDefaultFinality(t *testing.T) {
+	assert.Equal(t, FinalityPending, defaultFinality(&ChainEvent{EventType: "transfer"}))
+	assert.Equal(t, FinalitySettled, defaultFinality(&ChainEvent{EventType: "transfer", Confirmed: true}))
+	assert.Equal(t, FinalityFailed, defaultFinality(&ChainEvent{EventType: EventTypeFailedTx, Confirmed: true}))
+
+	var tracker *confirmationTracker
+	assert.Equal(t, FinalitySettled, tracker.finality(&ChainEvent{Confirmed: true}), "untracked confirmed events are final")
+}
+
+func TestConfirmationTracker_HoldsUntilFinal(t *testing.T) {
+	tracker := newConfirmationTracker([]uint64{1, 12}, 12, 0, false)
+	var solidified uint64
+	tracker.setFinality(func(countConfirmed bool, blockNum uint64) bool {
+		return countConfirmed && blockNum <= solidified
+	})
+	tracker.track(&ChainEvent{TxHash: "a", BlockNumber: 100})
+
+	// The last milestone passes before the block is solidified
+	events := tracker.advance(112)
+	require.Len(t, events, 2)
+	assert.False(t, events[1].Confirmed)
+	assert.Len(t, tracker.pending, 1, "held until final")
+
+	assert.Empty(t, tracker.advance(113))
+
+	solidified = 100
+	events = tracker.advance(114)
+	require.Len(t, events, 1)
+	assert.True(t, events[0].Confirmed)
+	assert.Equal(t, uint64(14), events[0].Confirmations)
+	assert.Equal(t, FinalitySettled, events[0].FinalityStatus)
+	assert.Empty(t, tracker.pending)
+}
+
+func TestConfirmationTracker_PerEventDepth(t *testing.T) {
+	tracker := newConfirmationTracker([]uint64{12}, 12, 0, false)
+	tracker.setDepth(func(event *ChainEvent) uint64 {
+		if event.TxHash == "deep" {
+			return 30
+		}
+		return 0
+	})
+	tracker.track(&ChainEvent{TxHash: "deep", BlockNumber: 100})
+	tracker.track(&ChainEvent{TxHash: "chain", BlockNumber: 100})
+
+	events := tracker.advance(112)
+	require.Len(t, events, 2)
+	confirmed := map[string]bool{}
+	for _, event := range events {
+		confirmed[event.TxHash] = event.Confirmed
+	}
+	assert.Equal(t, map[string]bool{"deep": false, "chain": true}, confirmed)
+	assert.Len(t, tracker.pending, 1, "the deep one is held until its own depth")
+
+	assert.Empty(t, tracker.advance(129))
+	events = tracker.advance(130)
+	require.Len(t, events, 1)
+	assert.Equal(t, "deep", events[0].TxHash)
+	assert.True(t, events[0].Confirmed)
+	assert.Equal(t, uint64(30), events[0].Confirmations)
+	assert.Empty(t, tracker.pending)
+}
@@ -0,0 +1,95 @@
+package watcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/fbsobreira/gotron-sdk/pkg/proto/api"
+	"github.com/fbsobreira/gotron-sdk/pkg/proto/core"
+)
+
+// deadlineTronClient bounds every call to a TRON node by timeout. gotron-sdk
+// calls take no context, so a call still running at the deadline is
+// abandoned and reported as a timeout, which the endpoint health tracking
+// and the next poll treat like any other RPC error.
+type deadlineTronClient struct {
+	client  tronRPC
+	timeout time.Duration
+}
+
+func newDeadlineTronClient(client tronRPC, timeout time.Duration) tronRPC {
+	if timeout <= 0 {
+		return client
+	}
+	return &deadlineTronClient{client: client, timeout: timeout}
+}
+
+// withDeadline runs call, returning context.DeadlineExceeded if it hasn't
+// finished within timeout (0 = no timeout of its own), or ctx's error if ctx
+// ends first.
+func withDeadline[T any](ctx context.Context, timeout time.Duration, method string, call func() (T, error)) (T, error) {
+	type result struct {
+		v   T
+		err error
+	}
+	done := make(chan result, 1) // buffered: an abandoned call doesn't leak blocked
+	go func() {
+		v, err := call()
+		done <- result{v, err}
+	}()
+
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	select {
+	case r := <-done:
+		return r.v, r.err
+	case <-ctx.Done():
+		var zero T
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) && timeout > 0 {
+			return zero, fmt.Errorf("%s: no response within %s: %w", method, timeout, context.DeadlineExceeded)
+		}
+		return zero, fmt.Errorf("%s: %w", method, ctx.Err())
+	}
+}
+
+// tronCall runs a watcher call to the node under ctx and the chain's
+// RPCRetry policy. Each endpoint call is already bounded by CallTimeout
+// (deadlineTronClient, below any failover); tronCall adds the watcher
+// context, so shutdown abandons a hung call instead of waiting it out, and
+// retries the timeouts that endpoint bound reports.
+func tronCall[T any](ctx context.Context, w *TronWatcher, op string, call func() (T, error)) (T, error) {
+	return retryRPC(ctx, w.cfg.RPCRetry, w.chainName, op, func() (T, error) {
+		return withDeadline(ctx, 0, op, call)
+	})
+}
+
+func (d *deadlineTronClient) GetNowBlock() (*api.BlockExtention, error) {
+	return withDeadline(context.Background(), d.timeout, "GetNowBlock", d.client.GetNowBlock)
+}
+
+func (d *deadlineTronClient) GetBlockByNum(num int64) (*api.BlockExtention, error) {
+	return withDeadline(context.Background(), d.timeout, "GetBlockByNum", func() (*api.BlockExtention, error) {
+		return d.client.GetBlockByNum(num)
+	})
+}
+
+func (d *deadlineTronClient) GetTransactionInfoByID(id string) (*core.TransactionInfo, error) {
+	return withDeadline(context.Background(), d.timeout, "GetTransactionInfoByID", func() (*core.TransactionInfo, error) {
+		return d.client.GetTransactionInfoByID(id)
+	})
+}
+
+func (d *deadlineTronClient) GetBlockInfoByNum(num int64) (*api.TransactionInfoList, error) {
+	return withDeadline(context.Background(), d.timeout, "GetBlockInfoByNum", func() (*api.TransactionInfoList, error) {
+		return d.client.GetBlockInfoByNum(num)
+	})
+}
+
+func (d *deadlineTronClient) GetNodeInfo() (*core.NodeInfo, error) {
+	return withDeadline(context.Background(), d.timeout, "GetNodeInfo", d.client.GetNodeInfo)
+}
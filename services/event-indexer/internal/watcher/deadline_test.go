@@ -0,0 +1,117 @@
+package watcher
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fbsobreira/gotron-sdk/pkg/proto/api"
+	"github.com/protocol-bank/event-indexer/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// hungTronClient never answers GetNowBlock until released.
+type hungTronClient struct {
+	*fakeTronClient
+	release chan struct{}
+}
+
+func (h *hungTronClient) GetNowBlock() (*api.BlockExtention, error) {
+	<-h.release
+	return h.fakeTronClient.GetNowBlock()
+}
+
+func TestDeadlineTronClient_TimesOut(t *testing.T) {
+	hung := &hungTronClient{fakeTronClient: newFakeTronClient(200), release: make(chan struct{})}
+	defer close(hung.release)
+	client := newDeadlineTronClient(hung, 50*time.Millisecond)
+
+	began := time.Now()
+	_, err := client.GetNowBlock()
+	assert.Less(t, time.Since(began), time.Second, "a hung call returns at the deadline")
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Contains(t, err.Error(), "GetNowBlock")
+
+	// Calls that answer in time pass through
+	block, err := client.GetBlockByNum(190)
+	require.NoError(t, err)
+	assert.Equal(t, int64(190), block.GetBlockHeader().GetRawData().GetNumber())
+}
+
+func TestTronWatcher_PollHungNode(t *testing.T) {
+	hung := &hungTronClient{fakeTronClient: newFakeTronClient(200), release: make(chan struct{})}
+	defer close(hung.release)
+	w := newTestTronWatcher(newDeadlineTronClient(hung, 50*time.Millisecond))
+	_, addr := testTronAddress(0x22)
+	w.AddTronAddress(addr)
+
+	done := make(chan struct{})
+	go func() {
+		w.poll(context.Background())
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("poll blocked on a hung node")
+	}
+	assert.Equal(t, HealthRPCError, w.Health().Status)
+	assert.Contains(t, w.Health().LastError, context.DeadlineExceeded.Error())
+}
+
+func TestNewDeadlineTronClient_Disabled(t *testing.T) {
+	client := newFakeTronClient(200)
+	assert.Same(t, tronRPC(client), newDeadlineTronClient(client, 0))
+}
+
+func TestTronWatcher_PollCanceledOnHungNode(t *testing.T) {
+	hung := &hungTronClient{fakeTronClient: newFakeTronClient(200), release: make(chan struct{})}
+	defer close(hung.release)
+	w := newTestTronWatcher(hung) // no per-endpoint deadline: only the watcher context ends the call
+	_, addr := testTronAddress(0x22)
+	w.AddTronAddress(addr)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	done := make(chan struct{})
+	go func() {
+		w.poll(ctx)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("poll outlived the watcher context")
+	}
+	assert.Equal(t, HealthRPCError, w.Health().Status)
+}
+
+// slowOnceTronClient hangs on the first GetNowBlock, then answers.
+type slowOnceTronClient struct {
+	*fakeTronClient
+	calls   atomic.Int32
+	release chan struct{}
+}
+
+func (s *slowOnceTronClient) GetNowBlock() (*api.BlockExtention, error) {
+	if s.calls.Add(1) == 1 {
+		<-s.release
+	}
+	return s.fakeTronClient.GetNowBlock()
+}
+
+func TestTronWatcher_PollRetriesTimedOutCall(t *testing.T) {
+	slow := &slowOnceTronClient{fakeTronClient: newFakeTronClient(200), release: make(chan struct{})}
+	defer close(slow.release)
+	w := newTestTronWatcher(newDeadlineTronClient(slow, 50*time.Millisecond))
+	w.cfg.RPCRetry = config.RetryPolicy{MaxAttempts: 2, Backoff: time.Millisecond}
+	_, addr := testTronAddress(0x22)
+	w.AddTronAddress(addr)
+
+	w.poll(context.Background())
+	assert.Equal(t, int32(2), slow.calls.Load())
+	assert.Equal(t, int64(200), w.lastBlock, "the retry after the timeout reached the head")
+	assert.Equal(t, HealthOK, w.Health().Status)
+}
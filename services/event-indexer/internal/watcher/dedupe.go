@@ -0,0 +1,155 @@
+package watcher
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+)
+
+// defaultConfirmedDedupeSize bounds the EventIDs remembered for
+// confirmed-phase deduplication.
+const defaultConfirmedDedupeSize = 100_000
+
+// confirmedDedupe suppresses repeated confirmed-phase emissions of the same
+// logical transfer. A reorg that re-mines a transfer at another height
+// starts its confirmations over, and without this the confirmed phase
+// (and later milestones) would fire again. It is keyed on the reorg-stable
+// EventID and remembers, per event, the deepest confirmed emission; only
+// deeper ones get through. The oldest EventIDs are forgotten first. A nil
+// dedupe lets everything through.
+type confirmedDedupe struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List // front = oldest EventID
+	entries map[string]*list.Element
+}
+
+type confirmedEntry struct {
+	id    string
+	depth uint64 // deepest confirmed emission
+}
+
+func newConfirmedDedupe(size int) *confirmedDedupe {
+	if size <= 0 {
+		size = defaultConfirmedDedupeSize
+	}
+	return &confirmedDedupe{size: size, order: list.New(), entries: make(map[string]*list.Element)}
+}
+
+// duplicate reports whether event repeats a confirmed emission already
+// dispatched, and records it otherwise.
+func (d *confirmedDedupe) duplicate(event *ChainEvent) bool {
+	if d == nil || !event.Confirmed || event.EventID == "" || event.FinalityStatus == FinalityReorged {
+		return false
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if elem, ok := d.entries[event.EventID]; ok {
+		entry := elem.Value.(*confirmedEntry)
+		if event.Confirmations <= entry.depth {
+			log.Debug().
+				Str("event_id", event.EventID).
+				Uint64("block", event.BlockNumber).
+				Uint64("confirmations", event.Confirmations).
+				Msg("Confirmed event already emitted, suppressing duplicate")
+			return true
+		}
+		entry.depth = event.Confirmations
+		return false
+	}
+
+	d.entries[event.EventID] = d.order.PushBack(&confirmedEntry{id: event.EventID, depth: event.Confirmations})
+	if d.order.Len() > d.size {
+		oldest := d.order.Front()
+		d.order.Remove(oldest)
+		delete(d.entries, oldest.Value.(*confirmedEntry).id)
+	}
+	return false
+}
+
+// defaultSeenEventsSize bounds the EventIDs remembered for detection-time
+// deduplication.
+const defaultSeenEventsSize = 100_000
+
+// seenEvents suppresses transfers detected again in a block already
+// scanned, as when a backfill range overlaps live polling or a restart
+// resumes behind blocks whose events were delivered. It is a bounded LRU
+// keyed on EventID (chainID:txHash:logIndex) that remembers the block each
+// event was detected in: the same event in the same block is a repeat, the
+// same event in another block (re-mined after a reorg) is not. A nil set
+// lets everything through.
+type seenEvents struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List // front = most recently seen
+	entries map[string]*list.Element
+}
+
+type seenEvent struct {
+	id      string
+	chainID uint64
+	block   uint64
+}
+
+func newSeenEvents(size int) *seenEvents {
+	if size <= 0 {
+		size = defaultSeenEventsSize
+	}
+	return &seenEvents{size: size, order: list.New(), entries: make(map[string]*list.Element)}
+}
+
+// repeat reports whether event was already detected in the same block, and
+// records it otherwise.
+func (s *seenEvents) repeat(event *ChainEvent) bool {
+	if s == nil || event.EventID == "" {
+		return false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if elem, ok := s.entries[event.EventID]; ok {
+		s.order.MoveToFront(elem)
+		entry := elem.Value.(*seenEvent)
+		if entry.block == event.BlockNumber {
+			log.Debug().
+				Str("event_id", event.EventID).
+				Uint64("block", event.BlockNumber).
+				Msg("Event already detected in this block, suppressing duplicate")
+			return true
+		}
+		entry.block = event.BlockNumber
+		return false
+	}
+
+	s.entries[event.EventID] = s.order.PushFront(&seenEvent{id: event.EventID, chainID: event.ChainID, block: event.BlockNumber})
+	if s.order.Len() > s.size {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.entries, oldest.Value.(*seenEvent).id)
+	}
+	return false
+}
+
+// forget drops a chain's events detected from fromBlock on, which a reorg
+// replaced, so a transaction re-mined at the same height is emitted again.
+func (s *seenEvents) forget(chainID, fromBlock uint64) {
+	if s == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for elem := s.order.Front(); elem != nil; {
+		next := elem.Next()
+		if entry := elem.Value.(*seenEvent); entry.chainID == chainID && entry.block >= fromBlock {
+			s.order.Remove(elem)
+			delete(s.entries, entry.id)
+		}
+		elem = next
+	}
+}
@@ -0,0 +1,140 @@
+package watcher
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/fbsobreira/gotron-sdk/pkg/proto/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChainWatcher_ConfirmedDedupeAcrossReorg(t *testing.T) {
+	ctx := context.Background()
+	watched := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	other := common.HexToAddress("0x1111111111111111111111111111111111111111")
+
+	client := newFakeEVMClient(1020)
+	original := testTransferLog(1000, 3, other, watched, big.NewInt(5))
+	client.addLog(original)
+
+	w := newTestChainWatcher(t, client)
+	w.AddAddress(watched)
+	w.dispatch.confirmed = newConfirmedDedupe(0)
+
+	var mu sync.Mutex
+	var confirmed []*ChainEvent
+	w.dispatch.addHandler(func(event *ChainEvent) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if event.Confirmed {
+			confirmed = append(confirmed, event)
+		}
+		return nil
+	})
+
+	w.processBlock(ctx, 1000, 1020)
+
+	// The block is reorged out and the transaction re-mined three blocks
+	// later, at the same position
+	client.mu.Lock()
+	delete(client.logs, 1000)
+	client.mu.Unlock()
+	remined := original
+	remined.BlockNumber = 1003
+	client.addLog(remined)
+	w.processBlock(ctx, 1003, 1020)
+	w.gate.drain()
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, confirmed, 1, "one confirmed emission for the logical transfer")
+	assert.Equal(t, uint64(1000), confirmed[0].BlockNumber)
+}
+
+func TestConfirmedDedupe(t *testing.T) {
+	d := newConfirmedDedupe(2)
+	event := func(id string, confirmations uint64) *ChainEvent {
+		return &ChainEvent{EventID: id, Confirmed: true, Confirmations: confirmations}
+	}
+
+	assert.False(t, d.duplicate(event("a", 12)))
+	assert.True(t, d.duplicate(event("a", 12)))
+	assert.True(t, d.duplicate(event("a", 5)), "re-mined, shallower")
+	assert.False(t, d.duplicate(event("a", 64)), "a deeper milestone is new")
+
+	assert.False(t, d.duplicate(&ChainEvent{EventID: "a"}), "pending phase isn't deduped")
+	assert.False(t, d.duplicate(&ChainEvent{EventID: "a", Confirmed: true, Confirmations: 64, FinalityStatus: FinalityReorged}))
+	assert.False(t, d.duplicate(event("", 12)), "events without an ID")
+
+	// Bounded: the oldest IDs are forgotten
+	assert.False(t, d.duplicate(event("b", 12)))
+	assert.False(t, d.duplicate(event("c", 12)))
+	assert.False(t, d.duplicate(event("a", 12)))
+	assert.True(t, d.duplicate(event("c", 12)))
+
+	var off *confirmedDedupe
+	assert.False(t, off.duplicate(event("a", 12)))
+}
+
+func TestTronWatcher_SeenEventsAcrossRescans(t *testing.T) {
+	client := newFakeTronClient(200)
+	token, _ := testTronAddress(0xaa)
+	from, _ := testTronAddress(0x11)
+	to, toAddr := testTronAddress(0x22)
+	transfer := func(value int64) *core.TransactionInfo_Log {
+		return &core.TransactionInfo_Log{
+			Address: token,
+			Topics:  [][]byte{mustHex(trc20TransferSig), leftPad32(from), leftPad32(to)},
+			Data:    leftPad32(big.NewInt(value).Bytes()),
+		}
+	}
+	// A router moving the token twice in one transaction
+	client.addLogs(190, "a190", transfer(1), transfer(2))
+
+	w := newTestTronWatcher(client)
+	w.AddTronAddress(toAddr)
+	w.dispatch.seen = newSeenEvents(0)
+
+	assert.Equal(t, []string{"a190", "a190"}, collectTronTxs(t, w, 190, 200), "both logs of the transaction")
+	assert.Empty(t, collectTronTxs(t, w, 190, 200), "the overlapping rescan emits nothing")
+
+	// A reorg replacing the block makes its events new again
+	w.emitReorged(190)
+	assert.Equal(t, []string{"a190", "a190"}, collectTronTxs(t, w, 190, 200))
+}
+
+func TestSeenEvents(t *testing.T) {
+	s := newSeenEvents(2)
+	event := func(chainID uint64, id string, block uint64) *ChainEvent {
+		return &ChainEvent{ChainID: chainID, EventID: id, BlockNumber: block}
+	}
+
+	assert.False(t, s.repeat(event(1, "a", 100)))
+	assert.True(t, s.repeat(event(1, "a", 100)))
+	assert.False(t, s.repeat(event(1, "a", 103)), "re-mined at another height")
+	assert.True(t, s.repeat(event(1, "a", 103)))
+	assert.False(t, s.repeat(event(1, "", 100)), "events without an ID")
+
+	// Bounded LRU: the least recently seen ID is forgotten
+	assert.False(t, s.repeat(event(1, "b", 100)))
+	assert.True(t, s.repeat(event(1, "a", 103)))
+	assert.False(t, s.repeat(event(1, "c", 100)))
+	assert.False(t, s.repeat(event(1, "b", 100)), "evicted")
+	assert.True(t, s.repeat(event(1, "c", 100)))
+
+	// Reorgs forget one chain's events from the fork point on
+	s.forget(1, 100)
+	assert.False(t, s.repeat(event(1, "c", 100)))
+	assert.False(t, s.repeat(event(2, "d", 100)))
+	s.forget(1, 101)
+	assert.True(t, s.repeat(event(1, "c", 100)))
+	assert.True(t, s.repeat(event(2, "d", 100)))
+
+	var off *seenEvents
+	assert.False(t, off.repeat(event(1, "a", 100)))
+	off.forget(1, 0)
+}
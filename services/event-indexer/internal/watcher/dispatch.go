@@ -0,0 +1,203 @@
+package watcher
+
+import (
+	"sync"
+	"time"
+
+	"github.com/protocol-bank/event-indexer/internal/config"
+	"github.com/rs/zerolog/log"
+)
+
+// DeadLetterHandler receives events whose handler failed every attempt
+// allowed by the event type's retry policy.
+type DeadLetterHandler func(event *ChainEvent, err error)
+
+// dispatcher fans events out to registered handlers, retrying failed
+// deliveries according to per-event-type retry policies. A single dispatcher
+// is shared by all chain watchers of a MultiChainWatcher.
+type dispatcher struct {
+	mu            sync.RWMutex
+	handlers      []routedHandler
+	reorgHandlers []ReorgHandler
+	addressTags   map[string]map[string]bool // normalized address → tags
+	policies      map[string]config.RetryPolicy
+	defaultPolicy config.RetryPolicy
+	deadLetter    DeadLetterHandler
+	limiter       *handlerLimiter  // global cap on concurrent handlers, nil = unlimited
+	partitionBy   string           // stamped on events for PartitionKey
+	phases        *phaseSequencer  // per-event phase ordering, nil = unordered
+	throttle      *addressThrottle // per-address event cap, nil = off
+	balances      *balanceTracker  // running balances, nil = off
+	confirmed     *confirmedDedupe // confirmed-phase dedupe, nil = off
+	seen          *seenEvents      // detection-time dedupe, nil = off
+	tokenPolicies *tokenPolicies   // per-token policies, nil = off
+	queue         *dispatchQueue   // bounded delivery workers, nil = goroutine per delivery
+	emitted       *seenEvents      // events already counted by eventsEmitted
+	done          <-chan struct{}  // cuts retry backoff short on shutdown, nil = never
+}
+
+func newDispatcher(policies map[string]config.RetryPolicy, defaultPolicy config.RetryPolicy) *dispatcher {
+	if policies == nil {
+		policies = make(map[string]config.RetryPolicy)
+	}
+	if defaultPolicy.MaxAttempts < 1 {
+		defaultPolicy.MaxAttempts = 1
+	}
+	return &dispatcher{
+		addressTags:   make(map[string]map[string]bool),
+		policies:      policies,
+		defaultPolicy: defaultPolicy,
+		deadLetter:    logDeadLetter,
+		emitted:       newSeenEvents(0),
+	}
+}
+
+// addHandler registers a handler for all subsequent events.
+func (d *dispatcher) addHandler(handler EventHandler) {
+	d.addTaggedHandler(handler)
+}
+
+// addTaggedHandler registers a handler that only receives events whose
+// sender or recipient carries one of tags. No tags means every event.
+func (d *dispatcher) addTaggedHandler(handler EventHandler, tags ...string) {
+	routed := routedHandler{handler: handler}
+	if len(tags) > 0 {
+		routed.tags = make(map[string]bool, len(tags))
+		for _, tag := range tags {
+			routed.tags[tag] = true
+		}
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.handlers = append(d.handlers, routed)
+}
+
+// setDeadLetterHandler replaces the default (log-only) dead-letter sink.
+func (d *dispatcher) setDeadLetterHandler(handler DeadLetterHandler) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.deadLetter = handler
+}
+
+// dispatch delivers event to every handler concurrently. Deliveries are
+// spawned on the gate so lame duck shutdown waits for them, retries included.
+// Running balances are updated before delivery, and their balance_delta
+// events follow the transfer. Repeated confirmed emissions are dropped when
+// confirmed-phase dedupe is on.
+func (d *dispatcher) dispatch(gate *drainGate, event *ChainEvent) {
+	event.SchemaVersion = EventSchemaVersion
+	if event.Network == "" {
+		event.Network = NetworkSymbol(event.ChainID)
+	}
+	if event.PartitionBy == "" {
+		event.PartitionBy = d.partitionBy
+	}
+	if event.FinalityStatus == "" {
+		event.FinalityStatus = defaultFinality(event)
+	}
+	if !d.tokenPolicies.admit(event) || d.confirmed.duplicate(event) {
+		return
+	}
+	deltas := d.balances.apply(event)
+	if d.throttle.allow(event.WatchedAddress) {
+		// 里程碑/确认阶段副本与重组通知不重复计数
+		if event.FinalityStatus != FinalityReorged && !d.emitted.repeat(event) {
+			eventsEmitted.WithLabelValues(event.ChainName, event.EventType).Inc()
+		}
+		d.fanOut(gate, event)
+	}
+	for _, delta := range deltas {
+		d.dispatch(gate, delta)
+	}
+}
+
+// fanOut spawns a delivery of event to each handler it routes to.
+func (d *dispatcher) fanOut(gate *drainGate, event *ChainEvent) {
+	d.mu.RLock()
+	handlers := make([]EventHandler, 0, len(d.handlers))
+	indexes := make([]int, 0, len(d.handlers))
+	for i, routed := range d.handlers {
+		if d.routesTo(routed, event) {
+			handlers = append(handlers, routed.handler)
+			indexes = append(indexes, i)
+		}
+	}
+	d.mu.RUnlock()
+
+	for i, handler := range handlers {
+		prev, done := d.phases.enqueue(event.EventID, indexes[i])
+		d.queue.submit(gate, event, func() {
+			defer done()
+			if prev != nil {
+				<-prev
+			}
+			d.deliver(handler, event)
+		}, done)
+	}
+}
+
+// deliver invokes handler, retrying with exponential backoff until it
+// succeeds or the policy is exhausted, then dead-letters the event. A
+// panicking handler counts as a failed attempt; shutdown stops the wait
+// and dead-letters the event with the last error.
+func (d *dispatcher) deliver(handler EventHandler, event *ChainEvent) {
+	policy := d.policyFor(event.EventType)
+	backoff := policy.Backoff
+
+	var err error
+	for attempt := 1; attempt <= policy.MaxAttempts; attempt++ {
+		// The slot is only held while the handler runs, not during backoff
+		release := d.limiter.acquire(event.ChainID)
+		err = callHandler(handler, event)
+		release()
+		if err == nil {
+			return
+		}
+		if attempt < policy.MaxAttempts {
+			log.Warn().
+				Err(err).
+				Str("event_type", event.EventType).
+				Str("tx", event.TxHash).
+				Int("attempt", attempt).
+				Msg("Event handler failed, retrying")
+			if !d.wait(backoff) {
+				break
+			}
+			backoff *= 2
+		}
+	}
+
+	d.mu.RLock()
+	deadLetter := d.deadLetter
+	d.mu.RUnlock()
+	deadLetter(event, err)
+}
+
+// wait sleeps for a retry backoff, returning false if shutdown cut it short.
+func (d *dispatcher) wait(backoff time.Duration) bool {
+	select {
+	case <-d.done:
+		return false
+	case <-time.After(backoff):
+		return true
+	}
+}
+
+// policyFor returns the retry policy configured for an event type.
+func (d *dispatcher) policyFor(eventType string) config.RetryPolicy {
+	if policy, ok := d.policies[eventType]; ok && policy.MaxAttempts > 0 {
+		return policy
+	}
+	return d.defaultPolicy
+}
+
+// logDeadLetter is the default dead-letter sink.
+func logDeadLetter(event *ChainEvent, err error) {
+	log.Error().
+		Err(err).
+		Uint64("chain_id", event.ChainID).
+		Str("event_type", event.EventType).
+		Str("tx", event.TxHash).
+		Msg("Event handler retries exhausted, event dead-lettered")
+}
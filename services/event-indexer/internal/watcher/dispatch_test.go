@@ -0,0 +1,187 @@
+package watcher
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/protocol-bank/event-indexer/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDispatcher_RetryPolicyPerEventType(t *testing.T) {
+	d := newDispatcher(map[string]config.RetryPolicy{
+		"trc20_transfer": {MaxAttempts: 5},
+		"heartbeat":      {MaxAttempts: 1},
+	}, config.RetryPolicy{MaxAttempts: 2})
+
+	var mu sync.Mutex
+	attempts := map[string]int{}
+	deadLettered := map[string]error{}
+
+	d.addHandler(func(event *ChainEvent) error {
+		mu.Lock()
+		defer mu.Unlock()
+		attempts[event.EventType]++
+		return errors.New("downstream unavailable")
+	})
+	d.setDeadLetterHandler(func(event *ChainEvent, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		deadLettered[event.EventType] = err
+	})
+
+	var gate drainGate
+	gate.enter()
+	d.dispatch(&gate, &ChainEvent{EventType: "trc20_transfer"})
+	d.dispatch(&gate, &ChainEvent{EventType: "heartbeat"})
+	d.dispatch(&gate, &ChainEvent{EventType: "transfer"})
+	gate.leave()
+	gate.drain()
+
+	assert.Equal(t, 5, attempts["trc20_transfer"])
+	assert.Equal(t, 1, attempts["heartbeat"])
+	assert.Equal(t, 2, attempts["transfer"], "unlisted types use the default policy")
+	assert.Greater(t, attempts["trc20_transfer"], attempts["heartbeat"])
+
+	assert.Len(t, deadLettered, 3)
+	assert.EqualError(t, deadLettered["heartbeat"], "downstream unavailable")
+}
+
+func TestDispatcher_RetrySucceedsBeforeDeadLetter(t *testing.T) {
+	d := newDispatcher(nil, config.RetryPolicy{MaxAttempts: 3})
+
+	calls := 0
+	d.addHandler(func(event *ChainEvent) error {
+		calls++
+		if calls < 2 {
+			return errors.New("transient")
+		}
+		return nil
+	})
+	deadLettered := false
+	d.setDeadLetterHandler(func(*ChainEvent, error) { deadLettered = true })
+
+	var gate drainGate
+	gate.enter()
+	d.dispatch(&gate, &ChainEvent{EventType: "transfer"})
+	gate.leave()
+	gate.drain()
+
+	assert.Equal(t, 2, calls)
+	assert.False(t, deadLettered)
+}
+
+func TestDispatcher_ShutdownCutsBackoffShort(t *testing.T) {
+	d := newDispatcher(nil, config.RetryPolicy{MaxAttempts: 3, Backoff: time.Hour})
+	done := make(chan struct{})
+	d.done = done
+
+	calls := 0
+	d.addHandler(func(event *ChainEvent) error {
+		calls++
+		return errors.New("downstream unavailable")
+	})
+	deadLettered := make(chan error, 1)
+	d.setDeadLetterHandler(func(_ *ChainEvent, err error) { deadLettered <- err })
+
+	var gate drainGate
+	gate.enter()
+	d.dispatch(&gate, &ChainEvent{EventType: "transfer"})
+	gate.leave()
+	close(done)
+
+	select {
+	case err := <-deadLettered:
+		assert.EqualError(t, err, "downstream unavailable")
+	case <-time.After(time.Second):
+		t.Fatal("backoff wasn't interrupted by shutdown")
+	}
+	gate.drain()
+	assert.Equal(t, 1, calls)
+}
+
+func TestDispatcher_PhaseOrdering(t *testing.T) {
+	d := newDispatcher(nil, config.RetryPolicy{MaxAttempts: 3})
+	d.phases = newPhaseSequencer()
+
+	var mu sync.Mutex
+	var delivered []string
+	failedOnce := map[string]bool{}
+	d.addHandler(func(event *ChainEvent) error {
+		if !event.Confirmed {
+			// The pending phase is slow and fails its first attempt, so an
+			// unordered dispatcher would deliver the confirmed phase first
+			time.Sleep(20 * time.Millisecond)
+			mu.Lock()
+			first := !failedOnce[event.EventID]
+			failedOnce[event.EventID] = true
+			mu.Unlock()
+			if first {
+				return errors.New("transient")
+			}
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		phase := "pending"
+		if event.Confirmed {
+			phase = "confirmed"
+		}
+		delivered = append(delivered, event.EventID+"/"+phase)
+		return nil
+	})
+
+	var gate drainGate
+	gate.enter()
+	for _, id := range []string{"1:0xa:0", "1:0xa:1"} {
+		pending := &ChainEvent{EventType: "transfer", EventID: id}
+		confirmed := *pending
+		confirmed.Confirmed = true
+		d.dispatch(&gate, pending)
+		d.dispatch(&gate, &confirmed)
+	}
+	gate.leave()
+	gate.drain()
+
+	require.Len(t, delivered, 4)
+	for _, id := range []string{"1:0xa:0", "1:0xa:1"} {
+		pendingAt := slices.Index(delivered, id+"/pending")
+		confirmedAt := slices.Index(delivered, id+"/confirmed")
+		assert.Less(t, pendingAt, confirmedAt, "pending phase of %s must be delivered first", id)
+	}
+	assert.Empty(t, d.phases.tails, "finished sequences are released")
+}
+
+func TestDispatcher_StampsSchemaVersion(t *testing.T) {
+	d := newDispatcher(nil, config.RetryPolicy{MaxAttempts: 1})
+
+	var mu sync.Mutex
+	var received []*ChainEvent
+	d.addHandler(func(event *ChainEvent) error {
+		mu.Lock()
+		defer mu.Unlock()
+		received = append(received, event)
+		return nil
+	})
+
+	var gate drainGate
+	gate.enter()
+	d.dispatch(&gate, &ChainEvent{EventType: "transfer", SchemaVersion: 99})
+	gate.leave()
+	gate.drain()
+
+	require.NotEmpty(t, received)
+	for _, event := range received {
+		assert.Equal(t, EventSchemaVersion, event.SchemaVersion, event.EventType)
+	}
+
+	encoded, err := json.Marshal(received[0])
+	require.NoError(t, err)
+	var decoded map[string]any
+	require.NoError(t, json.Unmarshal(encoded, &decoded))
+	assert.EqualValues(t, EventSchemaVersion, decoded["SchemaVersion"])
+}
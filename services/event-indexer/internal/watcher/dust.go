@@ -0,0 +1,30 @@
+package watcher
+
+import (
+	"math/big"
+
+	"github.com/rs/zerolog/log"
+)
+
+// belowMinTransferValue reports whether event is a fungible transfer of less
+// than minValue raw units, counting it as filtered. The scan paths skip such
+// dust before dispatching or tracking it, so an airdrop doesn't flood
+// handlers or the pending confirmation queue. A nil minValue keeps all.
+func belowMinTransferValue(minValue *big.Int, event *ChainEvent) bool {
+	if minValue == nil || !policyTransferTypes[event.EventType] {
+		return false
+	}
+	value, ok := new(big.Int).SetString(event.Value, 10)
+	if !ok || value.Cmp(minValue) >= 0 {
+		return false
+	}
+
+	eventsFiltered.WithLabelValues(event.ChainName, event.EventType).Inc()
+	log.Debug().
+		Str("chain", event.ChainName).
+		Str("tx", event.TxHash).
+		Str("value", event.Value).
+		Str("min", minValue.String()).
+		Msg("Transfer below MinTransferValue, skipping")
+	return true
+}
@@ -0,0 +1,44 @@
+package watcher
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChainWatcher_MinTransferValue(t *testing.T) {
+	client := newFakeEVMClient(1020)
+	watched := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	from := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	client.addLog(testTransferLog(1000, 0, from, watched, big.NewInt(999)))
+	client.addLog(testTransferLog(1000, 1, from, watched, big.NewInt(1000)))
+
+	w := newTestChainWatcher(t, client)
+	w.cfg.MinTransferValue = big.NewInt(1000)
+	w.AddAddress(watched)
+	filtered := testutil.ToFloat64(eventsFiltered.WithLabelValues(w.chainName, "transfer"))
+
+	events := collectEVMEvents(t, w, 1000, 1020)
+	require.Len(t, events, 1)
+	assert.Equal(t, "1000", events[0].Value, "the threshold itself is kept")
+	assert.Equal(t, filtered+1, testutil.ToFloat64(eventsFiltered.WithLabelValues(w.chainName, "transfer")))
+}
+
+func TestTronWatcher_MinTransferValue(t *testing.T) {
+	client := newFakeTronClient(200)
+	token, _ := testTronAddress(0xaa)
+	from, _ := testTronAddress(0x11)
+	to, toAddr := testTronAddress(0x22)
+	client.addTransfer(190, "d190", token, from, to, big.NewInt(1))
+	client.addTransfer(190, "a190", token, from, to, big.NewInt(5_000_000))
+
+	w := newTestTronWatcher(client)
+	w.cfg.MinTransferValue = big.NewInt(1_000_000)
+	w.AddTronAddress(toAddr)
+
+	assert.Equal(t, []string{"a190"}, collectTronTxs(t, w, 190, 200))
+}
@@ -0,0 +1,487 @@
+package watcher
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/fbsobreira/gotron-sdk/pkg/proto/api"
+	"github.com/fbsobreira/gotron-sdk/pkg/proto/core"
+	"github.com/protocol-bank/event-indexer/internal/config"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// endpointFailureThreshold consecutive failures bench an endpoint
+	endpointFailureThreshold = 3
+	// endpointStatsWeight is the weight of the newest call in the rolling stats
+	endpointStatsWeight = 0.2
+	// defaultEndpointCooldown is how long after its last failure an endpoint
+	// is forgiven, when the chain config leaves it unset
+	defaultEndpointCooldown = 30 * time.Second
+)
+
+// endpointStats are the rolling health stats of one RPC endpoint.
+type endpointStats struct {
+	url         string
+	success     float64       // moving average of call outcomes, 1 = all succeeded
+	latency     time.Duration // moving average latency of successful calls
+	failures    int           // consecutive failures
+	lastFailure time.Time
+	head        uint64 // highest block the endpoint reported, 0 = unknown
+}
+
+// score ranks available endpoints: reliability first, discounted by latency.
+func (e *endpointStats) score() float64 {
+	return e.success / (1 + e.latency.Seconds())
+}
+
+// endpointSelector picks, for each call, the healthiest of a chain's RPC
+// endpoints. Errors and slow responses lower an endpoint's score, and
+// repeated failures bench it outright. An endpoint that hasn't failed for a
+// cooldown starts over with a clean slate. Ties go to the earlier endpoint,
+// so the primary is preferred when healthy. Calls for a given block go to
+// an endpoint that has reported a head at or past it, so a lagging node
+// isn't asked for blocks it doesn't have yet.
+type endpointSelector struct {
+	mu        sync.Mutex
+	chainName string
+	endpoints []*endpointStats
+	active    int // endpoint of the latest call
+	cooldown  time.Duration
+	now       func() time.Time
+}
+
+func newEndpointSelector(chainName string, urls []string, cooldown time.Duration) *endpointSelector {
+	if cooldown <= 0 {
+		cooldown = defaultEndpointCooldown
+	}
+	s := &endpointSelector{chainName: chainName, cooldown: cooldown, now: time.Now}
+	for _, url := range urls {
+		s.endpoints = append(s.endpoints, &endpointStats{url: url, success: 1})
+	}
+	return s
+}
+
+// pick returns the index of the endpoint to use for the next call. If every
+// endpoint is benched, the one that recovers first is tried anyway.
+func (s *endpointSelector) pick() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.pickWhere(func(int, *endpointStats) bool { return true })
+}
+
+// pickAt returns the endpoint to use for a call for data of block, skipping
+// the ones tried already: the best of those that reported a head at or
+// past block, or else the best of the rest. It returns -1 once every
+// endpoint was tried.
+func (s *endpointSelector) pickAt(block uint64, tried map[int]bool) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if i := s.pickWhere(func(i int, e *endpointStats) bool { return !tried[i] && e.head >= block }); i >= 0 {
+		return i
+	}
+	return s.pickWhere(func(i int, _ *endpointStats) bool { return !tried[i] })
+}
+
+// pickWhere returns the best endpoint among those eligible, -1 if none is.
+// s.mu must be held.
+func (s *endpointSelector) pickWhere(eligible func(i int, e *endpointStats) bool) int {
+	now := s.now()
+	best, soonest := -1, -1
+	for i, e := range s.endpoints {
+		if !e.lastFailure.IsZero() && now.Sub(e.lastFailure) >= s.cooldown {
+			if e.failures >= endpointFailureThreshold {
+				log.Info().Str("chain", s.chainName).Str("rpc", e.url).Msg("RPC endpoint cooldown over, eligible again")
+			}
+			*e = endpointStats{url: e.url, success: 1, head: e.head}
+		}
+		if !eligible(i, e) {
+			continue
+		}
+		if e.failures >= endpointFailureThreshold {
+			if soonest < 0 || e.lastFailure.Before(s.endpoints[soonest].lastFailure) {
+				soonest = i
+			}
+			continue
+		}
+		if best < 0 || e.score() > s.endpoints[best].score() {
+			best = i
+		}
+	}
+	if best < 0 {
+		best = soonest
+	}
+	if best >= 0 {
+		s.active = best
+	}
+	return best
+}
+
+// observeHead notes the head an endpoint reported.
+func (s *endpointSelector) observeHead(i int, head uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.endpoints[i].head = max(s.endpoints[i].head, head)
+}
+
+// reported reports whether some endpoint has reported a head at or past
+// block, so that an endpoint without the block lags behind the chain.
+func (s *endpointSelector) reported(block uint64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, e := range s.endpoints {
+		if e.head >= block {
+			return true
+		}
+	}
+	return false
+}
+
+// benched returns the endpoints benched by repeated failures.
+func (s *endpointSelector) benched() []int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var benched []int
+	for i, e := range s.endpoints {
+		if e.failures >= endpointFailureThreshold {
+			benched = append(benched, i)
+		}
+	}
+	return benched
+}
+
+// checkBenched probes every benched endpoint with check and gives the ones
+// that pass a clean slate without waiting out their cooldown, so a
+// recovered primary takes over again as soon as it is healthy.
+func (s *endpointSelector) checkBenched(check func(i int) error) {
+	for _, i := range s.benched() {
+		if err := check(i); err != nil {
+			continue
+		}
+		s.mu.Lock()
+		e := s.endpoints[i]
+		*e = endpointStats{url: e.url, success: 1, head: e.head}
+		s.mu.Unlock()
+		log.Info().Str("chain", s.chainName).Str("rpc", e.url).Msg("RPC endpoint passed health check, eligible again")
+	}
+}
+
+// url returns the URL of endpoint i.
+func (s *endpointSelector) url(i int) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.endpoints[i].url
+}
+
+// activeURL returns the endpoint the latest call went to.
+func (s *endpointSelector) activeURL() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.endpoints[s.active].url
+}
+
+// record folds the outcome of a call into the endpoint's stats.
+func (s *endpointSelector) record(i int, latency time.Duration, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e := s.endpoints[i]
+	if !isEndpointFailure(err) {
+		e.success += endpointStatsWeight * (1 - e.success)
+		e.latency += time.Duration(endpointStatsWeight * float64(latency-e.latency))
+		e.failures = 0
+		return
+	}
+
+	e.success -= endpointStatsWeight * e.success
+	e.failures++
+	e.lastFailure = s.now()
+	if e.failures == endpointFailureThreshold {
+		log.Warn().
+			Err(err).
+			Str("chain", s.chainName).
+			Str("rpc", e.url).
+			Dur("cooldown", s.cooldown).
+			Msg("RPC endpoint failing, benched")
+	}
+}
+
+// isEndpointFailure reports whether err reflects on the endpoint's health.
+// Missing data, oversized responses and our own cancellations don't.
+func isEndpointFailure(err error) bool {
+	return err != nil &&
+		!errors.Is(err, ethereum.NotFound) &&
+		!errors.Is(err, context.Canceled) &&
+		!isSizeLimitError(err)
+}
+
+// errEndpointLagging is recorded against an endpoint missing a block that
+// another endpoint already reported.
+var errEndpointLagging = errors.New("endpoint is behind the reported head")
+
+// callEndpoint runs fn against the selected client and records the outcome.
+func callEndpoint[C, T any](s *endpointSelector, clients []C, fn func(C) (T, error)) (T, error) {
+	i := s.pick()
+	start := s.now()
+	v, err := fn(clients[i])
+	s.record(i, s.now().Sub(start), err)
+	return v, err
+}
+
+// callHeadEndpoint runs fn, a head query, like callEndpoint and notes the
+// head the endpoint reported.
+func callHeadEndpoint[C, T any](s *endpointSelector, clients []C, fn func(C) (T, error), head func(T) uint64) (T, error) {
+	i := s.pick()
+	start := s.now()
+	v, err := fn(clients[i])
+	s.record(i, s.now().Sub(start), err)
+	if err == nil {
+		s.observeHead(i, head(v))
+	}
+	return v, err
+}
+
+// callBlockEndpoint runs fn, a call for data of block, against an endpoint
+// that has reported a head at or past block when one has. A not-found or
+// empty answer (per empty, nil = none) for a block some endpoint reported
+// means the endpoint lags: it counts as a failure and the call fails over
+// to the next endpoint, the last one's answer being returned as is.
+func callBlockEndpoint[C, T any](s *endpointSelector, clients []C, block uint64, fn func(C) (T, error), empty func(T) bool) (T, error) {
+	tried := make(map[int]bool, len(clients))
+	for {
+		i := s.pickAt(block, tried)
+		start := s.now()
+		v, err := fn(clients[i])
+		missing := errors.Is(err, ethereum.NotFound) || (err == nil && empty != nil && empty(v))
+		tried[i] = true
+		if missing && len(tried) < len(clients) && s.reported(block) {
+			s.record(i, 0, errEndpointLagging)
+			log.Debug().Str("chain", s.chainName).Str("rpc", s.url(i)).Uint64("block", block).Msg("RPC endpoint missing a reported block, failing over")
+			continue
+		}
+		s.record(i, s.now().Sub(start), err)
+		return v, err
+	}
+}
+
+// endpointURLs lists a chain's RPC endpoints: RPCURL first, then RPCURLs,
+// skipping blanks and duplicates.
+func endpointURLs(cfg config.ChainConfig) []string {
+	seen := make(map[string]bool)
+	var urls []string
+	for _, url := range append([]string{cfg.RPCURL}, cfg.RPCURLs...) {
+		url = strings.TrimSpace(url)
+		if url == "" || seen[url] {
+			continue
+		}
+		seen[url] = true
+		urls = append(urls, url)
+	}
+	return urls
+}
+
+// endpointChecker is implemented by clients spreading calls over several
+// endpoints.
+type endpointChecker interface {
+	// checkEndpoints probes the benched endpoints with a cheap call
+	checkEndpoints(ctx context.Context)
+	endpoints() *endpointSelector
+}
+
+// runEndpointHealthChecks probes client's benched endpoints every interval
+// until ctx is done. It returns at once for a single-endpoint client or a
+// non-positive interval.
+func runEndpointHealthChecks(ctx context.Context, client any, interval time.Duration) {
+	checker, ok := client.(endpointChecker)
+	if !ok || interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			checker.checkEndpoints(ctx)
+		}
+	}
+}
+
+// activeEndpoint returns the endpoint client's calls currently go to: the
+// selected one for several, the only one otherwise.
+func activeEndpoint(client any, cfg config.ChainConfig) (string, bool) {
+	if checker, ok := client.(endpointChecker); ok {
+		return checker.endpoints().activeURL(), true
+	}
+	urls := endpointURLs(cfg)
+	if len(urls) == 0 {
+		return "", false
+	}
+	return urls[0], true
+}
+
+// redactEndpoint reduces an endpoint URL to its host, dropping the paths
+// and query strings that hosted providers put API keys in.
+func redactEndpoint(endpoint string) string {
+	if u, err := url.Parse(endpoint); err == nil && u.Host != "" {
+		return u.Host
+	}
+	host, _, _ := strings.Cut(endpoint, "/")
+	return host
+}
+
+// multiEVMClient spreads evmRPC calls over several endpoints by health.
+type multiEVMClient struct {
+	clients  []evmRPC
+	selector *endpointSelector
+}
+
+func newMultiEVMClient(chainName string, urls []string, clients []evmRPC, cooldown time.Duration) *multiEVMClient {
+	return &multiEVMClient{clients: clients, selector: newEndpointSelector(chainName, urls, cooldown)}
+}
+
+func (m *multiEVMClient) endpoints() *endpointSelector { return m.selector }
+
+func (m *multiEVMClient) checkEndpoints(ctx context.Context) {
+	m.selector.checkBenched(func(i int) error {
+		_, err := m.clients[i].BlockNumber(ctx)
+		return err
+	})
+}
+
+func (m *multiEVMClient) BlockNumber(ctx context.Context) (uint64, error) {
+	return callHeadEndpoint(m.selector, m.clients, func(c evmRPC) (uint64, error) {
+		return c.BlockNumber(ctx)
+	}, func(head uint64) uint64 { return head })
+}
+
+func (m *multiEVMClient) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	if number == nil {
+		return callEndpoint(m.selector, m.clients, func(c evmRPC) (*types.Header, error) {
+			return c.HeaderByNumber(ctx, number)
+		})
+	}
+	return callBlockEndpoint(m.selector, m.clients, number.Uint64(), func(c evmRPC) (*types.Header, error) {
+		return c.HeaderByNumber(ctx, number)
+	}, nil)
+}
+
+func (m *multiEVMClient) BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error) {
+	if number == nil {
+		return callEndpoint(m.selector, m.clients, func(c evmRPC) (*types.Block, error) {
+			return c.BlockByNumber(ctx, number)
+		})
+	}
+	return callBlockEndpoint(m.selector, m.clients, number.Uint64(), func(c evmRPC) (*types.Block, error) {
+		return c.BlockByNumber(ctx, number)
+	}, nil)
+}
+
+func (m *multiEVMClient) TransactionCount(ctx context.Context, blockHash common.Hash) (uint, error) {
+	return callEndpoint(m.selector, m.clients, func(c evmRPC) (uint, error) {
+		return c.TransactionCount(ctx, blockHash)
+	})
+}
+
+// FilterLogs goes to an endpoint that has reported the query's last block:
+// a node without it would answer with no logs rather than an error.
+func (m *multiEVMClient) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	if q.ToBlock == nil || q.ToBlock.Sign() < 0 {
+		return callEndpoint(m.selector, m.clients, func(c evmRPC) ([]types.Log, error) {
+			return c.FilterLogs(ctx, q)
+		})
+	}
+	return callBlockEndpoint(m.selector, m.clients, q.ToBlock.Uint64(), func(c evmRPC) ([]types.Log, error) {
+		return c.FilterLogs(ctx, q)
+	}, nil)
+}
+
+func (m *multiEVMClient) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	return callEndpoint(m.selector, m.clients, func(c evmRPC) (*types.Receipt, error) {
+		return c.TransactionReceipt(ctx, txHash)
+	})
+}
+
+func (m *multiEVMClient) TransactionByHash(ctx context.Context, hash common.Hash) (*types.Transaction, bool, error) {
+	type result struct {
+		tx      *types.Transaction
+		pending bool
+	}
+	r, err := callEndpoint(m.selector, m.clients, func(c evmRPC) (result, error) {
+		tx, pending, err := c.TransactionByHash(ctx, hash)
+		return result{tx, pending}, err
+	})
+	return r.tx, r.pending, err
+}
+
+func (m *multiEVMClient) TransactionSender(ctx context.Context, tx *types.Transaction, block common.Hash, index uint) (common.Address, error) {
+	return callEndpoint(m.selector, m.clients, func(c evmRPC) (common.Address, error) {
+		return c.TransactionSender(ctx, tx, block, index)
+	})
+}
+
+// multiTronClient spreads tronRPC calls over several endpoints by health.
+type multiTronClient struct {
+	clients  []tronRPC
+	selector *endpointSelector
+}
+
+func newMultiTronClient(chainName string, urls []string, clients []tronRPC, cooldown time.Duration) *multiTronClient {
+	return &multiTronClient{clients: clients, selector: newEndpointSelector(chainName, urls, cooldown)}
+}
+
+func (m *multiTronClient) endpoints() *endpointSelector { return m.selector }
+
+func (m *multiTronClient) checkEndpoints(context.Context) {
+	m.selector.checkBenched(func(i int) error {
+		_, err := m.clients[i].GetNowBlock()
+		return err
+	})
+}
+
+func (m *multiTronClient) GetNowBlock() (*api.BlockExtention, error) {
+	return callHeadEndpoint(m.selector, m.clients, func(c tronRPC) (*api.BlockExtention, error) {
+		return c.GetNowBlock()
+	}, func(block *api.BlockExtention) uint64 {
+		return uint64(max(block.GetBlockHeader().GetRawData().GetNumber(), 0))
+	})
+}
+
+// GetBlockByNum fails over on an empty block: TRON nodes answer a block
+// past their head with one rather than an error.
+func (m *multiTronClient) GetBlockByNum(num int64) (*api.BlockExtention, error) {
+	return callBlockEndpoint(m.selector, m.clients, uint64(max(num, 0)), func(c tronRPC) (*api.BlockExtention, error) {
+		return c.GetBlockByNum(num)
+	}, func(block *api.BlockExtention) bool {
+		return block.GetBlockHeader() == nil
+	})
+}
+
+func (m *multiTronClient) GetTransactionInfoByID(id string) (*core.TransactionInfo, error) {
+	return callEndpoint(m.selector, m.clients, func(c tronRPC) (*core.TransactionInfo, error) {
+		return c.GetTransactionInfoByID(id)
+	})
+}
+
+func (m *multiTronClient) GetBlockInfoByNum(num int64) (*api.TransactionInfoList, error) {
+	return callBlockEndpoint(m.selector, m.clients, uint64(max(num, 0)), func(c tronRPC) (*api.TransactionInfoList, error) {
+		return c.GetBlockInfoByNum(num)
+	}, nil)
+}
+
+func (m *multiTronClient) GetNodeInfo() (*core.NodeInfo, error) {
+	return callEndpoint(m.selector, m.clients, func(c tronRPC) (*core.NodeInfo, error) {
+		return c.GetNodeInfo()
+	})
+}
@@ -0,0 +1,164 @@
+package watcher
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/protocol-bank/event-indexer/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flakyEVMClient fails BlockNumber while down is set and counts calls.
+type flakyEVMClient struct {
+	*fakeEVMClient
+	down  atomic.Bool
+	calls atomic.Int32
+}
+
+func (f *flakyEVMClient) BlockNumber(ctx context.Context) (uint64, error) {
+	f.calls.Add(1)
+	if f.down.Load() {
+		return 0, errors.New("503 Service Unavailable")
+	}
+	return f.fakeEVMClient.BlockNumber(ctx)
+}
+
+func TestMultiEVMClient_HealthWeightedSelection(t *testing.T) {
+	ctx := context.Background()
+	primary := &flakyEVMClient{fakeEVMClient: newFakeEVMClient(1000)}
+	backup := &flakyEVMClient{fakeEVMClient: newFakeEVMClient(1000)}
+	primary.down.Store(true)
+
+	now := time.Unix(1_700_000_000, 0)
+	m := newMultiEVMClient("Test", []string{"primary", "backup"}, []evmRPC{primary, backup}, time.Minute)
+	m.selector.now = func() time.Time { return now }
+
+	// The primary is preferred until it errors, then calls steer away
+	for i := 0; i < 10; i++ {
+		_, _ = m.BlockNumber(ctx)
+	}
+	assert.Equal(t, int32(1), primary.calls.Load(), "failing endpoint stops being selected")
+	assert.Equal(t, int32(9), backup.calls.Load())
+
+	// Once the cooldown has passed without failures it recovers
+	primary.down.Store(false)
+	now = now.Add(time.Minute)
+	head, err := m.BlockNumber(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1000), head)
+	assert.Equal(t, int32(2), primary.calls.Load(), "recovered primary is preferred again")
+}
+
+func TestEndpointSelector_BenchesRepeatedFailures(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	s := newEndpointSelector("Test", []string{"primary", "slow"}, time.Minute)
+	s.now = func() time.Time { return now }
+
+	// A slow but working endpoint scores below a briefly erroring one...
+	s.record(1, 5*time.Second, nil)
+	s.record(0, 0, errors.New("timeout"))
+	assert.Equal(t, 0, s.pick())
+
+	// ...until repeated failures bench the latter
+	s.record(0, 0, errors.New("timeout"))
+	s.record(0, 0, errors.New("timeout"))
+	assert.Equal(t, 1, s.pick())
+
+	// Benched endpoints are still tried when nothing else is available
+	for i := 0; i < endpointFailureThreshold; i++ {
+		s.record(1, 0, errors.New("timeout"))
+	}
+	assert.Equal(t, 0, s.pick(), "the endpoint benched first recovers first")
+
+	now = now.Add(time.Minute)
+	assert.Equal(t, 0, s.pick())
+	assert.Equal(t, 1.0, s.endpoints[0].success, "stats reset after the cooldown")
+
+	// Missing data isn't the endpoint's fault
+	assert.False(t, isEndpointFailure(nil))
+	assert.False(t, isEndpointFailure(ethereum.NotFound))
+	assert.False(t, isEndpointFailure(context.Canceled))
+	assert.True(t, isEndpointFailure(errors.New("connection refused")))
+}
+
+func TestMultiEVMClient_HealthCheckPromotesPrimary(t *testing.T) {
+	ctx := context.Background()
+	primary := &flakyEVMClient{fakeEVMClient: newFakeEVMClient(1000)}
+	backup := &flakyEVMClient{fakeEVMClient: newFakeEVMClient(1000)}
+	primary.down.Store(true)
+
+	m := newMultiEVMClient("Test", []string{"https://primary.example/v3/key", "backup:8545"}, []evmRPC{primary, backup}, time.Hour)
+	for i := 0; i < endpointFailureThreshold; i++ {
+		m.selector.record(0, 0, errors.New("503 Service Unavailable"))
+	}
+	_, _ = m.BlockNumber(ctx)
+	endpoint, ok := activeEndpoint(m, config.ChainConfig{})
+	require.True(t, ok)
+	assert.Equal(t, "backup:8545", redactEndpoint(endpoint))
+
+	// Still down: stays benched
+	m.checkEndpoints(ctx)
+	assert.Equal(t, []int{0}, m.selector.benched())
+
+	// Back up: promoted without waiting out the hour-long cooldown
+	primary.down.Store(false)
+	m.checkEndpoints(ctx)
+	assert.Empty(t, m.selector.benched())
+	calls := primary.calls.Load()
+	_, err := m.BlockNumber(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, calls+1, primary.calls.Load())
+	endpoint, _ = activeEndpoint(m, config.ChainConfig{})
+	assert.Equal(t, "primary.example", redactEndpoint(endpoint), "API key path is not exposed")
+}
+
+// laggingEVMClient reports the chain's head but only serves blocks up to
+// synced, as a node behind a load balancer might.
+type laggingEVMClient struct {
+	*fakeEVMClient
+	synced uint64
+}
+
+func (l *laggingEVMClient) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	if number.Uint64() > l.synced {
+		return nil, ethereum.NotFound
+	}
+	return l.fakeEVMClient.HeaderByNumber(ctx, number)
+}
+
+func TestMultiEVMClient_FailsOverFromLaggingEndpoint(t *testing.T) {
+	ctx := context.Background()
+	lagging := &laggingEVMClient{fakeEVMClient: newFakeEVMClient(1000), synced: 995}
+	synced := &laggingEVMClient{fakeEVMClient: newFakeEVMClient(1000), synced: 1000}
+	m := newMultiEVMClient("Test", []string{"lagging", "synced"}, []evmRPC{lagging, synced}, time.Minute)
+
+	head, err := m.BlockNumber(ctx)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1000), head)
+
+	// The block was reported, so not found means the endpoint lags
+	header, err := m.HeaderByNumber(ctx, big.NewInt(1000))
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1000), header.Number.Uint64())
+	assert.Equal(t, "synced", m.selector.activeURL())
+
+	// A block no endpoint reported yet is not found without failover
+	_, err = m.HeaderByNumber(ctx, big.NewInt(1001))
+	assert.ErrorIs(t, err, ethereum.NotFound)
+
+	// Calls for a block go to an endpoint that reported it
+	s := newEndpointSelector("Test", []string{"primary", "backup"}, time.Minute)
+	s.observeHead(0, 990)
+	s.observeHead(1, 1000)
+	assert.Equal(t, 0, s.pickAt(990, nil))
+	assert.Equal(t, 1, s.pickAt(995, nil))
+	assert.Equal(t, 0, s.pickAt(1005, nil), "none has it: the best endpoint")
+	assert.Equal(t, -1, s.pickAt(995, map[int]bool{0: true, 1: true}))
+}
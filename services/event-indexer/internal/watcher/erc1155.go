@@ -0,0 +1,177 @@
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/rs/zerolog/log"
+)
+
+// ERC-1155 event types. A TransferBatch log is emitted as one
+// erc1155_batch event per id/value pair.
+const (
+	EventTypeERC1155Single = "erc1155_single"
+	EventTypeERC1155Batch  = "erc1155_batch"
+)
+
+var (
+	erc1155SingleTopic = crypto.Keccak256Hash([]byte("TransferSingle(address,address,address,uint256,uint256)"))
+	erc1155BatchTopic  = crypto.Keccak256Hash([]byte("TransferBatch(address,address,address,uint256[],uint256[])"))
+	erc1155Topics      = []common.Hash{erc1155SingleTopic, erc1155BatchTopic}
+)
+
+// Non-indexed parameters of TransferSingle (id, value) and TransferBatch
+// (ids, values).
+var erc1155SingleData, erc1155BatchData = func() (abi.Arguments, abi.Arguments) {
+	uint256, _ := abi.NewType("uint256", "", nil)
+	uint256s, _ := abi.NewType("uint256[]", "", nil)
+	return abi.Arguments{{Type: uint256}, {Type: uint256}}, abi.Arguments{{Type: uint256s}, {Type: uint256s}}
+}()
+
+// erc1155Transfer is one id/value pair of a decoded ERC-1155 transfer log.
+type erc1155Transfer struct {
+	Operator common.Address // who moved the tokens, may differ from From
+	From     common.Address
+	To       common.Address
+	ID       *big.Int
+	Value    *big.Int
+}
+
+// isERC1155Topic reports whether topic0 is TransferSingle or TransferBatch.
+func isERC1155Topic(topic common.Hash) bool {
+	return topic == erc1155SingleTopic || topic == erc1155BatchTopic
+}
+
+// parseERC1155Transfers decodes a TransferSingle or TransferBatch log into
+// its id/value pairs. operator, from and to are indexed; the ids and values
+// are ABI-encoded in data.
+func parseERC1155Transfers(topics []common.Hash, data []byte) ([]erc1155Transfer, error) {
+	if len(topics) != 4 || !isERC1155Topic(topics[0]) {
+		return nil, fmt.Errorf("erc1155 transfer: expected 4 topics with a TransferSingle/TransferBatch signature, got %d", len(topics))
+	}
+	var addrs [3]common.Address
+	for i := range addrs {
+		raw, ok := addressTopic(topics[i+1].Bytes())
+		if !ok {
+			return nil, fmt.Errorf("erc1155 transfer: malformed address topic %s", topics[i+1].Hex())
+		}
+		addrs[i] = common.BytesToAddress(raw)
+	}
+
+	var ids, values []*big.Int
+	if topics[0] == erc1155SingleTopic {
+		unpacked, err := erc1155SingleData.Unpack(data)
+		if err != nil {
+			return nil, fmt.Errorf("erc1155 transfer: %w", err)
+		}
+		ids, values = []*big.Int{unpacked[0].(*big.Int)}, []*big.Int{unpacked[1].(*big.Int)}
+	} else {
+		unpacked, err := erc1155BatchData.Unpack(data)
+		if err != nil {
+			return nil, fmt.Errorf("erc1155 transfer: %w", err)
+		}
+		ids, values = unpacked[0].([]*big.Int), unpacked[1].([]*big.Int)
+		if len(ids) != len(values) {
+			return nil, fmt.Errorf("erc1155 transfer: %d ids but %d values", len(ids), len(values))
+		}
+	}
+
+	transfers := make([]erc1155Transfer, len(ids))
+	for i := range ids {
+		transfers[i] = erc1155Transfer{Operator: addrs[0], From: addrs[1], To: addrs[2], ID: ids[i], Value: values[i]}
+	}
+	return transfers, nil
+}
+
+// processERC1155Log emits a TransferSingle/TransferBatch log touching a
+// watched address (or a scoped token) and reports whether it did.
+func (w *ChainWatcher) processERC1155Log(ctx context.Context, vLog types.Log, addresses []common.Address, currentBlock uint64, timestamp time.Time, fees map[common.Hash]*txFee) bool {
+	transfers, err := parseERC1155Transfers(vLog.Topics, vLog.Data)
+	if err != nil {
+		log.Debug().Err(err).Str("chain", w.chainName).Str("tx", vLog.TxHash.Hex()).Msg("Skipping malformed ERC-1155 log")
+		return false
+	}
+	if len(transfers) == 0 {
+		return false
+	}
+
+	// from/to are the same for every pair of the log
+	watched := watchedSide(transfers[0].From, transfers[0].To, addresses)
+	isRelevant := watched != ""
+	if !isRelevant && (!w.scopedTokens[vLog.Address] || w.cfg.DropUnwatchedTransfers) {
+		return false
+	}
+	zeroToZero := transfers[0].From == common.Address(zeroAddress) && transfers[0].To == common.Address(zeroAddress)
+	if zeroToZero && dropZeroToZero(w.chainName, vLog.TxHash.Hex(), w.cfg.EmitZeroAddressTransfers) {
+		return false
+	}
+
+	eventType := EventTypeERC1155Single
+	if vLog.Topics[0] == erc1155BatchTopic {
+		eventType = EventTypeERC1155Batch
+	}
+	confirmations := confirmationsAt(currentBlock, vLog.BlockNumber)
+
+	var fee *txFee
+	if w.cfg.IncludeFeeInfo {
+		fee = w.evmTxFee(ctx, vLog.TxHash, fees)
+	}
+
+	for i, transfer := range transfers {
+		event := &ChainEvent{
+			ChainID:      w.chainID,
+			ChainName:    w.chainName,
+			EventType:    eventType,
+			TxHash:       vLog.TxHash.Hex(),
+			BlockNumber:  vLog.BlockNumber,
+			FromAddress:  transfer.From.Hex(),
+			ToAddress:    transfer.To.Hex(),
+			Value:        transfer.Value.String(),
+			TokenAddress: vLog.Address.Hex(),
+			TokenID:      transfer.ID.String(),
+			Operator:     transfer.Operator.Hex(),
+			Timestamp:    timestamp,
+			Confirmed:    confirmations >= w.confirmations.required(),
+
+			Confirmations: confirmations,
+
+			TouchesWatched: isRelevant,
+			WatchedAddress: watched,
+			EventID:        eventID(w.chainID, vLog.TxHash.Hex(), vLog.Index),
+			LogIndex:       vLog.Index,
+
+			ZeroAddressTransfer: zeroToZero,
+		}
+		if eventType == EventTypeERC1155Batch {
+			// Pairs of one log share its index
+			event.EventID = fmt.Sprintf("%s:%d", event.EventID, i)
+		}
+		event.FinalityStatus = w.milestones.finality(event)
+		if fee != nil {
+			event.FeePaid = fee.paid
+			event.FeePayer = fee.payer
+		}
+
+		log.Info().
+			Str("chain", w.chainName).
+			Str("tx", event.TxHash).
+			Str("type", eventType).
+			Str("from", event.FromAddress).
+			Str("to", event.ToAddress).
+			Str("id", event.TokenID).
+			Str("value", event.Value).
+			Bool("confirmed", event.Confirmed).
+			Msg("ERC-1155 transfer detected")
+
+		if w.emit(event) {
+			w.milestones.track(event)
+		}
+	}
+	return true
+}
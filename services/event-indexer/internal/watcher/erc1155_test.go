@@ -0,0 +1,119 @@
+package watcher
+
+import (
+	"math/big"
+	"strconv"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testERC1155Log builds a TransferSingle (one id) or TransferBatch log
+// emitted by a fixed multi-token contract.
+func testERC1155Log(t *testing.T, block uint64, index uint, operator, from, to common.Address, ids, values []*big.Int) types.Log {
+	t.Helper()
+	sig := erc1155BatchTopic
+	var data []byte
+	var err error
+	if len(ids) == 1 && len(values) == 1 {
+		sig = erc1155SingleTopic
+		data, err = erc1155SingleData.Pack(ids[0], values[0])
+	} else {
+		data, err = erc1155BatchData.Pack(ids, values)
+	}
+	require.NoError(t, err)
+	return types.Log{
+		Address:     common.HexToAddress("0x76BE3b62873462d2142405439777e971754E8E77"),
+		Topics:      []common.Hash{sig, common.BytesToHash(operator.Bytes()), common.BytesToHash(from.Bytes()), common.BytesToHash(to.Bytes())},
+		Data:        data,
+		BlockNumber: block,
+		TxHash:      common.BigToHash(new(big.Int).SetUint64(block*1000 + uint64(index))),
+		Index:       index,
+	}
+}
+
+func TestParseERC1155Transfers(t *testing.T) {
+	operator := common.HexToAddress("0x3333333333333333333333333333333333333333")
+	from := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	to := common.HexToAddress("0x2222222222222222222222222222222222222222")
+
+	single := testERC1155Log(t, 1, 0, operator, from, to, []*big.Int{big.NewInt(7)}, []*big.Int{big.NewInt(100)})
+	transfers, err := parseERC1155Transfers(single.Topics, single.Data)
+	require.NoError(t, err)
+	require.Len(t, transfers, 1)
+	assert.Equal(t, erc1155Transfer{Operator: operator, From: from, To: to, ID: big.NewInt(7), Value: big.NewInt(100)}, transfers[0])
+
+	batch := testERC1155Log(t, 1, 1, operator, from, to, []*big.Int{big.NewInt(1), big.NewInt(2), big.NewInt(3)}, []*big.Int{big.NewInt(10), big.NewInt(20), big.NewInt(30)})
+	transfers, err = parseERC1155Transfers(batch.Topics, batch.Data)
+	require.NoError(t, err)
+	require.Len(t, transfers, 3)
+	for i, transfer := range transfers {
+		assert.Equal(t, operator, transfer.Operator)
+		assert.Equal(t, int64(i+1), transfer.ID.Int64())
+		assert.Equal(t, int64(10*(i+1)), transfer.Value.Int64())
+	}
+
+	mismatched, err := erc1155BatchData.Pack([]*big.Int{big.NewInt(1), big.NewInt(2)}, []*big.Int{big.NewInt(10)})
+	require.NoError(t, err)
+	for name, tt := range map[string]struct {
+		topics []common.Hash
+		data   []byte
+	}{
+		"no topics":              {nil, nil},
+		"missing operator":       {single.Topics[:3], single.Data},
+		"not an 1155 signature":  {append([]common.Hash{common.HexToHash(trc20TransferSig)}, single.Topics[1:]...), single.Data},
+		"truncated data":         {single.Topics, single.Data[:32]},
+		"batch length mismatch":  {batch.Topics, mismatched},
+		"operator isn't address": {[]common.Hash{erc1155SingleTopic, common.HexToHash("0xff00000000000000000000003333333333333333333333333333333333333333"), single.Topics[2], single.Topics[3]}, single.Data},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.NotPanics(t, func() {
+				_, err := parseERC1155Transfers(tt.topics, tt.data)
+				assert.Error(t, err)
+			})
+		})
+	}
+}
+
+func TestChainWatcher_ERC1155Transfers(t *testing.T) {
+	client := newFakeEVMClient(1000)
+	watched := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	operator := common.HexToAddress("0x3333333333333333333333333333333333333333")
+	other := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	client.addLog(testERC1155Log(t, 1000, 0, operator, other, watched, []*big.Int{big.NewInt(7)}, []*big.Int{big.NewInt(100)}))
+	client.addLog(testERC1155Log(t, 1000, 1, operator, watched, other, []*big.Int{big.NewInt(1), big.NewInt(2)}, []*big.Int{big.NewInt(10), big.NewInt(20)}))
+	client.addLog(testERC1155Log(t, 1000, 2, operator, other, other, []*big.Int{big.NewInt(9)}, []*big.Int{big.NewInt(1)}))
+
+	w := newTestChainWatcher(t, client)
+	w.AddAddress(watched)
+
+	events := collectEVMEvents(t, w, 1000, 1000)
+	require.Len(t, events, 3, "one single event and one per batch pair; unwatched logs are dropped")
+
+	byID := map[string]*ChainEvent{}
+	for _, e := range events {
+		byID[e.EventID] = e
+	}
+	single := byID[eventID(1, common.BigToHash(big.NewInt(1000000)).Hex(), 0)]
+	require.NotNil(t, single)
+	assert.Equal(t, EventTypeERC1155Single, single.EventType)
+	assert.Equal(t, "7", single.TokenID)
+	assert.Equal(t, "100", single.Value)
+	assert.Equal(t, operator.Hex(), single.Operator)
+	assert.Equal(t, other.Hex(), single.FromAddress)
+	assert.Equal(t, watched.Hex(), single.WatchedAddress)
+
+	batchID := eventID(1, common.BigToHash(big.NewInt(1000001)).Hex(), 1)
+	for i, want := range []struct{ id, value string }{{"1", "10"}, {"2", "20"}} {
+		pair := byID[batchID+":"+strconv.Itoa(i)]
+		require.NotNil(t, pair, "batch pair %d", i)
+		assert.Equal(t, EventTypeERC1155Batch, pair.EventType)
+		assert.Equal(t, want.id, pair.TokenID)
+		assert.Equal(t, want.value, pair.Value)
+		assert.Equal(t, operator.Hex(), pair.Operator)
+		assert.Equal(t, watched.Hex(), pair.WatchedAddress)
+	}
+}
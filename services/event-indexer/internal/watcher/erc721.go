@@ -0,0 +1,43 @@
+package watcher
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// EventTypeERC721Transfer is the event type of NFT (ERC-721) transfers.
+const EventTypeERC721Transfer = "erc721_transfer"
+
+// erc721Transfer is a decoded ERC-721 Transfer(address,address,uint256) log.
+type erc721Transfer struct {
+	From    common.Address
+	To      common.Address
+	TokenID *big.Int
+}
+
+// parseERC721Transfer decodes an ERC-721 Transfer log. It shares the ERC-20
+// signature, but all three parameters are indexed: the token ID is the fourth
+// topic and data is empty.
+func parseERC721Transfer(topics []common.Hash, data []byte) (*erc721Transfer, error) {
+	if len(topics) != 4 {
+		return nil, fmt.Errorf("erc721 transfer: expected 4 topics, got %d", len(topics))
+	}
+	if len(data) != 0 {
+		return nil, fmt.Errorf("erc721 transfer: unexpected %d bytes of data", len(data))
+	}
+	from, ok := addressTopic(topics[1].Bytes())
+	if !ok {
+		return nil, fmt.Errorf("erc721 transfer: malformed from topic %s", topics[1].Hex())
+	}
+	to, ok := addressTopic(topics[2].Bytes())
+	if !ok {
+		return nil, fmt.Errorf("erc721 transfer: malformed to topic %s", topics[2].Hex())
+	}
+	return &erc721Transfer{
+		From:    common.BytesToAddress(from),
+		To:      common.BytesToAddress(to),
+		TokenID: new(big.Int).SetBytes(topics[3].Bytes()),
+	}, nil
+}
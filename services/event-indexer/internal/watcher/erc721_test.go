@@ -0,0 +1,84 @@
+package watcher
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testERC721Log builds an ERC-721 Transfer log emitted by a fixed NFT contract.
+func testERC721Log(block uint64, index uint, from, to common.Address, tokenID *big.Int) types.Log {
+	return types.Log{
+		Address:     common.HexToAddress("0xBC4CA0EdA7647A8aB7C2061c2E118A18a936f13D"),
+		Topics:      []common.Hash{common.HexToHash(trc20TransferSig), common.BytesToHash(from.Bytes()), common.BytesToHash(to.Bytes()), common.BigToHash(tokenID)},
+		BlockNumber: block,
+		TxHash:      common.BigToHash(new(big.Int).SetUint64(block*1000 + uint64(index))),
+		Index:       index,
+	}
+}
+
+func TestParseERC721Transfer(t *testing.T) {
+	from := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	to := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	sig := common.HexToHash(trc20TransferSig)
+	valid := []common.Hash{sig, common.BytesToHash(from.Bytes()), common.BytesToHash(to.Bytes()), common.BigToHash(big.NewInt(7421))}
+
+	transfer, err := parseERC721Transfer(valid, nil)
+	require.NoError(t, err)
+	assert.Equal(t, from, transfer.From)
+	assert.Equal(t, to, transfer.To)
+	assert.Equal(t, "7421", transfer.TokenID.String())
+
+	for name, tt := range map[string]struct {
+		topics []common.Hash
+		data   []byte
+	}{
+		"no topics":             {nil, nil},
+		"ERC-20 layout":         {valid[:3], common.LeftPadBytes([]byte{1}, 32)},
+		"extra topic":           {append(append([]common.Hash{}, valid...), sig), nil},
+		"data present":          {valid, common.LeftPadBytes([]byte{1}, 32)},
+		"from isn't an address": {[]common.Hash{sig, common.HexToHash("0xff00000000000000000000001111111111111111111111111111111111111111"), valid[2], valid[3]}, nil},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.NotPanics(t, func() {
+				_, err := parseERC721Transfer(tt.topics, tt.data)
+				assert.Error(t, err)
+			})
+		})
+	}
+}
+
+func TestChainWatcher_ERC721Transfer(t *testing.T) {
+	client := newFakeEVMClient(1000)
+	watched := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	other := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	client.addLog(testERC721Log(1000, 0, other, watched, big.NewInt(7421)))
+	client.addLog(testTransferLog(1000, 1, other, watched, big.NewInt(5)))
+
+	w := newTestChainWatcher(t, client)
+	w.AddAddress(watched)
+
+	events := collectEVMEvents(t, w, 1000, 1000)
+	require.Len(t, events, 2)
+
+	byType := map[string]*ChainEvent{}
+	for _, e := range events {
+		byType[e.EventType] = e
+	}
+	nft := byType[EventTypeERC721Transfer]
+	require.NotNil(t, nft)
+	assert.Equal(t, "7421", nft.TokenID)
+	assert.Empty(t, nft.Value)
+	assert.Equal(t, other.Hex(), nft.FromAddress)
+	assert.Equal(t, watched.Hex(), nft.ToAddress)
+	assert.Equal(t, watched.Hex(), nft.WatchedAddress)
+
+	fungible := byType["transfer"]
+	require.NotNil(t, fungible)
+	assert.Equal(t, "5", fungible.Value)
+	assert.Empty(t, fungible.TokenID)
+}
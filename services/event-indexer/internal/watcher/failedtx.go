@@ -0,0 +1,100 @@
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/rs/zerolog/log"
+)
+
+// EventTypeFailedTx is emitted for a reverted transaction sent by a watched
+// address. Nothing was transferred, but the sender was still charged gas,
+// which hot-wallet accounting has to record.
+const EventTypeFailedTx = "failed_tx"
+
+// processFailedTxs scans a block's transactions for ones sent by a watched
+// address and emits a failed_tx event for each that reverted. Reverted
+// transactions leave no logs, so they never surface through FilterLogs.
+func (w *ChainWatcher) processFailedTxs(ctx context.Context, blockNumber, currentBlock uint64, addresses []common.Address, timestamp time.Time) int {
+	if len(addresses) == 0 {
+		return 0
+	}
+	watched := make(map[common.Address]bool, len(addresses))
+	for _, addr := range addresses {
+		watched[addr] = true
+	}
+
+	block, err := w.client.BlockByNumber(ctx, new(big.Int).SetUint64(blockNumber))
+	if err != nil {
+		log.Error().Err(err).Uint64("block", blockNumber).Str("chain", w.chainName).Msg("Failed to get block for failed tx scan")
+		return 0
+	}
+
+	emitted := 0
+
+	for i, tx := range block.Transactions() {
+		// ethclient caches senders from the block response, so this is normally free
+		sender, err := w.client.TransactionSender(ctx, tx, block.Hash(), uint(i))
+		if err != nil {
+			log.Warn().Err(err).Str("tx", tx.Hash().Hex()).Str("chain", w.chainName).Msg("Failed to resolve tx sender")
+			continue
+		}
+		if !watched[sender] {
+			continue
+		}
+
+		receipt, err := w.client.TransactionReceipt(ctx, tx.Hash())
+		if err != nil {
+			log.Warn().Err(err).Str("tx", tx.Hash().Hex()).Str("chain", w.chainName).Msg("Failed to fetch receipt for failed tx scan")
+			continue
+		}
+		if receipt.Status != types.ReceiptStatusFailed {
+			continue
+		}
+
+		w.emitFailedTx(tx, receipt, sender, blockNumber, currentBlock, timestamp)
+		emitted++
+	}
+	return emitted
+}
+
+func (w *ChainWatcher) emitFailedTx(tx *types.Transaction, receipt *types.Receipt, sender common.Address, blockNumber, currentBlock uint64, timestamp time.Time) {
+	to := ""
+	if tx.To() != nil {
+		to = tx.To().Hex()
+	}
+
+	confirmations := confirmationsAt(currentBlock, blockNumber)
+	event := &ChainEvent{
+		ChainID:       w.chainID,
+		ChainName:     w.chainName,
+		EventType:     EventTypeFailedTx,
+		TxHash:        tx.Hash().Hex(),
+		BlockNumber:   blockNumber,
+		FromAddress:   sender.Hex(),
+		ToAddress:     to,
+		Value:         "0",
+		Timestamp:     timestamp,
+		Confirmed:     confirmations >= w.confirmations.required(),
+		Confirmations: confirmations,
+		FeePaid:       receiptFee(receipt, tx),
+		FeePayer:      sender.Hex(),
+
+		TouchesWatched: true,
+		WatchedAddress: sender.Hex(),
+		EventID:        fmt.Sprintf("%d:%s:failed", w.chainID, tx.Hash().Hex()),
+	}
+
+	log.Info().
+		Str("chain", w.chainName).
+		Str("tx", event.TxHash).
+		Str("from", event.FromAddress).
+		Str("fee", event.FeePaid.String()).
+		Msg("Failed transaction from watched address")
+
+	w.emit(event)
+}
@@ -0,0 +1,56 @@
+package watcher
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChainWatcher_FailedTx(t *testing.T) {
+	client := newFakeEVMClient(1000)
+	watched := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	other := common.HexToAddress("0x3333333333333333333333333333333333333333")
+	contract := common.HexToAddress("0x4444444444444444444444444444444444444444")
+
+	addTx := func(nonce uint64, sender common.Address, status uint64) *types.Transaction {
+		tx := types.NewTx(&types.LegacyTx{Nonce: nonce, GasPrice: big.NewInt(50), Gas: 100000, To: &contract})
+		client.addTx(tx.Hash(), tx, &types.Receipt{
+			Status:            status,
+			GasUsed:           21000,
+			EffectiveGasPrice: big.NewInt(20_000_000_000),
+			BlockNumber:       big.NewInt(990),
+		}, sender)
+		return tx
+	}
+	reverted := addTx(1, watched, types.ReceiptStatusFailed)
+	addTx(2, watched, types.ReceiptStatusSuccessful)
+	addTx(3, other, types.ReceiptStatusFailed)
+
+	t.Run("disabled by default", func(t *testing.T) {
+		w := newTestChainWatcher(t, client)
+		w.AddAddress(watched)
+		assert.Empty(t, collectEVMEvents(t, w, 990, 1000))
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		w := newTestChainWatcher(t, client)
+		w.cfg.IndexFailedTxs = true
+		w.AddAddress(watched)
+		events := collectEVMEvents(t, w, 990, 1000)
+
+		require.Len(t, events, 1, "only the reverted tx from the watched sender")
+		event := events[0]
+		assert.Equal(t, EventTypeFailedTx, event.EventType)
+		assert.Equal(t, reverted.Hash().Hex(), event.TxHash)
+		assert.Equal(t, watched.Hex(), event.FromAddress)
+		assert.Equal(t, contract.Hex(), event.ToAddress)
+		assert.Equal(t, "0", event.Value)
+		assert.Equal(t, "420000000000000", event.FeePaid.String())
+		assert.Equal(t, watched.Hex(), event.FeePayer)
+		assert.Equal(t, uint64(990), event.BlockNumber)
+	})
+}
@@ -0,0 +1,108 @@
+package watcher
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/fbsobreira/gotron-sdk/pkg/proto/core"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// txFee is the fee paid by a transaction and the account that paid it.
+type txFee struct {
+	paid  *big.Int
+	payer string
+}
+
+// evmTxFee fetches the receipt and sender of an EVM transaction and returns
+// gasUsed * effectiveGasPrice (wei). Results are memoized in fees so a tx
+// emitting several watched transfers is only fetched once per block.
+func (w *ChainWatcher) evmTxFee(ctx context.Context, txHash common.Hash, fees map[common.Hash]*txFee) *txFee {
+	if fee, ok := fees[txHash]; ok {
+		return fee
+	}
+
+	fee := w.fetchEVMTxFee(ctx, txHash)
+	fees[txHash] = fee
+	return fee
+}
+
+func (w *ChainWatcher) fetchEVMTxFee(ctx context.Context, txHash common.Hash) *txFee {
+	receipt, err := w.client.TransactionReceipt(ctx, txHash)
+	if err != nil {
+		log.Warn().Err(err).Str("tx", txHash.Hex()).Str("chain", w.chainName).Msg("Failed to fetch receipt for fee info")
+		return nil
+	}
+
+	tx, _, err := w.client.TransactionByHash(ctx, txHash)
+	if err != nil {
+		log.Warn().Err(err).Str("tx", txHash.Hex()).Str("chain", w.chainName).Msg("Failed to fetch transaction for fee info")
+		return nil
+	}
+
+	fee := &txFee{paid: receiptFee(receipt, tx)}
+
+	sender, err := w.client.TransactionSender(ctx, tx, receipt.BlockHash, receipt.TransactionIndex)
+	if err != nil {
+		log.Warn().Err(err).Str("tx", txHash.Hex()).Str("chain", w.chainName).Msg("Failed to resolve fee payer")
+	} else {
+		fee.payer = sender.Hex()
+	}
+	return fee
+}
+
+// receiptFee returns gasUsed * effectiveGasPrice (wei) for a mined transaction.
+func receiptFee(receipt *types.Receipt, tx *types.Transaction) *big.Int {
+	// Pre-London nodes omit effectiveGasPrice; the legacy gas price is what was paid
+	price := receipt.EffectiveGasPrice
+	if price == nil {
+		price = tx.GasPrice()
+	}
+	return new(big.Int).Mul(new(big.Int).SetUint64(receipt.GasUsed), price)
+}
+
+// tronTxFee returns the fee (SUN) charged to a TRON transaction and the owner
+// of its contract, who is the account the fee is burned from.
+func tronTxFee(tx *core.Transaction, info *core.TransactionInfo, prefix byte) *txFee {
+	return &txFee{
+		paid:  big.NewInt(info.GetFee()),
+		payer: tronTxOwner(tx, prefix),
+	}
+}
+
+// tronTxOwner returns the Base58 owner address of a transaction's contract.
+// Every TRON contract type declares owner_address as field 1, so it is read
+// straight off the wire instead of unmarshalling each contract type.
+func tronTxOwner(tx *core.Transaction, prefix byte) string {
+	contracts := tx.GetRawData().GetContract()
+	if len(contracts) == 0 {
+		return ""
+	}
+
+	b := contracts[0].GetParameter().GetValue()
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return ""
+		}
+		b = b[n:]
+
+		if num == 1 && typ == protowire.BytesType {
+			owner, n := protowire.ConsumeBytes(b)
+			if n < 0 {
+				return ""
+			}
+			return hexBytesToTronAddress(owner, prefix)
+		}
+
+		n = protowire.ConsumeFieldValue(num, typ, b)
+		if n < 0 {
+			return ""
+		}
+		b = b[n:]
+	}
+	return ""
+}
@@ -0,0 +1,153 @@
+package watcher
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/fbsobreira/gotron-sdk/pkg/proto/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+func TestChainWatcher_FeeInfo(t *testing.T) {
+	client := newFakeEVMClient(1000)
+	payer := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	watched := common.HexToAddress("0x2222222222222222222222222222222222222222")
+
+	// Two watched transfers in the same transaction
+	first := testTransferLog(990, 0, payer, watched, big.NewInt(7))
+	second := testTransferLog(990, 1, payer, watched, big.NewInt(8))
+	second.TxHash = first.TxHash
+	client.addLog(first)
+	client.addLog(second)
+
+	tx := types.NewTx(&types.LegacyTx{Nonce: 1, GasPrice: big.NewInt(50), Gas: 100000})
+	client.addTx(first.TxHash, tx, &types.Receipt{
+		GasUsed:           52000,
+		EffectiveGasPrice: big.NewInt(30_000_000_000),
+	}, payer)
+
+	t.Run("disabled by default", func(t *testing.T) {
+		w := newTestChainWatcher(t, client)
+		w.AddAddress(watched)
+		events := collectEVMEvents(t, w, 990, 1000)
+
+		require.Len(t, events, 2)
+		assert.Nil(t, events[0].FeePaid)
+		assert.Empty(t, events[0].FeePayer)
+		assert.Zero(t, client.receiptCalls)
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		w := newTestChainWatcher(t, client)
+		w.cfg.IncludeFeeInfo = true
+		w.AddAddress(watched)
+		events := collectEVMEvents(t, w, 990, 1000)
+
+		require.Len(t, events, 2)
+		for _, event := range events {
+			assert.Equal(t, "1560000000000000", event.FeePaid.String())
+			assert.Equal(t, payer.Hex(), event.FeePayer)
+		}
+		assert.Equal(t, 1, client.receiptCalls, "receipt fetched once per tx")
+	})
+
+	t.Run("falls back to legacy gas price", func(t *testing.T) {
+		legacy := newFakeEVMClient(1000)
+		legacy.addLog(first)
+		legacy.addTx(first.TxHash, tx, &types.Receipt{GasUsed: 21000}, payer)
+
+		w := newTestChainWatcher(t, legacy)
+		w.cfg.IncludeFeeInfo = true
+		w.AddAddress(watched)
+		events := collectEVMEvents(t, w, 990, 1000)
+
+		require.Len(t, events, 1)
+		assert.Equal(t, "1050000", events[0].FeePaid.String())
+	})
+
+	t.Run("missing receipt leaves fee unset", func(t *testing.T) {
+		empty := newFakeEVMClient(1000)
+		empty.addLog(first)
+
+		w := newTestChainWatcher(t, empty)
+		w.cfg.IncludeFeeInfo = true
+		w.AddAddress(watched)
+		events := collectEVMEvents(t, w, 990, 1000)
+
+		require.Len(t, events, 1)
+		assert.Nil(t, events[0].FeePaid)
+	})
+}
+
+func TestTronWatcher_FeeInfo(t *testing.T) {
+	client := newFakeTronClient(200)
+	token, _ := testTronAddress(0xaa)
+	from, fromAddr := testTronAddress(0x11)
+	to, toAddr := testTronAddress(0x22)
+	client.addTransfer(180, "a180", token, from, to, big.NewInt(1))
+
+	param, err := anypb.New(&core.TriggerSmartContract{
+		OwnerAddress:    append([]byte{0x41}, from...),
+		ContractAddress: append([]byte{0x41}, token...),
+	})
+	require.NoError(t, err)
+	client.blocks[180].Transactions[0].Transaction.RawData.Contract = []*core.Transaction_Contract{{
+		Type:      core.Transaction_Contract_TriggerSmartContract,
+		Parameter: param,
+	}}
+	client.txInfos["a180"].Fee = 345_000
+
+	process := func(w *TronWatcher) *ChainEvent {
+		var got *ChainEvent
+		w.dispatch.addHandler(func(event *ChainEvent) error {
+			got = event
+			return nil
+		})
+		w.gate.enter()
+		w.processBlock(context.Background(), 180, 200)
+		w.gate.leave()
+		w.gate.drain()
+		require.NotNil(t, got)
+		return got
+	}
+
+	t.Run("disabled by default", func(t *testing.T) {
+		w := newTestTronWatcher(client)
+		w.AddTronAddress(toAddr)
+		got := process(w)
+		assert.Nil(t, got.FeePaid)
+		assert.Empty(t, got.FeePayer)
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		w := newTestTronWatcher(client)
+		w.cfg.IncludeFeeInfo = true
+		w.AddTronAddress(toAddr)
+		got := process(w)
+		assert.Equal(t, big.NewInt(345_000), got.FeePaid)
+		assert.Equal(t, fromAddr, got.FeePayer)
+	})
+}
+
+func TestTronTxOwner(t *testing.T) {
+	owner, ownerAddr := testTronAddress(0x33)
+
+	param, err := anypb.New(&core.TransferContract{
+		OwnerAddress: append([]byte{0x41}, owner...),
+		ToAddress:    append([]byte{0x41}, owner...),
+		Amount:       10,
+	})
+	require.NoError(t, err)
+
+	tx := &core.Transaction{RawData: &core.TransactionRaw{
+		Contract: []*core.Transaction_Contract{{Type: core.Transaction_Contract_TransferContract, Parameter: param}},
+	}}
+	assert.Equal(t, ownerAddr, tronTxOwner(tx, tronMainnetPrefix))
+	assert.Empty(t, tronTxOwner(&core.Transaction{RawData: &core.TransactionRaw{}}, tronMainnetPrefix))
+	assert.Empty(t, tronTxOwner(nil, tronMainnetPrefix))
+}
@@ -0,0 +1,64 @@
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/protocol-bank/event-indexer/internal/config"
+	"github.com/rs/zerolog/log"
+)
+
+// needsSolidified reports whether the confirmation mode consults the
+// solidified block.
+func (w *TronWatcher) needsSolidified() bool {
+	mode := w.cfg.ConfirmationMode
+	return mode == config.ConfirmationModeSolidified || mode == config.ConfirmationModeBoth
+}
+
+// refreshSolidified updates the solidified block height from the node. On
+// failure the previous value is kept; it can only be lower than the real
+// one, so confirmation stays conservative.
+func (w *TronWatcher) refreshSolidified(ctx context.Context) {
+	if !w.needsSolidified() {
+		return
+	}
+
+	info, err := tronCall(ctx, w, "GetNodeInfo", w.client.GetNodeInfo)
+	if err != nil {
+		log.Warn().Err(err).Str("chain", w.chainName).Msg("Failed to get solidified block")
+		return
+	}
+	num, err := parseSolidityBlock(info.GetSolidityBlock())
+	if err != nil {
+		log.Warn().Err(err).Str("chain", w.chainName).Msg("Failed to parse solidified block")
+		return
+	}
+	w.solidBlock.Store(num)
+}
+
+// isFinal combines the block-count verdict with the solidified block
+// according to the configured confirmation mode.
+func (w *TronWatcher) isFinal(countConfirmed bool, blockNum int64) bool {
+	solidified := blockNum <= w.solidBlock.Load()
+	switch w.cfg.ConfirmationMode {
+	case config.ConfirmationModeSolidified:
+		return solidified
+	case config.ConfirmationModeBoth:
+		return countConfirmed && solidified
+	default:
+		return countConfirmed
+	}
+}
+
+// parseSolidityBlock extracts the height from NodeInfo.SolidityBlock, which
+// full nodes report as "Num:<height>,ID:<block id>".
+func parseSolidityBlock(s string) (int64, error) {
+	for _, part := range strings.Split(s, ",") {
+		if num, ok := strings.CutPrefix(strings.TrimSpace(part), "Num:"); ok {
+			return strconv.ParseInt(num, 10, 64)
+		}
+	}
+	return 0, fmt.Errorf("no block number in %q", s)
+}
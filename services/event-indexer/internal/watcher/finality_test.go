@@ -0,0 +1,67 @@
+package watcher
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/protocol-bank/event-indexer/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTronWatcher_ConfirmationMode(t *testing.T) {
+	// Head 200 and 19 required confirmations: block 175 meets the block
+	// count, block 190 doesn't
+	tests := []struct {
+		name       string
+		mode       string
+		block      int64
+		solidified int64
+		confirmed  bool
+	}{
+		{"both: both met", config.ConfirmationModeBoth, 175, 180, true},
+		{"both: only count met", config.ConfirmationModeBoth, 175, 170, false},
+		{"both: only solidified met", config.ConfirmationModeBoth, 190, 195, false},
+		{"both: neither met", config.ConfirmationModeBoth, 190, 180, false},
+		{"solidified: only solidified met", config.ConfirmationModeSolidified, 190, 195, true},
+		{"solidified: only count met", config.ConfirmationModeSolidified, 175, 170, false},
+		{"blocks: only count met", config.ConfirmationModeBlocks, 175, 0, true},
+		{"default is blocks", "", 175, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newFakeTronClient(200)
+			client.solidified = tt.solidified
+			token, _ := testTronAddress(0xaa)
+			from, _ := testTronAddress(0x11)
+			to, toAddr := testTronAddress(0x22)
+			client.addTransfer(tt.block, "c0", token, from, to, big.NewInt(1))
+
+			w := newTestTronWatcher(client)
+			w.cfg.ConfirmationMode = tt.mode
+			w.AddTronAddress(toAddr)
+			w.refreshSolidified(context.Background())
+
+			var got []*ChainEvent
+			w.dispatch.addHandler(func(event *ChainEvent) error {
+				got = append(got, event)
+				return nil
+			})
+			collectTronTxs(t, w, tt.block, 200)
+
+			require.Len(t, got, 1)
+			assert.Equal(t, tt.confirmed, got[0].Confirmed)
+		})
+	}
+}
+
+func TestParseSolidityBlock(t *testing.T) {
+	num, err := parseSolidityBlock("Num:61234567,ID:0000000003a66c87e5d2b3b1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(61234567), num)
+
+	_, err = parseSolidityBlock("")
+	assert.Error(t, err)
+}
@@ -0,0 +1,130 @@
+package watcher
+
+import (
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// HealthStatus is the coarse health of a single chain watcher.
+type HealthStatus string
+
+const (
+	HealthOK HealthStatus = "ok"
+	// HealthChainHalted means the RPC answers but the head stopped advancing
+	HealthChainHalted HealthStatus = "chain_halted"
+	// HealthRPCError means the latest head request failed
+	HealthRPCError HealthStatus = "rpc_error"
+)
+
+// Event types emitted when a chain halt is detected and when it clears.
+const (
+	EventTypeChainHalted  = "chain_halted"
+	EventTypeChainResumed = "chain_resumed"
+)
+
+// minHaltThreshold keeps fast chains (sub-second block times) from being
+// flagged as halted between two polls.
+const minHaltThreshold = time.Minute
+
+// ChainHealth is a point-in-time view of a watcher's head tracking.
+type ChainHealth struct {
+	Status     HealthStatus
+	Head       uint64
+	LastHeadAt time.Time // when Head was first observed
+	LastError  string    // latest RPC error, if Status is HealthRPCError
+
+	// RecentReorgs is the bounded reorg history, oldest first
+	RecentReorgs []ReorgInfo
+
+	// Paused is set while the chain was paused via PauseChain
+	Paused bool
+}
+
+// headMonitor watches the reported chain head and detects halts: a head
+// that stays put for longer than haltBlocks block times while the RPC keeps
+// answering. A zero threshold disables halt detection.
+type headMonitor struct {
+	mu        sync.Mutex
+	now       func() time.Time
+	threshold time.Duration
+	health    ChainHealth
+	halted    bool
+}
+
+func newHeadMonitor(blockTime time.Duration, haltBlocks uint64) *headMonitor {
+	var threshold time.Duration
+	if haltBlocks > 0 {
+		threshold = max(blockTime*time.Duration(haltBlocks), minHaltThreshold)
+	}
+	return &headMonitor{
+		now:       time.Now,
+		threshold: threshold,
+		health:    ChainHealth{Status: HealthOK},
+	}
+}
+
+// observeHead records a successfully fetched head. It reports whether the
+// chain just became halted or just resumed, so the caller can alert once per
+// transition.
+func (m *headMonitor) observeHead(head uint64) (halted, resumed bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	if head != m.health.Head || m.health.LastHeadAt.IsZero() {
+		m.health.Head = head
+		m.health.LastHeadAt = now
+		resumed = m.halted
+		m.halted = false
+	} else if m.threshold > 0 && !m.halted && now.Sub(m.health.LastHeadAt) > m.threshold {
+		m.halted = true
+		halted = true
+	}
+
+	m.health.LastError = ""
+	m.health.Status = HealthOK
+	if m.halted {
+		m.health.Status = HealthChainHalted
+	}
+	return halted, resumed
+}
+
+// observeError records a failed head request. An RPC failure says nothing
+// about whether the chain itself is producing blocks, so halt state is kept.
+func (m *headMonitor) observeError(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.health.Status = HealthRPCError
+	m.health.LastError = err.Error()
+}
+
+func (m *headMonitor) snapshot() ChainHealth {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.health
+}
+
+// haltEvent builds the alert event for a halt transition and logs it.
+func haltEvent(chainID uint64, chainName string, health ChainHealth, halted bool) *ChainEvent {
+	eventType := EventTypeChainResumed
+	if halted {
+		eventType = EventTypeChainHalted
+		log.Error().
+			Str("chain", chainName).
+			Uint64("head", health.Head).
+			Time("last_head_at", health.LastHeadAt).
+			Msg("Chain head stopped advancing, chain appears halted")
+	} else {
+		log.Info().Str("chain", chainName).Uint64("head", health.Head).Msg("Chain head advancing again")
+	}
+
+	return &ChainEvent{
+		ChainID:     chainID,
+		ChainName:   chainName,
+		EventType:   eventType,
+		BlockNumber: health.Head,
+		Timestamp:   health.LastHeadAt,
+	}
+}
@@ -0,0 +1,112 @@
+package watcher
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeClock is a manually advanced time source.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func TestTronWatcher_DetectsChainHalt(t *testing.T) {
+	ctx := context.Background()
+	clock := &fakeClock{now: time.Unix(1_700_000_000, 0)}
+	client := newFakeTronClient(100)
+	_, addr := testTronAddress(0x22)
+
+	w := newTestTronWatcher(client)
+	w.health = newHeadMonitor(3*time.Second, 30) // halted after 90s
+	w.health.now = clock.Now
+	w.AddTronAddress(addr)
+
+	var mu sync.Mutex
+	var alerts []string
+	w.dispatch.addHandler(func(event *ChainEvent) error {
+		mu.Lock()
+		defer mu.Unlock()
+		alerts = append(alerts, event.EventType)
+		return nil
+	})
+
+	// Head advances normally
+	for head := int64(100); head <= 110; head++ {
+		client.setHead(head)
+		w.poll(ctx)
+		clock.Advance(3 * time.Second)
+	}
+	assert.Equal(t, HealthOK, w.Health().Status)
+
+	// Head stops at 110 but the RPC keeps answering
+	clock.Advance(60 * time.Second)
+	w.poll(ctx)
+	assert.Equal(t, HealthOK, w.Health().Status, "within the threshold")
+
+	clock.Advance(30 * time.Second)
+	w.poll(ctx)
+	w.poll(ctx)
+	health := w.Health()
+	assert.Equal(t, HealthChainHalted, health.Status)
+	assert.Equal(t, uint64(110), health.Head)
+
+	// An RPC failure is reported as such, not as a halt
+	client.setHeadErr(errors.New("connection reset"))
+	w.poll(ctx)
+	assert.Equal(t, HealthRPCError, w.Health().Status)
+	assert.Equal(t, "connection reset", w.Health().LastError)
+
+	// RPC recovers, chain is still halted
+	client.setHeadErr(nil)
+	w.poll(ctx)
+	assert.Equal(t, HealthChainHalted, w.Health().Status)
+
+	// Chain resumes
+	client.setHead(111)
+	w.poll(ctx)
+	assert.Equal(t, HealthOK, w.Health().Status)
+
+	w.gate.drain()
+	assert.ElementsMatch(t, []string{EventTypeChainHalted, EventTypeChainResumed}, alerts, "one alert per transition")
+}
+
+func TestHeadMonitor_RPCErrorIsNotHalt(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1_700_000_000, 0)}
+	m := newHeadMonitor(12*time.Second, 10)
+	m.now = clock.Now
+
+	m.observeHead(500)
+	clock.Advance(10 * time.Minute)
+	m.observeError(errors.New("dial tcp: connection refused"))
+	assert.Equal(t, HealthRPCError, m.snapshot().Status)
+
+	// First successful answer after the outage shows the head moved on
+	halted, resumed := m.observeHead(550)
+	assert.False(t, halted)
+	assert.False(t, resumed)
+	assert.Equal(t, HealthOK, m.snapshot().Status)
+}
+
+func TestHeadMonitor_Thresholds(t *testing.T) {
+	assert.Equal(t, minHaltThreshold, newHeadMonitor(250*time.Millisecond, 10).threshold, "fast chains are floored")
+	assert.Equal(t, 2*time.Minute, newHeadMonitor(12*time.Second, 10).threshold)
+	assert.Zero(t, newHeadMonitor(12*time.Second, 0).threshold, "disabled")
+}
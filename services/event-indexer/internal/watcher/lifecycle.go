@@ -0,0 +1,47 @@
+package watcher
+
+// FinalityStatus is an event's place in the settlement lifecycle. It
+// refines Confirmed, which stays set for compatibility: Credited and Settled
+// events are Confirmed, the others aren't.
+type FinalityStatus string
+
+const (
+	// FinalityPending: seen on chain, below the required confirmations
+	FinalityPending FinalityStatus = "pending"
+	// FinalityCredited: has the required confirmations, and further
+	// confirmation milestones will still be reported
+	FinalityCredited FinalityStatus = "credited"
+	// FinalitySettled: confirmed, and no further updates will follow
+	FinalitySettled FinalityStatus = "settled"
+	// FinalityReorged: the block was replaced before the event settled;
+	// anything credited for it should be reversed
+	FinalityReorged FinalityStatus = "reorged"
+	// FinalityFailed: the transaction reverted (failed_tx events)
+	FinalityFailed FinalityStatus = "failed"
+)
+
+// defaultFinality derives the status of events emitted without one from
+// the legacy flags.
+func defaultFinality(event *ChainEvent) FinalityStatus {
+	switch {
+	case event.EventType == EventTypeFailedTx:
+		return FinalityFailed
+	case event.Confirmed:
+		return FinalitySettled
+	default:
+		return FinalityPending
+	}
+}
+
+// finality returns the status of a tracked event: confirmed events are only
+// settled once past the last milestone, since more updates follow until then.
+func (t *confirmationTracker) finality(event *ChainEvent) FinalityStatus {
+	switch {
+	case !event.Confirmed:
+		return FinalityPending
+	case t != nil && len(t.milestones) > 0 && event.Confirmations < t.milestones[len(t.milestones)-1]:
+		return FinalityCredited
+	default:
+		return FinalitySettled
+	}
+}
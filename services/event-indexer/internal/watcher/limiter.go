@@ -0,0 +1,61 @@
+package watcher
+
+import (
+	"context"
+
+	"github.com/rs/zerolog/log"
+	"golang.org/x/sync/semaphore"
+)
+
+// handlerLimiter caps concurrent handler executions across every chain of a
+// MultiChainWatcher. Each chain may have a number of reserved slots that
+// only it can use; the rest of the global limit forms a shared pool that
+// chains acquire from in FIFO order once their reservation is busy. A busy
+// chain therefore can't starve the others, and the total never exceeds the
+// global limit.
+type handlerLimiter struct {
+	shared   *semaphore.Weighted
+	reserved map[uint64]*semaphore.Weighted
+}
+
+// newHandlerLimiter returns nil (unlimited) for a non-positive limit.
+// Reservations must leave at least one shared slot for chains without one;
+// otherwise they are ignored and the limit applies as a plain global cap.
+func newHandlerLimiter(limit int, minSlots map[uint64]int) *handlerLimiter {
+	if limit <= 0 {
+		return nil
+	}
+
+	l := &handlerLimiter{reserved: make(map[uint64]*semaphore.Weighted)}
+	shared := int64(limit)
+	for chainID, slots := range minSlots {
+		if slots <= 0 {
+			continue
+		}
+		l.reserved[chainID] = semaphore.NewWeighted(int64(slots))
+		shared -= int64(slots)
+	}
+	if shared < 1 {
+		log.Warn().Int("limit", limit).Msg("Per-chain handler reservations leave no shared slots, ignoring them")
+		l.reserved = make(map[uint64]*semaphore.Weighted)
+		shared = int64(limit)
+	}
+	l.shared = semaphore.NewWeighted(shared)
+	return l
+}
+
+// acquire blocks until chainID may run one handler and returns the func that
+// gives the slot back.
+func (l *handlerLimiter) acquire(chainID uint64) func() {
+	if l == nil {
+		return func() {}
+	}
+
+	if reserved, ok := l.reserved[chainID]; ok && reserved.TryAcquire(1) {
+		return func() { reserved.Release(1) }
+	}
+
+	// Acquire only fails for a cancelled context
+	_ = l.shared.Acquire(context.Background(), 1)
+	return func() { l.shared.Release(1) }
+}
@@ -0,0 +1,76 @@
+package watcher
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/protocol-bank/event-indexer/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDispatcher_GlobalHandlerCapAcrossChains(t *testing.T) {
+	const limit = 3
+
+	d := newDispatcher(nil, config.RetryPolicy{MaxAttempts: 1})
+	d.limiter = newHandlerLimiter(limit, map[uint64]int{1: 1, 2: 1})
+
+	var mu sync.Mutex
+	running := make(map[uint64]int)
+	total, peak := 0, 0
+	release := make(chan struct{})
+	d.addHandler(func(event *ChainEvent) error {
+		mu.Lock()
+		running[event.ChainID]++
+		total++
+		peak = max(peak, total)
+		mu.Unlock()
+
+		<-release
+
+		mu.Lock()
+		running[event.ChainID]--
+		total--
+		mu.Unlock()
+		return nil
+	})
+
+	// Chain 1 floods the dispatcher before chain 2 sends anything
+	var gate drainGate
+	gate.enter()
+	for i := 0; i < 10; i++ {
+		d.dispatch(&gate, &ChainEvent{ChainID: 1})
+	}
+	for i := 0; i < 3; i++ {
+		d.dispatch(&gate, &ChainEvent{ChainID: 2})
+	}
+	gate.leave()
+
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return total == limit
+	}, time.Second, time.Millisecond)
+
+	// Give any excess goroutine a chance to (wrongly) get in
+	time.Sleep(20 * time.Millisecond)
+	mu.Lock()
+	assert.Equal(t, limit, total)
+	assert.GreaterOrEqual(t, running[2], 1, "chain 2's reserved slot is not starved by chain 1")
+	mu.Unlock()
+
+	close(release)
+	gate.drain()
+	assert.Equal(t, limit, peak, "global cap held across both chains")
+}
+
+func TestHandlerLimiter_Config(t *testing.T) {
+	assert.Nil(t, newHandlerLimiter(0, map[uint64]int{1: 1}), "zero limit is unlimited")
+
+	l := newHandlerLimiter(2, map[uint64]int{1: 1, 2: 1})
+	assert.Empty(t, l.reserved, "reservations leaving no shared slot are ignored")
+
+	// Nil limiter hands out no-op releases
+	var unlimited *handlerLimiter
+	unlimited.acquire(1)()
+}
@@ -0,0 +1,186 @@
+package watcher
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/protocol-bank/event-indexer/internal/config"
+	"github.com/rs/zerolog/log"
+)
+
+// fastLogRangeResponse is the response time under which a ranged
+// eth_getLogs call counts as fast and the range grows.
+const fastLogRangeResponse = 2 * time.Second
+
+// logRanges prefetches the logs of upcoming blocks with one eth_getLogs call
+// per range while a chain catches up, and tunes the range to the provider:
+// it doubles after fast responses, up to max, and halves on errors such as
+// range or response size limits and timeouts, down to min. Blocks are still
+// processed one by one from the prefetched logs, which are only used when
+// they carry the hash of the block header being processed. It is used by
+// the chain's polling loop and, in WebSocket mode, its subscription.
+type logRanges struct {
+	mu        sync.Mutex
+	chainName string
+	min       uint64
+	max       uint64
+	current   uint64
+
+	limit    uint64 // last block of the current processing pass
+	from, to uint64 // prefetched range, empty when from > to
+	logs     map[uint64][]types.Log
+}
+
+// newLogRanges returns the chain's range prefetcher, or nil (one call per
+// block) if LogRangeMax is at most 1.
+func newLogRanges(cfg config.ChainConfig) *logRanges {
+	if cfg.LogRangeMax <= 1 {
+		return nil
+	}
+	lo := max(cfg.LogRangeMin, 1)
+	hi := max(cfg.LogRangeMax, lo)
+	initial := cfg.LogRangeInitial
+	if initial == 0 {
+		initial = lo
+	}
+	return &logRanges{
+		chainName: cfg.Name,
+		min:       lo,
+		max:       hi,
+		current:   min(max(initial, lo), hi),
+		from:      1,
+	}
+}
+
+// size is the number of blocks the next ranged call covers.
+func (r *logRanges) size() uint64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.current
+}
+
+// plan starts a processing pass ending at block to, dropping logs prefetched
+// by an earlier pass.
+func (r *logRanges) plan(to uint64) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.limit = to
+	r.clear()
+}
+
+// reset drops the prefetched logs, e.g. after a reorg replaced their blocks.
+func (r *logRanges) reset() {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.clear()
+}
+
+// clear drops the prefetched logs. r.mu must be held.
+func (r *logRanges) clear() {
+	r.from, r.to = 1, 0
+	r.logs = nil
+}
+
+// prefetched returns the prefetched logs of blockNumber if every one of
+// them is from the block with blockHash. Logs of another block mean it was
+// replaced since the prefetch, which is then dropped. r.mu must be held.
+func (r *logRanges) prefetched(blockNumber uint64, blockHash common.Hash) ([]types.Log, bool) {
+	logs := r.logs[blockNumber]
+	for _, l := range logs {
+		if l.BlockHash != blockHash {
+			log.Debug().Str("chain", r.chainName).Uint64("block", blockNumber).Msg("Prefetched logs are from a replaced block, refetching")
+			r.clear()
+			return nil, false
+		}
+	}
+	return logs, true
+}
+
+// succeeded grows the range after a fast response.
+func (r *logRanges) succeeded(elapsed time.Duration) {
+	if elapsed >= fastLogRangeResponse || r.current >= r.max {
+		return
+	}
+	r.current = min(r.current*2, r.max)
+	log.Debug().Str("chain", r.chainName).Uint64("range", r.current).Msg("Growing eth_getLogs block range")
+}
+
+// failed shrinks the range after an error, reporting whether it could.
+func (r *logRanges) failed(err error) bool {
+	if r.current <= r.min {
+		return false
+	}
+	r.current = max(r.current/2, r.min)
+	log.Warn().Err(err).Str("chain", r.chainName).Uint64("range", r.current).Msg("Shrinking eth_getLogs block range")
+	return true
+}
+
+// fetch returns the logs matching query in blockNumber, the block with
+// blockHash, from a ranged call covering it and the blocks after it in the
+// current pass. It reports false when the block should be queried on its
+// own: ranging is disabled, the block's hash is unknown (zero) or doesn't
+// match the logs, the block is the last of the pass, or a ranged call
+// failed at the minimum range during this pass.
+func (r *logRanges) fetch(ctx context.Context, client evmRPC, blockNumber uint64, blockHash common.Hash, query ethereum.FilterQuery) ([]types.Log, bool) {
+	if r == nil || blockHash == (common.Hash{}) {
+		return nil, false
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if blockNumber >= r.from && blockNumber <= r.to {
+		return r.prefetched(blockNumber, blockHash)
+	}
+	if blockNumber >= r.limit {
+		return nil, false
+	}
+
+	for {
+		size := min(r.current, r.limit-blockNumber+1)
+		if size <= 1 {
+			return nil, false
+		}
+		to := blockNumber + size - 1
+		query.FromBlock = new(big.Int).SetUint64(blockNumber)
+		query.ToBlock = new(big.Int).SetUint64(to)
+
+		start := time.Now()
+		logs, err := client.FilterLogs(ctx, query)
+		if err != nil {
+			if ctx.Err() != nil || !r.failed(err) {
+				// Query the rest of the pass block by block
+				r.limit = 0
+				return nil, false
+			}
+			continue
+		}
+		r.succeeded(time.Since(start))
+
+		r.from, r.to = blockNumber, to
+		r.logs = make(map[uint64][]types.Log)
+		for _, l := range logs {
+			r.logs[l.BlockNumber] = append(r.logs[l.BlockNumber], l)
+		}
+		return r.prefetched(blockNumber, blockHash)
+	}
+}
+
+// blockLogs returns the logs matching query, a single-block filter for the
+// block with blockHash, from the range prefetch when it covers the block.
+func (w *ChainWatcher) blockLogs(ctx context.Context, blockNumber uint64, blockHash common.Hash, query ethereum.FilterQuery) ([]types.Log, error) {
+	if logs, ok := w.logRanges.fetch(ctx, w.client, blockNumber, blockHash, query); ok {
+		return logs, nil
+	}
+	return w.client.FilterLogs(ctx, query)
+}
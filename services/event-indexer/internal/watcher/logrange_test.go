@@ -0,0 +1,136 @@
+package watcher
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/protocol-bank/event-indexer/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rangeLimitedClient rejects eth_getLogs calls spanning more than maxRange
+// blocks, as hosted providers do, and records the span of each call.
+type rangeLimitedClient struct {
+	*fakeEVMClient
+	maxRange uint64
+
+	mu    sync.Mutex
+	spans []uint64
+}
+
+func (c *rangeLimitedClient) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	span := q.ToBlock.Uint64() - q.FromBlock.Uint64() + 1
+	c.mu.Lock()
+	c.spans = append(c.spans, span)
+	c.mu.Unlock()
+	if span > c.maxRange {
+		return nil, errors.New("query returned more than 10000 results: block range too large")
+	}
+	return c.fakeEVMClient.FilterLogs(ctx, q)
+}
+
+func TestNewLogRanges(t *testing.T) {
+	assert.Nil(t, newLogRanges(config.ChainConfig{LogRangeMax: 1}), "disabled")
+
+	r := newLogRanges(config.ChainConfig{LogRangeMin: 2, LogRangeMax: 50})
+	require.NotNil(t, r)
+	assert.Equal(t, uint64(2), r.size(), "starts at the minimum by default")
+
+	r = newLogRanges(config.ChainConfig{LogRangeMin: 2, LogRangeMax: 50, LogRangeInitial: 500})
+	assert.Equal(t, uint64(50), r.size(), "initial clamped to the maximum")
+}
+
+func TestChainWatcher_AdaptiveLogRange(t *testing.T) {
+	fake := newFakeEVMClient(1030)
+	watched := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	from := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	for block := uint64(1001); block <= 1030; block++ {
+		fake.addLog(testTransferLog(block, 0, from, watched, big.NewInt(int64(block))))
+	}
+
+	run := func(t *testing.T, client *rangeLimitedClient, cfg config.ChainConfig) []*ChainEvent {
+		t.Helper()
+		w := newTestChainWatcher(t, client)
+		w.AddAddress(watched)
+		w.logRanges = newLogRanges(cfg)
+
+		var mu sync.Mutex
+		var events []*ChainEvent
+		w.dispatch.addHandler(func(event *ChainEvent) error {
+			mu.Lock()
+			defer mu.Unlock()
+			events = append(events, event)
+			return nil
+		})
+		w.gate.enter()
+		assert.Equal(t, uint64(1030), w.processBlocks(context.Background(), 1000, 1030, 1030))
+		w.gate.leave()
+		w.gate.inflight.Wait()
+		return events
+	}
+
+	t.Run("grows on success, shrinks on range too large", func(t *testing.T) {
+		client := &rangeLimitedClient{fakeEVMClient: fake, maxRange: 10}
+		events := run(t, client, config.ChainConfig{Name: "Test", LogRangeMin: 2, LogRangeMax: 16, LogRangeInitial: 4})
+
+		assert.Len(t, events, 30, "every block's transfer, each once")
+		// 1001-1004, 1005-1012, 16 rejected, 1013-1020, then the last 10
+		assert.Equal(t, []uint64{4, 8, 16, 8, 10}, client.spans)
+	})
+
+	t.Run("falls back to single blocks at the minimum", func(t *testing.T) {
+		client := &rangeLimitedClient{fakeEVMClient: fake, maxRange: 1}
+		events := run(t, client, config.ChainConfig{Name: "Test", LogRangeMin: 4, LogRangeMax: 8})
+
+		assert.Len(t, events, 30)
+		spans := []uint64{4}
+		for range 30 {
+			spans = append(spans, 1)
+		}
+		assert.Equal(t, spans, client.spans, "one failed ranged call, then single blocks for the rest of the pass")
+	})
+}
+
+func TestChainWatcher_LogRangeRefetchesReplacedBlock(t *testing.T) {
+	fake := newFakeEVMClient(1004)
+	watched := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	from := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	for block := uint64(1001); block <= 1004; block++ {
+		fake.addLog(testTransferLog(block, 0, from, watched, big.NewInt(int64(block))))
+	}
+	client := &rangeLimitedClient{fakeEVMClient: fake, maxRange: 100}
+	w := newTestChainWatcher(t, client)
+	w.AddAddress(watched)
+	w.logRanges = newLogRanges(config.ChainConfig{Name: "Test", LogRangeMin: 4, LogRangeMax: 4})
+
+	var mu sync.Mutex
+	var events []*ChainEvent
+	w.dispatch.addHandler(func(event *ChainEvent) error {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, event)
+		return nil
+	})
+
+	w.gate.enter()
+	w.logRanges.plan(1004)
+	w.processBlock(context.Background(), 1001, 1004)
+	w.processBlock(context.Background(), 1002, 1004)
+	// Blocks 1003 and 1004 are replaced after the prefetch, without a
+	// parent hash change at 1003 for reorg detection to catch
+	fake.reorg(1003)
+	w.processBlock(context.Background(), 1003, 1004)
+	w.processBlock(context.Background(), 1004, 1004)
+	w.gate.leave()
+	w.gate.inflight.Wait()
+
+	assert.Len(t, events, 4)
+	assert.Equal(t, []uint64{4, 1, 1}, client.spans, "the replaced blocks are queried again on their own")
+}
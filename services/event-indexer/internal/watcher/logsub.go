@@ -0,0 +1,202 @@
+package watcher
+
+import (
+	"context"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/rs/zerolog/log"
+)
+
+// Bounds of the wait between log subscription attempts; the watcher polls
+// while it waits.
+const (
+	resubscribeInitialBackoff = time.Second
+	resubscribeMaxBackoff     = time.Minute
+)
+
+// catchUpRange is how many blocks are processed between checkpoints when
+// catching up on blocks missed while the subscription was down.
+const catchUpRange = 100
+
+// BlockRange is an inclusive range of block heights.
+type BlockRange struct {
+	From uint64
+	To   uint64
+}
+
+// calculateBlockRanges splits [from, to] into consecutive ranges of at most
+// maxRange blocks.
+func calculateBlockRanges(from, to, maxRange uint64) []BlockRange {
+	var ranges []BlockRange
+	for start := from; start <= to; start += maxRange {
+		end := start + maxRange - 1
+		if end > to {
+			end = to
+		}
+		ranges = append(ranges, BlockRange{From: start, To: end})
+	}
+	return ranges
+}
+
+// logSubscriber is the subset of *ethclient.Client used for log
+// subscriptions.
+type logSubscriber interface {
+	SubscribeFilterLogs(ctx context.Context, q ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error)
+}
+
+// watchLogs drives block processing from a Transfer log subscription. A log
+// for block N processes every block up to N, so reorg detection and the
+// other per-block checks see a contiguous chain; between logs the head is
+// refreshed every PollInterval to advance confirmations. The subscription
+// matches Transfer topics only, so addresses added at runtime need no
+// resubscribe. While it is down the watcher polls, resubscribing with
+// exponential backoff.
+func (w *ChainWatcher) watchLogs(ctx context.Context) {
+	lastBlock := w.checkpoints.resume(ctx)
+	backoff := resubscribeInitialBackoff
+
+	for ctx.Err() == nil {
+		logs := make(chan types.Log, 64)
+		sub, err := w.logSub.SubscribeFilterLogs(ctx, ethereum.FilterQuery{Topics: [][]common.Hash{w.logTopics()}}, logs)
+		if err != nil {
+			log.Warn().Err(err).Str("chain", w.chainName).Dur("retry_in", backoff).Msg("Log subscription failed, polling until retry")
+			lastBlock = w.pollFor(ctx, lastBlock, backoff)
+			backoff = min(backoff*2, resubscribeMaxBackoff)
+			continue
+		}
+
+		log.Info().Str("chain", w.chainName).Msg("Log subscription established")
+		backoff = resubscribeInitialBackoff
+		lastBlock = w.consumeLogs(ctx, sub, logs, lastBlock)
+		sub.Unsubscribe()
+	}
+}
+
+// pollFor polls every PollInterval for d, standing in for a subscription
+// that is down.
+func (w *ChainWatcher) pollFor(ctx context.Context, lastBlock uint64, d time.Duration) uint64 {
+	ticker := time.NewTicker(w.cfg.PollInterval)
+	defer ticker.Stop()
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return lastBlock
+		case <-timer.C:
+			return lastBlock
+		case <-ticker.C:
+			lastBlock = w.pollTick(ctx, lastBlock)
+		}
+	}
+}
+
+// consumeLogs backfills the blocks missed before sub was established, then
+// processes blocks as their logs arrive until sub fails or ctx is done.
+func (w *ChainWatcher) consumeLogs(ctx context.Context, sub ethereum.Subscription, logs <-chan types.Log, lastBlock uint64) uint64 {
+	lastBlock = w.backfill(ctx, lastBlock)
+
+	ticker := time.NewTicker(w.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return lastBlock
+		case err := <-sub.Err():
+			log.Warn().Err(err).Str("chain", w.chainName).Uint64("last_block", lastBlock).Msg("Log subscription dropped, falling back to polling")
+			return lastBlock
+		case l := <-logs:
+			// Reorged logs: the replacing block is processed when it arrives
+			if l.Removed || l.BlockNumber <= lastBlock {
+				continue
+			}
+			lastBlock = w.processThrough(ctx, lastBlock, l.BlockNumber)
+		case <-ticker.C:
+			w.refreshHead(ctx, lastBlock)
+		}
+	}
+}
+
+// backfill processes the blocks between lastBlock and the head, in ranges
+// of catchUpRange with a checkpoint after each. Without a checkpoint it
+// starts from the head.
+func (w *ChainWatcher) backfill(ctx context.Context, lastBlock uint64) uint64 {
+	if w.paused.Load() || !w.gate.enter() {
+		return lastBlock
+	}
+	defer w.gate.leave()
+
+	head, err := w.client.BlockNumber(ctx)
+	if err != nil {
+		log.Error().Err(err).Str("chain", w.chainName).Msg("Failed to get block number")
+		w.health.observeError(err)
+		return lastBlock
+	}
+	w.trackHead(head)
+	if w.checkpoints.atHead(lastBlock) || lastBlock >= head {
+		return max(lastBlock, head)
+	}
+
+	log.Info().Str("chain", w.chainName).Uint64("from", lastBlock+1).Uint64("to", head).Msg("Backfilling blocks missed by the log subscription")
+	for _, r := range calculateBlockRanges(lastBlock+1, head, catchUpRange) {
+		lastBlock = w.processBlocks(ctx, lastBlock, r.To, head)
+		w.checkpoint.Store(lastBlock)
+		w.checkpoints.advance(ctx, lastBlock, head)
+		if lastBlock < r.To {
+			break
+		}
+	}
+	if !w.separateConfirmationChecks {
+		w.emitMilestones(head)
+	}
+	return lastBlock
+}
+
+// processThrough processes the blocks up to block, in which a subscribed
+// log arrived.
+func (w *ChainWatcher) processThrough(ctx context.Context, lastBlock, block uint64) uint64 {
+	if w.paused.Load() || !w.gate.enter() {
+		return lastBlock
+	}
+	defer w.gate.leave()
+	defer recoverPanic(w.cfg.RecoverPanics, w.chainName, "subscription")
+
+	head := max(block, w.health.snapshot().Head)
+	w.trackHead(head)
+	if w.checkpoints.atHead(lastBlock) {
+		lastBlock = block - 1
+	}
+	lastBlock = w.processBlocks(ctx, lastBlock, block, head)
+	if !w.separateConfirmationChecks {
+		w.emitMilestones(head)
+	}
+	w.checkpoint.Store(lastBlock)
+	w.checkpoints.advance(ctx, lastBlock, head)
+	return lastBlock
+}
+
+// refreshHead advances confirmations and the checkpoint to the current head
+// while no logs arrive.
+func (w *ChainWatcher) refreshHead(ctx context.Context, lastBlock uint64) {
+	if w.paused.Load() || !w.gate.enter() {
+		return
+	}
+	defer w.gate.leave()
+
+	head, err := w.client.BlockNumber(ctx)
+	if err != nil {
+		log.Error().Err(err).Str("chain", w.chainName).Msg("Failed to get block number")
+		w.health.observeError(err)
+		return
+	}
+	w.trackHead(head)
+	if !w.separateConfirmationChecks {
+		w.emitMilestones(head)
+	}
+	w.checkpoints.advance(ctx, lastBlock, head)
+}
@@ -0,0 +1,122 @@
+package watcher
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSubscription is an ethereum.Subscription whose failure the test
+// controls.
+type fakeSubscription struct {
+	errs chan error
+	once sync.Once
+}
+
+func (s *fakeSubscription) Err() <-chan error { return s.errs }
+
+func (s *fakeSubscription) Unsubscribe() { s.once.Do(func() { close(s.errs) }) }
+
+// fakeLogSubscriber plays back scripted subscription attempts: a nil error
+// establishes a subscription whose log channel is handed to the test.
+type fakeLogSubscriber struct {
+	attempts chan error
+	subs     chan subscribed
+}
+
+type subscribed struct {
+	sub  *fakeSubscription
+	logs chan<- types.Log
+}
+
+func (f *fakeLogSubscriber) SubscribeFilterLogs(ctx context.Context, q ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
+	if err := <-f.attempts; err != nil {
+		return nil, err
+	}
+	sub := &fakeSubscription{errs: make(chan error, 1)}
+	f.subs <- subscribed{sub: sub, logs: ch}
+	return sub, nil
+}
+
+func TestChainWatcher_LogSubscription(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	watched := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	other := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	client := newFakeEVMClient(110)
+	w := newTestChainWatcher(t, client)
+	w.AddAddress(watched)
+	w.cfg.PollInterval = 5 * time.Millisecond
+
+	subscriber := &fakeLogSubscriber{attempts: make(chan error, 4), subs: make(chan subscribed, 4)}
+	w.logSub = subscriber
+
+	blocks := make(chan uint64, 8)
+	w.dispatch.addHandler(func(event *ChainEvent) error {
+		if event.EventType == "transfer" && !event.Confirmed {
+			blocks <- event.BlockNumber
+		}
+		return nil
+	})
+	expectBlock := func(t *testing.T, want uint64) {
+		t.Helper()
+		select {
+		case got := <-blocks:
+			assert.Equal(t, want, got)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("no event for block %d", want)
+		}
+	}
+	advance := func(head uint64, l types.Log) {
+		client.mu.Lock()
+		client.head = head
+		client.mu.Unlock()
+		client.addLog(l)
+	}
+
+	subscriber.attempts <- nil
+	w.Start(ctx)
+	first := <-subscriber.subs
+
+	// A subscribed log processes its block
+	l := testTransferLog(112, 0, other, watched, big.NewInt(1))
+	advance(112, l)
+	first.logs <- l
+	expectBlock(t, 112)
+
+	// Blocks mined while the subscription was down are backfilled
+	advance(115, testTransferLog(114, 0, other, watched, big.NewInt(2)))
+	subscriber.attempts <- nil
+	first.sub.errs <- errors.New("connection reset")
+	second := <-subscriber.subs
+	expectBlock(t, 114)
+
+	// A failed resubscribe falls back to polling until the next attempt
+	subscriber.attempts <- errors.New("dial failed")
+	second.sub.errs <- errors.New("connection reset")
+	advance(118, testTransferLog(117, 0, other, watched, big.NewInt(3)))
+	expectBlock(t, 117)
+
+	subscriber.attempts <- nil
+	third := <-subscriber.subs
+	l = testTransferLog(119, 0, other, watched, big.NewInt(4))
+	advance(119, l)
+	third.logs <- l
+	expectBlock(t, 119)
+
+	require.Empty(t, blocks, "every block processed once")
+}
+
+func TestCalculateBlockRanges_Empty(t *testing.T) {
+	assert.Empty(t, calculateBlockRanges(101, 100, 10))
+}
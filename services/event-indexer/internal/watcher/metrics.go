@@ -0,0 +1,135 @@
+package watcher
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Throughput counters, labelled by chain name. They are process-wide like
+// the other counters here and exported through RegisterMetrics.
+var (
+	blocksProcessed = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "indexer_blocks_processed_total",
+		Help: "Blocks processed, per chain.",
+	}, []string{"chain"})
+
+	eventsEmitted = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "indexer_events_emitted_total",
+		Help: "Events delivered to handlers, counted on first emission (not milestone or confirmed copies), per chain and event type.",
+	}, []string{"chain", "event_type"})
+
+	eventsFiltered = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "indexer_events_filtered_total",
+		Help: "Transfers below the chain's MinTransferValue that were not emitted, per chain and event type.",
+	}, []string{"chain", "event_type"})
+
+	eventsDropped = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "indexer_events_dropped_total",
+		Help: "Event deliveries dropped because the dispatch queue was full, per chain and event type.",
+	}, []string{"chain", "event_type"})
+)
+
+// Process-wide anomaly counters, read from the atomics the watchers keep.
+var (
+	recoveredPanicsCounter = prometheus.NewCounterFunc(prometheus.CounterOpts{
+		Name: "indexer_recovered_panics_total",
+		Help: "Panics recovered in block processing and event handlers.",
+	}, func() float64 { return float64(RecoveredPanics()) })
+
+	malformedTronAddressesCounter = prometheus.NewCounterFunc(prometheus.CounterOpts{
+		Name: "indexer_malformed_tron_addresses_total",
+		Help: "TRON addresses with a malformed length that could not be converted.",
+	}, func() float64 { return float64(MalformedTronAddresses()) })
+
+	zeroAddressTransfersCounter = prometheus.NewCounterFunc(prometheus.CounterOpts{
+		Name: "indexer_zero_address_transfers_total",
+		Help: "Transfers from and to the zero address.",
+	}, func() float64 { return float64(ZeroAddressTransfers()) })
+
+	throttledEventsCounter = prometheus.NewCounterFunc(prometheus.CounterOpts{
+		Name: "indexer_throttled_events_total",
+		Help: "Events withheld by the per-address event cap.",
+	}, func() float64 { return float64(ThrottledEvents()) })
+)
+
+var blockLagDesc = prometheus.NewDesc(
+	"indexer_block_lag",
+	"Blocks between the chain head and the last processed block.",
+	[]string{"chain"}, nil,
+)
+
+// lagCollector reports every running chain's block lag at scrape time,
+// so chains that join after a startup retry are included.
+type lagCollector struct {
+	mcw *MultiChainWatcher
+}
+
+func (c lagCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- blockLagDesc
+}
+
+func (c lagCollector) Collect(ch chan<- prometheus.Metric) {
+	watchers, tronWatchers := c.mcw.chainWatchers()
+	for _, w := range watchers {
+		if lag, ok := blockLag(w.health.snapshot().Head, w.checkpoint.Load()); ok {
+			ch <- prometheus.MustNewConstMetric(blockLagDesc, prometheus.GaugeValue, float64(lag), w.chainName)
+		}
+	}
+	for _, tw := range tronWatchers {
+		if lag, ok := blockLag(tw.health.snapshot().Head, tw.checkpoint.Load()); ok {
+			ch <- prometheus.MustNewConstMetric(blockLagDesc, prometheus.GaugeValue, float64(lag), tw.chainName)
+		}
+	}
+}
+
+var endpointActiveDesc = prometheus.NewDesc(
+	"indexer_rpc_endpoint_active",
+	"The RPC endpoint a chain's calls currently go to, by host (always 1).",
+	[]string{"chain", "endpoint"}, nil,
+)
+
+// endpointCollector reports each running chain's active RPC endpoint at
+// scrape time.
+type endpointCollector struct {
+	mcw *MultiChainWatcher
+}
+
+func (c endpointCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- endpointActiveDesc
+}
+
+func (c endpointCollector) Collect(ch chan<- prometheus.Metric) {
+	watchers, tronWatchers := c.mcw.chainWatchers()
+	for _, w := range watchers {
+		if endpoint, ok := activeEndpoint(w.client, w.cfg); ok {
+			ch <- prometheus.MustNewConstMetric(endpointActiveDesc, prometheus.GaugeValue, 1, w.chainName, redactEndpoint(endpoint))
+		}
+	}
+	for _, tw := range tronWatchers {
+		if endpoint, ok := activeEndpoint(tw.client, tw.cfg); ok {
+			ch <- prometheus.MustNewConstMetric(endpointActiveDesc, prometheus.GaugeValue, 1, tw.chainName, redactEndpoint(endpoint))
+		}
+	}
+}
+
+// blockLag is head minus the last processed block, not reported before
+// the first poll.
+func blockLag(head, processed uint64) (uint64, bool) {
+	if processed == 0 {
+		return 0, false
+	}
+	return confirmationsAt(head, processed), true
+}
+
+// RegisterMetrics registers the indexer's Prometheus metrics with reg.
+func (mcw *MultiChainWatcher) RegisterMetrics(reg prometheus.Registerer) error {
+	for _, c := range []prometheus.Collector{
+		blocksProcessed, eventsEmitted, eventsFiltered, eventsDropped,
+		recoveredPanicsCounter, malformedTronAddressesCounter, zeroAddressTransfersCounter, throttledEventsCounter,
+		lagCollector{mcw: mcw}, endpointCollector{mcw: mcw},
+	} {
+		if err := reg.Register(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
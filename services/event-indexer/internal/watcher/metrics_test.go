@@ -0,0 +1,75 @@
+package watcher
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/protocol-bank/event-indexer/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMultiChainWatcher_Metrics(t *testing.T) {
+	client := newFakeEVMClient(1010)
+	watched := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	from := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	client.addLog(testTransferLog(1001, 0, from, watched, big.NewInt(5)))
+	client.addLog(testTransferLog(1003, 0, from, watched, big.NewInt(6)))
+
+	w := newTestChainWatcher(t, client)
+	w.chainName = "Metrics Test"
+	w.AddAddress(watched)
+	mcw := &MultiChainWatcher{watchers: map[uint64]*ChainWatcher{1: w}}
+	reg := prometheus.NewRegistry()
+	require.NoError(t, mcw.RegisterMetrics(reg))
+	for _, name := range []string{"indexer_recovered_panics_total", "indexer_malformed_tron_addresses_total", "indexer_zero_address_transfers_total", "indexer_throttled_events_total"} {
+		count, err := testutil.GatherAndCount(reg, name)
+		require.NoError(t, err)
+		assert.Equal(t, 1, count, name)
+	}
+
+	blocks := blocksProcessed.WithLabelValues("Metrics Test")
+	transfers := eventsEmitted.WithLabelValues("Metrics Test", "transfer")
+	blocksBefore, transfersBefore := testutil.ToFloat64(blocks), testutil.ToFloat64(transfers)
+
+	// Not reported before the first poll
+	assert.Equal(t, 0, testutil.CollectAndCount(lagCollector{mcw: mcw}))
+
+	w.health.observeHead(1010)
+	w.gate.enter()
+	w.checkpoint.Store(w.processBlocks(context.Background(), 1000, 1004, 1010))
+	w.gate.leave()
+	w.gate.inflight.Wait()
+
+	assert.Equal(t, 4.0, testutil.ToFloat64(blocks)-blocksBefore)
+	assert.Equal(t, 2.0, testutil.ToFloat64(transfers)-transfersBefore)
+	assert.NoError(t, testutil.CollectAndCompare(lagCollector{mcw: mcw}, strings.NewReader(`
+# HELP indexer_block_lag Blocks between the chain head and the last processed block.
+# TYPE indexer_block_lag gauge
+indexer_block_lag{chain="Metrics Test"} 6
+`)))
+}
+
+func TestDispatcher_EventsEmittedCountsFirstEmissionOnly(t *testing.T) {
+	d := newDispatcher(nil, config.RetryPolicy{MaxAttempts: 1})
+	var gate drainGate
+	transfers := eventsEmitted.WithLabelValues("Emitted Test", "transfer")
+	before := testutil.ToFloat64(transfers)
+
+	pending := &ChainEvent{ChainName: "Emitted Test", EventType: "transfer", EventID: "1:0xabc:0", BlockNumber: 100}
+	d.dispatch(&gate, pending)
+	for _, status := range []FinalityStatus{FinalityCredited, FinalitySettled, FinalityReorged} {
+		copied := *pending
+		copied.Confirmed = status != FinalityReorged
+		copied.FinalityStatus = status
+		d.dispatch(&gate, &copied)
+	}
+	gate.inflight.Wait()
+
+	assert.Equal(t, 1.0, testutil.ToFloat64(transfers)-before)
+}
@@ -0,0 +1,164 @@
+package watcher
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/fbsobreira/gotron-sdk/pkg/proto/core"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/protobuf/proto"
+)
+
+// Event types of transfers carried by a TRON transaction's contracts
+// rather than by event logs.
+const (
+	EventTypeTRXTransfer   = "trx_transfer"
+	EventTypeTRC10Transfer = "trc10_transfer"
+)
+
+// trxDecimals is the precision of TRX amounts (SUN).
+const trxDecimals = 6
+
+// nativeTransfer is a TRX or TRC-10 transfer decoded from a transaction
+// contract. token is the TRC-10 asset ID, empty for TRX.
+type nativeTransfer struct {
+	eventType string
+	owner     []byte
+	to        []byte
+	token     string
+	amount    int64
+}
+
+// decodeNativeTransfer decodes a TransferContract or TransferAssetContract.
+func decodeNativeTransfer(contract *core.Transaction_Contract) (nativeTransfer, bool) {
+	switch contract.GetType() {
+	case core.Transaction_Contract_TransferContract:
+		transfer := &core.TransferContract{}
+		if err := proto.Unmarshal(contract.GetParameter().GetValue(), transfer); err != nil {
+			return nativeTransfer{}, false
+		}
+		return nativeTransfer{
+			eventType: EventTypeTRXTransfer,
+			owner:     transfer.GetOwnerAddress(),
+			to:        transfer.GetToAddress(),
+			amount:    transfer.GetAmount(),
+		}, true
+	case core.Transaction_Contract_TransferAssetContract:
+		transfer := &core.TransferAssetContract{}
+		if err := proto.Unmarshal(contract.GetParameter().GetValue(), transfer); err != nil {
+			return nativeTransfer{}, false
+		}
+		return nativeTransfer{
+			eventType: EventTypeTRC10Transfer,
+			owner:     transfer.GetOwnerAddress(),
+			to:        transfer.GetToAddress(),
+			token:     string(transfer.GetAssetName()),
+			amount:    transfer.GetAmount(),
+		}, true
+	}
+	return nativeTransfer{}, false
+}
+
+// contractSucceeded reports whether the transaction's i-th contract
+// executed successfully. Plain transfers report DEFAULT or SUCCESS.
+func contractSucceeded(tx *core.Transaction, i int) bool {
+	ret := tx.GetRet()
+	if i >= len(ret) {
+		return true // not reported: included transactions succeeded
+	}
+	switch ret[i].GetContractRet() {
+	case core.Transaction_Result_DEFAULT, core.Transaction_Result_SUCCESS:
+		return true
+	}
+	return false
+}
+
+// processNativeTransfers emits the TRX and TRC-10 transfers in the
+// transaction's contract list, which don't produce event logs, and returns
+// the number of events emitted. Only the full block carries raw
+// transactions, so the tx info fallback path can't see them.
+//
+// Transfer contracts don't run in the VM and emit no logs, so these events
+// never duplicate a log-based one; their EventIDs (":contract:<index>") are
+// also disjoint from log and calldata EventIDs, so the confirmed-phase
+// dedupe tells them apart. processBlock visits each txID once.
+func (w *TronWatcher) processNativeTransfers(tx *core.Transaction, txID string, blockNum, currentBlock int64, timestamp time.Time) int {
+	emitted := 0
+	for i, contract := range tx.GetRawData().GetContract() {
+		transfer, ok := decodeNativeTransfer(contract)
+		if !ok || !contractSucceeded(tx, i) {
+			continue
+		}
+
+		// Raw 21-byte addresses (network prefix + 20 bytes) → Base58Check
+		fromAddr := hexBytesToTronAddress(transfer.owner, w.addrPrefix)
+		toAddr := hexBytesToTronAddress(transfer.to, w.addrPrefix)
+
+		w.mu.RLock()
+		var watched string
+		switch {
+		case w.addresses[toAddr]:
+			watched = toAddr
+		case w.addresses[fromAddr]:
+			watched = fromAddr
+		}
+		w.mu.RUnlock()
+		isRelevant := watched != ""
+
+		// Token-scoped mode matches TRC-10 assets by ID
+		if !isRelevant && (transfer.token == "" || !w.scopedTokens[transfer.token] || w.cfg.DropUnwatchedTransfers) {
+			continue
+		}
+
+		value := big.NewInt(transfer.amount)
+		confirmations := confirmationsAt(uint64(currentBlock), uint64(blockNum))
+		confirmed := w.isFinal(confirmations >= w.confirmations.required(), blockNum)
+
+		event := &ChainEvent{
+			ChainID:        w.chainID,
+			ChainName:      w.chainName,
+			EventType:      transfer.eventType,
+			TxHash:         txID,
+			BlockNumber:    uint64(blockNum),
+			FromAddress:    fromAddr,
+			ToAddress:      toAddr,
+			Value:          value.String(),
+			TokenAddress:   transfer.token,
+			Timestamp:      timestamp,
+			Confirmed:      confirmed,
+			Confirmations:  confirmations,
+			TouchesWatched: isRelevant,
+			WatchedAddress: watched,
+			Memo:           tronTxMemo(tx),
+			EventID:        fmt.Sprintf("%d:%s:contract:%d", w.chainID, txID, i),
+		}
+		if transfer.eventType == EventTypeTRXTransfer {
+			event.TokenSymbol = "TRX"
+			event.NormalizedValue = FormatAmount(value, trxDecimals)
+		} else {
+			w.tokens.annotate(event, value)
+		}
+		if belowMinTransferValue(w.cfg.MinTransferValue, event) {
+			continue
+		}
+		event.FinalityStatus = w.milestones.finality(event)
+
+		log.Info().
+			Str("chain", w.chainName).
+			Str("tx", txID).
+			Str("type", transfer.eventType).
+			Str("token", transfer.token).
+			Str("from", fromAddr).
+			Str("to", toAddr).
+			Str("value", event.Value).
+			Bool("confirmed", confirmed).
+			Msg("TRON native transfer detected")
+
+		if w.emit(event) {
+			w.milestones.track(event)
+		}
+		emitted++
+	}
+	return emitted
+}
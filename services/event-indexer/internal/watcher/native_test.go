@@ -0,0 +1,113 @@
+package watcher
+
+import (
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/fbsobreira/gotron-sdk/pkg/proto/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+func TestTronWatcher_NativeTransfers(t *testing.T) {
+	client := newFakeTronClient(200)
+	owner, ownerAddr := testTronAddress(0x11)
+	to, toAddr := testTronAddress(0x22)
+	other, _ := testTronAddress(0x33)
+	raw := func(addr []byte) []byte { return append([]byte{tronMainnetPrefix}, addr...) }
+
+	transfer := func(txID string, result core.Transaction_ResultContractResult, contractType core.Transaction_Contract_ContractType, msg proto.Message) {
+		client.addLogs(190, txID)
+		param, err := anypb.New(msg)
+		require.NoError(t, err)
+		txs := client.blocks[190].Transactions
+		tx := txs[len(txs)-1].Transaction
+		tx.RawData.Contract = []*core.Transaction_Contract{{Type: contractType, Parameter: param}}
+		tx.Ret = []*core.Transaction_Result{{ContractRet: result}}
+	}
+	transfer("a190", core.Transaction_Result_SUCCESS, core.Transaction_Contract_TransferContract,
+		&core.TransferContract{OwnerAddress: raw(owner), ToAddress: raw(to), Amount: 1_500_000})
+	transfer("b190", core.Transaction_Result_DEFAULT, core.Transaction_Contract_TransferAssetContract,
+		&core.TransferAssetContract{AssetName: []byte("1002000"), OwnerAddress: raw(owner), ToAddress: raw(to), Amount: 42})
+	transfer("c190", core.Transaction_Result_OUT_OF_ENERGY, core.Transaction_Contract_TransferContract,
+		&core.TransferContract{OwnerAddress: raw(owner), ToAddress: raw(to), Amount: 7})
+	transfer("d190", core.Transaction_Result_SUCCESS, core.Transaction_Contract_TransferContract,
+		&core.TransferContract{OwnerAddress: raw(owner), ToAddress: raw(other), Amount: 9})
+
+	w := newTestTronWatcher(client)
+	w.AddTronAddress(toAddr)
+
+	var mu sync.Mutex
+	events := make(map[string]*ChainEvent)
+	w.dispatch.addHandler(func(event *ChainEvent) error {
+		mu.Lock()
+		defer mu.Unlock()
+		events[event.TxHash] = event
+		return nil
+	})
+	assert.Equal(t, []string{"a190", "b190"}, collectTronTxs(t, w, 190, 200), "failed and unwatched transfers emit nothing")
+
+	trx := events["a190"]
+	require.NotNil(t, trx)
+	assert.Equal(t, EventTypeTRXTransfer, trx.EventType)
+	assert.Equal(t, ownerAddr, trx.FromAddress)
+	assert.Equal(t, toAddr, trx.ToAddress)
+	assert.Equal(t, toAddr, trx.WatchedAddress)
+	assert.Equal(t, "1500000", trx.Value)
+	assert.Equal(t, "1.5", trx.NormalizedValue)
+	assert.Equal(t, "TRX", trx.TokenSymbol)
+	assert.Empty(t, trx.TokenAddress)
+	assert.Equal(t, "728126428:a190:contract:0", trx.EventID)
+
+	trc10 := events["b190"]
+	require.NotNil(t, trc10)
+	assert.Equal(t, EventTypeTRC10Transfer, trc10.EventType)
+	assert.Equal(t, ownerAddr, trc10.FromAddress)
+	assert.Equal(t, toAddr, trc10.ToAddress)
+	assert.Equal(t, "1002000", trc10.TokenAddress)
+	assert.Equal(t, "42", trc10.Value)
+}
+
+func TestTronWatcher_NativeTransferDedupe(t *testing.T) {
+	client := newFakeTronClient(200)
+	owner, _ := testTronAddress(0x11)
+	to, toAddr := testTronAddress(0x22)
+	token, _ := testTronAddress(0xaa)
+
+	client.addLogs(180, "a180")
+	param, err := anypb.New(&core.TransferContract{
+		OwnerAddress: append([]byte{tronMainnetPrefix}, owner...),
+		ToAddress:    append([]byte{tronMainnetPrefix}, to...),
+		Amount:       1_000_000,
+	})
+	require.NoError(t, err)
+	txs := client.blocks[180].Transactions
+	txs[0].Transaction.RawData.Contract = []*core.Transaction_Contract{{Type: core.Transaction_Contract_TransferContract, Parameter: param}}
+	// The node lists the transaction twice
+	client.blocks[180].Transactions = append(txs, txs[0])
+	// A TRC-20 transfer in the same block
+	client.addTransfer(180, "b180", token, owner, to, big.NewInt(5))
+
+	w := newTestTronWatcher(client)
+	w.dispatch.confirmed = newConfirmedDedupe(0)
+	w.AddTronAddress(toAddr)
+
+	var mu sync.Mutex
+	var ids []string
+	w.dispatch.addHandler(func(event *ChainEvent) error {
+		mu.Lock()
+		defer mu.Unlock()
+		ids = append(ids, event.EventID)
+		return nil
+	})
+	assert.Equal(t, []string{"a180", "b180"}, collectTronTxs(t, w, 180, 200), "the native transfer is emitted once")
+	assert.NotEqual(t, ids[0], ids[1])
+
+	// Re-scanning the confirmed block emits nothing new
+	ids = nil
+	assert.Empty(t, collectTronTxs(t, w, 180, 200))
+	assert.Empty(t, ids)
+}
@@ -0,0 +1,56 @@
+package watcher
+
+import (
+	"fmt"
+	"runtime/debug"
+	"sync/atomic"
+
+	"github.com/rs/zerolog/log"
+)
+
+// recoveredPanics counts panics recovered in chain loops and handlers,
+// across all watchers.
+var recoveredPanics atomic.Uint64
+
+// RecoveredPanics returns how many panics have been recovered since startup.
+func RecoveredPanics() uint64 {
+	return recoveredPanics.Load()
+}
+
+// recoverPanic, when deferred with enabled set, stops a panic from unwinding
+// past the caller and logs it with the chain and the work it interrupted.
+// With enabled unset the panic propagates as usual.
+func recoverPanic(enabled bool, chainName, scope string) {
+	if !enabled {
+		return
+	}
+	if r := recover(); r != nil {
+		recoveredPanics.Add(1)
+		log.Error().
+			Interface("panic", r).
+			Str("chain", chainName).
+			Str("scope", scope).
+			Bytes("stack", debug.Stack()).
+			Msg("Recovered from panic, skipping")
+	}
+}
+
+// callHandler invokes handler, turning a panic into an error so it goes
+// through the event type's retry policy and dead-lettering like any other
+// handler failure.
+func callHandler(handler EventHandler, event *ChainEvent) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			recoveredPanics.Add(1)
+			log.Error().
+				Interface("panic", r).
+				Uint64("chain_id", event.ChainID).
+				Str("event_type", event.EventType).
+				Str("tx", event.TxHash).
+				Bytes("stack", debug.Stack()).
+				Msg("Event handler panicked")
+			err = fmt.Errorf("handler panicked: %v", r)
+		}
+	}()
+	return handler(event)
+}
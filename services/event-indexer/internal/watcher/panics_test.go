@@ -0,0 +1,103 @@
+package watcher
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/protocol-bank/event-indexer/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDispatcher_RecoversHandlerPanic(t *testing.T) {
+	d := newDispatcher(nil, config.RetryPolicy{MaxAttempts: 2})
+
+	var mu sync.Mutex
+	delivered := 0
+	var deadLettered []error
+
+	d.addHandler(func(event *ChainEvent) error {
+		panic("nil map in handler")
+	})
+	d.addHandler(func(event *ChainEvent) error {
+		mu.Lock()
+		defer mu.Unlock()
+		delivered++
+		return nil
+	})
+	d.setDeadLetterHandler(func(event *ChainEvent, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		deadLettered = append(deadLettered, err)
+	})
+
+	before := RecoveredPanics()
+
+	var gate drainGate
+	gate.enter()
+	d.dispatch(&gate, &ChainEvent{EventType: "transfer", TxHash: "0x01"})
+	d.dispatch(&gate, &ChainEvent{EventType: "transfer", TxHash: "0x02"})
+	gate.leave()
+	gate.drain()
+
+	assert.Equal(t, 2, delivered, "other handlers keep receiving events")
+	require.Len(t, deadLettered, 2, "a panic is a failed attempt, retried then dead-lettered")
+	assert.EqualError(t, deadLettered[0], "handler panicked: nil map in handler")
+	assert.Equal(t, before+4, RecoveredPanics(), "every panicking attempt is counted")
+}
+
+// panickyEVMClient panics on the log query of one block.
+type panickyEVMClient struct {
+	*fakeEVMClient
+	block uint64
+}
+
+func (p *panickyEVMClient) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	if q.FromBlock.Uint64() == p.block {
+		panic("malformed log")
+	}
+	return p.fakeEVMClient.FilterLogs(ctx, q)
+}
+
+func TestChainWatcher_PanicSkipsBlock(t *testing.T) {
+	watched := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	other := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	fake := newFakeEVMClient(103)
+	for block := uint64(101); block <= 103; block++ {
+		fake.addLog(testTransferLog(block, 0, other, watched, big.NewInt(1)))
+	}
+
+	w := newTestChainWatcher(t, &panickyEVMClient{fakeEVMClient: fake, block: 102})
+	w.cfg.RecoverPanics = true
+	w.AddAddress(watched)
+
+	var mu sync.Mutex
+	var blocks []uint64
+	w.dispatch.addHandler(func(event *ChainEvent) error {
+		mu.Lock()
+		defer mu.Unlock()
+		blocks = append(blocks, event.BlockNumber)
+		return nil
+	})
+
+	before := RecoveredPanics()
+	last := w.poll(context.Background(), 100)
+	w.gate.drain()
+
+	assert.Equal(t, uint64(103), last, "the loop moves past the panicking block")
+	assert.ElementsMatch(t, []uint64{101, 103}, blocks)
+	assert.Equal(t, before+1, RecoveredPanics())
+}
+
+func TestChainWatcher_PanicPropagatesWhenNotIsolated(t *testing.T) {
+	fake := newFakeEVMClient(103)
+	w := newTestChainWatcher(t, &panickyEVMClient{fakeEVMClient: fake, block: 102})
+	w.AddAddress(common.HexToAddress("0x2222222222222222222222222222222222222222"))
+
+	assert.Panics(t, func() { w.processBlock(context.Background(), 102, 103) })
+}
@@ -0,0 +1,30 @@
+package watcher
+
+import (
+	"strconv"
+
+	"github.com/protocol-bank/event-indexer/internal/config"
+)
+
+// PartitionKey returns the key a message bus should partition on so that
+// related events stay in order on a single partition. By default it is the
+// watched address the event involves; PartitionBy switches it to the token
+// contract or the chain. Events lacking the chosen field fall back to the
+// token, then to the chain, so the key is never empty.
+func (e *ChainEvent) PartitionKey() string {
+	switch e.PartitionBy {
+	case config.PartitionByChain:
+	case config.PartitionByToken:
+		if e.TokenAddress != "" {
+			return tagKey(e.TokenAddress)
+		}
+	default:
+		if e.WatchedAddress != "" {
+			return tagKey(e.WatchedAddress)
+		}
+		if e.TokenAddress != "" {
+			return tagKey(e.TokenAddress)
+		}
+	}
+	return strconv.FormatUint(e.ChainID, 10)
+}
@@ -0,0 +1,86 @@
+package watcher
+
+import (
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/protocol-bank/event-indexer/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChainEvent_PartitionKey(t *testing.T) {
+	client := newFakeEVMClient(1000)
+	watched := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	other := common.HexToAddress("0x3333333333333333333333333333333333333333")
+	client.addLog(testTransferLog(990, 0, common.HexToAddress("0x1111111111111111111111111111111111111111"), watched, big.NewInt(1)))
+	client.addLog(testTransferLog(990, 1, other, watched, big.NewInt(2)))
+	client.addLog(testTransferLog(990, 2, watched, other, big.NewInt(3)))
+
+	t.Run("same watched address, same key", func(t *testing.T) {
+		w := newTestChainWatcher(t, client)
+		w.AddAddress(watched)
+		events := collectEVMEvents(t, w, 990, 1000)
+
+		require.Len(t, events, 3)
+		for _, event := range events {
+			assert.Equal(t, "0x2222222222222222222222222222222222222222", event.PartitionKey())
+		}
+	})
+
+	t.Run("by token", func(t *testing.T) {
+		w := newTestChainWatcher(t, client)
+		w.dispatch.partitionBy = config.PartitionByToken
+		w.AddAddress(watched)
+		events := collectEVMEvents(t, w, 990, 1000)
+
+		require.Len(t, events, 3)
+		for _, event := range events {
+			assert.Equal(t, "0xdac17f958d2ee523a2206206994597c13d831ec7", event.PartitionKey())
+		}
+	})
+
+	t.Run("by chain", func(t *testing.T) {
+		event := &ChainEvent{ChainID: 1, WatchedAddress: watched.Hex(), PartitionBy: config.PartitionByChain}
+		assert.Equal(t, "1", event.PartitionKey())
+	})
+
+	t.Run("falls back when the field is missing", func(t *testing.T) {
+		assert.Equal(t, "728126428", (&ChainEvent{ChainID: 728126428, EventType: EventTypeChainHalted}).PartitionKey())
+		assert.Equal(t, "0xdac17f958d2ee523a2206206994597c13d831ec7",
+			(&ChainEvent{ChainID: 1, TokenAddress: "0xdAC17F958D2ee523a2206206994597C13D831ec7"}).PartitionKey())
+	})
+}
+
+func TestTronWatcher_WatchedAddressPartition(t *testing.T) {
+	client := newFakeTronClient(200)
+	token, _ := testTronAddress(0xaa)
+	from, fromAddr := testTronAddress(0x11)
+	to, toAddr := testTronAddress(0x22)
+	client.addTransfer(190, "a1", token, from, to, big.NewInt(1))
+	client.addTransfer(190, "a2", token, to, from, big.NewInt(2))
+
+	w := newTestTronWatcher(client)
+	w.AddTronAddress(toAddr)
+
+	var mu sync.Mutex
+	var got []*ChainEvent
+	w.dispatch.addHandler(func(event *ChainEvent) error {
+		mu.Lock()
+		defer mu.Unlock()
+		got = append(got, event)
+		return nil
+	})
+	collectTronTxs(t, w, 190, 200) // waits for delivery
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	require.Len(t, got, 2)
+	for _, event := range got {
+		assert.Equal(t, toAddr, event.PartitionKey(), "Base58 keys are kept as-is")
+		assert.NotEqual(t, fromAddr, event.WatchedAddress)
+	}
+}
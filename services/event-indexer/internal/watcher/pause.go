@@ -0,0 +1,41 @@
+package watcher
+
+import (
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+)
+
+// PauseChain stops processing on a single chain while the others keep
+// running, e.g. when only that chain's RPC is misbehaving. The chain's
+// checkpoint holds during the pause, so ResumeChain picks up at the first
+// block that wasn't processed. A block already in progress finishes.
+func (mcw *MultiChainWatcher) PauseChain(chainID uint64) error {
+	return mcw.setChainPaused(chainID, true)
+}
+
+// ResumeChain resumes a chain paused with PauseChain.
+func (mcw *MultiChainWatcher) ResumeChain(chainID uint64) error {
+	return mcw.setChainPaused(chainID, false)
+}
+
+func (mcw *MultiChainWatcher) setChainPaused(chainID uint64, paused bool) error {
+	watchers, tronWatchers := mcw.chainWatchers()
+	var name string
+	if w, ok := watchers[chainID]; ok {
+		w.paused.Store(paused)
+		name = w.chainName
+	} else if tw, ok := tronWatchers[chainID]; ok {
+		tw.paused.Store(paused)
+		name = tw.chainName
+	} else {
+		return fmt.Errorf("unknown chain %d", chainID)
+	}
+
+	if paused {
+		log.Warn().Str("chain", name).Msg("Chain watcher paused")
+	} else {
+		log.Info().Str("chain", name).Msg("Chain watcher resumed")
+	}
+	return nil
+}
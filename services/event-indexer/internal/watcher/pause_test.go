@@ -0,0 +1,75 @@
+package watcher
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMultiChainWatcher_PauseChain(t *testing.T) {
+	evmClient := newFakeEVMClient(1000)
+	watched := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	evmClient.addLog(testTransferLog(1001, 0, common.HexToAddress("0x1111111111111111111111111111111111111111"), watched, big.NewInt(1)))
+	evm := newTestChainWatcher(t, evmClient)
+	evm.AddAddress(watched)
+
+	tronClient := newFakeTronClient(200)
+	token, _ := testTronAddress(0xaa)
+	from, _ := testTronAddress(0x11)
+	to, toAddr := testTronAddress(0x22)
+	tronClient.addTransfer(201, "a201", token, from, to, big.NewInt(1))
+	tron := newTestTronWatcher(tronClient)
+	tron.AddTronAddress(toAddr)
+
+	// Both watchers share the orchestrator's dispatcher
+	mcw := &MultiChainWatcher{
+		watchers:     map[uint64]*ChainWatcher{evm.chainID: evm},
+		tronWatchers: map[uint64]*TronWatcher{tron.chainID: tron},
+		dispatch:     evm.dispatch,
+	}
+	tron.dispatch = mcw.dispatch
+
+	var mu sync.Mutex
+	emitted := make(map[uint64][]string)
+	mcw.AddHandler(func(event *ChainEvent) error {
+		mu.Lock()
+		defer mu.Unlock()
+		emitted[event.ChainID] = append(emitted[event.ChainID], event.TxHash)
+		return nil
+	})
+
+	ctx := context.Background()
+	tron.poll(ctx) // establishes the checkpoint at 200
+
+	require.NoError(t, mcw.PauseChain(tron.chainID))
+	assert.True(t, mcw.Health()[tron.chainID].Paused)
+
+	evmClient.head = 1001
+	tronClient.setHeadBlock(testTronHeader(201))
+	assert.Equal(t, uint64(1001), evm.poll(ctx, 1000))
+	tron.poll(ctx)
+	evm.gate.inflight.Wait()
+	tron.gate.inflight.Wait()
+
+	mu.Lock()
+	assert.Len(t, emitted[evm.chainID], 1, "other chain keeps emitting")
+	assert.Empty(t, emitted[tron.chainID], "paused chain emits nothing")
+	mu.Unlock()
+	assert.Equal(t, int64(200), tron.lastBlock, "checkpoint holds while paused")
+
+	require.NoError(t, mcw.ResumeChain(tron.chainID))
+	tron.poll(ctx)
+	tron.gate.inflight.Wait()
+
+	mu.Lock()
+	assert.Equal(t, []string{"a201"}, emitted[tron.chainID], "resumes from the held checkpoint")
+	mu.Unlock()
+	assert.False(t, mcw.Health()[tron.chainID].Paused)
+
+	assert.Error(t, mcw.PauseChain(999999))
+}
@@ -0,0 +1,58 @@
+package watcher
+
+import (
+	"fmt"
+	"sync"
+)
+
+// eventID identifies a log-derived event: chain, transaction and log index.
+// Later phases of the same event (confirmed, milestones) share it.
+func eventID(chainID uint64, txHash string, logIndex uint) string {
+	return fmt.Sprintf("%d:%s:%d", chainID, txHash, logIndex)
+}
+
+// phaseSequencer orders deliveries that share an event ID, per handler.
+// Dispatch is concurrent, so without it a confirmed (or milestone) copy of
+// an event could reach a handler while the pending delivery of the same
+// event is still running or retrying. Each delivery waits for the previous
+// delivery of the same event to the same handler, so phases arrive in the
+// order they were dispatched: pending before confirmed.
+type phaseSequencer struct {
+	mu    sync.Mutex
+	tails map[phaseKey]chan struct{} // done channel of the latest delivery
+}
+
+type phaseKey struct {
+	eventID string
+	handler int // index into dispatcher.handlers, which only grows
+}
+
+func newPhaseSequencer() *phaseSequencer {
+	return &phaseSequencer{tails: make(map[phaseKey]chan struct{})}
+}
+
+// enqueue must be called in dispatch order. The returned channel, if not
+// nil, closes when the previous delivery for the key is done; done marks
+// this delivery finished.
+func (s *phaseSequencer) enqueue(eventID string, handler int) (<-chan struct{}, func()) {
+	if s == nil || eventID == "" {
+		return nil, func() {}
+	}
+
+	key := phaseKey{eventID: eventID, handler: handler}
+	ch := make(chan struct{})
+
+	s.mu.Lock()
+	prev := s.tails[key]
+	s.tails[key] = ch
+	s.mu.Unlock()
+
+	return prev, func() {
+		close(ch)
+		s.mu.Lock()
+		if s.tails[key] == ch {
+			delete(s.tails, key)
+		}
+		s.mu.Unlock()
+	}
+}
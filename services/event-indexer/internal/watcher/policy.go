@@ -0,0 +1,173 @@
+package watcher
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/protocol-bank/event-indexer/internal/config"
+	"github.com/rs/zerolog/log"
+)
+
+// TokenPolicyStore loads the per-token policies, e.g. from Postgres.
+type TokenPolicyStore interface {
+	LoadTokenPolicies(ctx context.Context) ([]config.TokenPolicy, error)
+}
+
+// policyTransferTypes are the event types token policies apply to; the
+// fungible ones are also subject to MinAmount.
+var policyTransferTypes = map[string]bool{
+	"transfer":              true,
+	"trc20_transfer":        true,
+	EventTypeTRC10Transfer:  true,
+	EventTypeERC721Transfer: false,
+	EventTypeERC1155Single:  false,
+	EventTypeERC1155Batch:   false,
+}
+
+type tokenPolicyKey struct {
+	chainID uint64
+	token   string
+}
+
+// tokenPolicies applies per-token policies to transfers as they are
+// dispatched: disabled tokens, tokens off a chain's allowlist and
+// transfers below a token's minimum amount are dropped. A token's
+// confirmation requirement holds back the Confirmed flag at detection and
+// is the transfer's required depth in the confirmation tracker, which
+// emits it Confirmed once it has that many. Policies can only raise the
+// chain's requirement. Tokens without a policy pass unchanged unless their
+// chain has an allowlist. A nil tokenPolicies lets everything through.
+type tokenPolicies struct {
+	mu          sync.RWMutex
+	byToken     map[tokenPolicyKey]config.TokenPolicy
+	allowlisted map[uint64]bool // chains with at least one allowlisted token
+}
+
+func newTokenPolicies() *tokenPolicies {
+	return &tokenPolicies{
+		byToken:     make(map[tokenPolicyKey]config.TokenPolicy),
+		allowlisted: make(map[uint64]bool),
+	}
+}
+
+// policyToken normalizes a token for lookups: hex addresses compare
+// case-insensitively, Base58 addresses and asset IDs as given.
+func policyToken(token string) string {
+	token = strings.TrimSpace(token)
+	if strings.HasPrefix(token, "0x") || strings.HasPrefix(token, "0X") {
+		return strings.ToLower(token)
+	}
+	return token
+}
+
+// set replaces every policy.
+func (p *tokenPolicies) set(policies []config.TokenPolicy) {
+	byToken := make(map[tokenPolicyKey]config.TokenPolicy, len(policies))
+	allowlisted := make(map[uint64]bool)
+	for _, policy := range policies {
+		byToken[tokenPolicyKey{policy.ChainID, policyToken(policy.Token)}] = policy
+		if policy.Allowlisted {
+			allowlisted[policy.ChainID] = true
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.byToken = byToken
+	p.allowlisted = allowlisted
+}
+
+// lookup returns the policy governing event, if any, and whether its
+// chain has an allowlist.
+func (p *tokenPolicies) lookup(event *ChainEvent) (policy config.TokenPolicy, ok, allowlist bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	policy, ok = p.byToken[tokenPolicyKey{event.ChainID, policyToken(event.TokenAddress)}]
+	return policy, ok, p.allowlisted[event.ChainID]
+}
+
+// confirmations returns the confirmations event's token policy requires,
+// 0 if none governs it.
+func (p *tokenPolicies) confirmations(event *ChainEvent) uint64 {
+	if _, governed := policyTransferTypes[event.EventType]; p == nil || !governed {
+		return 0
+	}
+	policy, ok, _ := p.lookup(event)
+	if !ok {
+		return 0
+	}
+	return policy.Confirmations
+}
+
+// holdConfirmed clears Confirmed on a just detected event that is short of
+// its token's confirmation requirement.
+func (p *tokenPolicies) holdConfirmed(event *ChainEvent) {
+	if event.Confirmed && event.Confirmations < p.confirmations(event) {
+		event.Confirmed = false
+		event.FinalityStatus = FinalityPending
+	}
+}
+
+// admit reports whether event may be emitted.
+func (p *tokenPolicies) admit(event *ChainEvent) bool {
+	fungible, governed := policyTransferTypes[event.EventType]
+	if p == nil || !governed {
+		return true
+	}
+
+	policy, ok, allowlist := p.lookup(event)
+	if !ok {
+		return !allowlist
+	}
+	if !policy.Enabled || (allowlist && !policy.Allowlisted) {
+		return false
+	}
+	if fungible && policy.MinAmount != nil {
+		if value, valid := new(big.Int).SetString(event.Value, 10); valid && value.Cmp(policy.MinAmount) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// SetTokenPolicyStore loads the token policies from store, which Start then
+// refreshes every interval. Must be called before Start.
+func (mcw *MultiChainWatcher) SetTokenPolicyStore(ctx context.Context, store TokenPolicyStore, interval time.Duration) error {
+	mcw.tokenPolicyStore = store
+	mcw.tokenPolicyInterval = interval
+	mcw.dispatch.tokenPolicies = newTokenPolicies()
+	return mcw.RefreshTokenPolicies(ctx)
+}
+
+// RefreshTokenPolicies reloads the token policies. On failure the previous
+// ones stay in effect.
+func (mcw *MultiChainWatcher) RefreshTokenPolicies(ctx context.Context) error {
+	policies, err := mcw.tokenPolicyStore.LoadTokenPolicies(ctx)
+	if err != nil {
+		return err
+	}
+	mcw.dispatch.tokenPolicies.set(policies)
+	log.Debug().Int("policies", len(policies)).Msg("Token policies loaded")
+	return nil
+}
+
+// runTokenPolicyRefresh reloads the token policies every interval until ctx
+// is cancelled.
+func (mcw *MultiChainWatcher) runTokenPolicyRefresh(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := mcw.RefreshTokenPolicies(ctx); err != nil {
+				log.Warn().Err(err).Msg("Failed to refresh token policies, keeping the previous ones")
+			}
+		}
+	}
+}
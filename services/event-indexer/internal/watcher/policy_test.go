@@ -0,0 +1,90 @@
+package watcher
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/protocol-bank/event-indexer/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMultiChainWatcher_TokenPolicyRefresh(t *testing.T) {
+	const token = "0xdac17f958d2ee523a2206206994597c13d831ec7" // testTransferLog's token, lowercased
+	columns := []string{"chain_id", "token_address", "min_amount", "confirmations", "enabled", "allowlisted"}
+
+	client := newFakeEVMClient(1010)
+	watched := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	from := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	client.addLog(testTransferLog(990, 0, from, watched, big.NewInt(5)))
+	client.addLog(testTransferLog(990, 1, from, watched, big.NewInt(500)))
+
+	w := newTestChainWatcher(t, client)
+	w.AddAddress(watched)
+	mcw := &MultiChainWatcher{watchers: map[uint64]*ChainWatcher{1: w}, dispatch: w.dispatch}
+
+	var mu sync.Mutex
+	var events []*ChainEvent
+	w.dispatch.addHandler(func(event *ChainEvent) error {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, event)
+		return nil
+	})
+	process := func() []*ChainEvent {
+		events = nil
+		w.gate.enter()
+		w.processBlock(context.Background(), 990, 1010)
+		w.gate.leave()
+		w.gate.inflight.Wait()
+		return events
+	}
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+	policies := store.NewPostgresFromDB(db)
+
+	// Minimum amount and a deeper confirmation tier
+	mock.ExpectQuery("SELECT (.+) FROM token_policies").WillReturnRows(sqlmock.NewRows(columns).
+		AddRow(1, token, "100", 30, true, false))
+	require.NoError(t, mcw.SetTokenPolicyStore(context.Background(), policies, 0))
+	got := process()
+	require.Len(t, got, 1, "the transfer below the minimum is dropped")
+	assert.Equal(t, "500", got[0].Value)
+	assert.False(t, got[0].Confirmed, "20 confirmations are short of the token's 30")
+	assert.Equal(t, FinalityPending, got[0].FinalityStatus)
+
+	// Disabled
+	mock.ExpectQuery("SELECT (.+) FROM token_policies").WillReturnRows(sqlmock.NewRows(columns).
+		AddRow(1, token, "100", 30, false, false))
+	require.NoError(t, mcw.RefreshTokenPolicies(context.Background()))
+	assert.Empty(t, process())
+
+	// A failed refresh keeps the previous policies
+	mock.ExpectQuery("SELECT (.+) FROM token_policies").WillReturnError(errors.New("connection reset"))
+	assert.Error(t, mcw.RefreshTokenPolicies(context.Background()))
+	assert.Empty(t, process())
+
+	// Another token allowlisted: this one is off the chain's allowlist
+	mock.ExpectQuery("SELECT (.+) FROM token_policies").WillReturnRows(sqlmock.NewRows(columns).
+		AddRow(1, "0x4444444444444444444444444444444444444444", nil, 0, true, true))
+	require.NoError(t, mcw.RefreshTokenPolicies(context.Background()))
+	assert.Empty(t, process())
+
+	// Policy removed: both transfers, confirmed at the chain's requirement
+	mock.ExpectQuery("SELECT (.+) FROM token_policies").WillReturnRows(sqlmock.NewRows(columns))
+	require.NoError(t, mcw.RefreshTokenPolicies(context.Background()))
+	got = process()
+	require.Len(t, got, 2)
+	for _, event := range got {
+		assert.True(t, event.Confirmed)
+	}
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
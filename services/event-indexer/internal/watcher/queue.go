@@ -0,0 +1,90 @@
+package watcher
+
+import (
+	"time"
+
+	"github.com/protocol-bank/event-indexer/internal/config"
+	"github.com/rs/zerolog/log"
+)
+
+// defaultDispatchQueueSize is the queue capacity used when none is set.
+const defaultDispatchQueueSize = 10000
+
+// dispatchQueue runs handler deliveries on a fixed pool of workers, shared by
+// every chain of a MultiChainWatcher, instead of a goroutine per delivery.
+// A full queue blocks the dispatching chain for up to timeout, then drops
+// the delivery, so a stalled handler applies backpressure without growing
+// memory without bound.
+type dispatchQueue struct {
+	jobs    chan queuedDelivery
+	timeout time.Duration
+}
+
+type queuedDelivery struct {
+	gate *drainGate
+	run  func()
+}
+
+// dispatchWorkers is the configured worker count. With a handler cap but no
+// workers, the cap sizes the pool, so deliveries waiting for a slot don't
+// each hold a goroutine.
+func dispatchWorkers(cfg *config.Config) int {
+	if cfg.DispatchWorkers <= 0 && cfg.HandlerConcurrency > 0 {
+		return cfg.HandlerConcurrency
+	}
+	return cfg.DispatchWorkers
+}
+
+// newDispatchQueue starts workers draining a queue of size deliveries. It
+// returns nil, a goroutine per delivery, for a non-positive worker count.
+func newDispatchQueue(workers, size int, timeout time.Duration) *dispatchQueue {
+	if workers <= 0 {
+		return nil
+	}
+	if size <= 0 {
+		size = defaultDispatchQueueSize
+	}
+	q := &dispatchQueue{jobs: make(chan queuedDelivery, size), timeout: timeout}
+	for range workers {
+		go q.work()
+	}
+	return q
+}
+
+func (q *dispatchQueue) work() {
+	for job := range q.jobs {
+		job.run()
+		job.gate.inflight.Done()
+	}
+}
+
+// submit queues run for event, counting it in flight on gate until it
+// finishes so draining waits for queued deliveries too. If the queue stays
+// full for the timeout the delivery is dropped: it is logged and counted,
+// and dropped is called in place of run.
+func (q *dispatchQueue) submit(gate *drainGate, event *ChainEvent, run, dropped func()) {
+	if q == nil {
+		gate.spawn(run)
+		return
+	}
+
+	gate.inflight.Add(1)
+	job := queuedDelivery{gate: gate, run: run}
+	select {
+	case q.jobs <- job:
+		return
+	default:
+	}
+
+	timer := time.NewTimer(q.timeout)
+	defer timer.Stop()
+	select {
+	case q.jobs <- job:
+	case <-timer.C:
+		gate.inflight.Done()
+		eventsDropped.WithLabelValues(event.ChainName, event.EventType).Inc()
+		log.Warn().Str("chain", event.ChainName).Str("event_id", event.EventID).Str("event_type", event.EventType).
+			Int("queue_size", cap(q.jobs)).Dur("waited", q.timeout).Msg("Dispatch queue full, dropping event delivery")
+		dropped()
+	}
+}
@@ -0,0 +1,124 @@
+package watcher
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/protocol-bank/event-indexer/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDispatcher_BoundedQueue(t *testing.T) {
+	const workers = 2
+
+	d := newDispatcher(nil, config.RetryPolicy{MaxAttempts: 1})
+	d.queue = newDispatchQueue(workers, 2, 10*time.Millisecond)
+
+	var mu sync.Mutex
+	running, peak := 0, 0
+	var delivered atomic.Int32
+	release := make(chan struct{})
+	d.addHandler(func(event *ChainEvent) error {
+		mu.Lock()
+		running++
+		peak = max(peak, running)
+		mu.Unlock()
+
+		<-release
+
+		mu.Lock()
+		running--
+		mu.Unlock()
+		delivered.Add(1)
+		return nil
+	})
+
+	dropped := testutil.ToFloat64(eventsDropped.WithLabelValues("Queue Test", "transfer"))
+
+	var gate drainGate
+	gate.enter()
+	// Fill both workers before queueing more
+	for i := 0; i < workers; i++ {
+		d.dispatch(&gate, &ChainEvent{ChainID: 1, ChainName: "Queue Test", EventType: "transfer"})
+	}
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return running == workers
+	}, time.Second, time.Millisecond)
+
+	// Two fit in the queue, the third waits out the timeout and is dropped
+	for i := 0; i < 3; i++ {
+		d.dispatch(&gate, &ChainEvent{ChainID: 1, ChainName: "Queue Test", EventType: "transfer"})
+	}
+	gate.leave()
+	assert.Equal(t, dropped+1, testutil.ToFloat64(eventsDropped.WithLabelValues("Queue Test", "transfer")))
+
+	close(release)
+	gate.inflight.Wait()
+	assert.Equal(t, int32(4), delivered.Load(), "queued deliveries run before draining completes")
+	assert.Equal(t, workers, peak, "handlers run concurrently up to the worker count")
+}
+
+func TestDispatcher_DroppedDeliveryReleasesLaterPhases(t *testing.T) {
+	d := newDispatcher(nil, config.RetryPolicy{MaxAttempts: 1})
+	d.phases = newPhaseSequencer()
+	d.queue = newDispatchQueue(1, 1, 10*time.Millisecond)
+
+	release := make(chan struct{})
+	var delivered sync.Map
+	d.addHandler(func(event *ChainEvent) error {
+		if event.EventID == "blocker" {
+			<-release
+		}
+		key := event.EventID
+		if event.Confirmed {
+			key += "/confirmed"
+		}
+		delivered.Store(key, true)
+		return nil
+	})
+
+	var gate drainGate
+	gate.enter()
+	d.dispatch(&gate, &ChainEvent{EventID: "blocker"})
+	assert.Eventually(t, func() bool { return len(d.queue.jobs) == 0 }, time.Second, time.Millisecond)
+	d.dispatch(&gate, &ChainEvent{EventID: "other"})
+	// Queue full: the pending phase of "e" is dropped
+	d.dispatch(&gate, &ChainEvent{EventID: "e"})
+	gate.leave()
+	close(release)
+	assert.Eventually(t, func() bool {
+		_, ok := delivered.Load("other")
+		return ok
+	}, time.Second, time.Millisecond)
+
+	// The confirmed phase doesn't wait forever on the dropped one
+	gate.enter()
+	d.dispatch(&gate, &ChainEvent{EventID: "e", Confirmed: true})
+	gate.leave()
+
+	done := make(chan struct{})
+	go func() {
+		gate.inflight.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("delivery after a dropped phase never ran")
+	}
+	_, ok := delivered.Load("e/confirmed")
+	assert.True(t, ok)
+	_, ok = delivered.Load("e")
+	assert.False(t, ok, "the dropped phase is never delivered")
+}
+
+func TestDispatchWorkers(t *testing.T) {
+	assert.Equal(t, 64, dispatchWorkers(&config.Config{DispatchWorkers: 64, HandlerConcurrency: 8}))
+	assert.Equal(t, 8, dispatchWorkers(&config.Config{HandlerConcurrency: 8}), "the handler cap sizes the pool")
+	assert.Equal(t, 0, dispatchWorkers(&config.Config{}), "unlimited: a goroutine per delivery")
+}
@@ -0,0 +1,92 @@
+package watcher
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"golang.org/x/sync/semaphore"
+)
+
+// defaultConfirmationCheckConcurrency bounds concurrent chain re-checks when
+// the configured limit is unset.
+const defaultConfirmationCheckConcurrency = 4
+
+// runConfirmationChecks re-checks every chain's pending confirmations each
+// interval, on its own schedule rather than inline with block polling, so a
+// chain with a large pending queue or a slow poll doesn't hold up another
+// chain's milestones. Chains are independent, so they're checked in parallel.
+func (mcw *MultiChainWatcher) runConfirmationChecks(ctx context.Context, interval time.Duration, limit int) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			mcw.checkConfirmations(ctx, limit)
+		}
+	}
+}
+
+// checkConfirmations runs one re-check pass over all chains, up to limit at
+// a time, and returns when every chain is done.
+func (mcw *MultiChainWatcher) checkConfirmations(ctx context.Context, limit int) {
+	watchers, tronWatchers := mcw.chainWatchers()
+	checks := make([]func(), 0, len(watchers)+len(tronWatchers))
+	for _, w := range watchers {
+		checks = append(checks, w.recheckConfirmations)
+	}
+	for _, tw := range tronWatchers {
+		checks = append(checks, tw.recheckConfirmations)
+	}
+	runBounded(ctx, limit, checks)
+}
+
+// runBounded runs fns concurrently, at most limit at once (<= 0 = default).
+func runBounded(ctx context.Context, limit int, fns []func()) {
+	if limit <= 0 {
+		limit = defaultConfirmationCheckConcurrency
+	}
+	sem := semaphore.NewWeighted(int64(limit))
+
+	var wg sync.WaitGroup
+	for _, fn := range fns {
+		if err := sem.Acquire(ctx, 1); err != nil {
+			break
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer sem.Release(1)
+			fn()
+		}()
+	}
+	wg.Wait()
+}
+
+// recheckConfirmations emits milestones crossed at the latest observed head.
+func (w *ChainWatcher) recheckConfirmations() {
+	head := w.health.snapshot().Head
+	if head == 0 || w.paused.Load() || !w.gate.enter() {
+		return
+	}
+	defer w.gate.leave()
+	defer recoverPanic(w.cfg.RecoverPanics, w.chainName, "confirmation check")
+	w.emitMilestones(head)
+	log.Debug().Str("chain", w.chainName).Uint64("head", head).Msg("Confirmations re-checked")
+}
+
+// recheckConfirmations emits milestones crossed at the latest observed head.
+func (w *TronWatcher) recheckConfirmations() {
+	head := w.health.snapshot().Head
+	if head == 0 || w.paused.Load() || !w.gate.enter() {
+		return
+	}
+	defer w.gate.leave()
+	defer recoverPanic(w.cfg.RecoverPanics, w.chainName, "confirmation check")
+	w.emitMilestones(int64(head))
+	log.Debug().Str("chain", w.chainName).Uint64("head", head).Msg("Confirmations re-checked")
+}
@@ -0,0 +1,92 @@
+package watcher
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMultiChainWatcher_ConcurrentConfirmationChecks(t *testing.T) {
+	evm := newTestChainWatcher(t, newFakeEVMClient(1000))
+	tron := newTestTronWatcher(newFakeTronClient(200))
+	mcw := &MultiChainWatcher{
+		watchers:     map[uint64]*ChainWatcher{evm.chainID: evm},
+		tronWatchers: map[uint64]*TronWatcher{tron.chainID: tron},
+		dispatch:     evm.dispatch,
+	}
+	tron.dispatch = mcw.dispatch
+
+	evm.milestones = newConfirmationTracker([]uint64{6}, 12, 0, false)
+	evm.milestones.track(&ChainEvent{ChainID: evm.chainID, TxHash: "evm", BlockNumber: 990})
+	evm.trackHead(1000)
+	tron.milestones = newConfirmationTracker([]uint64{6}, 19, 0, false)
+	tron.milestones.track(&ChainEvent{ChainID: tron.chainID, TxHash: "tron", BlockNumber: 190})
+	tron.trackHead(200)
+
+	emitted := make(chan string, 2)
+	mcw.AddHandler(func(event *ChainEvent) error {
+		emitted <- event.TxHash
+		return nil
+	})
+
+	// Hold up the EVM chain's check, as a huge pending queue would
+	evm.milestones.mu.Lock()
+	done := make(chan struct{})
+	go func() {
+		mcw.checkConfirmations(context.Background(), 2)
+		close(done)
+	}()
+
+	select {
+	case tx := <-emitted:
+		assert.Equal(t, "tron", tx, "TRON confirmations don't wait for the EVM chain")
+	case <-time.After(2 * time.Second):
+		t.Fatal("TRON check blocked behind the EVM chain")
+	}
+
+	evm.milestones.mu.Unlock()
+	<-done
+	assert.Equal(t, "evm", <-emitted)
+}
+
+func TestRunBounded(t *testing.T) {
+	var mu sync.Mutex
+	running, peak := 0, 0
+	fns := make([]func(), 6)
+	for i := range fns {
+		fns[i] = func() {
+			mu.Lock()
+			running++
+			peak = max(peak, running)
+			mu.Unlock()
+
+			time.Sleep(10 * time.Millisecond)
+
+			mu.Lock()
+			running--
+			mu.Unlock()
+		}
+	}
+
+	runBounded(context.Background(), 2, fns)
+	require.Zero(t, running, "returns once every check is done")
+	assert.Equal(t, 2, peak)
+}
+
+func TestChainWatcher_SeparateConfirmationChecks(t *testing.T) {
+	client := newFakeEVMClient(1000)
+	w := newTestChainWatcher(t, client)
+	w.separateConfirmationChecks = true
+	w.milestones = newConfirmationTracker([]uint64{6}, 12, 0, false)
+	w.milestones.track(&ChainEvent{TxHash: "a", BlockNumber: 990})
+
+	w.poll(context.Background(), 999)
+	assert.Len(t, w.milestones.pending, 1, "polling leaves milestones to the cross-chain check")
+
+	w.recheckConfirmations()
+	assert.Empty(t, w.milestones.pending)
+}
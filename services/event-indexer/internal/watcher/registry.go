@@ -0,0 +1,39 @@
+package watcher
+
+import (
+	"math/big"
+
+	"github.com/protocol-bank/event-indexer/internal/config"
+)
+
+// tokenRegistry is a chain's configured token symbols and decimals, keyed
+// like token policies: hex addresses lowercased, Base58 addresses and
+// TRC-10 asset IDs as given. A nil registry knows no tokens.
+type tokenRegistry map[string]config.TokenInfo
+
+func newTokenRegistry(tokens map[string]config.TokenInfo) tokenRegistry {
+	if len(tokens) == 0 {
+		return nil
+	}
+	r := make(tokenRegistry, len(tokens))
+	for token, info := range tokens {
+		r[policyToken(token)] = info
+	}
+	return r
+}
+
+func (r tokenRegistry) lookup(token string) (config.TokenInfo, bool) {
+	info, ok := r[policyToken(token)]
+	return info, ok
+}
+
+// annotate sets the symbol and normalized value of a transfer of a
+// registered token. Unknown tokens are left as they are.
+func (r tokenRegistry) annotate(event *ChainEvent, value *big.Int) {
+	info, ok := r.lookup(event.TokenAddress)
+	if !ok {
+		return
+	}
+	event.TokenSymbol = info.Symbol
+	event.NormalizedValue = FormatAmount(value, info.Decimals)
+}
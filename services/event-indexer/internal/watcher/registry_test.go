@@ -0,0 +1,73 @@
+package watcher
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/protocol-bank/event-indexer/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChainWatcher_TokenRegistry(t *testing.T) {
+	client := newFakeEVMClient(1020)
+	watched := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	from := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	client.addLog(testTransferLog(1000, 0, from, watched, big.NewInt(1_500_000)))
+
+	w := newTestChainWatcher(t, client)
+	w.tokens = newTokenRegistry(map[string]config.TokenInfo{
+		"0xdAC17F958D2ee523a2206206994597C13D831ec7": {Symbol: "USDT", Decimals: 6},
+	})
+	w.AddAddress(watched)
+
+	events := collectEVMEvents(t, w, 1000, 1020)
+	require.Len(t, events, 1)
+	assert.Equal(t, "USDT", events[0].TokenSymbol)
+	assert.Equal(t, "1.5", events[0].NormalizedValue)
+	assert.Equal(t, "1500000", events[0].Value)
+}
+
+func TestChainWatcher_TokenRegistryUnknownToken(t *testing.T) {
+	client := newFakeEVMClient(1020)
+	watched := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	from := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	client.addLog(testTransferLog(1000, 0, from, watched, big.NewInt(1_500_000)))
+
+	w := newTestChainWatcher(t, client)
+	w.tokens = newTokenRegistry(map[string]config.TokenInfo{
+		"0x0000000000000000000000000000000000000001": {Symbol: "OTHER", Decimals: 18},
+	})
+	w.AddAddress(watched)
+
+	events := collectEVMEvents(t, w, 1000, 1020)
+	require.Len(t, events, 1)
+	assert.Empty(t, events[0].TokenSymbol)
+	assert.Empty(t, events[0].NormalizedValue)
+}
+
+func TestTronWatcher_TokenRegistry(t *testing.T) {
+	client := newFakeTronClient(200)
+	token, _ := testTronAddress(0xaa)
+	from, _ := testTronAddress(0x11)
+	to, toAddr := testTronAddress(0x22)
+	client.addTransfer(190, "a190", token, from, to, big.NewInt(2_000_000))
+
+	w := newTestTronWatcher(client)
+	w.tokens = newTokenRegistry(map[string]config.TokenInfo{
+		rawBytesToTronAddress(token, tronMainnetPrefix): {Symbol: "USDT", Decimals: 6},
+	})
+	w.AddTronAddress(toAddr)
+
+	var got []*ChainEvent
+	w.dispatch.addHandler(func(event *ChainEvent) error {
+		got = append(got, event)
+		return nil
+	})
+	collectTronTxs(t, w, 190, 200)
+
+	require.Len(t, got, 1)
+	assert.Equal(t, "USDT", got[0].TokenSymbol)
+	assert.Equal(t, "2", got[0].NormalizedValue)
+}
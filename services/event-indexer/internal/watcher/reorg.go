@@ -0,0 +1,233 @@
+package watcher
+
+import (
+	"runtime/debug"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// defaultReorgHistorySize bounds the reorg history when none is configured.
+const defaultReorgHistorySize = 50
+
+// ReorgInfo records a detected chain reorganization.
+type ReorgInfo struct {
+	ChainID    uint64
+	FromBlock  uint64 // first replaced block
+	ToBlock    uint64 // last replaced block
+	OldHash    string // hash we had processed at ToBlock
+	NewHash    string // hash the canonical chain now has at ToBlock
+	DetectedAt time.Time
+
+	// Deep is set when no common ancestor was found within the tracked
+	// block hashes: the fork is at or below FromBlock
+	Deep bool
+}
+
+// ReorgEvent notifies reorg handlers that blocks from FromBlock on were
+// replaced, so consumers can invalidate cached confirmations.
+type ReorgEvent struct {
+	ChainID       uint64
+	FromBlock     uint64 // first replaced block
+	DepthEstimate uint64 // number of replaced blocks
+	OldHash       string // hash we had processed at the last replaced block
+	NewHash       string // hash the canonical chain now has there
+	Deep          bool   // fork is older than the tracked block hashes
+}
+
+// ReorgHandler is called once per detected reorg.
+type ReorgHandler func(event ReorgEvent)
+
+func newReorgEvent(info ReorgInfo) ReorgEvent {
+	return ReorgEvent{
+		ChainID:       info.ChainID,
+		FromBlock:     info.FromBlock,
+		DepthEstimate: info.ToBlock - info.FromBlock + 1,
+		OldHash:       info.OldHash,
+		NewHash:       info.NewHash,
+		Deep:          info.Deep,
+	}
+}
+
+// addReorgHandler registers a handler for all subsequent reorgs.
+func (d *dispatcher) addReorgHandler(handler ReorgHandler) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.reorgHandlers = append(d.reorgHandlers, handler)
+}
+
+// dispatchReorg delivers event to every reorg handler concurrently, on the
+// gate so lame duck shutdown waits for them. A panicking handler is logged
+// and counted; reorg notifications aren't retried.
+func (d *dispatcher) dispatchReorg(gate *drainGate, event ReorgEvent) {
+	d.mu.RLock()
+	handlers := append([]ReorgHandler(nil), d.reorgHandlers...)
+	d.mu.RUnlock()
+
+	for _, handler := range handlers {
+		gate.spawn(func() {
+			defer func() {
+				if r := recover(); r != nil {
+					recoveredPanics.Add(1)
+					log.Error().
+						Interface("panic", r).
+						Uint64("chain_id", event.ChainID).
+						Uint64("from_block", event.FromBlock).
+						Bytes("stack", debug.Stack()).
+						Msg("Reorg handler panicked")
+				}
+			}()
+			handler(event)
+		})
+	}
+}
+
+// detectReorg reports whether a block's parent hash no longer matches the
+// hash recorded for the previous height.
+func detectReorg(previousHash, currentParent string) bool {
+	return previousHash != currentParent
+}
+
+// reorgTracker remembers the hashes of the last processed blocks to detect
+// reorgs and find where they forked, and keeps a bounded, oldest-first
+// history of the ones it found.
+type reorgTracker struct {
+	mu        sync.Mutex
+	chainID   uint64
+	chainName string
+	size      int
+	window    int // block hashes kept to find a common ancestor
+
+	blocks  []trackedBlock // consecutive processed blocks, oldest first
+	history []ReorgInfo
+}
+
+// trackedBlock is a processed block's height and hash.
+type trackedBlock struct {
+	number uint64
+	hash   string
+}
+
+// parentLookup returns the parent hash of the canonical block at number.
+type parentLookup func(number uint64) (string, error)
+
+func newReorgTracker(chainID uint64, chainName string, size, window int) *reorgTracker {
+	if size <= 0 {
+		size = defaultReorgHistorySize
+	}
+	return &reorgTracker{chainID: chainID, chainName: chainName, size: size, window: max(window, 1)}
+}
+
+// findCommonAncestor returns the height of the tracked block with hash. A
+// canonical hash found there marks where a reorg forked off.
+func (r *reorgTracker) findCommonAncestor(hash string) (uint64, bool) {
+	for i := len(r.blocks) - 1; i >= 0; i-- {
+		if r.blocks[i].hash == hash {
+			return r.blocks[i].number, true
+		}
+	}
+	return 0, false
+}
+
+// observe records a processed block and returns the reorg it reveals, if
+// any. Only consecutive blocks can be compared; gaps reset the baseline.
+// On a reorg, parentOf walks the canonical chain back until it meets a
+// tracked hash; if none of the tracked blocks is canonical the reorg is
+// flagged Deep. Without parentOf, or if it fails, only the blocks known to be
+// replaced are reported.
+func (r *reorgTracker) observe(number uint64, hash, parentHash string, parentOf parentLookup) *ReorgInfo {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var reorg *ReorgInfo
+	n := len(r.blocks)
+	switch {
+	case n > 0 && number != r.blocks[n-1].number+1:
+		r.blocks = r.blocks[:0]
+	case n > 0 && detectReorg(r.blocks[n-1].hash, parentHash):
+		last := r.blocks[n-1]
+		reorg = &ReorgInfo{
+			ChainID:    r.chainID,
+			ToBlock:    last.number,
+			OldHash:    last.hash,
+			NewHash:    parentHash,
+			DetectedAt: time.Now(),
+		}
+		canonical, deep := r.forkPoint(last.number, parentHash, parentOf)
+		reorg.FromBlock, reorg.Deep = canonical[0].number, deep
+		r.history = append(r.history, *reorg)
+		if len(r.history) > r.size {
+			r.history = append(r.history[:0], r.history[len(r.history)-r.size:]...)
+		}
+
+		// Swap the replaced blocks for the canonical ones
+		kept := 0
+		for kept < len(r.blocks) && r.blocks[kept].number < reorg.FromBlock {
+			kept++
+		}
+		r.blocks = append(r.blocks[:kept], canonical...)
+
+		if reorg.Deep {
+			log.Error().
+				Str("chain", r.chainName).
+				Uint64("from_block", reorg.FromBlock).
+				Uint64("to_block", reorg.ToBlock).
+				Int("tracked_blocks", r.window).
+				Msg("ALERT: deep reorg, no common ancestor within the tracked block hashes")
+		} else {
+			log.Warn().
+				Str("chain", r.chainName).
+				Uint64("from_block", reorg.FromBlock).
+				Uint64("block", reorg.ToBlock).
+				Str("old_hash", reorg.OldHash).
+				Str("new_hash", reorg.NewHash).
+				Msg("Chain reorg detected")
+		}
+	}
+
+	r.blocks = append(r.blocks, trackedBlock{number: number, hash: hash})
+	if len(r.blocks) > r.window {
+		r.blocks = append(r.blocks[:0], r.blocks[len(r.blocks)-r.window:]...)
+	}
+	return reorg
+}
+
+// forkPoint walks back from the replaced block at height, whose canonical
+// hash is hash, and returns the canonical blocks that replaced tracked ones,
+// oldest first; the first is the fork point. deep is set when every tracked
+// block was replaced.
+func (r *reorgTracker) forkPoint(height uint64, hash string, parentOf parentLookup) (canonical []trackedBlock, deep bool) {
+	oldest := r.blocks[0].number
+	for {
+		// hash is canonical at height: a tracked match there is the ancestor
+		if ancestor, ok := r.findCommonAncestor(hash); ok && ancestor == height {
+			break
+		}
+		canonical = append(canonical, trackedBlock{number: height, hash: hash})
+		if height <= oldest {
+			deep = true
+			break
+		}
+		if parentOf == nil {
+			break
+		}
+		parent, err := parentOf(height)
+		if err != nil {
+			log.Warn().Err(err).Str("chain", r.chainName).Uint64("block", height).Msg("Failed to walk back to the reorg's common ancestor")
+			break
+		}
+		hash = parent
+		height--
+	}
+	slices.Reverse(canonical)
+	return canonical, deep
+}
+
+// recent returns a copy of the reorg history, oldest first.
+func (r *reorgTracker) recent() []ReorgInfo {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]ReorgInfo(nil), r.history...)
+}
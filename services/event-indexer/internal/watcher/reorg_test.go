@@ -0,0 +1,258 @@
+package watcher
+
+import (
+	"context"
+	"encoding/hex"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChainWatcher_ReorgHistory(t *testing.T) {
+	ctx := context.Background()
+	client := newFakeEVMClient(110)
+	w := newTestChainWatcher(t, client)
+	w.AddAddress(common.HexToAddress("0x2222222222222222222222222222222222222222"))
+
+	w.processBlock(ctx, 100, 110)
+	w.processBlock(ctx, 101, 110)
+	oldHash := client.headerAt(101).Hash().Hex()
+
+	client.reorg(101)
+	newHash := client.headerAt(101).Hash().Hex()
+	w.processBlock(ctx, 102, 110)
+	w.processBlock(ctx, 103, 110)
+
+	history := w.ReorgHistory()
+	require.Len(t, history, 1)
+	assert.Equal(t, uint64(1), history[0].ChainID)
+	assert.Equal(t, uint64(101), history[0].FromBlock)
+	assert.Equal(t, uint64(101), history[0].ToBlock)
+	assert.Equal(t, oldHash, history[0].OldHash)
+	assert.Equal(t, newHash, history[0].NewHash)
+	assert.False(t, history[0].DetectedAt.IsZero())
+
+	// Exposed on the status surface as well
+	mcw := &MultiChainWatcher{watchers: map[uint64]*ChainWatcher{1: w}}
+	assert.Equal(t, history, mcw.ReorgHistory(1))
+	assert.Equal(t, history, mcw.Health()[1].RecentReorgs)
+	assert.Nil(t, mcw.ReorgHistory(999))
+}
+
+func TestChainWatcher_HeadsWakeOrderedPoll(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	client := newFakeEVMClient(101)
+	watched := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	from := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	client.addLog(testTransferLog(101, 0, from, watched, big.NewInt(5)))
+
+	w := newTestChainWatcher(t, client)
+	w.cfg.PollInterval = time.Hour
+	w.checkpoints.start = 100
+	w.AddAddress(watched)
+	events := make(chan *ChainEvent, 4)
+	w.dispatch.addHandler(func(event *ChainEvent) error {
+		events <- event
+		return nil
+	})
+
+	// A subscribed head runs the ordered poll at once instead of processing
+	// the block on its own, so reorg detection sees heights in order
+	heads := make(chan struct{}, 1)
+	go w.pollBlocks(ctx, heads)
+	heads <- struct{}{}
+
+	select {
+	case event := <-events:
+		assert.Equal(t, uint64(101), event.BlockNumber)
+	case <-time.After(time.Second):
+		t.Fatal("head did not wake the poll loop")
+	}
+	assert.Eventually(t, func() bool { return w.checkpoint.Load() == 101 }, time.Second, time.Millisecond)
+}
+
+func TestTronWatcher_ReorgHistory(t *testing.T) {
+	ctx := context.Background()
+	client := newFakeTronClient(200)
+	_, addr := testTronAddress(0x22)
+	w := newTestTronWatcher(client)
+	w.AddTronAddress(addr)
+
+	w.processBlock(ctx, 150, 200)
+	client.reorg(150)
+	w.processBlock(ctx, 151, 200)
+
+	history := w.ReorgHistory()
+	require.Len(t, history, 1)
+	assert.Equal(t, uint64(150), history[0].FromBlock)
+	assert.NotEqual(t, history[0].OldHash, history[0].NewHash)
+
+	client.mu.Lock()
+	assert.Equal(t, hex.EncodeToString(client.blockID(150)), history[0].NewHash)
+	client.mu.Unlock()
+}
+
+func TestReorgTracker_BoundedHistory(t *testing.T) {
+	r := newReorgTracker(1, "Test", 3, 12)
+
+	// Every block's parent mismatches the previous hash
+	for n := uint64(1); n <= 10; n++ {
+		r.observe(n, "hash", "other", nil)
+	}
+
+	history := r.recent()
+	require.Len(t, history, 3)
+	assert.Equal(t, []uint64{7, 8, 9}, []uint64{history[0].FromBlock, history[1].FromBlock, history[2].FromBlock})
+
+	// Non-consecutive heights can't be compared
+	assert.Nil(t, r.observe(20, "hash", "other", nil))
+}
+
+func TestReorgTracker_DefaultSize(t *testing.T) {
+	assert.Equal(t, defaultReorgHistorySize, newReorgTracker(1, "Test", 0, 0).size)
+}
+
+func TestChainWatcher_ReorgHandler(t *testing.T) {
+	ctx := context.Background()
+	client := newFakeEVMClient(110)
+	w := newTestChainWatcher(t, client)
+	w.AddAddress(common.HexToAddress("0x2222222222222222222222222222222222222222"))
+	mcw := &MultiChainWatcher{watchers: map[uint64]*ChainWatcher{1: w}, dispatch: w.dispatch}
+
+	events := make(chan ReorgEvent, 2)
+	mcw.RegisterReorgHandler(func(event ReorgEvent) { events <- event })
+	mcw.RegisterReorgHandler(func(ReorgEvent) { panic("broken consumer") })
+
+	w.processBlock(ctx, 100, 110)
+	w.processBlock(ctx, 101, 110)
+	oldHash := client.headerAt(101).Hash().Hex()
+	client.reorg(101)
+	w.processBlock(ctx, 102, 110)
+	w.gate.drain()
+
+	require.Len(t, events, 1, "a panicking handler doesn't affect the others")
+	assert.Equal(t, ReorgEvent{
+		ChainID:       1,
+		FromBlock:     101,
+		DepthEstimate: 1,
+		OldHash:       oldHash,
+		NewHash:       client.headerAt(101).Hash().Hex(),
+	}, <-events)
+}
+
+func TestChainWatcher_ReorgWalksBackToCommonAncestor(t *testing.T) {
+	ctx := context.Background()
+	client := newFakeEVMClient(130)
+	w := newTestChainWatcher(t, client)
+	w.AddAddress(common.HexToAddress("0x2222222222222222222222222222222222222222"))
+
+	for n := uint64(100); n <= 105; n++ {
+		w.processBlock(ctx, n, 130)
+	}
+	client.mu.Lock()
+	oldHash := client.headerAt(105).Hash().Hex()
+	client.mu.Unlock()
+
+	// Replacing 103 replaces its descendants too
+	client.reorg(103)
+	w.processBlock(ctx, 106, 130)
+
+	history := w.ReorgHistory()
+	require.Len(t, history, 1)
+	assert.Equal(t, uint64(103), history[0].FromBlock, "forked after 102")
+	assert.Equal(t, uint64(105), history[0].ToBlock)
+	assert.Equal(t, oldHash, history[0].OldHash)
+	assert.False(t, history[0].Deep)
+	assert.Equal(t, uint64(3), newReorgEvent(history[0]).DepthEstimate)
+
+	ancestor, ok := w.reorgs.findCommonAncestor(client.headerAt(102).Hash().Hex())
+	assert.True(t, ok)
+	assert.Equal(t, uint64(102), ancestor)
+	_, ok = w.reorgs.findCommonAncestor(oldHash)
+	assert.False(t, ok, "replaced blocks are forgotten")
+
+	t.Run("deep reorg beyond the tracked hashes", func(t *testing.T) {
+		for n := uint64(107); n <= 120; n++ {
+			w.processBlock(ctx, n, 130)
+		}
+		client.reorg(104)
+		w.processBlock(ctx, 121, 130)
+
+		history := w.ReorgHistory()
+		require.Len(t, history, 2)
+		assert.True(t, history[1].Deep)
+		assert.Equal(t, uint64(109), history[1].FromBlock, "oldest of the 12 tracked blocks")
+		assert.Equal(t, uint64(120), history[1].ToBlock)
+	})
+}
+
+func TestTronWatcher_ReorgWalksBackToCommonAncestor(t *testing.T) {
+	ctx := context.Background()
+	client := newFakeTronClient(200)
+	_, addr := testTronAddress(0x22)
+	w := newTestTronWatcher(client)
+	w.AddTronAddress(addr)
+
+	for n := int64(150); n <= 153; n++ {
+		w.processBlock(ctx, n, 200)
+	}
+	// TRON block IDs don't chain in the fake: fork each replaced block
+	client.reorg(152)
+	client.reorg(153)
+	w.processBlock(ctx, 154, 200)
+
+	history := w.ReorgHistory()
+	require.Len(t, history, 1)
+	assert.Equal(t, uint64(152), history[0].FromBlock)
+	assert.Equal(t, uint64(153), history[0].ToBlock)
+}
+
+// headerCountingClient counts HeaderByNumber calls per block.
+type headerCountingClient struct {
+	*fakeEVMClient
+	mu      sync.Mutex
+	headers map[uint64]int
+}
+
+func (c *headerCountingClient) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	c.mu.Lock()
+	c.headers[number.Uint64()]++
+	c.mu.Unlock()
+	return c.fakeEVMClient.HeaderByNumber(ctx, number)
+}
+
+func TestChainWatcher_ReusesSubscribedHeadHeader(t *testing.T) {
+	ctx := context.Background()
+	watched := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	from := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	fake := newFakeEVMClient(101)
+	fake.addLog(testTransferLog(101, 0, from, watched, big.NewInt(5)))
+	client := &headerCountingClient{fakeEVMClient: fake, headers: make(map[uint64]int)}
+
+	w := newTestChainWatcher(t, client)
+	w.AddAddress(watched)
+	var timestamps []time.Time
+	w.dispatch.addHandler(func(event *ChainEvent) error {
+		timestamps = append(timestamps, event.Timestamp)
+		return nil
+	})
+
+	// The subscription already delivered the head's header
+	fake.mu.Lock()
+	head := fake.headerAt(101)
+	fake.mu.Unlock()
+	w.headHeader.Store(head)
+
+	assert.Equal(t, uint64(101), w.poll(ctx, 99))
+	w.gate.drain()
+	assert.Equal(t, 1, client.headers[100], "blocks below the head are fetched")
+	assert.Zero(t, client.headers[101], "the subscribed head header is reused")
+	assert.Equal(t, []time.Time{time.Unix(int64(head.Time), 0)}, timestamps)
+}
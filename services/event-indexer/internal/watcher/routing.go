@@ -0,0 +1,60 @@
+package watcher
+
+import "strings"
+
+// routedHandler is a handler restricted to events touching tagged addresses.
+type routedHandler struct {
+	handler EventHandler
+	tags    map[string]bool // nil = receives every event
+}
+
+// tagAddress associates tags with a watched address, adding to any it
+// already has.
+func (d *dispatcher) tagAddress(addr string, tags ...string) {
+	key := tagKey(addr)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	set, ok := d.addressTags[key]
+	if !ok {
+		set = make(map[string]bool, len(tags))
+		d.addressTags[key] = set
+	}
+	for _, tag := range tags {
+		set[tag] = true
+	}
+}
+
+// untagAddress drops every tag of an address.
+func (d *dispatcher) untagAddress(addr string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.addressTags, tagKey(addr))
+}
+
+// routesTo reports whether routed wants event. Callers hold d.mu.
+func (d *dispatcher) routesTo(routed routedHandler, event *ChainEvent) bool {
+	if routed.tags == nil {
+		return true
+	}
+	for _, addr := range []string{event.FromAddress, event.ToAddress} {
+		if addr == "" {
+			continue
+		}
+		for tag := range d.addressTags[tagKey(addr)] {
+			if routed.tags[tag] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// tagKey normalizes an address for tag lookup. EVM hex addresses are
+// case-insensitive (checksummed or not); TRON Base58 is case-sensitive.
+func tagKey(addr string) string {
+	if strings.HasPrefix(addr, "0x") || strings.HasPrefix(addr, "0X") {
+		return strings.ToLower(addr)
+	}
+	return addr
+}
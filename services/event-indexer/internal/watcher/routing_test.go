@@ -0,0 +1,64 @@
+package watcher
+
+import (
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/protocol-bank/event-indexer/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingHandler collects the tx hashes it receives.
+type recordingHandler struct {
+	mu  sync.Mutex
+	txs []string
+}
+
+func (r *recordingHandler) handle(event *ChainEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.txs = append(r.txs, event.TxHash)
+	return nil
+}
+
+func TestTronWatcher_TaggedHandlerRouting(t *testing.T) {
+	client := newFakeTronClient(200)
+	token, _ := testTronAddress(0xaa)
+	from, _ := testTronAddress(0x11)
+	exchange, exchangeAddr := testTronAddress(0x22)
+	retail, retailAddr := testTronAddress(0x33)
+	client.addTransfer(190, "a190", token, from, exchange, big.NewInt(1))
+	client.addTransfer(190, "b190", token, from, retail, big.NewInt(2))
+
+	w := newTestTronWatcher(client)
+	w.AddTronAddress(exchangeAddr)
+	w.AddTronAddress(retailAddr)
+	w.dispatch.tagAddress(exchangeAddr, "exchange", "vip")
+
+	exchangeHandler := &recordingHandler{}
+	otcHandler := &recordingHandler{}
+	w.dispatch.addTaggedHandler(exchangeHandler.handle, "exchange")
+	w.dispatch.addTaggedHandler(otcHandler.handle, "otc")
+
+	all := collectTronTxs(t, w, 190, 200)
+
+	assert.Equal(t, []string{"a190", "b190"}, all, "untagged handlers see every event")
+	assert.Equal(t, []string{"a190"}, exchangeHandler.txs)
+	assert.Empty(t, otcHandler.txs)
+}
+
+func TestDispatcher_TagKeyIgnoresEVMHexCase(t *testing.T) {
+	d := newDispatcher(nil, config.RetryPolicy{MaxAttempts: 1})
+	addr := common.HexToAddress("0xabcdef0000000000000000000000000000000001")
+	d.tagAddress(addr.Hex(), "treasury")
+
+	routed := routedHandler{tags: map[string]bool{"treasury": true}}
+	assert.True(t, d.routesTo(routed, &ChainEvent{ToAddress: "0xABCDEF0000000000000000000000000000000001"}))
+	assert.False(t, d.routesTo(routed, &ChainEvent{ToAddress: "0x0000000000000000000000000000000000000002"}))
+	assert.False(t, d.routesTo(routed, &ChainEvent{EventType: EventTypeChainHalted}), "events without participants skip tagged handlers")
+
+	d.untagAddress(addr.Hex())
+	assert.False(t, d.routesTo(routed, &ChainEvent{ToAddress: addr.Hex()}))
+}
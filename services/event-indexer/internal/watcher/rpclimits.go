@@ -0,0 +1,134 @@
+package watcher
+
+import (
+	"context"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/fbsobreira/gotron-sdk/pkg/proto/api"
+	"github.com/rs/zerolog/log"
+)
+
+// sizeLimitMarkers are substrings of errors returned when a response is too
+// large: gRPC's ResourceExhausted (gotron-sdk flattens the status into the
+// message) and the log-size caps of common hosted EVM providers.
+var sizeLimitMarkers = []string{
+	"resourceexhausted",
+	"larger than max",
+	"message too large",
+	"response size exceeded",
+	"response is too big",
+	"query returned more than",
+	"too many logs",
+}
+
+// logRangeLimitMarkers are substrings of provider errors rejecting an
+// eth_getLogs block range as too wide.
+var logRangeLimitMarkers = []string{
+	"block range",
+	"range too large",
+	"range is too large",
+	"exceed maximum block range",
+	"limited to a",
+}
+
+// isLogRangeLimitError reports whether an eth_getLogs error may clear with
+// a narrower block range: the response was too large, or the provider caps
+// the range.
+func isLogRangeLimitError(err error) bool {
+	if isSizeLimitError(err) {
+		return true
+	}
+	if err == nil {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	for _, marker := range logRangeLimitMarkers {
+		if strings.Contains(msg, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// isSizeLimitError reports whether err means the response exceeded a client
+// or provider size limit, as opposed to a transient failure.
+func isSizeLimitError(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	for _, marker := range sizeLimitMarkers {
+		if strings.Contains(msg, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// filterLogsNarrowed refetches a block's Transfer logs with narrower queries
+// after the unfiltered one hit a size limit: queries for transfers from
+// and to watched addresses (ERC-20/721 and ERC-1155 index them at different
+// topics), and one per-contract query for token-scoped mode. Unknown-log capture needs the whole block and is
+// skipped for such blocks.
+func (w *ChainWatcher) filterLogsNarrowed(ctx context.Context, blockNumber uint64, addresses []common.Address) ([]types.Log, error) {
+	block := big.NewInt(int64(blockNumber))
+
+	var queries []ethereum.FilterQuery
+	if len(addresses) > 0 {
+		watched := make([]common.Hash, 0, len(addresses))
+		for _, addr := range addresses {
+			watched = append(watched, common.BytesToHash(addr.Bytes()))
+		}
+		queries = append(queries,
+			ethereum.FilterQuery{FromBlock: block, ToBlock: block, Topics: [][]common.Hash{w.transferTopics, watched}},
+			ethereum.FilterQuery{FromBlock: block, ToBlock: block, Topics: [][]common.Hash{w.transferTopics, nil, watched}},
+			// ERC-1155 logs put the operator first: from is topic2, to is topic3
+			ethereum.FilterQuery{FromBlock: block, ToBlock: block, Topics: [][]common.Hash{erc1155Topics, nil, watched}},
+			ethereum.FilterQuery{FromBlock: block, ToBlock: block, Topics: [][]common.Hash{erc1155Topics, nil, nil, watched}},
+		)
+	}
+	if len(w.scopedTokens) > 0 {
+		tokens := make([]common.Address, 0, len(w.scopedTokens))
+		for token := range w.scopedTokens {
+			tokens = append(tokens, token)
+		}
+		queries = append(queries, ethereum.FilterQuery{FromBlock: block, ToBlock: block, Addresses: tokens, Topics: [][]common.Hash{w.logTopics()}})
+	}
+
+	if w.cfg.CaptureUnknownLogs {
+		log.Warn().Uint64("block", blockNumber).Str("chain", w.chainName).Msg("Block logs too large, unknown logs not captured for this block")
+	}
+
+	type logKey struct {
+		tx    common.Hash
+		index uint
+	}
+	seen := make(map[logKey]bool)
+	var logs []types.Log
+	for _, q := range queries {
+		result, err := w.client.FilterLogs(ctx, q)
+		if err != nil {
+			return nil, err
+		}
+		for _, l := range result {
+			key := logKey{l.TxHash, l.Index}
+			if !seen[key] {
+				seen[key] = true
+				logs = append(logs, l)
+			}
+		}
+	}
+	return logs, nil
+}
+
+// blockTxInfos is the fallback when a full TRON block exceeds the message
+// size limit: the block's transaction infos alone (logs, fees, timestamp)
+// are much smaller than the block with every raw transaction.
+func (w *TronWatcher) blockTxInfos(blockNum int64) (*api.TransactionInfoList, error) {
+	log.Warn().Int64("block", blockNum).Str("chain", w.chainName).Msg("TRON block exceeds size limit, falling back to per-transaction infos")
+	return w.client.GetBlockInfoByNum(blockNum)
+}
@@ -0,0 +1,68 @@
+package watcher
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChainWatcher_LogsSizeLimitFallback(t *testing.T) {
+	client := newFakeEVMClient(1000)
+	watched := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	other := common.HexToAddress("0x3333333333333333333333333333333333333333")
+
+	// A busy block: many unrelated transfers plus one in and one out
+	for i := uint(0); i < 50; i++ {
+		client.addLog(testTransferLog(990, i, other, other, big.NewInt(1)))
+	}
+	client.addLog(testTransferLog(990, 50, other, watched, big.NewInt(2)))
+	client.addLog(testTransferLog(990, 51, watched, other, big.NewInt(3)))
+	client.maxLogs = 10
+
+	w := newTestChainWatcher(t, client)
+	w.AddAddress(watched)
+	events := collectEVMEvents(t, w, 990, 1000)
+
+	values := make([]string, 0, len(events))
+	for _, event := range events {
+		values = append(values, event.Value)
+	}
+	sort.Strings(values)
+	assert.Equal(t, []string{"2", "3"}, values)
+}
+
+func TestTronWatcher_BlockSizeLimitFallback(t *testing.T) {
+	client := newFakeTronClient(200)
+	token, _ := testTronAddress(0xaa)
+	from, _ := testTronAddress(0x11)
+	to, toAddr := testTronAddress(0x22)
+	client.addTransfer(190, "a190", token, from, to, big.NewInt(7))
+	client.blockErrs[190] = errors.New("Get block by num: rpc error: code = ResourceExhausted desc = grpc: received message larger than max (40000000 vs. 33554432)")
+
+	w := newTestTronWatcher(client)
+	w.AddTronAddress(toAddr)
+
+	var got []*ChainEvent
+	w.dispatch.addHandler(func(event *ChainEvent) error {
+		got = append(got, event)
+		return nil
+	})
+	assert.Equal(t, []string{"a190"}, collectTronTxs(t, w, 190, 200))
+
+	require.Len(t, got, 1)
+	assert.Equal(t, "7", got[0].Value)
+	assert.Equal(t, int64(190*3), got[0].Timestamp.Unix(), "timestamp taken from the tx info")
+}
+
+func TestIsSizeLimitError(t *testing.T) {
+	assert.True(t, isSizeLimitError(errors.New("rpc error: code = ResourceExhausted desc = grpc: received message larger than max")))
+	assert.True(t, isSizeLimitError(fmt.Errorf("filter: %w", errors.New("query returned more than 10000 results"))))
+	assert.False(t, isSizeLimitError(errors.New("connection refused")))
+	assert.False(t, isSizeLimitError(nil))
+}
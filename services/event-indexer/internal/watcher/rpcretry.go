@@ -0,0 +1,94 @@
+package watcher
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/protocol-bank/event-indexer/internal/config"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// rpcRetryMaxBackoff caps the doubling wait between RPC retries.
+const rpcRetryMaxBackoff = 10 * time.Second
+
+// transientMarkers are substrings of errors that a retry may clear: gRPC
+// statuses flattened into the message by gotron-sdk, timeouts, dropped
+// connections and HTTP 5xx/429 responses from gateways.
+var transientMarkers = []string{
+	"code = unavailable",
+	"code = deadlineexceeded",
+	"code = aborted",
+	"timeout",
+	"timed out",
+	"connection reset",
+	"connection refused",
+	"broken pipe",
+	"unexpected eof",
+	"too many requests",
+	"internal server error",
+	"bad gateway",
+	"service unavailable",
+	"gateway timeout",
+}
+
+// isTransientError reports whether err is a momentary RPC failure worth
+// retrying, as opposed to a permanent one (not found, invalid request,
+// oversized response) that would fail the same way again.
+func isTransientError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || isSizeLimitError(err) {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, errTxInfoPending) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	var httpErr rpc.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode >= 500 || httpErr.StatusCode == 429
+	}
+	if s, ok := status.FromError(err); ok && s.Code() != codes.Unknown {
+		switch s.Code() {
+		case codes.Unavailable, codes.DeadlineExceeded, codes.Aborted:
+			return true
+		}
+		return false
+	}
+
+	msg := strings.ToLower(err.Error())
+	for _, marker := range transientMarkers {
+		if strings.Contains(msg, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// retryRPC calls call until it succeeds, fails permanently or policy's
+// attempts are used up, doubling the backoff between attempts. The last
+// error is returned; ctx cancellation stops the wait.
+func retryRPC[T any](ctx context.Context, policy config.RetryPolicy, chainName, op string, call func() (T, error)) (T, error) {
+	backoff := policy.Backoff
+	for attempt := 1; ; attempt++ {
+		result, err := call()
+		if err == nil || !isTransientError(err) || attempt >= policy.MaxAttempts {
+			return result, err
+		}
+
+		log.Warn().Err(err).Str("chain", chainName).Str("call", op).Int("attempt", attempt).Dur("retry_in", backoff).Msg("Transient RPC error, retrying")
+		select {
+		case <-ctx.Done():
+			return result, err
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, rpcRetryMaxBackoff)
+	}
+}
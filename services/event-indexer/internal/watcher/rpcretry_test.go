@@ -0,0 +1,167 @@
+package watcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/fbsobreira/gotron-sdk/pkg/proto/api"
+	"github.com/fbsobreira/gotron-sdk/pkg/proto/core"
+	"github.com/protocol-bank/event-indexer/internal/config"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestIsTransientError(t *testing.T) {
+	for _, tc := range []struct {
+		err       error
+		transient bool
+	}{
+		{context.DeadlineExceeded, true},
+		{fmt.Errorf("get block: %w", context.DeadlineExceeded), true},
+		{status.Error(codes.Unavailable, "node restarting"), true},
+		{status.Error(codes.NotFound, "block not found"), false},
+		{errors.New("rpc error: code = Unavailable desc = connection reset by peer"), true},
+		{errors.New("rpc error: code = ResourceExhausted desc = grpc: received message larger than max"), false},
+		{rpc.HTTPError{StatusCode: 503, Status: "503 Service Unavailable"}, true},
+		{rpc.HTTPError{StatusCode: 400, Status: "400 Bad Request"}, false},
+		{errors.New("transaction info not found"), false},
+		{context.Canceled, false},
+		{nil, false},
+	} {
+		assert.Equal(t, tc.transient, isTransientError(tc.err), "%v", tc.err)
+	}
+}
+
+// flakyTronClient fails the first blockFailures block fetches and the
+// first infoFailures transaction info fetches with a transient error, or
+// with infoErr when set.
+type flakyTronClient struct {
+	*fakeTronClient
+
+	mu            sync.Mutex
+	blockFailures int
+	infoFailures  int
+	infoErr       error
+}
+
+var errTronUnavailable = errors.New("rpc error: code = Unavailable desc = connection reset by peer")
+
+func (f *flakyTronClient) GetBlockByNum(num int64) (*api.BlockExtention, error) {
+	f.mu.Lock()
+	if f.blockFailures > 0 {
+		f.blockFailures--
+		f.mu.Unlock()
+		return nil, errTronUnavailable
+	}
+	f.mu.Unlock()
+	return f.fakeTronClient.GetBlockByNum(num)
+}
+
+func (f *flakyTronClient) GetTransactionInfoByID(id string) (*core.TransactionInfo, error) {
+	f.mu.Lock()
+	if f.infoFailures > 0 {
+		f.infoFailures--
+		err := f.infoErr
+		f.mu.Unlock()
+		if err == nil {
+			err = errTronUnavailable
+		}
+		return nil, err
+	}
+	f.mu.Unlock()
+	return f.fakeTronClient.GetTransactionInfoByID(id)
+}
+
+func (f *flakyTronClient) fail(blocks, infos int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.blockFailures, f.infoFailures = blocks, infos
+}
+
+func newFlakyTronWatcher(t *testing.T, policy config.RetryPolicy) (*TronWatcher, *flakyTronClient, *[]string) {
+	t.Helper()
+	client := &flakyTronClient{fakeTronClient: newFakeTronClient(100)}
+	token, _ := testTronAddress(0xaa)
+	from, _ := testTronAddress(0x11)
+	to, toAddr := testTronAddress(0x22)
+	client.addTransfer(101, "a101", token, from, to, big.NewInt(5))
+
+	w := newTestTronWatcher(client)
+	w.cfg.RPCRetry = policy
+	w.AddTronAddress(toAddr)
+
+	var mu sync.Mutex
+	var txs []string
+	w.dispatch.addHandler(func(event *ChainEvent) error {
+		mu.Lock()
+		defer mu.Unlock()
+		txs = append(txs, event.TxHash)
+		return nil
+	})
+	w.poll(context.Background()) // baseline at 100
+	return w, client, &txs
+}
+
+func TestTronWatcher_RetriesTransientRPCErrors(t *testing.T) {
+	w, client, txs := newFlakyTronWatcher(t, config.RetryPolicy{MaxAttempts: 3})
+	client.fail(2, 2)
+	client.setHead(102)
+
+	w.poll(context.Background())
+	w.gate.inflight.Wait()
+	assert.Equal(t, int64(102), w.lastBlock)
+	assert.Equal(t, []string{"a101"}, *txs)
+}
+
+func TestTronWatcher_ExhaustedRetriesHoldBlock(t *testing.T) {
+	for name, failures := range map[string][2]int{
+		"block":   {2, 0},
+		"tx info": {0, 2},
+	} {
+		t.Run(name, func(t *testing.T) {
+			w, client, txs := newFlakyTronWatcher(t, config.RetryPolicy{MaxAttempts: 2})
+			client.fail(failures[0], failures[1])
+			client.setHead(102)
+
+			// Block 101 keeps failing: nothing emitted, checkpoint held
+			w.poll(context.Background())
+			w.gate.inflight.Wait()
+			assert.Equal(t, int64(100), w.lastBlock)
+			assert.Empty(t, *txs)
+			assert.Equal(t, HealthRPCError, w.Health().Status)
+
+			// The next tick retries it
+			w.poll(context.Background())
+			w.gate.inflight.Wait()
+			assert.Equal(t, int64(102), w.lastBlock)
+			assert.Equal(t, []string{"a101"}, *txs)
+		})
+	}
+}
+
+func TestTronWatcher_UnindexedTxInfoHoldsBlock(t *testing.T) {
+	w, client, txs := newFlakyTronWatcher(t, config.RetryPolicy{MaxAttempts: 2})
+	client.mu.Lock()
+	client.infoErr = errors.New("transaction info not found")
+	client.mu.Unlock()
+	client.fail(0, 2)
+	client.setHead(102)
+
+	// The node serves block 101 but hasn't indexed its transaction yet:
+	// the block is held rather than its transfer skipped
+	w.poll(context.Background())
+	w.gate.inflight.Wait()
+	assert.Equal(t, int64(100), w.lastBlock)
+	assert.Empty(t, *txs)
+
+	w.poll(context.Background())
+	w.gate.inflight.Wait()
+	assert.Equal(t, int64(102), w.lastBlock)
+	assert.Equal(t, []string{"a101"}, *txs)
+}
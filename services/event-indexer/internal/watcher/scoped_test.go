@@ -0,0 +1,139 @@
+package watcher
+
+import (
+	"math/big"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChainWatcher_TokenScopedMode(t *testing.T) {
+	client := newFakeEVMClient(1000)
+	watched := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	alice := common.HexToAddress("0x3333333333333333333333333333333333333333")
+	bob := common.HexToAddress("0x4444444444444444444444444444444444444444")
+	other := common.HexToAddress("0x5555555555555555555555555555555555555555")
+
+	deposit := testTransferLog(990, 0, alice, watched, big.NewInt(1))
+	volume := testTransferLog(990, 1, alice, bob, big.NewInt(2))
+	otherDeposit := testTransferLog(990, 2, bob, watched, big.NewInt(3))
+	otherDeposit.Address = other
+	otherVolume := testTransferLog(990, 3, bob, alice, big.NewInt(4))
+	otherVolume.Address = other
+	for _, l := range []types.Log{deposit, volume, otherDeposit, otherVolume} {
+		client.addLog(l)
+	}
+	scoped := deposit.Address
+
+	run := func(drop bool) map[string]bool {
+		w := newTestChainWatcher(t, client)
+		w.cfg.DropUnwatchedTransfers = drop
+		w.scopedTokens = scopedEVMTokens([]string{scoped.Hex()})
+		w.AddAddress(watched)
+
+		touches := make(map[string]bool)
+		for _, event := range collectEVMEvents(t, w, 990, 1000) {
+			touches[event.Value] = event.TouchesWatched
+		}
+		return touches
+	}
+
+	t.Run("tags every transfer of the scoped token", func(t *testing.T) {
+		assert.Equal(t, map[string]bool{"1": true, "2": false, "3": true}, run(false))
+	})
+
+	t.Run("drops unwatched transfers when configured", func(t *testing.T) {
+		assert.Equal(t, map[string]bool{"1": true, "3": true}, run(true))
+	})
+
+	t.Run("scoped token without a watch set", func(t *testing.T) {
+		w := newTestChainWatcher(t, client)
+		w.scopedTokens = scopedEVMTokens([]string{scoped.Hex()})
+		events := collectEVMEvents(t, w, 990, 1000)
+
+		values := make([]string, 0, len(events))
+		for _, event := range events {
+			assert.False(t, event.TouchesWatched)
+			values = append(values, event.Value)
+		}
+		sort.Strings(values)
+		assert.Equal(t, []string{"1", "2"}, values)
+	})
+}
+
+func TestTronWatcher_TokenScopedMode(t *testing.T) {
+	client := newFakeTronClient(200)
+	usdt, usdtAddr := testTronAddress(0xaa)
+	other, _ := testTronAddress(0xbb)
+	alice, _ := testTronAddress(0x11)
+	bob, _ := testTronAddress(0x33)
+	watched, watchedAddr := testTronAddress(0x22)
+
+	client.addTransfer(190, "a190", usdt, alice, watched, big.NewInt(1))
+	client.addTransfer(190, "b190", usdt, alice, bob, big.NewInt(2))
+	client.addTransfer(190, "c190", other, alice, bob, big.NewInt(3))
+
+	w := newTestTronWatcher(client)
+	w.scopedTokens = scopedTronTokens([]string{usdtAddr})
+	w.AddTronAddress(watchedAddr)
+
+	var mu sync.Mutex
+	touches := make(map[string]bool)
+	w.dispatch.addHandler(func(event *ChainEvent) error {
+		mu.Lock()
+		defer mu.Unlock()
+		touches[event.TxHash] = event.TouchesWatched
+		return nil
+	})
+	collectTronTxs(t, w, 190, 200)
+
+	assert.Equal(t, map[string]bool{"a190": true, "b190": false}, touches)
+}
+
+func TestChainWatcher_WatchedTokens(t *testing.T) {
+	client := newFakeEVMClient(1000)
+	watched := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	alice := common.HexToAddress("0x3333333333333333333333333333333333333333")
+	usdt := testTransferLog(990, 0, alice, watched, big.NewInt(1))
+	spam := testTransferLog(990, 1, alice, watched, big.NewInt(2))
+	spam.Address = common.HexToAddress("0x5555555555555555555555555555555555555555")
+	client.addLog(usdt)
+	client.addLog(spam)
+
+	run := func(tokens ...string) []string {
+		w := newTestChainWatcher(t, client)
+		w.watchedTokens = scopedEVMTokens(tokens)
+		w.AddAddress(watched)
+
+		var values []string
+		for _, event := range collectEVMEvents(t, w, 990, 1000) {
+			values = append(values, event.Value)
+		}
+		sort.Strings(values)
+		return values
+	}
+
+	assert.Equal(t, []string{"1"}, run(usdt.Address.Hex()))
+	assert.Equal(t, []string{"1", "2"}, run(), "no allowlist emits every token")
+	assert.Equal(t, []string{"1", "2"}, run("TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t"), "TRON entries don't restrict EVM chains")
+}
+
+func TestTronWatcher_WatchedTokens(t *testing.T) {
+	client := newFakeTronClient(200)
+	usdt, usdtAddr := testTronAddress(0xaa)
+	spam, _ := testTronAddress(0xbb)
+	from, _ := testTronAddress(0x11)
+	to, toAddr := testTronAddress(0x22)
+	client.addTransfer(190, "a190", usdt, from, to, big.NewInt(1))
+	client.addTransfer(190, "b190", spam, from, to, big.NewInt(2))
+
+	w := newTestTronWatcher(client)
+	w.watchedTokens = watchedTronTokens([]string{usdtAddr, "0xdAC17F958D2ee523a2206206994597C13D831ec7"})
+	w.AddTronAddress(toAddr)
+
+	assert.Equal(t, []string{"a190"}, collectTronTxs(t, w, 190, 200))
+}
@@ -0,0 +1,55 @@
+package watcher
+
+import "sync"
+
+// drainGate tracks in-flight block processing and handler goroutines so a
+// watcher can enter "lame duck" mode: once draining, no new blocks are
+// picked up, but work that already started is allowed to finish.
+type drainGate struct {
+	mu       sync.Mutex
+	draining bool
+	inflight sync.WaitGroup
+}
+
+// enter registers a new unit of work (e.g. a polling tick).
+// It returns false once the gate is draining; the caller must not proceed.
+func (g *drainGate) enter() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.draining {
+		return false
+	}
+	g.inflight.Add(1)
+	return true
+}
+
+// leave marks a unit of work registered via enter as finished.
+func (g *drainGate) leave() {
+	g.inflight.Done()
+}
+
+// isDraining reports whether BeginShutdown has been called.
+func (g *drainGate) isDraining() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.draining
+}
+
+// spawn runs fn in a goroutine that the gate waits for while draining.
+// It must only be called from work registered via enter, so the counter
+// is never zero while a drain is waiting.
+func (g *drainGate) spawn(fn func()) {
+	g.inflight.Add(1)
+	go func() {
+		defer g.inflight.Done()
+		fn()
+	}()
+}
+
+// drain stops accepting new work and blocks until in-flight work finishes.
+func (g *drainGate) drain() {
+	g.mu.Lock()
+	g.draining = true
+	g.mu.Unlock()
+	g.inflight.Wait()
+}
@@ -0,0 +1,103 @@
+package watcher
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTronWatcher_BeginShutdownWaitsForHandlers(t *testing.T) {
+	ctx := context.Background()
+	client := newFakeTronClient(100)
+	token, _ := testTronAddress(0xaa)
+	from, _ := testTronAddress(0x11)
+	to, toAddr := testTronAddress(0x22)
+	client.addTransfer(101, "a101", token, from, to, big.NewInt(5))
+
+	w := newTestTronWatcher(client)
+	w.AddTronAddress(toAddr)
+	w.lastBlock = 100
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	w.dispatch.addHandler(func(event *ChainEvent) error {
+		close(started)
+		<-release
+		return nil
+	})
+
+	client.setHead(101)
+	w.poll(ctx)
+	<-started
+
+	done := make(chan struct{})
+	go func() {
+		w.BeginShutdown()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("BeginShutdown returned while a handler was still running")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("BeginShutdown did not return after the handler finished")
+	}
+
+	// New blocks are no longer picked up
+	client.setHead(105)
+	w.poll(ctx)
+	assert.Equal(t, int64(101), w.lastBlock)
+	assert.Equal(t, []int64{101}, client.fetchedBlocks())
+}
+
+func TestTronWatcher_BeginShutdownFinishesCurrentBlock(t *testing.T) {
+	ctx := context.Background()
+	client := newFakeTronClient(103)
+	_, addr := testTronAddress(0x22)
+
+	w := newTestTronWatcher(client)
+	w.AddTronAddress(addr)
+	w.lastBlock = 100
+
+	fetching := make(chan struct{})
+	release := make(chan struct{})
+	client.onFetch = func(num int64) {
+		if num == 101 {
+			close(fetching)
+			<-release
+		}
+	}
+
+	polled := make(chan struct{})
+	go func() {
+		w.poll(ctx)
+		close(polled)
+	}()
+	<-fetching
+
+	done := make(chan struct{})
+	go func() {
+		w.BeginShutdown()
+		close(done)
+	}()
+
+	// Wait until the gate is draining before letting block 101 complete
+	assert.Eventually(t, w.gate.isDraining, time.Second, time.Millisecond)
+	close(release)
+
+	<-polled
+	<-done
+
+	// Block 101 finished, blocks 102-103 were never started
+	assert.Equal(t, int64(101), w.lastBlock)
+	assert.Equal(t, []int64{101}, client.fetchedBlocks())
+}
@@ -0,0 +1,150 @@
+package watcher
+
+import (
+	"time"
+)
+
+// IndexerSnapshot is a point-in-time dump of the indexer's internal state,
+// for diagnosing incidents without attaching a debugger.
+type IndexerSnapshot struct {
+	TakenAt time.Time
+	Chains  map[uint64]ChainSnapshot
+
+	// Startup is the startup outcome of every configured chain, including
+	// ones that failed and are being retried
+	Startup []ChainStartup
+
+	// process-wide counters
+	RecoveredPanics        uint64
+	MalformedTronAddresses uint64
+	ThrottledEvents        uint64
+	ZeroAddressTransfers   uint64
+}
+
+// ChainSnapshot is the state of a single chain watcher.
+type ChainSnapshot struct {
+	ChainID uint64
+	Name    string
+	Type    string
+
+	// Checkpoint is the last fully processed block
+	Checkpoint uint64
+	Health     ChainHealth
+
+	WatchedAddresses int
+	ScopedTokens     int
+
+	// PendingConfirmations are the events still tracked for confirmation
+	// milestones, oldest first
+	PendingConfirmations []PendingConfirmation
+
+	// caches, nil when disabled
+	TokenMetadata *CacheStats
+	TxInfos       *CacheStats
+}
+
+// PendingConfirmation is an event waiting for its next confirmation milestone.
+type PendingConfirmation struct {
+	EventID       string
+	EventType     string
+	TxHash        string
+	BlockNumber   uint64
+	Confirmations uint64 // at detection
+	NextMilestone uint64
+}
+
+// CacheStats describes a lookup cache.
+type CacheStats struct {
+	Entries int
+	Hits    uint64
+	Misses  uint64
+}
+
+// Snapshot returns the current state of every chain watcher.
+func (mcw *MultiChainWatcher) Snapshot() IndexerSnapshot {
+	watchers, tronWatchers := mcw.chainWatchers()
+	snapshot := IndexerSnapshot{
+		TakenAt:                time.Now(),
+		Chains:                 make(map[uint64]ChainSnapshot, len(watchers)+len(tronWatchers)),
+		Startup:                mcw.StartupReport(),
+		RecoveredPanics:        RecoveredPanics(),
+		MalformedTronAddresses: MalformedTronAddresses(),
+		ThrottledEvents:        ThrottledEvents(),
+		ZeroAddressTransfers:   ZeroAddressTransfers(),
+	}
+	for chainID, w := range watchers {
+		snapshot.Chains[chainID] = w.snapshot()
+	}
+	for chainID, tw := range tronWatchers {
+		snapshot.Chains[chainID] = tw.snapshot()
+	}
+	return snapshot
+}
+
+func (w *ChainWatcher) snapshot() ChainSnapshot {
+	w.mu.RLock()
+	watched := len(w.addresses)
+	w.mu.RUnlock()
+
+	return ChainSnapshot{
+		ChainID:              w.chainID,
+		Name:                 w.chainName,
+		Type:                 "evm",
+		Checkpoint:           w.checkpoint.Load(),
+		Health:               w.Health(),
+		WatchedAddresses:     watched,
+		ScopedTokens:         len(w.scopedTokens),
+		PendingConfirmations: w.milestones.snapshot(),
+		TokenMetadata:        w.tokenMeta.stats(),
+	}
+}
+
+func (w *TronWatcher) snapshot() ChainSnapshot {
+	w.mu.RLock()
+	watched := len(w.addresses)
+	w.mu.RUnlock()
+
+	return ChainSnapshot{
+		ChainID:              w.chainID,
+		Name:                 w.chainName,
+		Type:                 "tron",
+		Checkpoint:           w.checkpoint.Load(),
+		Health:               w.Health(),
+		WatchedAddresses:     watched,
+		ScopedTokens:         len(w.scopedTokens),
+		PendingConfirmations: w.milestones.snapshot(),
+		TokenMetadata:        w.tokenMeta.stats(),
+		TxInfos:              w.txInfos.stats(),
+	}
+}
+
+// snapshot lists the tracked events, oldest first.
+func (t *confirmationTracker) snapshot() []PendingConfirmation {
+	if t == nil {
+		return nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	out := make([]PendingConfirmation, 0, len(t.pending))
+	for _, tracked := range t.pending {
+		var next uint64
+		if tracked.next < len(t.milestones) {
+			next = t.milestones[tracked.next]
+		}
+		required := max(t.required, tracked.required)
+		if (t.confirmedPhase || tracked.held) && !tracked.confirmed && (next == 0 || required < next) {
+			next = required
+		}
+		out = append(out, PendingConfirmation{
+			EventID:       tracked.event.EventID,
+			EventType:     tracked.event.EventType,
+			TxHash:        tracked.event.TxHash,
+			BlockNumber:   tracked.event.BlockNumber,
+			Confirmations: tracked.event.Confirmations,
+			NextMilestone: next,
+		})
+	}
+	return out
+}
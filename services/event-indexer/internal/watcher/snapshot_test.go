@@ -0,0 +1,69 @@
+package watcher
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMultiChainWatcher_Snapshot(t *testing.T) {
+	ctx := context.Background()
+	watched := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	other := common.HexToAddress("0x1111111111111111111111111111111111111111")
+
+	client := newFakeEVMClient(105)
+	client.addLog(testTransferLog(104, 3, other, watched, big.NewInt(9)))
+	w := newTestChainWatcher(t, client)
+	w.milestones = newConfirmationTracker([]uint64{1, 12}, w.cfg.Confirmations, 0, false)
+	w.AddAddress(watched)
+	w.AddAddress(other)
+
+	// Poll through a reorg of block 103
+	w.processBlock(ctx, 102, 105)
+	w.processBlock(ctx, 103, 105)
+	client.reorg(103)
+	w.checkpoint.Store(w.poll(ctx, 103))
+	w.gate.inflight.Wait()
+
+	fake := newFakeTronClient(200)
+	_, tronAddr := testTronAddress(0x22)
+	tw := newTestTronWatcher(fake)
+	tw.txInfos = newTxInfoCache(8, time.Minute)
+	tw.AddTronAddress(tronAddr)
+	tw.poll(ctx) // initializes the checkpoint at the head
+
+	mcw := &MultiChainWatcher{
+		watchers:     map[uint64]*ChainWatcher{1: w},
+		tronWatchers: map[uint64]*TronWatcher{728126428: tw},
+	}
+	snapshot := mcw.Snapshot()
+
+	assert.False(t, snapshot.TakenAt.IsZero())
+	require.Len(t, snapshot.Chains, 2)
+
+	evm := snapshot.Chains[1]
+	assert.Equal(t, "evm", evm.Type)
+	assert.Equal(t, uint64(105), evm.Checkpoint)
+	assert.Equal(t, uint64(105), evm.Health.Head)
+	assert.Equal(t, 2, evm.WatchedAddresses)
+	require.Len(t, evm.PendingConfirmations, 1)
+	assert.Equal(t, uint64(104), evm.PendingConfirmations[0].BlockNumber)
+	assert.Equal(t, uint64(12), evm.PendingConfirmations[0].NextMilestone)
+	assert.Equal(t, "1:"+common.BigToHash(big.NewInt(104003)).Hex()+":3", evm.PendingConfirmations[0].EventID)
+	require.Len(t, evm.Health.RecentReorgs, 1)
+	assert.Equal(t, uint64(103), evm.Health.RecentReorgs[0].FromBlock)
+	assert.Nil(t, evm.TxInfos)
+
+	tron := snapshot.Chains[728126428]
+	assert.Equal(t, "tron", tron.Type)
+	assert.Equal(t, uint64(200), tron.Checkpoint)
+	assert.Equal(t, 1, tron.WatchedAddresses)
+	assert.Empty(t, tron.PendingConfirmations)
+	assert.Equal(t, &CacheStats{}, tron.TxInfos)
+	assert.Nil(t, tron.TokenMetadata, "decimals resolution disabled")
+}
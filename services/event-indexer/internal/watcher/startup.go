@@ -0,0 +1,51 @@
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Backoff bounds between startup connectivity probes.
+var (
+	startupInitialBackoff = 500 * time.Millisecond
+	startupMaxBackoff     = 10 * time.Second
+)
+
+// waitForRPC runs probe until it succeeds, retrying with exponential backoff
+// for up to timeout. Orchestrators often start the indexer before its RPC
+// dependencies, so a node that is briefly unreachable must not fail startup.
+// A zero timeout probes exactly once.
+func waitForRPC(ctx context.Context, chainName string, timeout time.Duration, probe func(ctx context.Context) error) error {
+	deadline := time.Now().Add(timeout)
+	backoff := startupInitialBackoff
+
+	for attempt := 1; ; attempt++ {
+		err := probe(ctx)
+		if err == nil {
+			return nil
+		}
+
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return fmt.Errorf("RPC unreachable after %d attempts: %w", attempt, err)
+		}
+
+		log.Warn().
+			Err(err).
+			Str("chain", chainName).
+			Int("attempt", attempt).
+			Dur("retry_in", backoff).
+			Msg("RPC not reachable yet, retrying")
+
+		wait := min(backoff, remaining)
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("waiting for RPC: %w", ctx.Err())
+		case <-time.After(wait):
+		}
+		backoff = min(backoff*2, startupMaxBackoff)
+	}
+}
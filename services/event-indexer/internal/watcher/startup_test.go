@@ -0,0 +1,70 @@
+package watcher
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func shortStartupBackoff(t *testing.T) {
+	initial, maxBackoff := startupInitialBackoff, startupMaxBackoff
+	startupInitialBackoff, startupMaxBackoff = time.Millisecond, 5*time.Millisecond
+	t.Cleanup(func() { startupInitialBackoff, startupMaxBackoff = initial, maxBackoff })
+}
+
+func TestWaitForRPC_BrieflyUnavailable(t *testing.T) {
+	shortStartupBackoff(t)
+
+	// Fails for the first three probes, then the node comes up
+	client := newFakeTronClient(100)
+	calls := 0
+	err := waitForRPC(context.Background(), "TRON Test", time.Second, func(context.Context) error {
+		calls++
+		if calls <= 3 {
+			return errors.New("connection refused")
+		}
+		_, err := client.GetNowBlock()
+		return err
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 4, calls)
+}
+
+func TestWaitForRPC_GivesUpAfterTimeout(t *testing.T) {
+	shortStartupBackoff(t)
+
+	start := time.Now()
+	err := waitForRPC(context.Background(), "TRON Test", 30*time.Millisecond, func(context.Context) error {
+		return errors.New("connection refused")
+	})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "connection refused")
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestWaitForRPC_ZeroTimeoutProbesOnce(t *testing.T) {
+	calls := 0
+	err := waitForRPC(context.Background(), "TRON Test", 0, func(context.Context) error {
+		calls++
+		return errors.New("connection refused")
+	})
+
+	require.Error(t, err)
+	assert.Equal(t, 1, calls)
+}
+
+func TestWaitForRPC_StopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := waitForRPC(ctx, "TRON Test", time.Minute, func(context.Context) error {
+		return errors.New("connection refused")
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,172 @@
+package watcher
+
+import (
+	"context"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// EventTypeAddressSummary is the event type of periodic per-address rollups.
+const EventTypeAddressSummary = "address_summary"
+
+// AddressSummary aggregates a watched address's activity in one token over
+// a summary window.
+type AddressSummary struct {
+	Address     string
+	Count       int      // number of events touching the address
+	NetAmount   *big.Int // incoming minus outgoing, in raw token units
+	WindowStart time.Time
+	WindowEnd   time.Time
+}
+
+type summaryKey struct {
+	chainID uint64
+	address string
+	token   string
+}
+
+type summaryEntry struct {
+	chainName string
+	count     int
+	net       *big.Int
+}
+
+// summaryTransferTypes are the fungible transfer types rolled up.
+var summaryTransferTypes = map[string]bool{
+	"transfer":             true,
+	"trc20_transfer":       true,
+	EventTypeTRXTransfer:   true,
+	EventTypeTRC10Transfer: true,
+}
+
+// addressSummarizer observes the event stream and periodically rolls it up
+// into one address_summary event per watched address and token. Only the
+// first detection of a transfer counts: its milestone and confirmed copies
+// and reorged notices are skipped.
+type addressSummarizer struct {
+	mu          sync.Mutex
+	entries     map[summaryKey]*summaryEntry
+	windowStart time.Time
+	isWatched   func(chainID uint64, addr string) bool
+	counted     *seenEvents // transfers already counted, by EventID and block
+}
+
+func newAddressSummarizer(now time.Time, isWatched func(chainID uint64, addr string) bool) *addressSummarizer {
+	return &addressSummarizer{
+		entries:     make(map[summaryKey]*summaryEntry),
+		windowStart: now,
+		isWatched:   isWatched,
+		counted:     newSeenEvents(0),
+	}
+}
+
+// observe is registered as an EventHandler and accumulates transfer events.
+func (s *addressSummarizer) observe(event *ChainEvent) error {
+	if !summaryTransferTypes[event.EventType] || event.FinalityStatus == FinalityReorged || s.counted.repeat(event) {
+		return nil
+	}
+
+	amount, ok := new(big.Int).SetString(event.Value, 10)
+	if !ok {
+		amount = new(big.Int)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.isWatched(event.ChainID, event.FromAddress) {
+		entry := s.entry(event, event.FromAddress)
+		entry.count++
+		entry.net.Sub(entry.net, amount)
+	}
+	if event.ToAddress != event.FromAddress && s.isWatched(event.ChainID, event.ToAddress) {
+		entry := s.entry(event, event.ToAddress)
+		entry.count++
+		entry.net.Add(entry.net, amount)
+	}
+	return nil
+}
+
+func (s *addressSummarizer) entry(event *ChainEvent, address string) *summaryEntry {
+	key := summaryKey{chainID: event.ChainID, address: address, token: event.TokenAddress}
+	entry, ok := s.entries[key]
+	if !ok {
+		entry = &summaryEntry{chainName: event.ChainName, net: new(big.Int)}
+		s.entries[key] = entry
+	}
+	return entry
+}
+
+// flush closes the current window at now and returns its summary events,
+// ordered by chain, address and token for stable output.
+func (s *addressSummarizer) flush(now time.Time) []*ChainEvent {
+	s.mu.Lock()
+	entries := s.entries
+	start := s.windowStart
+	s.entries = make(map[summaryKey]*summaryEntry)
+	s.windowStart = now
+	s.mu.Unlock()
+
+	keys := make([]summaryKey, 0, len(entries))
+	for key := range entries {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].chainID != keys[j].chainID {
+			return keys[i].chainID < keys[j].chainID
+		}
+		if keys[i].address != keys[j].address {
+			return keys[i].address < keys[j].address
+		}
+		return keys[i].token < keys[j].token
+	})
+
+	events := make([]*ChainEvent, 0, len(keys))
+	for _, key := range keys {
+		entry := entries[key]
+		events = append(events, &ChainEvent{
+			ChainID:      key.chainID,
+			ChainName:    entry.chainName,
+			EventType:    EventTypeAddressSummary,
+			ToAddress:    key.address,
+			Value:        entry.net.String(),
+			TokenAddress: key.token,
+			Timestamp:    now,
+			Summary: &AddressSummary{
+				Address:     key.address,
+				Count:       entry.count,
+				NetAmount:   entry.net,
+				WindowStart: start,
+				WindowEnd:   now,
+			},
+		})
+	}
+	return events
+}
+
+// runSummaries emits the rollup every interval until ctx is cancelled.
+func (mcw *MultiChainWatcher) runSummaries(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if !mcw.gate.enter() {
+				continue
+			}
+			events := mcw.summarizer.flush(now)
+			for _, event := range events {
+				mcw.dispatch.dispatch(&mcw.gate, event)
+			}
+			mcw.gate.leave()
+			log.Debug().Int("summaries", len(events)).Msg("Address summaries emitted")
+		}
+	}
+}
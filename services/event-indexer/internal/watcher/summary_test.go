@@ -0,0 +1,76 @@
+package watcher
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddressSummarizer_ReflectsWindow(t *testing.T) {
+	watched := map[string]bool{"TWatchedA": true, "TWatchedB": true}
+	start := time.Unix(1_700_000_000, 0)
+	s := newAddressSummarizer(start, func(chainID uint64, addr string) bool { return watched[addr] })
+
+	usdt, usdc := "TUSDT", "TUSDC"
+	for _, e := range []*ChainEvent{
+		{ChainID: 1, EventType: "trc20_transfer", FromAddress: "TOther", ToAddress: "TWatchedA", Value: "100", TokenAddress: usdt},
+		{ChainID: 1, EventType: "trc20_transfer", FromAddress: "TOther", ToAddress: "TWatchedA", Value: "50", TokenAddress: usdt},
+		{ChainID: 1, EventType: "trc20_transfer", FromAddress: "TWatchedA", ToAddress: "TOther", Value: "30", TokenAddress: usdt},
+		{ChainID: 1, EventType: "trc20_transfer", FromAddress: "TWatchedA", ToAddress: "TWatchedB", Value: "7", TokenAddress: usdc},
+		{ChainID: 1, EventType: "trc20_transfer", FromAddress: "TOther", ToAddress: "TOther2", Value: "999", TokenAddress: usdt},
+	} {
+		require.NoError(t, s.observe(e))
+	}
+
+	end := start.Add(time.Hour)
+	events := s.flush(end)
+	require.Len(t, events, 3)
+
+	byKey := map[string]*ChainEvent{}
+	for _, e := range events {
+		assert.Equal(t, EventTypeAddressSummary, e.EventType)
+		require.NotNil(t, e.Summary)
+		assert.Equal(t, start, e.Summary.WindowStart)
+		assert.Equal(t, end, e.Summary.WindowEnd)
+		byKey[e.Summary.Address+"/"+e.TokenAddress] = e
+	}
+
+	assert.Equal(t, 3, byKey["TWatchedA/TUSDT"].Summary.Count)
+	assert.Equal(t, "120", byKey["TWatchedA/TUSDT"].Value)
+	assert.Equal(t, 1, byKey["TWatchedA/TUSDC"].Summary.Count)
+	assert.Equal(t, "-7", byKey["TWatchedA/TUSDC"].Value)
+	assert.Equal(t, 1, byKey["TWatchedB/TUSDC"].Summary.Count)
+	assert.Equal(t, "7", byKey["TWatchedB/TUSDC"].Value)
+
+	// The next window starts empty
+	assert.Empty(t, s.flush(end.Add(time.Hour)))
+}
+
+func TestAddressSummarizer_CountsFirstDetectionOnly(t *testing.T) {
+	start := time.Unix(1_700_000_000, 0)
+	s := newAddressSummarizer(start, func(chainID uint64, addr string) bool { return addr == "TWatched" })
+
+	transfer := ChainEvent{ChainID: 1, EventType: "trc20_transfer", EventID: "1:a:0", BlockNumber: 100,
+		FromAddress: "TOther", ToAddress: "TWatched", Value: "100", TokenAddress: "TUSDT"}
+	milestone := transfer
+	milestone.Confirmations, milestone.Confirmed = 19, true
+	reorged := transfer
+	reorged.FinalityStatus = FinalityReorged
+	for _, e := range []ChainEvent{
+		transfer,
+		milestone,
+		reorged,
+		{ChainID: 1, EventType: EventTypeBalanceDelta, ToAddress: "TWatched", Value: "100", TokenAddress: "TUSDT"},
+		{ChainID: 1, EventType: EventTypeERC721Transfer, ToAddress: "TWatched", Value: "1", TokenAddress: "TUSDT"},
+		{ChainID: 1, EventType: EventTypeBlockProcessed},
+	} {
+		require.NoError(t, s.observe(&e))
+	}
+
+	events := s.flush(start.Add(time.Hour))
+	require.Len(t, events, 1)
+	assert.Equal(t, 1, events[0].Summary.Count)
+	assert.Equal(t, "100", events[0].Value)
+}
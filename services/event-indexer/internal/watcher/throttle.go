@@ -0,0 +1,103 @@
+package watcher
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// throttledEvents counts events withheld by the per-address cap.
+var throttledEvents atomic.Uint64
+
+// ThrottledEvents returns how many events the per-address cap has withheld
+// since startup.
+func ThrottledEvents() uint64 {
+	return throttledEvents.Load()
+}
+
+// addressThrottle caps the events dispatched per watched address per
+// window, a safety valve against a hyperactive address (e.g. an exchange
+// hot wallet added by mistake) flooding the handlers. Over the cap, events
+// are dropped, or sampled if sampleEvery is set. A nil throttle passes
+// everything.
+type addressThrottle struct {
+	mu          sync.Mutex
+	limit       int
+	window      time.Duration
+	sampleEvery int // pass every Nth event over the cap, 0 = drop them all
+	now         func() time.Time
+	windows     map[string]*throttleWindow
+	lastSweep   time.Time
+}
+
+type throttleWindow struct {
+	start time.Time
+	count int
+}
+
+// newAddressThrottle returns a throttle allowing limit events per address
+// per window, or nil (disabled) if limit is not positive.
+func newAddressThrottle(limit int, window time.Duration, sampleEvery int) *addressThrottle {
+	if limit <= 0 {
+		return nil
+	}
+	if window <= 0 {
+		window = time.Second
+	}
+	return &addressThrottle{
+		limit:       limit,
+		window:      window,
+		sampleEvery: sampleEvery,
+		now:         time.Now,
+		windows:     make(map[string]*throttleWindow),
+	}
+}
+
+// allow reports whether an event for the watched address addr may be
+// dispatched. Events without a watched address are never throttled.
+func (t *addressThrottle) allow(addr string) bool {
+	if t == nil || addr == "" {
+		return true
+	}
+	key := tagKey(addr)
+	now := t.now()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.sweep(now)
+	w, ok := t.windows[key]
+	if !ok || now.Sub(w.start) >= t.window {
+		w = &throttleWindow{start: now}
+		t.windows[key] = w
+	}
+	w.count++
+
+	over := w.count - t.limit
+	if over <= 0 {
+		return true
+	}
+	if over == 1 {
+		log.Warn().Str("address", addr).Int("limit", t.limit).Dur("window", t.window).Msg("Address exceeded event cap, throttling")
+	}
+	if t.sampleEvery > 0 && over%t.sampleEvery == 0 {
+		return true
+	}
+	throttledEvents.Add(1)
+	return false
+}
+
+// sweep forgets expired windows, at most once per window. Callers hold t.mu.
+func (t *addressThrottle) sweep(now time.Time) {
+	if now.Sub(t.lastSweep) < t.window {
+		return
+	}
+	t.lastSweep = now
+	for key, w := range t.windows {
+		if now.Sub(w.start) >= t.window {
+			delete(t.windows, key)
+		}
+	}
+}
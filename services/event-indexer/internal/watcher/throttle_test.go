@@ -0,0 +1,74 @@
+package watcher
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/protocol-bank/event-indexer/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDispatcher_AddressThrottle(t *testing.T) {
+	const hot, quiet = "0xAbC0000000000000000000000000000000000001", "0x0000000000000000000000000000000000000002"
+
+	now := time.Unix(1700000000, 0)
+	newThrottled := func(sampleEvery int) (*dispatcher, func() map[string]int) {
+		d := newDispatcher(nil, config.RetryPolicy{MaxAttempts: 1})
+		d.throttle = newAddressThrottle(3, time.Second, sampleEvery)
+		d.throttle.now = func() time.Time { return now }
+
+		var mu sync.Mutex
+		counts := make(map[string]int)
+		d.addHandler(func(event *ChainEvent) error {
+			mu.Lock()
+			defer mu.Unlock()
+			counts[event.WatchedAddress]++
+			return nil
+		})
+		return d, func() map[string]int {
+			mu.Lock()
+			defer mu.Unlock()
+			return counts
+		}
+	}
+
+	dispatchN := func(d *dispatcher, addr string, n int) {
+		var gate drainGate
+		gate.enter()
+		for i := 0; i < n; i++ {
+			d.dispatch(&gate, &ChainEvent{EventType: "transfer", WatchedAddress: addr})
+		}
+		gate.leave()
+		gate.drain()
+	}
+
+	t.Run("drop", func(t *testing.T) {
+		d, counts := newThrottled(0)
+		before := ThrottledEvents()
+
+		dispatchN(d, hot, 10)
+		dispatchN(d, quiet, 2)
+		dispatchN(d, "", 5) // not tied to a watched address
+
+		assert.Equal(t, 3, counts()[hot], "capped")
+		assert.Equal(t, 2, counts()[quiet], "other addresses unaffected")
+		assert.Equal(t, 5, counts()[""])
+		assert.Equal(t, before+7, ThrottledEvents())
+
+		// A new window resets the cap; addresses match case-insensitively
+		now = now.Add(time.Second)
+		dispatchN(d, "0xabc0000000000000000000000000000000000001", 1)
+		assert.Equal(t, 1, counts()["0xabc0000000000000000000000000000000000001"])
+	})
+
+	t.Run("sample", func(t *testing.T) {
+		d, counts := newThrottled(4)
+		dispatchN(d, hot, 3+8)
+		assert.Equal(t, 3+2, counts()[hot], "every 4th event over the cap is kept")
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		assert.Nil(t, newAddressThrottle(0, time.Second, 0))
+	})
+}
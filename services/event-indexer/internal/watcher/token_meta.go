@@ -0,0 +1,231 @@
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	tronclient "github.com/fbsobreira/gotron-sdk/pkg/client"
+	"github.com/rs/zerolog/log"
+)
+
+// trc20MetadataRPC is the constant-call subset of gotron-sdk used to look up
+// token metadata. evmMetadataClient provides it over eth_call.
+type trc20MetadataRPC interface {
+	TRC20GetDecimals(contractAddress string) (*big.Int, error)
+	TRC20GetTotalSupply(contractAddress string) (*big.Int, error)
+}
+
+// trc20TotalSupplySelector is the selector of totalSupply().
+const trc20TotalSupplySelector = "0x18160ddd"
+
+// tronMetadataClient adds the totalSupply() lookup gotron-sdk lacks.
+type tronMetadataClient struct {
+	*tronclient.GrpcClient
+}
+
+func (c tronMetadataClient) TRC20GetTotalSupply(contractAddress string) (*big.Int, error) {
+	result, err := c.TRC20Call("", contractAddress, trc20TotalSupplySelector, true, 0)
+	if err != nil {
+		return nil, err
+	}
+	out := result.GetConstantResult()
+	if len(out) == 0 || len(out[0]) != 32 {
+		return nil, fmt.Errorf("unexpected totalSupply() result")
+	}
+	return new(big.Int).SetBytes(out[0]), nil
+}
+
+// erc20DecimalsSelector is the selector of decimals().
+const erc20DecimalsSelector = "0x313ce567"
+
+// evmContractCaller is the eth_call subset of ethclient.
+type evmContractCaller interface {
+	CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error)
+}
+
+// evmMetadataTimeout bounds each metadata eth_call, which runs inline with
+// log processing.
+const evmMetadataTimeout = 10 * time.Second
+
+// evmMetadataClient answers the token metadata lookups with eth_call against
+// the latest block.
+type evmMetadataClient struct {
+	caller evmContractCaller
+}
+
+func (c evmMetadataClient) TRC20GetDecimals(contractAddress string) (*big.Int, error) {
+	return c.call(contractAddress, erc20DecimalsSelector)
+}
+
+func (c evmMetadataClient) TRC20GetTotalSupply(contractAddress string) (*big.Int, error) {
+	return c.call(contractAddress, trc20TotalSupplySelector)
+}
+
+// call makes a no-argument uint256 view call.
+func (c evmMetadataClient) call(contractAddress, selector string) (*big.Int, error) {
+	if !common.IsHexAddress(contractAddress) {
+		return nil, fmt.Errorf("invalid contract address %q", contractAddress)
+	}
+	to := common.HexToAddress(contractAddress)
+	ctx, cancel := context.WithTimeout(context.Background(), evmMetadataTimeout)
+	defer cancel()
+	out, err := c.caller.CallContract(ctx, ethereum.CallMsg{To: &to, Data: common.FromHex(selector)}, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(out) != 32 {
+		return nil, fmt.Errorf("unexpected %s result: %d bytes", selector, len(out))
+	}
+	return new(big.Int).SetBytes(out), nil
+}
+
+// tokenMetadataRetry is how long a failed decimals() or totalSupply() lookup
+// is cached before the contract is asked again: long enough not to hammer
+// the node for a non-standard token, short enough that an RPC hiccup doesn't
+// leave a real token unnormalized or unchecked until restart.
+const tokenMetadataRetry = 10 * time.Minute
+
+// tokenMetadata is the cached metadata for a single token contract.
+type tokenMetadata struct {
+	decimals uint8
+	known    bool // false if the contract didn't answer decimals() sensibly
+
+	// retryAt is when a failed decimals() lookup is retried, zero once known
+	retryAt time.Time
+
+	// totalSupply as first seen, nil if not looked up or unavailable
+	totalSupply *big.Int
+
+	// supplyRetryAt is when a failed totalSupply() lookup is retried
+	supplyRetryAt time.Time
+}
+
+// tokenMetadataCache memoizes per-token metadata so each contract is queried
+// once. Failed lookups are cached as unknown for tokenMetadataRetry to avoid
+// hammering the node with calls for non-standard tokens.
+type tokenMetadataCache struct {
+	mu      sync.Mutex
+	entries map[string]tokenMetadata
+	rpc     trc20MetadataRPC
+	now     func() time.Time
+
+	// which lookups to make: decimals() (ResolveTokenDecimals) and
+	// totalSupply() (CheckTotalSupply)
+	fetchDecimals, fetchSupply bool
+
+	hits, misses uint64
+}
+
+func newTokenMetadataCache(rpc trc20MetadataRPC) *tokenMetadataCache {
+	return &tokenMetadataCache{
+		entries:       make(map[string]tokenMetadata),
+		rpc:           rpc,
+		now:           time.Now,
+		fetchDecimals: true,
+	}
+}
+
+// decimals returns the token's decimals and whether they are known.
+func (c *tokenMetadataCache) decimals(token string) (uint8, bool) {
+	meta := c.lookup(token)
+	return meta.decimals, meta.known
+}
+
+// exceedsSupply reports whether value is larger than the token's cached
+// total supply, which no legitimate transfer can be. Unknown supply never
+// flags.
+func (c *tokenMetadataCache) exceedsSupply(token string, value *big.Int) bool {
+	supply := c.lookup(token).totalSupply
+	return supply != nil && value.Cmp(supply) > 0
+}
+
+// lookup returns the token's metadata, fetching it on first use. A failed
+// lookup is made again once its retry time has passed.
+func (c *tokenMetadataCache) lookup(token string) tokenMetadata {
+	c.mu.Lock()
+	meta, ok := c.entries[token]
+	now := c.now()
+	fetchDecimals := c.fetchDecimals && (!ok || !meta.retryAt.IsZero() && !now.Before(meta.retryAt))
+	fetchSupply := c.fetchSupply && (!ok || meta.totalSupply == nil && !now.Before(meta.supplyRetryAt))
+	if fetchDecimals || fetchSupply {
+		c.misses++
+	} else {
+		c.hits++
+	}
+	c.mu.Unlock()
+	if !fetchDecimals && !fetchSupply {
+		return meta
+	}
+
+	if fetchDecimals {
+		decimals := c.queryDecimals(token)
+		meta.decimals, meta.known, meta.retryAt = decimals.decimals, decimals.known, time.Time{}
+		if !meta.known {
+			meta.retryAt = c.now().Add(tokenMetadataRetry)
+		}
+	}
+	if fetchSupply {
+		meta.totalSupply, meta.supplyRetryAt = c.queryTotalSupply(token), time.Time{}
+		if meta.totalSupply == nil {
+			meta.supplyRetryAt = c.now().Add(tokenMetadataRetry)
+		}
+	}
+
+	c.mu.Lock()
+	c.entries[token] = meta
+	c.mu.Unlock()
+	return meta
+}
+
+// stats reports the cache's size and hit rate, nil if resolution is disabled.
+func (c *tokenMetadataCache) stats() *CacheStats {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return &CacheStats{Entries: len(c.entries), Hits: c.hits, Misses: c.misses}
+}
+
+func (c *tokenMetadataCache) queryDecimals(token string) (meta tokenMetadata) {
+	// gotron-sdk indexes the constant result without a length check, so a
+	// contract that returns nothing would otherwise panic the watcher
+	defer func() {
+		if r := recover(); r != nil {
+			log.Warn().Interface("panic", r).Str("token", token).Msg("Token decimals call panicked")
+			meta = tokenMetadata{}
+		}
+	}()
+
+	d, err := c.rpc.TRC20GetDecimals(token)
+	if err != nil {
+		log.Warn().Err(err).Str("token", token).Msg("Failed to resolve token decimals")
+		return tokenMetadata{}
+	}
+	if d == nil || d.Sign() < 0 || d.Cmp(big.NewInt(maxTokenDecimals)) > 0 {
+		log.Warn().Str("token", token).Msg("Token returned implausible decimals")
+		return tokenMetadata{}
+	}
+	return tokenMetadata{decimals: uint8(d.Uint64()), known: true}
+}
+
+func (c *tokenMetadataCache) queryTotalSupply(token string) (supply *big.Int) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Warn().Interface("panic", r).Str("token", token).Msg("Token totalSupply call panicked")
+			supply = nil
+		}
+	}()
+
+	supply, err := c.rpc.TRC20GetTotalSupply(token)
+	if err != nil {
+		log.Warn().Err(err).Str("token", token).Msg("Failed to resolve token total supply")
+		return nil
+	}
+	return supply
+}
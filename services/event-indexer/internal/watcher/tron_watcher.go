@@ -1,63 +1,273 @@
 package watcher
 
 import (
+	"cmp"
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
+	"fmt"
 	"math/big"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
+	"unicode/utf8"
 
 	tronclient "github.com/fbsobreira/gotron-sdk/pkg/client"
+	"github.com/fbsobreira/gotron-sdk/pkg/proto/api"
+	"github.com/fbsobreira/gotron-sdk/pkg/proto/core"
 	"github.com/protocol-bank/event-indexer/internal/config"
 	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
 )
 
 // TRC20 Transfer event signature (keccak256 of "Transfer(address,address,uint256)")
 const trc20TransferSig = "ddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"
 
+// tronRPC is the subset of gotron-sdk's GrpcClient used by TronWatcher,
+// extracted so the HTTP API client and tests can substitute for it.
+type tronRPC interface {
+	GetNowBlock() (*api.BlockExtention, error)
+	GetBlockByNum(num int64) (*api.BlockExtention, error)
+	GetTransactionInfoByID(id string) (*core.TransactionInfo, error)
+	GetBlockInfoByNum(num int64) (*api.TransactionInfoList, error)
+	GetNodeInfo() (*core.NodeInfo, error)
+}
+
 // TronWatcher monitors TRC20 Transfer events on the TRON network
-// using gotron-sdk's gRPC client with block polling.
+// using gotron-sdk's gRPC client (or the node's HTTP API) with block polling.
 type TronWatcher struct {
-	chainID   uint64
-	chainName string
-	client    *tronclient.GrpcClient
-	cfg       config.ChainConfig
-	addresses map[string]bool // TRON Base58 addresses
-	handlers  []EventHandler
-	mu        sync.RWMutex
+	chainID      uint64
+	chainName    string
+	client       tronRPC
+	cfg          config.ChainConfig
+	addresses    map[string]bool // TRON Base58 addresses
+	dispatch     *dispatcher
+	mu           sync.RWMutex
+	lastBlock    int64 // last fully processed block, owned by the polling loop
+	gate         drainGate
+	tokenMeta    *tokenMetadataCache // nil unless ResolveTokenDecimals or CheckTotalSupply is enabled
+	transferSigs map[string]bool     // accepted topic0 values (lowercase hex)
+
+	// nil unless ConfirmationMilestones is set
+	milestones *confirmationTracker
+
+	// token contracts (Base58) whose every transfer is emitted
+	scopedTokens map[string]bool
+
+	// token contracts (Base58) transfers are limited to, empty = all
+	watchedTokens map[string]bool
+
+	// head tracking for halt detection
+	health *headMonitor
+
+	// reorg detection and bounded history
+	reorgs *reorgTracker
+
+	// confirmation requirement, raised while the chain is reorging
+	confirmations *adaptiveConfirmations
+
+	// head timestamp sanity, owned by the polling loop
+	lastHeadTimestamp int64 // ms, of the last accepted head
+	badHeads          int   // consecutive heads rejected for their timestamp
+
+	// while set, polling is skipped and lastBlock holds
+	paused atomic.Bool
+
+	// latest solidified block, refreshed each poll unless the confirmation
+	// mode is block count only
+	solidBlock atomic.Int64
+
+	// address prefix byte of this network (0x41 unless configured)
+	addrPrefix byte
+
+	// milestones are re-checked by the MultiChainWatcher across chains
+	// rather than inline with polling
+	separateConfirmationChecks bool
+
+	// recently fetched transaction infos, nil unless TxInfoCacheSize is set
+	txInfos *txInfoCache
+
+	// lastBlock published for state snapshots
+	checkpoint atomic.Uint64
+
+	// durable checkpoint, trailing the head by CheckpointConfirmations
+	checkpoints checkpointer
+
+	// configured token symbols and decimals (TokenRegistry)
+	tokens tokenRegistry
+
+	// stops Start's loops and the gRPC connections on Close
+	closer *watcherCloser
 }
 
 // NewTronWatcher creates a new TRON block watcher
 func NewTronWatcher(ctx context.Context, cfg config.ChainConfig) (*TronWatcher, error) {
-	client := tronclient.NewGrpcClient(cfg.RPCURL)
-	if err := client.Start(); err != nil {
+	// One client per endpoint; with several, each call goes to the
+	// healthiest one
+	urls := endpointURLs(cfg)
+	var (
+		nodes    []tronRPC
+		metadata trc20MetadataRPC // constant calls, served by the primary
+		started  []*tronclient.GrpcClient
+	)
+	stopAll := func() {
+		for _, c := range started {
+			c.Stop()
+		}
+	}
+	if cfg.RPCProtocol == config.RPCProtocolHTTP {
+		for _, url := range urls {
+			c := newHTTPTronClient(url, cfg.CallTimeout, cfg.MaxMessageSize)
+			if metadata == nil {
+				metadata = c
+			}
+			nodes = append(nodes, c)
+		}
+	} else {
+		opts := []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
+		if cfg.MaxMessageSize > 0 {
+			// gotron-sdk's block calls set their own receive limit per call;
+			// this bounds everything else (head, tx info, constant calls)
+			opts = append(opts, grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(cfg.MaxMessageSize)))
+		}
+		for _, url := range urls {
+			c := tronclient.NewGrpcClient(url)
+			if err := c.Start(opts...); err != nil {
+				stopAll()
+				return nil, err
+			}
+			if cfg.CallTimeout > 0 {
+				c.SetTimeout(cfg.CallTimeout)
+			}
+			if metadata == nil {
+				metadata = tronMetadataClient{c}
+			}
+			started = append(started, c)
+			nodes = append(nodes, c)
+		}
+	}
+	if len(nodes) == 0 {
+		return nil, fmt.Errorf("no RPC endpoint configured")
+	}
+	// Every call is bounded by CallTimeout: the SDK's own gRPC deadline (or
+	// the HTTP client timeout) cancels the request, the wrapper guarantees
+	// the caller returns
+	clients := make([]tronRPC, len(nodes))
+	for i, c := range nodes {
+		clients[i] = newDeadlineTronClient(c, cfg.CallTimeout)
+	}
+	client := clients[0]
+	if len(clients) > 1 {
+		client = newMultiTronClient(cfg.Name, urls, clients, cfg.RPCEndpointCooldown)
+	}
+
+	// The gRPC connection is lazy; probe the node so an unreachable
+	// endpoint is retried here rather than failing every poll
+	err := waitForRPC(ctx, cfg.Name, cfg.StartupTimeout, func(context.Context) error {
+		_, err := client.GetNowBlock()
+		return err
+	})
+	if err != nil {
+		stopAll()
 		return nil, err
 	}
 
 	log.Info().
 		Uint64("chain_id", cfg.ChainID).
 		Str("name", cfg.Name).
-		Str("rpc", cfg.RPCURL).
+		Strs("rpc", urls).
+		Str("protocol", cmp.Or(cfg.RPCProtocol, config.RPCProtocolGRPC)).
 		Msg("TRON watcher connected")
 
-	return &TronWatcher{
-		chainID:   cfg.ChainID,
-		chainName: cfg.Name,
-		client:    client,
-		cfg:       cfg,
-		addresses: make(map[string]bool),
-		handlers:  []EventHandler{},
-	}, nil
+	w := newTronWatcher(cfg, client)
+	w.closer.release = stopAll
+	if cfg.ResolveTokenDecimals || cfg.CheckTotalSupply {
+		// Metadata is cached after one lookup, so the primary serves it
+		w.tokenMeta = newTokenMetadataCache(metadata)
+		w.tokenMeta.fetchDecimals = cfg.ResolveTokenDecimals
+		w.tokenMeta.fetchSupply = cfg.CheckTotalSupply
+	}
+	return w, nil
+}
+
+// newTronWatcher builds a watcher around an already-connected client.
+func newTronWatcher(cfg config.ChainConfig, client tronRPC) *TronWatcher {
+	cfg.PollInterval = pollInterval(cfg)
+	w := &TronWatcher{
+		chainID:       cfg.ChainID,
+		chainName:     cfg.Name,
+		client:        client,
+		cfg:           cfg,
+		addresses:     make(map[string]bool),
+		dispatch:      newDispatcher(nil, config.RetryPolicy{MaxAttempts: 1}),
+		transferSigs:  transferSigSet(cfg.TransferEventSigs),
+		milestones:    newConfirmationTracker(cfg.ConfirmationMilestones, cfg.Confirmations, cfg.MaxPendingConfirmations, cfg.EmitConfirmedEvents),
+		scopedTokens:  scopedTronTokens(cfg.ScopedTokens),
+		watchedTokens: watchedTronTokens(cfg.WatchedTokens),
+		health:        newHeadMonitor(getChainConfig(cfg.ChainID).BlockTime, cfg.HaltBlocks),
+		reorgs:        newReorgTracker(cfg.ChainID, cfg.Name, cfg.ReorgHistorySize, int(maxConfirmations(cfg))),
+		confirmations: newAdaptiveConfirmations(cfg),
+		addrPrefix:    tronAddressPrefix(cfg.AddressPrefix),
+		txInfos:       newTxInfoCache(cfg.TxInfoCacheSize, confirmationWindow(cfg.ChainID, maxConfirmations(cfg))),
+		checkpoints:   checkpointer{chainID: cfg.ChainID, chainName: cfg.Name, lag: cfg.CheckpointConfirmations, start: cfg.StartBlock},
+		tokens:        newTokenRegistry(cfg.TokenRegistry),
+		closer:        newWatcherCloser(),
+	}
+	w.milestones.setFinality(func(countConfirmed bool, blockNum uint64) bool {
+		return w.isFinal(countConfirmed, int64(blockNum))
+	})
+	w.milestones.setDepth(func(event *ChainEvent) uint64 {
+		return w.dispatch.tokenPolicies.confirmations(event)
+	})
+	return w
 }
 
-// AddTronAddress adds a TRON Base58 address to the watch list
-func (w *TronWatcher) AddTronAddress(addr string) {
+// tronAddressPrefix returns the configured prefix, defaulting to mainnet's.
+func tronAddressPrefix(prefix byte) byte {
+	if prefix == 0 {
+		return tronMainnetPrefix
+	}
+	return prefix
+}
+
+// AddTronAddress adds a TRON Base58 address to the watch list. It reports
+// whether the address is new, and rejects one that fails Base58Check
+// decoding or carries another network's prefix, which could never match.
+func (w *TronWatcher) AddTronAddress(addr string) (bool, error) {
+	addr, err := normalizeTronAddress(addr, w.addrPrefix)
+	if err != nil {
+		return false, err
+	}
+
 	w.mu.Lock()
 	defer w.mu.Unlock()
+	if w.addresses[addr] {
+		log.Debug().Str("address", addr).Str("chain", w.chainName).Msg("TRON address already on watch list")
+		return false, nil
+	}
 	w.addresses[addr] = true
 	log.Info().Str("address", addr).Str("chain", w.chainName).Msg("TRON address added to watch list")
+	return true, nil
+}
+
+// normalizeTronAddress trims addr and checks that it is a valid address of
+// the network with the given prefix. Mainnet-prefixed addresses are 34
+// characters starting with 'T'.
+func normalizeTronAddress(addr string, prefix byte) (string, error) {
+	addr = strings.TrimSpace(addr)
+	if err := config.ValidateAddress(addr, "tron", prefix); err != nil {
+		return "", fmt.Errorf("invalid TRON address %q: %w", addr, err)
+	}
+	return addr, nil
+}
+
+// isWatched reports whether a Base58 address is on the watch list.
+func (w *TronWatcher) isWatched(addr string) bool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.addresses[addr]
 }
 
 // RemoveTronAddress removes a TRON address from the watch list
@@ -68,14 +278,22 @@ func (w *TronWatcher) RemoveTronAddress(addr string) {
 }
 
 // Start begins polling TRON blocks for TRC20 Transfer events.
-// TRON doesn't support WebSocket subscriptions like EVM, so we poll every 3 seconds.
+// TRON doesn't support WebSocket subscriptions like EVM, so we poll every
+// PollInterval (~3s block time by default).
 func (w *TronWatcher) Start(ctx context.Context) {
 	log.Info().Str("chain", w.chainName).Msg("Starting TRON block watcher")
+	ctx = w.closer.bind(ctx)
 
-	ticker := time.NewTicker(3 * time.Second) // TRON block time is ~3 seconds
-	defer ticker.Stop()
+	// Resume from the durable checkpoint, re-processing the unconfirmed tail
+	if block := w.checkpoints.resume(ctx); !w.checkpoints.atHead(block) {
+		w.lastBlock = int64(block)
+		w.checkpoint.Store(block)
+	}
 
-	var lastBlock int64
+	go runEndpointHealthChecks(ctx, w.client, w.cfg.RPCHealthCheckInterval)
+
+	ticker := time.NewTicker(w.cfg.PollInterval)
+	defer ticker.Stop()
 
 	for {
 		select {
@@ -83,164 +301,598 @@ func (w *TronWatcher) Start(ctx context.Context) {
 			log.Info().Str("chain", w.chainName).Msg("TRON watcher stopped")
 			return
 		case <-ticker.C:
-			w.mu.RLock()
-			addrCount := len(w.addresses)
-			w.mu.RUnlock()
+			w.pollIsolated(ctx)
+		}
+	}
+}
 
-			if addrCount == 0 {
-				continue
-			}
+// BeginShutdown puts the watcher into lame duck mode: the polling loop stops
+// picking up new blocks, and the call blocks until the block currently being
+// processed and any outstanding handlers have finished.
+func (w *TronWatcher) BeginShutdown() {
+	w.gate.drain()
+	log.Info().Str("chain", w.chainName).Int64("last_block", w.lastBlock).Msg("TRON watcher drained")
+}
 
-			// Get latest block
-			block, err := w.client.GetNowBlock()
-			if err != nil {
-				log.Error().Err(err).Str("chain", w.chainName).Msg("Failed to get TRON block")
-				continue
-			}
+// Close stops the polling loop and closes the gRPC connections, e.g. when
+// the chain is removed from a running indexer. Call BeginShutdown first to
+// let the block in progress finish. It is safe to call more than once.
+func (w *TronWatcher) Close() error {
+	w.closer.close()
+	log.Info().Str("chain", w.chainName).Msg("TRON watcher closed")
+	return nil
+}
 
-			if block == nil || block.GetBlockHeader() == nil {
-				continue
-			}
+// pollIsolated runs poll, recovering a panic so the loop survives it. The
+// checkpoint only advances past fully processed blocks, so the next tick
+// resumes where this one stopped.
+func (w *TronWatcher) pollIsolated(ctx context.Context) {
+	defer recoverPanic(w.cfg.RecoverPanics, w.chainName, "poll")
+	w.poll(ctx)
+	w.checkpoints.advance(ctx, uint64(w.lastBlock), w.health.snapshot().Head)
+}
 
-			currentBlock := block.GetBlockHeader().GetRawData().GetNumber()
-			if lastBlock == 0 {
-				lastBlock = currentBlock
-				continue
-			}
+// poll runs a single polling iteration: fetch the tip and process new blocks.
+func (w *TronWatcher) poll(ctx context.Context) {
+	if w.paused.Load() {
+		return
+	}
+	if !w.gate.enter() {
+		return
+	}
+	defer w.gate.leave()
 
-			// Process new blocks
-			for blockNum := lastBlock + 1; blockNum <= currentBlock; blockNum++ {
-				w.processBlock(ctx, blockNum, currentBlock)
-			}
-			lastBlock = currentBlock
+	w.mu.RLock()
+	addrCount := len(w.addresses)
+	w.mu.RUnlock()
+
+	if addrCount == 0 && len(w.scopedTokens) == 0 {
+		return
+	}
+
+	// Get latest block
+	block, err := tronCall(ctx, w, "GetNowBlock", w.client.GetNowBlock)
+	if err != nil {
+		log.Error().Err(err).Str("chain", w.chainName).Msg("Failed to get TRON block")
+		w.health.observeError(err)
+		return
+	}
+
+	if block == nil || block.GetBlockHeader() == nil {
+		return
+	}
+
+	if !w.acceptHeadTimestamp(block.GetBlockHeader().GetRawData().GetTimestamp()) {
+		return
+	}
+
+	currentBlock := block.GetBlockHeader().GetRawData().GetNumber()
+	w.trackHead(uint64(currentBlock))
+	w.refreshSolidified(ctx)
+
+	// The checkpoint advances to the head, or in confirmed-only mode to the
+	// confirmed frontier
+	frontier := currentBlock
+	if w.cfg.ConfirmedOnly {
+		frontier = int64(confirmedFrontier(uint64(currentBlock), w.confirmations.required()))
+	}
+
+	if w.checkpoints.atHead(uint64(w.lastBlock)) {
+		w.lastBlock = frontier
+		w.checkpoint.Store(uint64(frontier))
+		return
+	}
+
+	// Process new blocks
+	for blockNum := w.lastBlock + 1; blockNum <= frontier; blockNum++ {
+		// Lame duck: let the block in progress finish, but don't start another
+		if w.gate.isDraining() {
+			return
+		}
+		// Backpressure: hold the checkpoint until milestones free up the
+		// pending confirmation queue
+		if w.milestones.full() {
+			log.Warn().Str("chain", w.chainName).Int64("block", blockNum).Msg("Pending confirmation queue full, pausing block processing")
+			break
+		}
+		// A block that still fails after retries holds the checkpoint, so
+		// the next tick retries it instead of losing its transfers
+		if err := w.processBlock(ctx, blockNum, currentBlock); err != nil {
+			log.Error().Err(err).Int64("block", blockNum).Str("chain", w.chainName).Msg("Failed to process TRON block, retrying next tick")
+			w.health.observeError(err)
+			break
+		}
+		blocksProcessed.WithLabelValues(w.chainName).Inc()
+		w.lastBlock = blockNum
+		w.checkpoint.Store(uint64(blockNum))
+	}
+
+	if !w.separateConfirmationChecks {
+		w.emitMilestones(currentBlock)
+	}
+}
+
+// emit dispatches a detected event unless it was already detected in the
+// same block, and reports whether it was dispatched.
+func (w *TronWatcher) emit(event *ChainEvent) bool {
+	if w.dispatch.seen.repeat(event) {
+		return false
+	}
+	w.dispatch.tokenPolicies.holdConfirmed(event)
+	w.dispatch.dispatch(&w.gate, event)
+	return true
+}
+
+// emitMilestones dispatches tracked events that crossed a confirmation
+// milestone at head.
+func (w *TronWatcher) emitMilestones(head int64) {
+	w.milestones.setRequired(w.confirmations.required())
+	for _, event := range w.milestones.advance(uint64(head)) {
+		w.dispatch.dispatch(&w.gate, event)
+	}
+}
+
+// parentHash returns a lookup of the parent hash of the canonical block at
+// a height, to walk back to a reorg's common ancestor.
+func (w *TronWatcher) parentHash(ctx context.Context) parentLookup {
+	return func(number uint64) (string, error) {
+		block, err := tronCall(ctx, w, "GetBlockByNum", func() (*api.BlockExtention, error) {
+			return w.client.GetBlockByNum(int64(number))
+		})
+		if err != nil {
+			return "", err
 		}
+		return hex.EncodeToString(block.GetBlockHeader().GetRawData().GetParentHash()), nil
 	}
 }
 
-// processBlock fetches a TRON block and scans its transactions for TRC20 transfers
-func (w *TronWatcher) processBlock(ctx context.Context, blockNum int64, currentBlock int64) {
-	block, err := w.client.GetBlockByNum(blockNum)
+// emitReorged reports tracked events in blocks a reorg replaced, reverses
+// the running balance deltas of those blocks and forgets the events
+// detected in them.
+func (w *TronWatcher) emitReorged(fromBlock uint64) {
+	w.dispatch.seen.forget(w.chainID, fromBlock)
+	for _, event := range w.milestones.orphan(fromBlock) {
+		w.dispatch.dispatch(&w.gate, event)
+	}
+	for _, event := range w.dispatch.balances.revert(w.chainID, fromBlock) {
+		w.dispatch.dispatch(&w.gate, event)
+	}
+}
+
+// acceptHeadTimestamp guards against nodes that report a fresh head before
+// its timestamp is populated (zero) or serve a head older than one already
+// seen. Such a head is treated as a transient node issue: the tick is
+// skipped, and repeated occurrences are escalated to an error.
+func (w *TronWatcher) acceptHeadTimestamp(ts int64) bool {
+	var reason string
+	switch {
+	case ts <= 0:
+		reason = "zero head timestamp"
+	case ts < w.lastHeadTimestamp:
+		reason = "head timestamp went backwards"
+	default:
+		w.lastHeadTimestamp = ts
+		w.badHeads = 0
+		return true
+	}
+
+	w.badHeads++
+	err := fmt.Errorf("%s (%d)", reason, ts)
+	w.health.observeError(err)
+
+	if w.cfg.BadHeadAlertAfter > 0 && w.badHeads >= w.cfg.BadHeadAlertAfter {
+		log.Error().Err(err).Str("chain", w.chainName).Int("consecutive", w.badHeads).Msg("TRON node keeps returning implausible head blocks")
+	} else {
+		log.Warn().Err(err).Str("chain", w.chainName).Msg("Skipping tick on implausible TRON head block")
+	}
+	return false
+}
+
+// trackHead updates head health and alerts on halt/resume transitions.
+func (w *TronWatcher) trackHead(head uint64) {
+	halted, resumed := w.health.observeHead(head)
+	if halted || resumed {
+		w.dispatch.dispatch(&w.gate, haltEvent(w.chainID, w.chainName, w.health.snapshot(), halted))
+	}
+}
+
+// Health reports whether the chain head is advancing, stalled or unreachable,
+// along with recently detected reorgs.
+func (w *TronWatcher) Health() ChainHealth {
+	health := w.health.snapshot()
+	health.RecentReorgs = w.reorgs.recent()
+	health.Paused = w.paused.Load()
+	return health
+}
+
+// ReorgHistory returns recently detected reorgs, oldest first.
+func (w *TronWatcher) ReorgHistory() []ReorgInfo {
+	return w.reorgs.recent()
+}
+
+// processBlock fetches a TRON block and scans its transactions for TRC20
+// transfers. Transient RPC failures are retried per the chain's RPCRetry
+// policy; if they persist the error is returned before anything in the
+// block is emitted, so the caller can retry the whole block later. A block
+// or transaction info that fails permanently is logged and skipped.
+func (w *TronWatcher) processBlock(ctx context.Context, blockNum int64, currentBlock int64) error {
+	_, err := w.scanBlock(ctx, blockNum, currentBlock, true)
+	return err
+}
+
+// scanBlock is processBlock's body, also returning the number of events
+// emitted. Live scans track the block for reorg detection and emit its
+// block event; backfills of historical blocks do neither, as they run
+// beside the live loop and out of its order.
+func (w *TronWatcher) scanBlock(ctx context.Context, blockNum int64, currentBlock int64, live bool) (int, error) {
+	// A panic skips this block rather than stopping the loop
+	defer recoverPanic(w.cfg.RecoverPanics, w.chainName, fmt.Sprintf("block %d", blockNum))
+
+	block, err := tronCall(ctx, w, "GetBlockByNum", func() (*api.BlockExtention, error) {
+		return w.client.GetBlockByNum(blockNum)
+	})
 	if err != nil {
+		if isSizeLimitError(err) {
+			return w.processBlockTxInfos(ctx, blockNum, currentBlock, live)
+		}
+		if isTransientError(err) {
+			return 0, fmt.Errorf("get block: %w", err)
+		}
 		log.Error().Err(err).Int64("block", blockNum).Str("chain", w.chainName).Msg("Failed to get TRON block")
-		return
+		return 0, nil
 	}
 
 	if block == nil {
-		return
+		return 0, nil
 	}
 
-	for _, tx := range block.GetTransactions() {
-		if tx == nil || tx.GetTransaction() == nil {
+	if live {
+		if reorg := w.reorgs.observe(uint64(blockNum), hex.EncodeToString(block.GetBlockid()), hex.EncodeToString(block.GetBlockHeader().GetRawData().GetParentHash()), w.parentHash(ctx)); reorg != nil {
+			w.confirmations.observe(*reorg)
+			w.dispatch.dispatchReorg(&w.gate, newReorgEvent(*reorg))
+			w.emitReorged(reorg.FromBlock)
+		}
+	}
+
+	// Skip blocks older than the cutoff before fetching any tx info
+	timestamp := time.Unix(block.GetBlockHeader().GetRawData().GetTimestamp()/1000, 0)
+	if timestamp.Before(w.cfg.MinEventTimestamp) {
+		log.Debug().Int64("block", blockNum).Str("chain", w.chainName).Msg("Block older than MinEventTimestamp, skipping")
+		return 0, nil
+	}
+
+	// Transaction infos (for TRC20 event logs) are fetched concurrently and
+	// all collected before any event is emitted, so a block abandoned on a
+	// transient failure has emitted nothing when it is retried. Info the
+	// node hasn't indexed yet counts as transient: the block is held, and
+	// the checkpoint stays before it, until every transaction's info is in
+	var fetched []fetchedTxInfo
+	for f := range w.fetchTxInfos(ctx, blockNum, block.GetTransactions()) {
+		if f.err != nil {
+			if isTransientError(f.err) {
+				return 0, fmt.Errorf("get transaction info %s: %w", f.txID, f.err)
+			}
+			log.Warn().Err(f.err).Str("tx", f.txID).Int64("block", blockNum).Str("chain", w.chainName).Msg("Failed to get TRON transaction info, skipping")
 			continue
 		}
+		fetched = append(fetched, f)
+	}
 
+	// TRX and TRC-10 transfers are in the raw transactions, not in logs
+	matched := 0
+	seen := make(map[string]bool, len(block.GetTransactions()))
+	for _, tx := range block.GetTransactions() {
 		txID := hex.EncodeToString(tx.GetTxid())
-
-		// Get transaction info for TRC20 event logs
-		txInfo, err := w.client.GetTransactionInfoByID(txID)
-		if err != nil {
+		if tx.GetTransaction() == nil || seen[txID] {
 			continue
 		}
+		seen[txID] = true
+		matched += w.processNativeTransfers(tx.GetTransaction(), txID, blockNum, currentBlock, timestamp)
+	}
+
+	for _, f := range fetched {
+		matched += w.processTxInfo(f.tx, f.txID, f.info, blockNum, currentBlock, timestamp)
+	}
+
+	if live && w.cfg.EmitBlockEvents {
+		w.emitBlockProcessed(blockNum, hex.EncodeToString(block.GetBlockid()), timestamp, len(block.GetTransactions()), matched)
+	}
+	return matched, nil
+}
+
+// processBlockTxInfos scans a block through its transaction info list, used
+// when the full block is too large to fetch. Raw transactions aren't
+// available on this path, so memo and fee payer are left empty.
+func (w *TronWatcher) processBlockTxInfos(ctx context.Context, blockNum int64, currentBlock int64, live bool) (int, error) {
+	infos, err := tronCall(ctx, w, "GetBlockInfoByNum", func() (*api.TransactionInfoList, error) {
+		return w.blockTxInfos(blockNum)
+	})
+	if err != nil {
+		if isTransientError(err) {
+			return 0, fmt.Errorf("get block transaction infos: %w", err)
+		}
+		log.Error().Err(err).Int64("block", blockNum).Str("chain", w.chainName).Msg("Failed to get TRON block transaction infos")
+		return 0, nil
+	}
+
+	matched := 0
+	var timestamp time.Time
+	for _, txInfo := range infos.GetTransactionInfo() {
 		if txInfo == nil {
 			continue
 		}
 
-		// Scan logs for TRC20 Transfer events
-		for _, eventLog := range txInfo.GetLog() {
-			if eventLog == nil || len(eventLog.GetTopics()) < 3 {
-				continue
-			}
+		timestamp = time.Unix(txInfo.GetBlockTimeStamp()/1000, 0)
+		if timestamp.Before(w.cfg.MinEventTimestamp) {
+			return matched, nil
+		}
 
-			// Check Transfer event signature
-			topicSig := hex.EncodeToString(eventLog.GetTopics()[0])
-			if topicSig != trc20TransferSig {
-				continue
-			}
+		matched += w.processTxInfo(nil, hex.EncodeToString(txInfo.GetId()), txInfo, blockNum, currentBlock, timestamp)
+	}
 
-			// Parse from/to addresses (32-byte topic → TRON Base58)
-			fromAddr := hexTopicToTronAddress(eventLog.GetTopics()[1])
-			toAddr := hexTopicToTronAddress(eventLog.GetTopics()[2])
+	// The block hash isn't part of the tx info list (nor, for an empty
+	// block, its timestamp)
+	if live && w.cfg.EmitBlockEvents {
+		w.emitBlockProcessed(blockNum, "", timestamp, len(infos.GetTransactionInfo()), matched)
+	}
+	return matched, nil
+}
 
-			// Check if either address is watched
-			w.mu.RLock()
-			isRelevant := w.addresses[fromAddr] || w.addresses[toAddr]
-			w.mu.RUnlock()
+// processTxInfo scans one transaction's logs for TRC20 transfers and returns
+// the number of events emitted. tx may be nil when only the transaction info
+// is available.
+func (w *TronWatcher) processTxInfo(tx *core.Transaction, txID string, txInfo *core.TransactionInfo, blockNum, currentBlock int64, timestamp time.Time) int {
+	memo := tronTxMemo(tx)
 
-			if !isRelevant {
-				continue
-			}
+	var fee *txFee
+	if w.cfg.IncludeFeeInfo {
+		fee = tronTxFee(tx, txInfo, w.addrPrefix)
+	}
 
-			// Parse value from data
-			value := new(big.Int).SetBytes(eventLog.GetData())
-
-			// Token contract address (hex → Base58)
-			tokenAddr := hexBytesToTronAddress(eventLog.GetAddress())
-
-			// Calculate confirmations
-			confirmations := currentBlock - blockNum
-			confirmed := uint64(confirmations) >= w.cfg.Confirmations
-
-			event := &ChainEvent{
-				ChainID:      w.chainID,
-				ChainName:    w.chainName,
-				EventType:    "trc20_transfer",
-				TxHash:       txID,
-				BlockNumber:  uint64(blockNum),
-				FromAddress:  fromAddr,
-				ToAddress:    toAddr,
-				Value:        value.String(),
-				TokenAddress: tokenAddr,
-				Timestamp:    time.Unix(block.GetBlockHeader().GetRawData().GetTimestamp()/1000, 0),
-				Confirmed:    confirmed,
-			}
+	// Scan logs for TRC20 Transfer events
+	emitted := 0
+	sawTransfer := false
+	for logIndex, eventLog := range txInfo.GetLog() {
+		if eventLog == nil {
+			continue
+		}
 
-			log.Info().
-				Str("chain", w.chainName).
-				Str("tx", txID).
-				Str("from", fromAddr).
-				Str("to", toAddr).
-				Str("value", value.String()).
-				Bool("confirmed", confirmed).
-				Msg("TRC20 Transfer event detected")
-
-			for _, handler := range w.handlers {
-				go handler(event)
+		// Check Transfer event signature (standard or per-chain override);
+		// anything else is only of interest in capture-unknown mode
+		topics := eventLog.GetTopics()
+		if len(topics) < 3 || !w.transferSigs[hex.EncodeToString(topics[0])] {
+			if w.cfg.CaptureUnknownLogs && w.processUnknownLog(eventLog, txID, uint(logIndex), blockNum, currentBlock, timestamp) {
+				emitted++
 			}
+			continue
+		}
+		sawTransfer = true
+
+		// Token contract address (hex → Base58), converted once: a malformed
+		// one is counted and logged by the conversion
+		tokenAddr := hexBytesToTronAddress(eventLog.GetAddress(), w.addrPrefix)
+
+		// Token allowlist: logs of other contracts go no further
+		if !w.tokenWatched(tokenAddr) {
+			continue
+		}
+
+		// Parse from/to addresses (32-byte topic → TRON Base58)
+		fromAddr := hexTopicToTronAddress(eventLog.GetTopics()[1], w.addrPrefix)
+		toAddr := hexTopicToTronAddress(eventLog.GetTopics()[2], w.addrPrefix)
+
+		// Check if either address is watched
+		w.mu.RLock()
+		var watched string
+		switch {
+		case w.addresses[toAddr]:
+			watched = toAddr
+		case w.addresses[fromAddr]:
+			watched = fromAddr
+		}
+		w.mu.RUnlock()
+		isRelevant := watched != ""
+
+		// Malformed token addresses are dropped unless configured to be
+		// emitted flagged for investigation
+		malformedToken := tokenAddr == ""
+		if malformedToken && !w.cfg.EmitMalformedTokenAddress {
+			log.Warn().Str("chain", w.chainName).Str("tx", txID).Msg("Dropping transfer with malformed token address")
+			continue
+		}
+
+		// Token-scoped mode emits every transfer of the token unless
+		// unwatched ones are configured to be dropped
+		if !isRelevant && (!w.scopedTokens[tokenAddr] || w.cfg.DropUnwatchedTransfers) {
+			continue
+		}
+		// Both sides the zero address: an anomaly, dropped unless configured
+		zeroToZero := isZeroAddressTopic(eventLog.GetTopics()[1]) && isZeroAddressTopic(eventLog.GetTopics()[2])
+		if zeroToZero && dropZeroToZero(w.chainName, txID, w.cfg.EmitZeroAddressTransfers) {
+			continue
+		}
+
+		// Parse value from data
+		value := new(big.Int).SetBytes(eventLog.GetData())
+
+		// Calculate confirmations
+		confirmations := confirmationsAt(uint64(currentBlock), uint64(blockNum))
+		confirmed := w.isFinal(confirmations >= w.confirmations.required(), blockNum)
+
+		event := &ChainEvent{
+			ChainID:         w.chainID,
+			ChainName:       w.chainName,
+			EventType:       "trc20_transfer",
+			TxHash:          txID,
+			BlockNumber:     uint64(blockNum),
+			FromAddress:     fromAddr,
+			ToAddress:       toAddr,
+			Value:           value.String(),
+			NormalizedValue: w.normalizeValue(tokenAddr, value),
+			TokenAddress:    tokenAddr,
+			Timestamp:       timestamp,
+			Confirmed:       confirmed,
+			Confirmations:   confirmations,
+			TouchesWatched:  isRelevant,
+			WatchedAddress:  watched,
+			Memo:            memo,
+			EventID:         eventID(w.chainID, txID, uint(logIndex)),
+			LogIndex:        uint(logIndex),
+
+			TokenAddressMalformed: malformedToken,
+			ExceedsTotalSupply:    w.exceedsTotalSupply(tokenAddr, value),
+			ZeroAddressTransfer:   zeroToZero,
+		}
+		w.tokens.annotate(event, value)
+		if belowMinTransferValue(w.cfg.MinTransferValue, event) {
+			continue
+		}
+		event.FinalityStatus = w.milestones.finality(event)
+		if fee != nil {
+			event.FeePaid = fee.paid
+			event.FeePayer = fee.payer
+		}
+
+		log.Info().
+			Str("chain", w.chainName).
+			Str("tx", txID).
+			Str("from", fromAddr).
+			Str("to", toAddr).
+			Str("value", value.String()).
+			Bool("confirmed", confirmed).
+			Msg("TRC20 Transfer event detected")
+
+		if w.emit(event) {
+			w.milestones.track(event)
+		}
+		emitted++
+	}
+
+	// Deep inspection: tokens that move balances without a Transfer log.
+	// The raw transaction is needed, so the tx info fallback path can't.
+	if w.cfg.DecodeTransferCalldata && !sawTransfer && tx != nil &&
+		w.processTransferCalldata(tx, txID, txInfo, fee, blockNum, currentBlock, timestamp) {
+		emitted++
+	}
+	return emitted
+}
+
+// scopedTronTokens builds the token-scoped mode contract set.
+func scopedTronTokens(tokens []string) map[string]bool {
+	set := make(map[string]bool, len(tokens))
+	for _, token := range tokens {
+		if token = strings.TrimSpace(token); token != "" {
+			set[token] = true
 		}
 	}
+	return set
 }
 
+// watchedTronTokens builds the WatchedTokens set, keeping only TRON
+// addresses: a list shared with EVM chains leaves the TRON chains
+// unrestricted unless it names a TRON token.
+func watchedTronTokens(tokens []string) map[string]bool {
+	set := make(map[string]bool, len(tokens))
+	for _, token := range tokens {
+		if token = strings.TrimSpace(token); isTronAddressFormat(token) {
+			set[token] = true
+		}
+	}
+	return set
+}
+
+// tokenWatched reports whether transfers of the contract (Base58, "" if
+// malformed) pass the WatchedTokens allowlist.
+func (w *TronWatcher) tokenWatched(contract string) bool {
+	return len(w.watchedTokens) == 0 || w.watchedTokens[contract]
+}
+
+// tronTxMemo returns the transaction's memo (raw_data.data). Memos are
+// usually text; anything that isn't valid UTF-8 is returned as 0x-hex so it
+// survives JSON encoding intact.
+func tronTxMemo(tx *core.Transaction) string {
+	data := tx.GetRawData().GetData()
+	if len(data) == 0 {
+		return ""
+	}
+	if utf8.Valid(data) {
+		return string(data)
+	}
+	return "0x" + hex.EncodeToString(data)
+}
+
+// normalizeValue scales a raw TRC20 amount by the token's decimals, from
+// the TokenRegistry or else resolved on chain. Returns "" when the token
+// isn't registered and decimal resolution is disabled or fails.
+func (w *TronWatcher) normalizeValue(tokenAddr string, value *big.Int) string {
+	if info, ok := w.tokens.lookup(tokenAddr); ok {
+		return FormatAmount(value, info.Decimals)
+	}
+	if w.tokenMeta == nil || tokenAddr == "" {
+		return ""
+	}
+	decimals, ok := w.tokenMeta.decimals(tokenAddr)
+	if !ok {
+		return ""
+	}
+	return FormatAmount(value, decimals)
+}
+
+// exceedsTotalSupply flags a transfer larger than the token's total supply,
+// a sign of an overflow exploit or a fake token. Off unless CheckTotalSupply.
+func (w *TronWatcher) exceedsTotalSupply(tokenAddr string, value *big.Int) bool {
+	if w.tokenMeta == nil || !w.tokenMeta.fetchSupply || tokenAddr == "" {
+		return false
+	}
+	return w.tokenMeta.exceedsSupply(tokenAddr, value)
+}
+
+// tronMainnetPrefix is the address prefix byte of TRON mainnet (and of the
+// current testnets); chains can override it with AddressPrefix.
+const tronMainnetPrefix = config.TronMainnetPrefix
+
 // hexTopicToTronAddress converts a 32-byte event topic to a TRON Base58Check address.
 // Topics contain the 20-byte address left-padded to 32 bytes.
-func hexTopicToTronAddress(topic []byte) string {
+func hexTopicToTronAddress(topic []byte, prefix byte) string {
 	if len(topic) < 20 {
 		return ""
 	}
 	// Extract last 20 bytes
 	addrBytes := topic[len(topic)-20:]
-	return rawBytesToTronAddress(addrBytes)
+	return rawBytesToTronAddress(addrBytes, prefix)
 }
 
-// hexBytesToTronAddress converts raw address bytes to TRON Base58Check
-func hexBytesToTronAddress(raw []byte) string {
-	if len(raw) == 0 {
-		return ""
-	}
-	// If already 21 bytes with 0x41 prefix, use directly
-	if len(raw) == 21 && raw[0] == 0x41 {
+// malformedTronAddresses counts addresses hexBytesToTronAddress couldn't
+// convert (wrong length or prefix), across all TRON watchers.
+var malformedTronAddresses atomic.Uint64
+
+// MalformedTronAddresses returns how many malformed TRON addresses have
+// been seen since startup.
+func MalformedTronAddresses() uint64 {
+	return malformedTronAddresses.Load()
+}
+
+// hexBytesToTronAddress converts raw address bytes to TRON Base58Check.
+// Only a 20-byte address or a 21-byte one with the network prefix is
+// accepted; anything else is logged and counted, and yields "" rather than
+// a truncated, valid-looking address.
+func hexBytesToTronAddress(raw []byte, prefix byte) string {
+	switch {
+	case len(raw) == 21 && raw[0] == prefix:
 		return base58CheckEncode(raw)
+	case len(raw) == 20:
+		return rawBytesToTronAddress(raw, prefix)
 	}
-	// Otherwise treat as 20-byte address
-	if len(raw) >= 20 {
-		return rawBytesToTronAddress(raw[len(raw)-20:])
-	}
+
+	malformedTronAddresses.Add(1)
+	log.Warn().Int("length", len(raw)).Str("raw", hex.EncodeToString(raw)).Msg("Malformed TRON address length")
 	return ""
 }
 
-// rawBytesToTronAddress prepends TRON mainnet prefix (0x41) and encodes to Base58Check
-func rawBytesToTronAddress(addrBytes []byte) string {
+// rawBytesToTronAddress prepends the network prefix byte and encodes to Base58Check
+func rawBytesToTronAddress(addrBytes []byte, prefix byte) string {
 	fullAddr := make([]byte, 21)
-	fullAddr[0] = 0x41 // TRON mainnet prefix
+	fullAddr[0] = prefix
 	copy(fullAddr[1:], addrBytes)
 	return base58CheckEncode(fullAddr)
 }
@@ -261,7 +913,7 @@ func doubleSHA256(data []byte) []byte {
 
 // base58Encode encodes bytes using the Base58 alphabet (Bitcoin/TRON style)
 func base58Encode(input []byte) string {
-	const alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+	const alphabet = config.Base58Alphabet
 
 	result := make([]byte, 0, len(input)*2)
 	x := new(big.Int).SetBytes(input)
@@ -290,6 +942,22 @@ func base58Encode(input []byte) string {
 	return string(result)
 }
 
+// transferSigSet normalizes configured Transfer topic0 signatures to a
+// lowercase hex set, defaulting to the standard Transfer(address,address,uint256).
+func transferSigSet(sigs []string) map[string]bool {
+	set := make(map[string]bool, len(sigs))
+	for _, sig := range sigs {
+		sig = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(sig), "0x"))
+		if len(sig) == 64 {
+			set[sig] = true
+		}
+	}
+	if len(set) == 0 {
+		set[trc20TransferSig] = true
+	}
+	return set
+}
+
 // isTronChain checks if a chain config is for TRON
 func isTronChain(cfg config.ChainConfig) bool {
 	return cfg.Type == "tron" || strings.HasPrefix(strings.ToLower(cfg.Name), "tron")
@@ -0,0 +1,547 @@
+package watcher
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fbsobreira/gotron-sdk/pkg/proto/api"
+	"github.com/fbsobreira/gotron-sdk/pkg/proto/core"
+	"github.com/protocol-bank/event-indexer/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeTronClient is an in-memory tronRPC serving canned blocks and tx infos.
+type fakeTronClient struct {
+	mu      sync.Mutex
+	head    int64
+	blocks  map[int64]*api.BlockExtention
+	txInfos map[string]*core.TransactionInfo
+	fetched []int64
+
+	// onFetch, if set, is called before GetBlockByNum returns
+	onFetch func(num int64)
+
+	// headErr, if set, is returned by GetNowBlock
+	headErr error
+
+	// headBlock, if set, is returned by GetNowBlock instead of a header at head
+	headBlock *api.BlockExtention
+
+	// forks bumps the block ID of a height to simulate a reorg
+	forks map[int64]byte
+
+	// blockErrs, if set for a height, is returned by GetBlockByNum
+	blockErrs map[int64]error
+
+	// solidified is reported by GetNodeInfo
+	solidified int64
+}
+
+func newFakeTronClient(head int64) *fakeTronClient {
+	return &fakeTronClient{
+		head:    head,
+		blocks:  make(map[int64]*api.BlockExtention),
+		txInfos: make(map[string]*core.TransactionInfo),
+		forks:   make(map[int64]byte),
+
+		blockErrs: make(map[int64]error),
+	}
+}
+
+// reorg replaces block n with a sibling block.
+func (f *fakeTronClient) reorg(n int64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.forks[n]++
+}
+
+// blockID mimics TRON block IDs: the height in the first 8 bytes, followed
+// by a fork marker. Callers hold f.mu.
+func (f *fakeTronClient) blockID(n int64) []byte {
+	id := make([]byte, 32)
+	binary.BigEndian.PutUint64(id, uint64(n))
+	id[8] = f.forks[n]
+	return id
+}
+
+func (f *fakeTronClient) setHead(n int64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.head = n
+}
+
+func (f *fakeTronClient) setHeadErr(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.headErr = err
+}
+
+func (f *fakeTronClient) setHeadBlock(block *api.BlockExtention) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.headBlock = block
+}
+
+func (f *fakeTronClient) fetchedBlocks() []int64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]int64(nil), f.fetched...)
+}
+
+func (f *fakeTronClient) GetNowBlock() (*api.BlockExtention, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.headErr != nil {
+		return nil, f.headErr
+	}
+	if f.headBlock != nil {
+		return f.headBlock, nil
+	}
+	return testTronHeader(f.head), nil
+}
+
+func (f *fakeTronClient) GetNodeInfo() (*core.NodeInfo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return &core.NodeInfo{SolidityBlock: fmt.Sprintf("Num:%d,ID:%016x", f.solidified, f.solidified)}, nil
+}
+
+func (f *fakeTronClient) GetBlockByNum(num int64) (*api.BlockExtention, error) {
+	f.mu.Lock()
+	f.fetched = append(f.fetched, num)
+	if err := f.blockErrs[num]; err != nil {
+		f.mu.Unlock()
+		return nil, err
+	}
+	block, ok := f.blocks[num]
+	if !ok {
+		block = testTronHeader(num)
+	}
+	block.Blockid = f.blockID(num)
+	block.BlockHeader.RawData.ParentHash = f.blockID(num - 1)
+	onFetch := f.onFetch
+	f.mu.Unlock()
+
+	if onFetch != nil {
+		onFetch(num)
+	}
+	return block, nil
+}
+
+func (f *fakeTronClient) GetTransactionInfoByID(id string) (*core.TransactionInfo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	info, ok := f.txInfos[id]
+	if !ok {
+		return nil, fmt.Errorf("transaction info not found")
+	}
+	return info, nil
+}
+
+func (f *fakeTronClient) GetBlockInfoByNum(num int64) (*api.TransactionInfoList, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	list := &api.TransactionInfoList{}
+	for _, tx := range f.blocks[num].GetTransactions() {
+		info := f.txInfos[hex.EncodeToString(tx.GetTxid())]
+		info.BlockTimeStamp = num * 3000
+		list.TransactionInfo = append(list.TransactionInfo, info)
+	}
+	return list, nil
+}
+
+// addTransfer registers a TRC20 Transfer log in the given block.
+func (f *fakeTronClient) addTransfer(blockNum int64, txID string, token, from, to []byte, value *big.Int) {
+	f.addLogs(blockNum, txID, &core.TransactionInfo_Log{
+		Address: token,
+		Topics:  [][]byte{mustHex(trc20TransferSig), leftPad32(from), leftPad32(to)},
+		Data:    leftPad32(value.Bytes()),
+	})
+}
+
+// addLogs registers a transaction carrying the given logs in the given block.
+func (f *fakeTronClient) addLogs(blockNum int64, txID string, logs ...*core.TransactionInfo_Log) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	block, ok := f.blocks[blockNum]
+	if !ok {
+		block = testTronHeader(blockNum)
+		f.blocks[blockNum] = block
+	}
+	block.Transactions = append(block.Transactions, &api.TransactionExtention{
+		Transaction: &core.Transaction{RawData: &core.TransactionRaw{}},
+		Txid:        mustHex(txID),
+	})
+	f.txInfos[txID] = &core.TransactionInfo{
+		Id:          mustHex(txID),
+		BlockNumber: blockNum,
+		Log:         logs,
+	}
+}
+
+// testTronHeader builds a header-only block; timestamps advance 3s per block.
+func testTronHeader(num int64) *api.BlockExtention {
+	return &api.BlockExtention{
+		BlockHeader: &core.BlockHeader{
+			RawData: &core.BlockHeaderRaw{Number: num, Timestamp: num * 3000},
+		},
+	}
+}
+
+// newTestTronWatcher builds a TronWatcher around a fake client without dialing.
+func newTestTronWatcher(client tronRPC) *TronWatcher {
+	return newTronWatcher(config.ChainConfig{
+		ChainID:       728126428,
+		Name:          "TRON Test",
+		Confirmations: 19,
+		Type:          "tron",
+	}, client)
+}
+
+// testTronAddress returns a 20-byte address filled with b and its Base58 form.
+func testTronAddress(b byte) ([]byte, string) {
+	raw := make([]byte, 20)
+	for i := range raw {
+		raw[i] = b
+	}
+	return raw, rawBytesToTronAddress(raw, tronMainnetPrefix)
+}
+
+func leftPad32(b []byte) []byte {
+	out := make([]byte, 32)
+	copy(out[32-len(b):], b)
+	return out
+}
+
+func mustHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func TestTronWatcher_CustomTransferSignature(t *testing.T) {
+	const customSig = "e19260aff97b920c7df27010903aeb9c8d2be5d310a2c67824cf3f15396e4c16"
+
+	client := newFakeTronClient(200)
+	token, _ := testTronAddress(0xaa)
+	from, _ := testTronAddress(0x11)
+	to, toAddr := testTronAddress(0x22)
+	client.addTransfer(190, "d190", token, from, to, big.NewInt(1))
+	client.addLogs(190, "e190", &core.TransactionInfo_Log{
+		Address: token,
+		Topics:  [][]byte{mustHex(customSig), leftPad32(from), leftPad32(to)},
+		Data:    leftPad32(big.NewInt(2).Bytes()),
+	})
+
+	cfg := config.ChainConfig{ChainID: 1, Name: "TRON Custom", Type: "tron"}
+
+	t.Run("default matches only the standard signature", func(t *testing.T) {
+		w := newTronWatcher(cfg, client)
+		w.AddTronAddress(toAddr)
+		assert.Equal(t, []string{"d190"}, collectTronTxs(t, w, 190, 200))
+	})
+
+	t.Run("override matches the custom signature", func(t *testing.T) {
+		custom := cfg
+		custom.TransferEventSigs = []string{"0x" + strings.ToUpper(customSig)}
+		w := newTronWatcher(custom, client)
+		w.AddTronAddress(toAddr)
+		assert.Equal(t, []string{"e190"}, collectTronTxs(t, w, 190, 200))
+	})
+
+	t.Run("override can list both", func(t *testing.T) {
+		both := cfg
+		both.TransferEventSigs = []string{trc20TransferSig, customSig}
+		w := newTronWatcher(both, client)
+		w.AddTronAddress(toAddr)
+		assert.ElementsMatch(t, []string{"d190", "e190"}, collectTronTxs(t, w, 190, 200))
+	})
+}
+
+// collectTronTxs processes one block and returns the tx hashes of emitted events.
+func collectTronTxs(t *testing.T, w *TronWatcher, blockNum, head int64) []string {
+	t.Helper()
+
+	var mu sync.Mutex
+	var txs []string
+	w.dispatch.addHandler(func(event *ChainEvent) error {
+		mu.Lock()
+		defer mu.Unlock()
+		txs = append(txs, event.TxHash)
+		return nil
+	})
+
+	w.gate.enter()
+	w.processBlock(context.Background(), blockNum, head)
+	w.gate.leave()
+	w.gate.inflight.Wait() // unlike drain, leaves the watcher usable
+
+	sort.Strings(txs)
+	return txs
+}
+
+func TestTronWatcher_MinEventTimestamp(t *testing.T) {
+	client := newFakeTronClient(200)
+	token, _ := testTronAddress(0xaa)
+	from, _ := testTronAddress(0x11)
+	to, toAddr := testTronAddress(0x22)
+	client.addTransfer(189, "a189", token, from, to, big.NewInt(1))
+	client.addTransfer(190, "a190", token, from, to, big.NewInt(2))
+
+	w := newTestTronWatcher(client)
+	w.cfg.MinEventTimestamp = time.Unix(190*3, 0)
+	w.AddTronAddress(toAddr)
+
+	assert.Empty(t, collectTronTxs(t, w, 189, 200))
+	assert.Equal(t, []string{"a190"}, collectTronTxs(t, w, 190, 200))
+}
+
+func TestTronWatcher_Memo(t *testing.T) {
+	client := newFakeTronClient(200)
+	token, _ := testTronAddress(0xaa)
+	from, _ := testTronAddress(0x11)
+	to, toAddr := testTronAddress(0x22)
+	client.addTransfer(190, "a190", token, from, to, big.NewInt(1))
+	client.addTransfer(190, "b190", token, from, to, big.NewInt(2))
+	client.addTransfer(190, "c190", token, from, to, big.NewInt(3))
+	txs := client.blocks[190].Transactions
+	txs[0].Transaction.RawData.Data = []byte("subaccount-8841")
+	txs[1].Transaction.RawData.Data = []byte{0xff, 0x00, 0x01}
+
+	w := newTestTronWatcher(client)
+	w.AddTronAddress(toAddr)
+
+	var mu sync.Mutex
+	memos := make(map[string]string)
+	w.dispatch.addHandler(func(event *ChainEvent) error {
+		mu.Lock()
+		defer mu.Unlock()
+		memos[event.TxHash] = event.Memo
+		return nil
+	})
+	collectTronTxs(t, w, 190, 200)
+
+	assert.Equal(t, map[string]string{
+		"a190": "subaccount-8841",
+		"b190": "0xff0001",
+		"c190": "",
+	}, memos)
+}
+
+func TestTronWatcher_ZeroTimestampHead(t *testing.T) {
+	ctx := context.Background()
+	client := newFakeTronClient(100)
+	_, addr := testTronAddress(0x22)
+
+	w := newTestTronWatcher(client)
+	w.cfg.BadHeadAlertAfter = 2
+	w.AddTronAddress(addr)
+	w.poll(ctx) // baseline at 100
+	assert.Equal(t, int64(100), w.lastBlock)
+
+	// Fresh head without a populated timestamp: skip the tick entirely
+	client.setHeadBlock(&api.BlockExtention{
+		BlockHeader: &core.BlockHeader{RawData: &core.BlockHeaderRaw{Number: 105}},
+	})
+	w.poll(ctx)
+	w.poll(ctx)
+	assert.Equal(t, int64(100), w.lastBlock)
+	assert.Empty(t, client.fetchedBlocks())
+	assert.Equal(t, 2, w.badHeads)
+	health := w.Health()
+	assert.Equal(t, HealthRPCError, health.Status)
+	assert.Contains(t, health.LastError, "zero head timestamp")
+	assert.Equal(t, uint64(100), health.Head, "bogus head is not trusted")
+
+	// A head older than one already seen is rejected the same way
+	stale := testTronHeader(106)
+	stale.BlockHeader.RawData.Timestamp = 1000
+	client.setHeadBlock(stale)
+	w.poll(ctx)
+	assert.Equal(t, int64(100), w.lastBlock)
+	assert.Contains(t, w.Health().LastError, "went backwards")
+
+	// Node recovers
+	client.setHeadBlock(nil)
+	client.setHead(102)
+	w.poll(ctx)
+	assert.Equal(t, int64(102), w.lastBlock)
+	assert.Zero(t, w.badHeads)
+	assert.Equal(t, HealthOK, w.Health().Status)
+}
+
+func TestHexBytesToTronAddress(t *testing.T) {
+	raw, want := testTronAddress(0x22)
+	assert.Equal(t, want, hexBytesToTronAddress(raw, tronMainnetPrefix))
+	assert.Equal(t, want, hexBytesToTronAddress(append([]byte{0x41}, raw...), tronMainnetPrefix))
+
+	before := MalformedTronAddresses()
+	assert.Empty(t, hexBytesToTronAddress(raw[:19], tronMainnetPrefix))
+	assert.Empty(t, hexBytesToTronAddress(nil, tronMainnetPrefix))
+	assert.Empty(t, hexBytesToTronAddress(append([]byte{0xa0}, raw...), tronMainnetPrefix), "21 bytes with the wrong prefix")
+	assert.Empty(t, hexBytesToTronAddress(leftPad32(raw), tronMainnetPrefix), "32 bytes aren't truncated")
+	assert.Equal(t, before+4, MalformedTronAddresses())
+}
+
+func TestTronWatcher_MalformedTokenAddress(t *testing.T) {
+	client := newFakeTronClient(200)
+	from, _ := testTronAddress(0x11)
+	to, toAddr := testTronAddress(0x22)
+	client.addTransfer(190, "bad0", []byte{0x41, 0xaa, 0xbb}, from, to, big.NewInt(5))
+
+	t.Run("dropped by default", func(t *testing.T) {
+		w := newTestTronWatcher(client)
+		w.AddTronAddress(toAddr)
+
+		before := MalformedTronAddresses()
+		assert.Empty(t, collectTronTxs(t, w, 190, 200))
+		assert.Equal(t, before+1, MalformedTronAddresses(), "anomaly counted")
+	})
+
+	t.Run("counted once with a token allowlist", func(t *testing.T) {
+		w := newTestTronWatcher(client)
+		_, usdt := testTronAddress(0xaa)
+		w.watchedTokens = map[string]bool{usdt: true}
+		w.AddTronAddress(toAddr)
+
+		before := MalformedTronAddresses()
+		assert.Empty(t, collectTronTxs(t, w, 190, 200))
+		assert.Equal(t, before+1, MalformedTronAddresses())
+	})
+
+	t.Run("emitted flagged", func(t *testing.T) {
+		w := newTestTronWatcher(client)
+		w.cfg.EmitMalformedTokenAddress = true
+		w.AddTronAddress(toAddr)
+
+		var got []*ChainEvent
+		w.dispatch.addHandler(func(event *ChainEvent) error {
+			got = append(got, event)
+			return nil
+		})
+		collectTronTxs(t, w, 190, 200)
+
+		require.Len(t, got, 1)
+		assert.True(t, got[0].TokenAddressMalformed)
+		assert.Empty(t, got[0].TokenAddress)
+		assert.Equal(t, "5", got[0].Value)
+	})
+}
+
+func TestTronAddressPrefix(t *testing.T) {
+	raw, _ := testTronAddress(0x22)
+
+	mainnet := rawBytesToTronAddress(raw, tronMainnetPrefix)
+	assert.True(t, strings.HasPrefix(mainnet, "T"), mainnet)
+
+	// 0xa0 was the prefix of the early TRON testnets
+	testnet := rawBytesToTronAddress(raw, 0xa0)
+	assert.True(t, strings.HasPrefix(testnet, "2"), testnet)
+	assert.Equal(t, testnet, hexBytesToTronAddress(append([]byte{0xa0}, raw...), 0xa0))
+	assert.Equal(t, testnet, hexTopicToTronAddress(leftPad32(raw), 0xa0))
+
+	// A watcher configured with the prefix decodes log addresses with it
+	client := newFakeTronClient(200)
+	token, _ := testTronAddress(0xaa)
+	from, _ := testTronAddress(0x11)
+	client.addTransfer(190, "a190", token, from, raw, big.NewInt(1))
+
+	w := newTronWatcher(config.ChainConfig{ChainID: 2494104990, Name: "TRON Legacy Testnet", Confirmations: 19, Type: "tron", AddressPrefix: 0xa0}, client)
+	w.AddTronAddress(testnet)
+
+	var got []*ChainEvent
+	w.dispatch.addHandler(func(event *ChainEvent) error {
+		got = append(got, event)
+		return nil
+	})
+	collectTronTxs(t, w, 190, 200)
+
+	require.Len(t, got, 1)
+	assert.Equal(t, testnet, got[0].ToAddress)
+	assert.True(t, strings.HasPrefix(got[0].TokenAddress, "2"), got[0].TokenAddress)
+	assert.Equal(t, tronMainnetPrefix, newTestTronWatcher(client).addrPrefix, "defaults to mainnet")
+}
+
+func TestBase58CheckEncode_RoundTrip(t *testing.T) {
+	const usdt = "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t"
+	raw, err := config.DecodeTronAddress(usdt, tronMainnetPrefix)
+	require.NoError(t, err)
+	assert.Equal(t, usdt, base58CheckEncode(raw))
+
+	testnet := rawBytesToTronAddress(raw[1:], 0xa0)
+	decoded, err := config.DecodeTronAddress(testnet, 0xa0)
+	require.NoError(t, err)
+	assert.Equal(t, raw[1:], decoded[1:])
+}
+
+func TestTronWatcher_AddTronAddressRejectsInvalid(t *testing.T) {
+	w := newTestTronWatcher(newFakeTronClient(100))
+	for _, addr := range []string{
+		"TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6u", // checksum
+		"TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj0t", // not Base58
+		rawBytesToTronAddress(make([]byte, 20), 0xa0),
+	} {
+		added, err := w.AddTronAddress(addr)
+		assert.Error(t, err, addr)
+		assert.False(t, added)
+		assert.False(t, w.isWatched(addr))
+	}
+
+	_, err := w.AddTronAddress("0x2222222222222222222222222222222222222222")
+	assert.ErrorContains(t, err, "want 34 characters starting with T")
+
+	// Surrounding whitespace from config lists is trimmed
+	_, addr := testTronAddress(0x22)
+	added, err := w.AddTronAddress(" " + addr + "\n")
+	require.NoError(t, err)
+	assert.True(t, added)
+	assert.True(t, w.isWatched(addr))
+}
+
+func TestTronWatcher_LogIndex(t *testing.T) {
+	client := newFakeTronClient(200)
+	token, _ := testTronAddress(0xaa)
+	from, _ := testTronAddress(0x11)
+	to, toAddr := testTronAddress(0x22)
+	transfer := func(value int64) *core.TransactionInfo_Log {
+		return &core.TransactionInfo_Log{
+			Address: token,
+			Topics:  [][]byte{mustHex(trc20TransferSig), leftPad32(from), leftPad32(to)},
+			Data:    leftPad32(big.NewInt(value).Bytes()),
+		}
+	}
+	// An approval log first, then two transfers in the same transaction
+	approval := &core.TransactionInfo_Log{Address: token, Topics: [][]byte{mustHex("8c5be1e5ebec7d5bd14f71427d1e84f3dd0314c0f7b2291e5b200ac8c7c3b925")}}
+	client.addLogs(190, "a190", approval, transfer(1), transfer(2))
+
+	w := newTestTronWatcher(client)
+	w.AddTronAddress(toAddr)
+	var mu sync.Mutex
+	byIndex := make(map[uint]*ChainEvent)
+	w.dispatch.addHandler(func(event *ChainEvent) error {
+		mu.Lock()
+		defer mu.Unlock()
+		byIndex[event.LogIndex] = event
+		return nil
+	})
+	collectTronTxs(t, w, 190, 200)
+
+	require.Len(t, byIndex, 2, "one event per Transfer log")
+	assert.Equal(t, "1", byIndex[1].Value)
+	assert.Equal(t, "2", byIndex[2].Value)
+	assert.Equal(t, byIndex[1].TxHash, byIndex[2].TxHash)
+	assert.NotEqual(t, byIndex[1].EventID, byIndex[2].EventID)
+}
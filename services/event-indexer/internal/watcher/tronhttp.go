@@ -0,0 +1,309 @@
+package watcher
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/fbsobreira/gotron-sdk/pkg/proto/api"
+	"github.com/fbsobreira/gotron-sdk/pkg/proto/core"
+	"google.golang.org/protobuf/proto"
+)
+
+// httpTronClient talks to a TRON node through its HTTP API (/wallet/*), for
+// providers that expose TronGrid's HTTP interface but block raw gRPC.
+// Responses are converted to the gotron-sdk protobuf types the gRPC client
+// returns, so the watcher produces the same events over either protocol.
+type httpTronClient struct {
+	baseURL string
+	client  *http.Client
+	maxSize int // response size limit in bytes, 0 = unlimited
+}
+
+func newHTTPTronClient(baseURL string, timeout time.Duration, maxSize int) *httpTronClient {
+	return &httpTronClient{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		client:  &http.Client{Timeout: timeout},
+		maxSize: maxSize,
+	}
+}
+
+// tronZeroAddress is the Base58 zero address, the caller of constant calls.
+const tronZeroAddress = "T9yD14Nj9j7xAB4dbGeiX9h8unkKHxuWwb"
+
+// post calls /wallet/<method> with body as JSON and decodes the response
+// into out. Non-2xx responses are returned as rpc.HTTPError so transient
+// ones (5xx, 429) are retried like EVM HTTP errors.
+func (c *httpTronClient) post(method string, body, out any) error {
+	reqBody := []byte("{}")
+	if body != nil {
+		var err error
+		if reqBody, err = json.Marshal(body); err != nil {
+			return fmt.Errorf("%s: %w", method, err)
+		}
+	}
+	resp, err := c.client.Post(c.baseURL+"/wallet/"+method, "application/json", bytes.NewReader(reqBody))
+	if err != nil {
+		return fmt.Errorf("%s: %w", method, err)
+	}
+	defer resp.Body.Close()
+
+	var reader io.Reader = resp.Body
+	if c.maxSize > 0 {
+		reader = io.LimitReader(resp.Body, int64(c.maxSize)+1)
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return fmt.Errorf("%s: %w", method, err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return rpc.HTTPError{StatusCode: resp.StatusCode, Status: resp.Status, Body: data}
+	}
+	if c.maxSize > 0 && len(data) > c.maxSize {
+		return fmt.Errorf("%s: response larger than max (%d bytes)", method, c.maxSize)
+	}
+
+	// The node reports failures as {"Error": "..."} with status 200
+	var apiErr struct {
+		Error string `json:"Error"`
+	}
+	if json.Unmarshal(data, &apiErr) == nil && apiErr.Error != "" {
+		return fmt.Errorf("%s: %s", method, apiErr.Error)
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("%s: decode response: %w", method, err)
+	}
+	return nil
+}
+
+func (c *httpTronClient) GetNowBlock() (*api.BlockExtention, error) {
+	var block httpBlock
+	if err := c.post("getnowblock", nil, &block); err != nil {
+		return nil, err
+	}
+	return block.toProto()
+}
+
+func (c *httpTronClient) GetBlockByNum(num int64) (*api.BlockExtention, error) {
+	var block httpBlock
+	if err := c.post("getblockbynum", map[string]int64{"num": num}, &block); err != nil {
+		return nil, err
+	}
+	return block.toProto()
+}
+
+// GetTransactionInfoByID mirrors gotron-sdk: an unknown transaction (the
+// node answers {}) is an error, not an empty info.
+func (c *httpTronClient) GetTransactionInfoByID(id string) (*core.TransactionInfo, error) {
+	var info httpTxInfo
+	if err := c.post("gettransactioninfobyid", map[string]string{"value": id}, &info); err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(info.ID, id) {
+		return nil, fmt.Errorf("transaction info not found")
+	}
+	return info.toProto(), nil
+}
+
+func (c *httpTronClient) GetBlockInfoByNum(num int64) (*api.TransactionInfoList, error) {
+	var infos []httpTxInfo
+	if err := c.post("gettransactioninfobyblocknum", map[string]int64{"num": num}, &infos); err != nil {
+		return nil, err
+	}
+	list := &api.TransactionInfoList{TransactionInfo: make([]*core.TransactionInfo, 0, len(infos))}
+	for _, info := range infos {
+		list.TransactionInfo = append(list.TransactionInfo, info.toProto())
+	}
+	return list, nil
+}
+
+func (c *httpTronClient) GetNodeInfo() (*core.NodeInfo, error) {
+	var info struct {
+		SolidityBlock string `json:"solidityBlock"`
+		Block         string `json:"block"`
+	}
+	if err := c.post("getnodeinfo", nil, &info); err != nil {
+		return nil, err
+	}
+	return &core.NodeInfo{SolidityBlock: info.SolidityBlock, Block: info.Block}, nil
+}
+
+// TRC20GetDecimals and TRC20GetTotalSupply serve token metadata lookups
+// (trc20MetadataRPC) through constant contract calls.
+func (c *httpTronClient) TRC20GetDecimals(contractAddress string) (*big.Int, error) {
+	return c.constantUint256(contractAddress, "decimals()")
+}
+
+func (c *httpTronClient) TRC20GetTotalSupply(contractAddress string) (*big.Int, error) {
+	return c.constantUint256(contractAddress, "totalSupply()")
+}
+
+func (c *httpTronClient) constantUint256(contractAddress, selector string) (*big.Int, error) {
+	var result struct {
+		ConstantResult []string `json:"constant_result"`
+	}
+	err := c.post("triggerconstantcontract", map[string]any{
+		"owner_address":     tronZeroAddress,
+		"contract_address":  contractAddress,
+		"function_selector": selector,
+		"visible":           true,
+	}, &result)
+	if err != nil {
+		return nil, err
+	}
+	if len(result.ConstantResult) == 0 {
+		return nil, fmt.Errorf("%s: empty result", selector)
+	}
+	out, err := hex.DecodeString(result.ConstantResult[0])
+	if err != nil || len(out) != 32 {
+		return nil, fmt.Errorf("unexpected %s result", selector)
+	}
+	return new(big.Int).SetBytes(out), nil
+}
+
+// httpBlock is a block as the HTTP API returns it: byte fields are hex, and
+// each transaction carries its protobuf-encoded raw data in raw_data_hex.
+type httpBlock struct {
+	BlockID     string `json:"blockID"`
+	BlockHeader struct {
+		RawData struct {
+			Number         int64  `json:"number"`
+			Timestamp      int64  `json:"timestamp"`
+			ParentHash     string `json:"parentHash"`
+			TxTrieRoot     string `json:"txTrieRoot"`
+			WitnessAddress string `json:"witness_address"`
+			Version        int32  `json:"version"`
+		} `json:"raw_data"`
+		WitnessSignature string `json:"witness_signature"`
+	} `json:"block_header"`
+	Transactions []struct {
+		TxID       string   `json:"txID"`
+		RawDataHex string   `json:"raw_data_hex"`
+		Signature  []string `json:"signature"`
+		Ret        []struct {
+			ContractRet string `json:"contractRet"`
+		} `json:"ret"`
+	} `json:"transactions"`
+}
+
+func (b *httpBlock) toProto() (*api.BlockExtention, error) {
+	raw := b.BlockHeader.RawData
+	header := &core.BlockHeader{
+		RawData: &core.BlockHeaderRaw{
+			Number:         raw.Number,
+			Timestamp:      raw.Timestamp,
+			Version:        raw.Version,
+			ParentHash:     decodeHexField(raw.ParentHash),
+			TxTrieRoot:     decodeHexField(raw.TxTrieRoot),
+			WitnessAddress: decodeHexField(raw.WitnessAddress),
+		},
+		WitnessSignature: decodeHexField(b.BlockHeader.WitnessSignature),
+	}
+	block := &api.BlockExtention{
+		Blockid:      decodeHexField(b.BlockID),
+		BlockHeader:  header,
+		Transactions: make([]*api.TransactionExtention, 0, len(b.Transactions)),
+	}
+
+	for _, tx := range b.Transactions {
+		txRaw := &core.TransactionRaw{}
+		if err := proto.Unmarshal(decodeHexField(tx.RawDataHex), txRaw); err != nil {
+			return nil, fmt.Errorf("block %d: decode transaction %s: %w", raw.Number, tx.TxID, err)
+		}
+		transaction := &core.Transaction{RawData: txRaw}
+		for _, sig := range tx.Signature {
+			transaction.Signature = append(transaction.Signature, decodeHexField(sig))
+		}
+		for _, ret := range tx.Ret {
+			transaction.Ret = append(transaction.Ret, &core.Transaction_Result{
+				ContractRet: core.Transaction_ResultContractResult(core.Transaction_ResultContractResult_value[ret.ContractRet]),
+			})
+		}
+		block.Transactions = append(block.Transactions, &api.TransactionExtention{
+			Txid:        decodeHexField(tx.TxID),
+			Transaction: transaction,
+		})
+	}
+	return block, nil
+}
+
+// httpTxInfo is a transaction info as the HTTP API returns it.
+type httpTxInfo struct {
+	ID              string   `json:"id"`
+	Fee             int64    `json:"fee"`
+	BlockNumber     int64    `json:"blockNumber"`
+	BlockTimeStamp  int64    `json:"blockTimeStamp"`
+	ContractResult  []string `json:"contractResult"`
+	ContractAddress string   `json:"contract_address"`
+	Receipt         struct {
+		EnergyUsage        int64  `json:"energy_usage"`
+		EnergyFee          int64  `json:"energy_fee"`
+		OriginEnergyUsage  int64  `json:"origin_energy_usage"`
+		EnergyUsageTotal   int64  `json:"energy_usage_total"`
+		NetUsage           int64  `json:"net_usage"`
+		NetFee             int64  `json:"net_fee"`
+		Result             string `json:"result"`
+		EnergyPenaltyTotal int64  `json:"energy_penalty_total"`
+	} `json:"receipt"`
+	Log []struct {
+		Address string   `json:"address"`
+		Topics  []string `json:"topics"`
+		Data    string   `json:"data"`
+	} `json:"log"`
+	Result     string `json:"result"`
+	ResMessage string `json:"resMessage"`
+}
+
+func (t *httpTxInfo) toProto() *core.TransactionInfo {
+	receipt := t.Receipt
+	info := &core.TransactionInfo{
+		Id:              decodeHexField(t.ID),
+		Fee:             t.Fee,
+		BlockNumber:     t.BlockNumber,
+		BlockTimeStamp:  t.BlockTimeStamp,
+		ContractAddress: decodeHexField(t.ContractAddress),
+		Receipt: &core.ResourceReceipt{
+			EnergyUsage:        receipt.EnergyUsage,
+			EnergyFee:          receipt.EnergyFee,
+			OriginEnergyUsage:  receipt.OriginEnergyUsage,
+			EnergyUsageTotal:   receipt.EnergyUsageTotal,
+			NetUsage:           receipt.NetUsage,
+			NetFee:             receipt.NetFee,
+			Result:             core.Transaction_ResultContractResult(core.Transaction_ResultContractResult_value[receipt.Result]),
+			EnergyPenaltyTotal: receipt.EnergyPenaltyTotal,
+		},
+		Result:     core.TransactionInfoCode(core.TransactionInfoCode_value[t.Result]),
+		ResMessage: decodeHexField(t.ResMessage),
+	}
+	for _, result := range t.ContractResult {
+		info.ContractResult = append(info.ContractResult, decodeHexField(result))
+	}
+	for _, l := range t.Log {
+		eventLog := &core.TransactionInfo_Log{
+			Address: decodeHexField(l.Address),
+			Data:    decodeHexField(l.Data),
+		}
+		for _, topic := range l.Topics {
+			eventLog.Topics = append(eventLog.Topics, decodeHexField(topic))
+		}
+		info.Log = append(info.Log, eventLog)
+	}
+	return info
+}
+
+// decodeHexField decodes a hex byte field of an HTTP API response; fields
+// that aren't valid hex decode to nil, like a missing field.
+func decodeHexField(s string) []byte {
+	b, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	if err != nil {
+		return nil
+	}
+	return b
+}
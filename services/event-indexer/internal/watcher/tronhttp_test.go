@@ -0,0 +1,195 @@
+package watcher
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fbsobreira/gotron-sdk/pkg/proto/api"
+	"github.com/fbsobreira/gotron-sdk/pkg/proto/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// newTronHTTPServer serves the fake client's chain through the node's HTTP
+// API, in the JSON shapes TronGrid returns.
+func newTronHTTPServer(t *testing.T, fake *fakeTronClient) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Num   int64  `json:"num"`
+			Value string `json:"value"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+
+		var resp any
+		switch r.URL.Path {
+		case "/wallet/getnowblock":
+			block, _ := fake.GetNowBlock()
+			resp = httpBlockJSON(t, block)
+		case "/wallet/getblockbynum":
+			block, _ := fake.GetBlockByNum(req.Num)
+			resp = httpBlockJSON(t, block)
+		case "/wallet/gettransactioninfobyid":
+			info, err := fake.GetTransactionInfoByID(req.Value)
+			if err != nil {
+				resp = map[string]any{}
+				break
+			}
+			resp = httpTxInfoJSON(info)
+		default:
+			http.NotFound(rw, r)
+			return
+		}
+		require.NoError(t, json.NewEncoder(rw).Encode(resp))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func httpBlockJSON(t *testing.T, block *api.BlockExtention) map[string]any {
+	raw := block.GetBlockHeader().GetRawData()
+	var txs []map[string]any
+	for _, tx := range block.GetTransactions() {
+		rawData, err := proto.Marshal(tx.GetTransaction().GetRawData())
+		require.NoError(t, err)
+		var ret []map[string]any
+		for _, r := range tx.GetTransaction().GetRet() {
+			ret = append(ret, map[string]any{"contractRet": r.GetContractRet().String()})
+		}
+		txs = append(txs, map[string]any{
+			"txID":         hex.EncodeToString(tx.GetTxid()),
+			"raw_data_hex": hex.EncodeToString(rawData),
+			"ret":          ret,
+		})
+	}
+	return map[string]any{
+		"blockID": hex.EncodeToString(block.GetBlockid()),
+		"block_header": map[string]any{"raw_data": map[string]any{
+			"number":     raw.GetNumber(),
+			"timestamp":  raw.GetTimestamp(),
+			"parentHash": hex.EncodeToString(raw.GetParentHash()),
+		}},
+		"transactions": txs,
+	}
+}
+
+func httpTxInfoJSON(info *core.TransactionInfo) map[string]any {
+	var logs []map[string]any
+	for _, l := range info.GetLog() {
+		var topics []string
+		for _, topic := range l.GetTopics() {
+			topics = append(topics, hex.EncodeToString(topic))
+		}
+		logs = append(logs, map[string]any{
+			"address": hex.EncodeToString(l.GetAddress()),
+			"topics":  topics,
+			"data":    hex.EncodeToString(l.GetData()),
+		})
+	}
+	return map[string]any{
+		"id":             hex.EncodeToString(info.GetId()),
+		"fee":            info.GetFee(),
+		"blockNumber":    info.GetBlockNumber(),
+		"blockTimeStamp": info.GetBlockTimeStamp(),
+		"log":            logs,
+	}
+}
+
+func TestHTTPTronClient_SameEventsAsGRPC(t *testing.T) {
+	fake := newFakeTronClient(200)
+	token, _ := testTronAddress(0xaa)
+	from, _ := testTronAddress(0x11)
+	to, toAddr := testTronAddress(0x22)
+	fake.addTransfer(190, "a190", token, from, to, big.NewInt(5_000_000))
+
+	// A native TRX transfer, carried only in the transaction's raw data
+	fake.addLogs(190, "b190")
+	param, err := anypb.New(&core.TransferContract{
+		OwnerAddress: append([]byte{tronMainnetPrefix}, from...),
+		ToAddress:    append([]byte{tronMainnetPrefix}, to...),
+		Amount:       1_500_000,
+	})
+	require.NoError(t, err)
+	native := fake.blocks[190].Transactions[1].Transaction
+	native.RawData.Contract = []*core.Transaction_Contract{{Type: core.Transaction_Contract_TransferContract, Parameter: param}}
+	native.RawData.Data = []byte("invoice-7")
+	native.Ret = []*core.Transaction_Result{{ContractRet: core.Transaction_Result_SUCCESS}}
+
+	collect := func(client tronRPC) map[string]*ChainEvent {
+		w := newTestTronWatcher(client)
+		w.AddTronAddress(toAddr)
+		var mu sync.Mutex
+		events := make(map[string]*ChainEvent)
+		w.dispatch.addHandler(func(event *ChainEvent) error {
+			mu.Lock()
+			defer mu.Unlock()
+			events[event.TxHash] = event
+			return nil
+		})
+		assert.Equal(t, []string{"a190", "b190"}, collectTronTxs(t, w, 190, 200))
+		return events
+	}
+
+	srv := newTronHTTPServer(t, fake)
+	viaGRPC := collect(fake)
+	viaHTTP := collect(newHTTPTronClient(srv.URL, time.Second, 0))
+
+	for _, txID := range []string{"a190", "b190"} {
+		want, got := viaGRPC[txID], viaHTTP[txID]
+		require.NotNil(t, got, txID)
+		assert.Equal(t, want.EventType, got.EventType, txID)
+		assert.Equal(t, want.EventID, got.EventID, txID)
+		assert.Equal(t, want.BlockNumber, got.BlockNumber, txID)
+		assert.Equal(t, want.FromAddress, got.FromAddress, txID)
+		assert.Equal(t, want.ToAddress, got.ToAddress, txID)
+		assert.Equal(t, want.TokenAddress, got.TokenAddress, txID)
+		assert.Equal(t, want.Value, got.Value, txID)
+		assert.Equal(t, want.Timestamp, got.Timestamp, txID)
+		assert.Equal(t, want.Memo, got.Memo, txID)
+	}
+	assert.Equal(t, "invoice-7", viaHTTP["b190"].Memo)
+}
+
+func TestHTTPTronClient_Errors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/wallet/getnowblock":
+			http.Error(rw, "upstream down", http.StatusServiceUnavailable)
+		case "/wallet/getblockbynum":
+			rw.Write([]byte(`{"Error": "class java.lang.NullPointerException : null"}`))
+		case "/wallet/gettransactioninfobyid":
+			rw.Write([]byte(`{}`))
+		case "/wallet/getnodeinfo":
+			rw.Write([]byte(`{"solidityBlock": "Num:1234,ID:00000000000004d2"}`))
+		}
+	}))
+	defer srv.Close()
+	client := newHTTPTronClient(srv.URL, time.Second, 0)
+
+	_, err := client.GetNowBlock()
+	assert.True(t, isTransientError(err), "5xx is retried: %v", err)
+
+	_, err = client.GetBlockByNum(1)
+	assert.ErrorContains(t, err, "NullPointerException")
+
+	_, err = client.GetTransactionInfoByID("a190")
+	assert.ErrorContains(t, err, "transaction info not found")
+
+	info, err := client.GetNodeInfo()
+	require.NoError(t, err)
+	num, err := parseSolidityBlock(info.GetSolidityBlock())
+	require.NoError(t, err)
+	assert.Equal(t, int64(1234), num)
+
+	limited := newHTTPTronClient(srv.URL, time.Second, 8)
+	_, err = limited.GetNodeInfo()
+	assert.True(t, isSizeLimitError(err), "oversized responses hit the size limit fallback: %v", err)
+}
@@ -0,0 +1,222 @@
+package watcher
+
+import (
+	"container/list"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fbsobreira/gotron-sdk/pkg/proto/api"
+	"github.com/fbsobreira/gotron-sdk/pkg/proto/core"
+)
+
+// txInfoCache is a bounded LRU of TRON transaction infos keyed by txID, so
+// blocks re-scanned after a reorg don't refetch every transaction. Entries
+// expire after the confirmation window: blocks older than that are
+// immutable and never re-scanned, so keeping their infos buys nothing.
+type txInfoCache struct {
+	mu      sync.Mutex
+	size    int
+	ttl     time.Duration
+	order   *list.List // front = most recently used
+	entries map[string]*list.Element
+	now     func() time.Time
+
+	hits, misses uint64
+}
+
+type txInfoEntry struct {
+	txID    string
+	info    *core.TransactionInfo
+	expires time.Time
+}
+
+// newTxInfoCache returns a cache holding up to size infos for ttl each, or
+// nil (caching disabled) if either is not positive.
+func newTxInfoCache(size int, ttl time.Duration) *txInfoCache {
+	if size <= 0 || ttl <= 0 {
+		return nil
+	}
+	return &txInfoCache{
+		size:    size,
+		ttl:     ttl,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+		now:     time.Now,
+	}
+}
+
+// get returns the cached info for txID if it is fresh and was recorded in
+// blockNum. A transaction re-included in another block by a reorg misses,
+// so its new receipt is fetched.
+func (c *txInfoCache) get(txID string, blockNum int64) (*core.TransactionInfo, bool) {
+	if c == nil {
+		return nil, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[txID]
+	if !ok {
+		c.misses++
+		return nil, false
+	}
+	entry := elem.Value.(*txInfoEntry)
+	if c.now().After(entry.expires) || entry.info.GetBlockNumber() != blockNum {
+		c.order.Remove(elem)
+		delete(c.entries, txID)
+		c.misses++
+		return nil, false
+	}
+	c.order.MoveToFront(elem)
+	c.hits++
+	return entry.info, true
+}
+
+// put caches info, evicting the least recently used entry when full.
+func (c *txInfoCache) put(txID string, info *core.TransactionInfo) {
+	if c == nil || info == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expires := c.now().Add(c.ttl)
+	if elem, ok := c.entries[txID]; ok {
+		entry := elem.Value.(*txInfoEntry)
+		entry.info = info
+		entry.expires = expires
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	c.entries[txID] = c.order.PushFront(&txInfoEntry{txID: txID, info: info, expires: expires})
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*txInfoEntry).txID)
+	}
+}
+
+// stats reports the cache's size and hit rate, nil if caching is disabled.
+func (c *txInfoCache) stats() *CacheStats {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return &CacheStats{Entries: c.order.Len(), Hits: c.hits, Misses: c.misses}
+}
+
+// confirmationWindow is how long a block stays below the required
+// confirmations, and so may still be re-scanned.
+func confirmationWindow(chainID, confirmations uint64) time.Duration {
+	return time.Duration(confirmations+1) * getChainConfig(chainID).BlockTime
+}
+
+// errTxInfoPending marks a transaction info the node doesn't have yet for
+// a transaction of a block it already served: it is still indexing the
+// block, so the lookup is retried rather than the transaction skipped.
+var errTxInfoPending = errors.New("transaction info not indexed yet")
+
+// isTxInfoNotFound reports whether err is the node's "transaction info not
+// found" answer (gotron's gRPC and HTTP clients both return it).
+func isTxInfoNotFound(err error) bool {
+	return err != nil && strings.Contains(strings.ToLower(err.Error()), "transaction info not found")
+}
+
+// transactionInfo returns a transaction's info, from the cache when it
+// holds a fresh copy for this block. Transient failures, including info
+// the node hasn't indexed yet, are retried per the chain's RPCRetry policy.
+func (w *TronWatcher) transactionInfo(ctx context.Context, txID string, blockNum int64) (*core.TransactionInfo, error) {
+	if info, ok := w.txInfos.get(txID, blockNum); ok {
+		return info, nil
+	}
+	info, err := tronCall(ctx, w, "GetTransactionInfoByID", func() (*core.TransactionInfo, error) {
+		info, err := w.client.GetTransactionInfoByID(txID)
+		if isTxInfoNotFound(err) {
+			return nil, fmt.Errorf("%w: %w", errTxInfoPending, err)
+		}
+		return info, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	w.txInfos.put(txID, info)
+	return info, nil
+}
+
+// defaultMaxTxConcurrency bounds concurrent transaction info fetches per
+// block when MaxTxConcurrency is unset.
+const defaultMaxTxConcurrency = 8
+
+// fetchedTxInfo is a block transaction with its fetched info, or the error
+// that fetching it failed with.
+type fetchedTxInfo struct {
+	tx   *core.Transaction
+	txID string
+	info *core.TransactionInfo
+	err  error
+}
+
+// fetchTxInfos fetches the infos of a block's transactions with up to
+// MaxTxConcurrency calls in flight and delivers them as they arrive. Each
+// transaction is fetched once, even if the block lists it twice; a failed
+// fetch is delivered with its error. The channel is closed once all are
+// delivered, or early if ctx is cancelled.
+func (w *TronWatcher) fetchTxInfos(ctx context.Context, blockNum int64, txs []*api.TransactionExtention) <-chan fetchedTxInfo {
+	concurrency := w.cfg.MaxTxConcurrency
+	if concurrency <= 0 {
+		concurrency = defaultMaxTxConcurrency
+	}
+
+	// Buffered for every transaction so workers never block on a reader
+	// that stopped early (e.g. a recovered panic)
+	out := make(chan fetchedTxInfo, len(txs))
+	jobs := make(chan fetchedTxInfo)
+	var wg sync.WaitGroup
+	for range min(concurrency, len(txs)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				info, err := w.transactionInfo(ctx, job.txID, blockNum)
+				if err == nil && info == nil {
+					continue
+				}
+				job.info, job.err = info, err
+				out <- job
+			}
+		}()
+	}
+
+	go func() {
+		defer close(out)
+		defer wg.Wait()
+		defer close(jobs)
+
+		seen := make(map[string]bool, len(txs))
+		for _, tx := range txs {
+			if tx == nil || tx.GetTransaction() == nil {
+				continue
+			}
+			txID := hex.EncodeToString(tx.GetTxid())
+			if seen[txID] {
+				continue
+			}
+			seen[txID] = true
+			select {
+			case jobs <- fetchedTxInfo{tx: tx.GetTransaction(), txID: txID}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
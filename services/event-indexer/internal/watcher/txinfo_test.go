@@ -0,0 +1,111 @@
+package watcher
+
+import (
+	"fmt"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fbsobreira/gotron-sdk/pkg/proto/core"
+	"github.com/stretchr/testify/assert"
+)
+
+// countingTronClient counts transaction info lookups per txID, and the
+// peak number of them in flight.
+type countingTronClient struct {
+	*fakeTronClient
+	mu    sync.Mutex
+	calls map[string]int
+	delay time.Duration // per lookup
+
+	inflight, peak int
+}
+
+func (c *countingTronClient) GetTransactionInfoByID(id string) (*core.TransactionInfo, error) {
+	c.mu.Lock()
+	c.calls[id]++
+	c.inflight++
+	c.peak = max(c.peak, c.inflight)
+	c.mu.Unlock()
+
+	time.Sleep(c.delay)
+
+	c.mu.Lock()
+	c.inflight--
+	c.mu.Unlock()
+	return c.fakeTronClient.GetTransactionInfoByID(id)
+}
+
+func TestTronWatcher_TxInfoCache(t *testing.T) {
+	fake := newFakeTronClient(200)
+	token, _ := testTronAddress(0xaa)
+	from, _ := testTronAddress(0x11)
+	to, toAddr := testTronAddress(0x22)
+	fake.addTransfer(190, "c190", token, from, to, big.NewInt(1))
+	client := &countingTronClient{fakeTronClient: fake, calls: make(map[string]int)}
+
+	w := newTestTronWatcher(client)
+	w.txInfos = newTxInfoCache(16, time.Minute)
+	w.AddTronAddress(toAddr)
+
+	// A reorg re-scan of the same block
+	assert.Equal(t, []string{"c190"}, collectTronTxs(t, w, 190, 200))
+	assert.Equal(t, []string{"c190"}, collectTronTxs(t, w, 190, 200))
+	assert.Equal(t, 1, client.calls["c190"], "served from the cache the second time")
+}
+
+func TestTxInfoCache(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	c := newTxInfoCache(2, time.Minute)
+	c.now = func() time.Time { return now }
+
+	c.put("a", &core.TransactionInfo{BlockNumber: 10})
+	c.put("b", &core.TransactionInfo{BlockNumber: 10})
+	_, ok := c.get("a", 10) // a becomes most recently used
+	assert.True(t, ok)
+
+	c.put("c", &core.TransactionInfo{BlockNumber: 11})
+	_, ok = c.get("b", 10)
+	assert.False(t, ok, "least recently used entry evicted")
+
+	_, ok = c.get("c", 12)
+	assert.False(t, ok, "re-included in another block")
+
+	now = now.Add(2 * time.Minute)
+	_, ok = c.get("a", 10)
+	assert.False(t, ok, "expired past the confirmation window")
+
+	assert.Nil(t, newTxInfoCache(0, time.Minute), "size 0 disables caching")
+	var disabled *txInfoCache
+	disabled.put("a", &core.TransactionInfo{})
+	_, ok = disabled.get("a", 0)
+	assert.False(t, ok)
+}
+
+func TestTronWatcher_ConcurrentTxInfoFetches(t *testing.T) {
+	fake := newFakeTronClient(200)
+	token, _ := testTronAddress(0xaa)
+	from, _ := testTronAddress(0x11)
+	to, toAddr := testTronAddress(0x22)
+	var want []string
+	for i := range 20 {
+		txID := fmt.Sprintf("%04x", i)
+		fake.addTransfer(190, txID, token, from, to, big.NewInt(int64(i+1)))
+		want = append(want, txID)
+	}
+	// A transaction listed twice is still only emitted once
+	block := fake.blocks[190]
+	block.Transactions = append(block.Transactions, block.Transactions[0])
+
+	client := &countingTronClient{fakeTronClient: fake, calls: make(map[string]int), delay: 10 * time.Millisecond}
+	w := newTestTronWatcher(client)
+	w.cfg.MaxTxConcurrency = 4
+	w.AddTronAddress(toAddr)
+
+	assert.ElementsMatch(t, want, collectTronTxs(t, w, 190, 200))
+	assert.Equal(t, 4, client.peak, "fetches run concurrently, capped by MaxTxConcurrency")
+	for _, txID := range want {
+		assert.Equal(t, 1, client.calls[txID])
+	}
+}
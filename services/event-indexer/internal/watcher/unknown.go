@@ -0,0 +1,117 @@
+package watcher
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/fbsobreira/gotron-sdk/pkg/proto/core"
+	"github.com/rs/zerolog/log"
+)
+
+// EventTypeUnknown is emitted in capture-unknown mode for logs that involve a
+// watched address but that the watcher can't parse.
+const EventTypeUnknown = "unknown"
+
+// addressTopic extracts the address from a topic holding a left-padded
+// 20-byte value, the ABI encoding of an indexed address.
+func addressTopic(topic []byte) ([]byte, bool) {
+	if len(topic) != 32 || !bytes.Equal(topic[:12], make([]byte, 12)) {
+		return nil, false
+	}
+	return topic[12:], true
+}
+
+// isTransferTopic reports whether topic0 is one of the accepted Transfer signatures.
+func (w *ChainWatcher) isTransferTopic(topic common.Hash) bool {
+	for _, t := range w.transferTopics {
+		if t == topic {
+			return true
+		}
+	}
+	return false
+}
+
+// processUnknownLog emits an unparseable log that was emitted by, or names in
+// an indexed topic, a watched address, and reports whether it did.
+func (w *ChainWatcher) processUnknownLog(vLog types.Log, currentBlock uint64, timestamp time.Time) bool {
+	w.mu.RLock()
+	involved := w.addresses[vLog.Address]
+	topics := make([]string, 0, len(vLog.Topics))
+	for i, topic := range vLog.Topics {
+		topics = append(topics, topic.Hex())
+		if raw, ok := addressTopic(topic.Bytes()); ok && i > 0 && w.addresses[common.BytesToAddress(raw)] {
+			involved = true
+		}
+	}
+	w.mu.RUnlock()
+
+	if !involved {
+		return false
+	}
+
+	confirmations := confirmationsAt(currentBlock, vLog.BlockNumber)
+	event := &ChainEvent{
+		ChainID:       w.chainID,
+		ChainName:     w.chainName,
+		EventType:     EventTypeUnknown,
+		TxHash:        vLog.TxHash.Hex(),
+		BlockNumber:   vLog.BlockNumber,
+		TokenAddress:  vLog.Address.Hex(),
+		Timestamp:     timestamp,
+		Confirmed:     confirmations >= w.confirmations.required(),
+		Confirmations: confirmations,
+		RawTopics:     topics,
+		RawData:       hexutil.Encode(vLog.Data),
+		EventID:       eventID(w.chainID, vLog.TxHash.Hex(), vLog.Index),
+		LogIndex:      vLog.Index,
+	}
+
+	log.Debug().Str("chain", w.chainName).Str("tx", event.TxHash).Str("contract", event.TokenAddress).Msg("Unknown log captured")
+	w.emit(event)
+	return true
+}
+
+// processUnknownLog emits an unparseable TRON log that was emitted by, or
+// names in an indexed topic, a watched address, and reports whether it did.
+func (w *TronWatcher) processUnknownLog(eventLog *core.TransactionInfo_Log, txID string, logIndex uint, blockNum, currentBlock int64, timestamp time.Time) bool {
+	contract := hexBytesToTronAddress(eventLog.GetAddress(), w.addrPrefix)
+
+	w.mu.RLock()
+	involved := w.addresses[contract]
+	topics := make([]string, 0, len(eventLog.GetTopics()))
+	for i, topic := range eventLog.GetTopics() {
+		topics = append(topics, hexutil.Encode(topic))
+		if raw, ok := addressTopic(topic); ok && i > 0 && w.addresses[rawBytesToTronAddress(raw, w.addrPrefix)] {
+			involved = true
+		}
+	}
+	w.mu.RUnlock()
+
+	if !involved {
+		return false
+	}
+
+	confirmations := confirmationsAt(uint64(currentBlock), uint64(blockNum))
+	event := &ChainEvent{
+		ChainID:       w.chainID,
+		ChainName:     w.chainName,
+		EventType:     EventTypeUnknown,
+		TxHash:        txID,
+		BlockNumber:   uint64(blockNum),
+		TokenAddress:  contract,
+		Timestamp:     timestamp,
+		Confirmed:     w.isFinal(confirmations >= w.confirmations.required(), blockNum),
+		Confirmations: confirmations,
+		RawTopics:     topics,
+		RawData:       hexutil.Encode(eventLog.GetData()),
+		EventID:       eventID(w.chainID, txID, logIndex),
+		LogIndex:      logIndex,
+	}
+
+	log.Debug().Str("chain", w.chainName).Str("tx", txID).Str("contract", contract).Msg("Unknown TRON log captured")
+	w.emit(event)
+	return true
+}
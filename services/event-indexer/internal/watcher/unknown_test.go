@@ -0,0 +1,112 @@
+package watcher
+
+import (
+	"math/big"
+	"sort"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/fbsobreira/gotron-sdk/pkg/proto/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Approval(address,address,uint256)
+const approvalSig = "8c5be1e5ebec7d5bd14f71427d1e84f3dd0314c0f7b2291e5b200ac8c7c3b925"
+
+func TestChainWatcher_CaptureUnknownLogs(t *testing.T) {
+	client := newFakeEVMClient(1000)
+	watched := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	spender := common.HexToAddress("0x3333333333333333333333333333333333333333")
+	token := common.HexToAddress("0xdAC17F958D2ee523a2206206994597C13D831ec7")
+
+	client.addLog(testTransferLog(990, 0, spender, watched, big.NewInt(1)))
+	// Approval naming the watched address as owner
+	client.addLog(types.Log{
+		Address:     token,
+		Topics:      []common.Hash{common.HexToHash(approvalSig), common.BytesToHash(watched.Bytes()), common.BytesToHash(spender.Bytes())},
+		Data:        common.LeftPadBytes(big.NewInt(500).Bytes(), 32),
+		BlockNumber: 990,
+		TxHash:      common.HexToHash("0xa1"),
+		Index:       1,
+	})
+	// Anonymous log emitted by the watched address itself
+	client.addLog(types.Log{Address: watched, Data: []byte{0xde, 0xad}, BlockNumber: 990, TxHash: common.HexToHash("0xa2"), Index: 2})
+	// Unknown log unrelated to any watched address
+	client.addLog(types.Log{
+		Address:     token,
+		Topics:      []common.Hash{common.HexToHash(approvalSig), common.BytesToHash(spender.Bytes()), common.BytesToHash(spender.Bytes())},
+		BlockNumber: 990,
+		TxHash:      common.HexToHash("0xa3"),
+		Index:       3,
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		w := newTestChainWatcher(t, client)
+		w.AddAddress(watched)
+		events := collectEVMEvents(t, w, 990, 1000)
+		require.Len(t, events, 1)
+		assert.Equal(t, "transfer", events[0].EventType)
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		w := newTestChainWatcher(t, client)
+		w.cfg.CaptureUnknownLogs = true
+		w.AddAddress(watched)
+		events := collectEVMEvents(t, w, 990, 1000)
+		require.Len(t, events, 3)
+		sort.Slice(events, func(i, j int) bool { return events[i].TxHash < events[j].TxHash })
+
+		assert.Equal(t, "transfer", events[2].EventType)
+
+		approval := events[0]
+		assert.Equal(t, EventTypeUnknown, approval.EventType)
+		assert.Equal(t, token.Hex(), approval.TokenAddress)
+		assert.Equal(t, []string{"0x" + approvalSig, common.BytesToHash(watched.Bytes()).Hex(), common.BytesToHash(spender.Bytes()).Hex()}, approval.RawTopics)
+		assert.Equal(t, "0x"+common.Bytes2Hex(common.LeftPadBytes(big.NewInt(500).Bytes(), 32)), approval.RawData)
+
+		anonymous := events[1]
+		assert.Equal(t, EventTypeUnknown, anonymous.EventType)
+		assert.Equal(t, watched.Hex(), anonymous.TokenAddress)
+		assert.Empty(t, anonymous.RawTopics)
+		assert.Equal(t, "0xdead", anonymous.RawData)
+	})
+}
+
+func TestTronWatcher_CaptureUnknownLogs(t *testing.T) {
+	client := newFakeTronClient(200)
+	token, tokenAddr := testTronAddress(0xaa)
+	owner, ownerAddr := testTronAddress(0x22)
+	spender, _ := testTronAddress(0x33)
+	client.addLogs(190, "a190", &core.TransactionInfo_Log{
+		Address: token,
+		Topics:  [][]byte{mustHex(approvalSig), leftPad32(owner), leftPad32(spender)},
+		Data:    leftPad32([]byte{0x05}),
+	})
+	client.addLogs(190, "b190", &core.TransactionInfo_Log{
+		Address: token,
+		Topics:  [][]byte{mustHex(approvalSig), leftPad32(spender), leftPad32(spender)},
+	})
+
+	w := newTestTronWatcher(client)
+	w.cfg.CaptureUnknownLogs = true
+	w.AddTronAddress(ownerAddr)
+
+	var got []*ChainEvent
+	w.dispatch.addHandler(func(event *ChainEvent) error {
+		got = append(got, event)
+		return nil
+	})
+	assert.Equal(t, []string{"a190"}, collectTronTxs(t, w, 190, 200))
+
+	require.Len(t, got, 1)
+	assert.Equal(t, EventTypeUnknown, got[0].EventType)
+	assert.Equal(t, tokenAddr, got[0].TokenAddress)
+	assert.Equal(t, "0x"+approvalSig, got[0].RawTopics[0])
+	assert.Equal(t, "0x"+hex32(0x05), got[0].RawData, "same 0x form as EVM")
+}
+
+func hex32(b byte) string {
+	return common.Bytes2Hex(leftPad32([]byte{b}))
+}
@@ -2,10 +2,12 @@ package watcher
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math/big"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ethereum/go-ethereum"
@@ -13,18 +15,26 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/protocol-bank/event-indexer/internal/config"
 	"github.com/rs/zerolog/log"
 )
 
-// ERC20 Transfer event signature
-var transferEventSig = common.HexToHash("0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef")
-
 // ERC20 ABI for decoding
 const erc20ABI = `[{"anonymous":false,"inputs":[{"indexed":true,"name":"from","type":"address"},{"indexed":true,"name":"to","type":"address"},{"indexed":false,"name":"value","type":"uint256"}],"name":"Transfer","type":"event"}]`
 
+// EventSchemaVersion ChainEvent 结构版本，随事件一起序列化。
+// 新增/修改字段时递增，消费方据此分支解析以支持生产者与消费者滚动升级
+//
+//	1: 初始版本
+//	2: 新增 LogIndex
+const EventSchemaVersion = 2
+
 // ChainEvent 链上事件
 type ChainEvent struct {
+	// SchemaVersion 事件结构版本 (EventSchemaVersion)，由分发器填充
+	SchemaVersion int
+
 	ChainID      uint64
 	ChainName    string
 	EventType    string
@@ -37,29 +47,188 @@ type ChainEvent struct {
 	TokenSymbol  string
 	Timestamp    time.Time
 	Confirmed    bool
+
+	// NormalizedValue 按代币精度换算后的金额 (如 "1.5")，精度未知时为空
+	NormalizedValue string
+
+	// Summary 仅 address_summary 事件携带：窗口内的笔数与净额
+	Summary *AddressSummary
+
+	// Balance 仅 balance_delta 事件携带：监听地址的余额变动与变动后的累计净额
+	Balance *BalanceDelta
+
+	// Confirmations 发出事件时的确认数 (head - blockNumber)
+	Confirmations uint64
+
+	// FeePaid/FeePayer 交易手续费 (EVM: wei, TRON: SUN) 及支付方，
+	// 仅在 IncludeFeeInfo 开启时填充
+	FeePaid  *big.Int
+	FeePayer string
+
+	// TouchesWatched 转账的 from/to 是否为监听地址 (按代币监听模式下可能为 false)
+	TouchesWatched bool
+
+	// Memo TRON 交易备注 (raw_data.data)，交易所用于区分共享充值地址的子账户
+	Memo string
+
+	// RawTopics/RawData 仅 unknown 事件携带: 无法解析日志的原始 topics 与 data (0x 前缀 hex)
+	RawTopics []string
+	RawData   string
+
+	// Network 规范化的网络标识 (如 "ETH", "TRON")，见 NetworkSymbol
+	Network string
+
+	// WatchedAddress 事件涉及的监听地址 (to 优先)，与监听地址无关时为空
+	WatchedAddress string
+
+	// PartitionBy 决定 PartitionKey 的取值 (address/token/chain)，由分发器填充
+	PartitionBy string
+
+	// TokenAddressMalformed 代币合约地址长度异常无法解析 (TokenAddress 为空)
+	TokenAddressMalformed bool
+
+	// EventID 事件唯一标识 (chainID:txHash:logIndex)，确认阶段/里程碑副本与原事件相同
+	EventID string
+
+	// LogIndex 日志在交易内的位置 (EVM: 区块内日志索引，TRON: txInfo.Log 下标)，
+	// 与 TxHash 组成日志事件的幂等键；非日志事件 (原生转账、调用数据解析、失败交易) 为 0
+	LogIndex uint
+
+	// DecodedFromCalldata 转账由 TriggerSmartContract 调用数据解析得出 (交易无 Transfer 日志)
+	DecodedFromCalldata bool
+
+	// Block 仅 block_processed 事件携带：区块哈希、交易数与匹配事件数
+	Block *BlockMeta
+
+	// FinalityStatus 生命周期状态 (pending/credited/settled/reorged/failed)，
+	// 未设置时由分发器根据 Confirmed 等字段推导
+	FinalityStatus FinalityStatus
+
+	// TokenID 仅 erc721_transfer/erc1155_* 事件携带: tokenId (十进制)，
+	// erc721_transfer 的 Value 为空
+	TokenID string
+
+	// Operator 仅 erc1155_* 事件携带: 执行转账的地址 (可能不同于 FromAddress)
+	Operator string
+
+	// ZeroAddressTransfer from 与 to 均为零地址 (既非铸造也非销毁，疑似畸形日志)，
+	// 仅在 EmitZeroAddressTransfers 开启时发出
+	ZeroAddressTransfer bool
+
+	// ExceedsTotalSupply 转账金额大于代币总供应量 (疑似溢出攻击或假币)，
+	// 仅在 CheckTotalSupply 开启时检查
+	ExceedsTotalSupply bool
 }
 
-// EventHandler 事件处理回调
-type EventHandler func(event *ChainEvent)
+// EventHandler 事件处理回调；返回错误时按事件类型的重试策略重试
+type EventHandler func(event *ChainEvent) error
+
+// evmRPC 是 ChainWatcher 依赖的 ethclient 方法子集，便于在测试中替换
+type evmRPC interface {
+	BlockNumber(ctx context.Context) (uint64, error)
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error)
+	TransactionCount(ctx context.Context, blockHash common.Hash) (uint, error)
+	FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error)
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+	TransactionByHash(ctx context.Context, hash common.Hash) (*types.Transaction, bool, error)
+	TransactionSender(ctx context.Context, tx *types.Transaction, block common.Hash, index uint) (common.Address, error)
+}
 
 // ChainWatcher 单链监听器
 type ChainWatcher struct {
 	chainID   uint64
 	chainName string
-	client    *ethclient.Client
+	client    evmRPC
 	wsClient  *ethclient.Client
+	logSub    logSubscriber // 日志订阅模式 (UseWebSocket)，nil = 轮询
 	cfg       config.ChainConfig
 	addresses map[common.Address]bool
-	handlers  []EventHandler
+	dispatch  *dispatcher
 	erc20ABI  abi.ABI
 	mu        sync.RWMutex
+	gate      drainGate
+
+	transferTopics []common.Hash // Transfer topic0 签名 (标准或按链覆盖)
+
+	// 确认里程碑跟踪，未配置时为 nil
+	milestones *confirmationTracker
+
+	// 按代币监听模式下的代币合约集合
+	scopedTokens map[common.Address]bool
+
+	// 代币白名单 (WatchedTokens)，为空时处理所有代币
+	watchedTokens map[common.Address]bool
+
+	// 链头健康状态 (停链检测)
+	health *headMonitor
+
+	// 重组检测与历史
+	reorgs *reorgTracker
+
+	// 按近期重组调整的确认数要求
+	confirmations *adaptiveConfirmations
+
+	// 追块时按自适应区块范围预取日志，未启用时为 nil (逐块查询)
+	logRanges *logRanges
+
+	// 回填最近一次成功的 eth_getLogs 区块范围 (0 = 尚未回填，使用 BackfillRange)
+	backfillStep atomic.Uint64
+
+	// 暂停时跳过轮询，检查点 (lastBlock) 保持不变
+	paused atomic.Bool
+
+	// 确认里程碑由 MultiChainWatcher 跨链并发检查，不在轮询中内联执行
+	separateConfirmationChecks bool
+
+	// 最近处理完成的区块 (轮询循环发布，供状态快照读取)
+	checkpoint atomic.Uint64
+
+	// 订阅推送的最新区块头，处理该高度时直接复用
+	headHeader atomic.Pointer[types.Header]
+
+	// 持久化检查点 (落后链头 CheckpointConfirmations 个区块)
+	checkpoints checkpointer
+
+	// 配置的代币符号与精度 (TokenRegistry)
+	tokens tokenRegistry
+
+	// 代币总供应量缓存 (eth_call totalSupply)，未开启 CheckTotalSupply 时为 nil
+	tokenMeta *tokenMetadataCache
+
+	// Close 时停止 Start 启动的循环并关闭 RPC 连接
+	closer *watcherCloser
 }
 
 // MultiChainWatcher 多链监听器 (EVM + TRON)
 type MultiChainWatcher struct {
+	// mu 保护链监听器集合 (启动失败的链在后台重试成功后加入，集合整体替换)
+	mu           sync.RWMutex
 	watchers     map[uint64]*ChainWatcher
 	tronWatchers map[uint64]*TronWatcher
-	handlers     []EventHandler
+	dispatch     *dispatcher // shared by all chain watchers
+	gate         drainGate   // tracks work emitted by the orchestrator itself
+	closing      atomic.Bool // set by BeginShutdown, stops startup retries
+
+	// 链启动 (并发构建，失败的链在后台按间隔重试)
+	parsedABI          abi.ABI
+	startChain         func(ctx context.Context, cfg config.ChainConfig) error
+	startupTimeout     time.Duration
+	startRetryInterval time.Duration
+	startup            map[uint64]ChainStartup
+	pending            map[uint64]*pendingChain
+	checkpointStore    CheckpointStore
+	watchSetStore      WatchSetStore // nil = runtime additions aren't persisted
+
+	tokenPolicyStore    TokenPolicyStore // nil = no token policies
+	tokenPolicyInterval time.Duration
+
+	summarizer      *addressSummarizer // nil unless AddressSummaryInterval is set
+	summaryInterval time.Duration
+
+	// 跨链并发确认检查 (间隔为 0 时各链在轮询中内联检查)
+	confirmationCheckInterval    time.Duration
+	confirmationCheckConcurrency int
 }
 
 // NewMultiChainWatcher 创建多链监听器 (EVM + TRON)
@@ -67,47 +236,63 @@ func NewMultiChainWatcher(ctx context.Context, cfg *config.Config) (*MultiChainW
 	mcw := &MultiChainWatcher{
 		watchers:     make(map[uint64]*ChainWatcher),
 		tronWatchers: make(map[uint64]*TronWatcher),
-		handlers:     []EventHandler{},
+		dispatch:     newDispatcher(cfg.EventRetryPolicies, cfg.DefaultRetryPolicy),
 	}
 
+	// 全局处理器并发上限，按链保留最小槽位
+	minSlots := make(map[uint64]int, len(cfg.Chains))
+	for chainID, chainCfg := range cfg.Chains {
+		minSlots[chainID] = chainCfg.MinHandlerSlots
+	}
+	mcw.dispatch.limiter = newHandlerLimiter(cfg.HandlerConcurrency, minSlots)
+	mcw.dispatch.partitionBy = cfg.PartitionBy
+	mcw.dispatch.done = ctx.Done()
+	// 有界分发队列: 固定 worker 数，队列满时短暂阻塞后丢弃
+	mcw.dispatch.queue = newDispatchQueue(dispatchWorkers(cfg), cfg.DispatchQueueSize, cfg.DispatchEnqueueTimeout)
+	if cfg.OrderedPhases {
+		mcw.dispatch.phases = newPhaseSequencer()
+	}
+	if cfg.DedupeConfirmedEvents {
+		mcw.dispatch.confirmed = newConfirmedDedupe(cfg.ConfirmedDedupeSize)
+	}
+	// 检测阶段去重: 回填与实时轮询重叠时同一区块内的事件只发出一次
+	if cfg.DedupeEvents {
+		mcw.dispatch.seen = newSeenEvents(cfg.EventDedupeSize)
+	}
+	mcw.dispatch.throttle = newAddressThrottle(cfg.AddressEventLimit, cfg.AddressEventWindow, cfg.AddressThrottleSampleEvery)
+
+	// 地址路由标签
+	for addr, tags := range cfg.AddressTags {
+		mcw.dispatch.tagAddress(addr, tags...)
+	}
+
+	// 周期性地址汇总 (可选)
+	if cfg.AddressSummaryInterval > 0 {
+		mcw.summarizer = newAddressSummarizer(time.Now(), mcw.isWatched)
+		mcw.summaryInterval = cfg.AddressSummaryInterval
+		mcw.dispatch.addHandler(mcw.summarizer.observe)
+	}
+
+	// 跨链并发确认检查 (可选)
+	mcw.confirmationCheckInterval = cfg.ConfirmationCheckInterval
+	mcw.confirmationCheckConcurrency = cfg.ConfirmationCheckConcurrency
+
 	// 解析 ERC20 ABI (for EVM chains)
 	parsedABI, err := abi.JSON(strings.NewReader(erc20ABI))
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse ERC20 ABI: %w", err)
 	}
+	mcw.parsedABI = parsedABI
 
-	// 为每条链创建监听器
-	for chainID, chainCfg := range cfg.Chains {
-		if isTronChain(chainCfg) {
-			// TRON chain → TronWatcher
-			tw, err := NewTronWatcher(ctx, chainCfg)
-			if err != nil {
-				log.Warn().Err(err).Uint64("chain_id", chainID).Msg("Failed to create TRON watcher, skipping")
-				continue
-			}
-			// Add watched TRON addresses (Base58 format, starts with 'T')
-			for _, addr := range cfg.WatchedAddresses {
-				if len(addr) == 34 && addr[0] == 'T' {
-					tw.AddTronAddress(addr)
-				}
-			}
-			mcw.tronWatchers[chainID] = tw
-			log.Info().Uint64("chain_id", chainID).Str("name", chainCfg.Name).Msg("TRON watcher created")
-		} else {
-			// EVM chain → ChainWatcher
-			watcher, err := newChainWatcher(ctx, chainCfg, parsedABI)
-			if err != nil {
-				log.Warn().Err(err).Uint64("chain_id", chainID).Msg("Failed to create EVM watcher, skipping")
-				continue
-			}
-			for _, addr := range cfg.WatchedAddresses {
-				if len(addr) == 42 && addr[:2] == "0x" {
-					watcher.AddAddress(common.HexToAddress(addr))
-				}
-			}
-			mcw.watchers[chainID] = watcher
-			log.Info().Uint64("chain_id", chainID).Str("name", chainCfg.Name).Msg("EVM watcher created")
-		}
+	// 并发创建各链监听器 (每条链单独超时)，启动失败的链在 Start 后于后台重试
+	mcw.startChain = mcw.connectChain
+	mcw.startupTimeout = cfg.ChainStartupTimeout
+	mcw.startRetryInterval = cfg.ChainStartRetryInterval
+	mcw.recordStartup(startChains(ctx, cfg.Chains, mcw.startupTimeout, mcw.startChain), cfg.Chains)
+
+	// 监听地址 (EVM 0x 格式 / TRON Base58 格式)，可按链限定生效范围
+	if err := mcw.watchConfiguredAddresses(cfg.WatchedAddresses, cfg.WatchedAddressChains); err != nil {
+		return nil, fmt.Errorf("invalid watched addresses: %w", err)
 	}
 
 	return mcw, nil
@@ -115,39 +300,147 @@ func NewMultiChainWatcher(ctx context.Context, cfg *config.Config) (*MultiChainW
 
 // newChainWatcher 创建单链监听器
 func newChainWatcher(ctx context.Context, cfg config.ChainConfig, parsedABI abi.ABI) (*ChainWatcher, error) {
-	// HTTP 客户端
-	client, err := ethclient.Dial(cfg.RPCURL)
+	// HTTP 客户端 (配置多个端点时按健康度为每次调用选择端点)
+	urls := endpointURLs(cfg)
+	dialed := make([]*ethclient.Client, 0, len(urls))
+	closeAll := func() {
+		for _, c := range dialed {
+			c.Close()
+		}
+	}
+	for _, url := range urls {
+		c, err := dialEVM(ctx, url, cfg.MaxMessageSize)
+		if err != nil {
+			closeAll()
+			return nil, fmt.Errorf("failed to connect to RPC: %w", err)
+		}
+		dialed = append(dialed, c)
+	}
+	if len(dialed) == 0 {
+		return nil, fmt.Errorf("no RPC endpoint configured")
+	}
+	var client evmRPC = dialed[0]
+	if len(dialed) > 1 {
+		clients := make([]evmRPC, len(dialed))
+		for i, c := range dialed {
+			clients[i] = c
+		}
+		client = newMultiEVMClient(cfg.Name, urls, clients, cfg.RPCEndpointCooldown)
+	}
+
+	// 等待 RPC 可达 (编排环境中依赖可能尚未启动)
+	err := waitForRPC(ctx, cfg.Name, cfg.StartupTimeout, func(ctx context.Context) error {
+		_, err := client.BlockNumber(ctx)
+		return err
+	})
 	if err != nil {
+		closeAll()
 		return nil, fmt.Errorf("failed to connect to RPC: %w", err)
 	}
 
 	// WebSocket 客户端 (可选)
 	var wsClient *ethclient.Client
 	if cfg.WSURL != "" {
-		wsClient, err = ethclient.Dial(cfg.WSURL)
+		wsClient, err = dialEVM(ctx, cfg.WSURL, cfg.MaxMessageSize)
 		if err != nil {
 			log.Warn().Err(err).Str("chain", cfg.Name).Msg("Failed to connect to WebSocket, using polling")
 		}
 	}
 
-	return &ChainWatcher{
+	w := newEVMWatcher(cfg, client, parsedABI)
+	w.wsClient = wsClient
+	if cfg.CheckTotalSupply {
+		// 总供应量查询后即缓存，由主端点提供
+		w.tokenMeta = newTokenMetadataCache(evmMetadataClient{caller: dialed[0]})
+		w.tokenMeta.fetchDecimals = false
+		w.tokenMeta.fetchSupply = true
+	}
+	w.closer.release = func() {
+		closeAll()
+		if wsClient != nil {
+			wsClient.Close()
+		}
+	}
+	if cfg.UseWebSocket && wsClient != nil {
+		w.logSub = wsClient
+	}
+	return w, nil
+}
+
+// dialEVM 连接 EVM 节点，maxMessageSize > 0 时限制 WebSocket 消息大小
+func dialEVM(ctx context.Context, url string, maxMessageSize int) (*ethclient.Client, error) {
+	var opts []rpc.ClientOption
+	if maxMessageSize > 0 {
+		opts = append(opts, rpc.WithWebsocketMessageSizeLimit(int64(maxMessageSize)))
+	}
+	c, err := rpc.DialOptions(ctx, url, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return ethclient.NewClient(c), nil
+}
+
+// newEVMWatcher 基于已连接的客户端构建监听器
+func newEVMWatcher(cfg config.ChainConfig, client evmRPC, parsedABI abi.ABI) *ChainWatcher {
+	cfg.PollInterval = pollInterval(cfg)
+	w := &ChainWatcher{
 		chainID:   cfg.ChainID,
 		chainName: cfg.Name,
 		client:    client,
-		wsClient:  wsClient,
 		cfg:       cfg,
 		addresses: make(map[common.Address]bool),
-		handlers:  []EventHandler{},
+		dispatch:  newDispatcher(nil, config.RetryPolicy{MaxAttempts: 1}),
 		erc20ABI:  parsedABI,
-	}, nil
+
+		transferTopics: transferTopics(cfg.TransferEventSigs),
+		milestones:     newConfirmationTracker(cfg.ConfirmationMilestones, cfg.Confirmations, cfg.MaxPendingConfirmations, cfg.EmitConfirmedEvents),
+		scopedTokens:   scopedEVMTokens(cfg.ScopedTokens),
+		watchedTokens:  scopedEVMTokens(cfg.WatchedTokens),
+		health:         newHeadMonitor(getChainConfig(cfg.ChainID).BlockTime, cfg.HaltBlocks),
+		reorgs:         newReorgTracker(cfg.ChainID, cfg.Name, cfg.ReorgHistorySize, int(maxConfirmations(cfg))),
+		confirmations:  newAdaptiveConfirmations(cfg),
+		logRanges:      newLogRanges(cfg),
+		tokens:         newTokenRegistry(cfg.TokenRegistry),
+		checkpoints:    checkpointer{chainID: cfg.ChainID, chainName: cfg.Name, lag: cfg.CheckpointConfirmations, start: cfg.StartBlock},
+		closer:         newWatcherCloser(),
+	}
+	// 代币策略要求更多确认数时，事件按其自身深度跟踪确认
+	w.milestones.setDepth(func(event *ChainEvent) uint64 {
+		return w.dispatch.tokenPolicies.confirmations(event)
+	})
+	return w
 }
 
 // AddAddress 添加监听地址
-func (w *ChainWatcher) AddAddress(addr common.Address) {
+func (w *ChainWatcher) AddAddress(addr common.Address) bool {
 	w.mu.Lock()
 	defer w.mu.Unlock()
+	if w.addresses[addr] {
+		log.Debug().Str("address", addr.Hex()).Str("chain", w.chainName).Msg("Address already on watch list")
+		return false
+	}
 	w.addresses[addr] = true
 	log.Info().Str("address", addr.Hex()).Str("chain", w.chainName).Msg("Address added to watch list")
+	return true
+}
+
+// isWatched 判断十六进制地址是否在监听列表中
+func (w *ChainWatcher) isWatched(addr string) bool {
+	if !common.IsHexAddress(addr) {
+		return false
+	}
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.addresses[common.HexToAddress(addr)]
+}
+
+// exceedsTotalSupply 转账金额大于代币总供应量 (疑似溢出攻击或假币)，
+// 未开启 CheckTotalSupply 或总供应量未知时不标记
+func (w *ChainWatcher) exceedsTotalSupply(token common.Address, value *big.Int) bool {
+	if w.tokenMeta == nil {
+		return false
+	}
+	return w.tokenMeta.exceedsSupply(token.Hex(), value)
 }
 
 // RemoveAddress 移除监听地址
@@ -160,9 +453,10 @@ func (w *ChainWatcher) RemoveAddress(addr common.Address) {
 // Start 启动多链监听 (EVM + TRON)
 func (mcw *MultiChainWatcher) Start(ctx context.Context) {
 	var wg sync.WaitGroup
+	watchers, tronWatchers := mcw.chainWatchers()
 
 	// Start EVM watchers
-	for chainID, watcher := range mcw.watchers {
+	for chainID, watcher := range watchers {
 		wg.Add(1)
 		go func(cID uint64, w *ChainWatcher) {
 			defer wg.Done()
@@ -171,7 +465,7 @@ func (mcw *MultiChainWatcher) Start(ctx context.Context) {
 	}
 
 	// Start TRON watchers
-	for chainID, tw := range mcw.tronWatchers {
+	for chainID, tw := range tronWatchers {
 		wg.Add(1)
 		go func(cID uint64, w *TronWatcher) {
 			defer wg.Done()
@@ -179,35 +473,200 @@ func (mcw *MultiChainWatcher) Start(ctx context.Context) {
 		}(chainID, tw)
 	}
 
+	// Retry chains that failed to start; each runs once it connects
+	for _, chainCfg := range mcw.pendingChains() {
+		wg.Add(1)
+		go func(cfg config.ChainConfig) {
+			defer wg.Done()
+			mcw.retryChain(ctx, cfg)
+		}(chainCfg)
+	}
+
+	// Start periodic address summaries
+	if mcw.summarizer != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			mcw.runSummaries(ctx, mcw.summaryInterval)
+		}()
+	}
+
+	// Start periodic token policy refreshes
+	if mcw.tokenPolicyStore != nil && mcw.tokenPolicyInterval > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			mcw.runTokenPolicyRefresh(ctx, mcw.tokenPolicyInterval)
+		}()
+	}
+
+	// Start cross-chain confirmation re-checks
+	if mcw.confirmationCheckInterval > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			mcw.runConfirmationChecks(ctx, mcw.confirmationCheckInterval, mcw.confirmationCheckConcurrency)
+		}()
+	}
+
+	wg.Wait()
+}
+
+// BeginShutdown 进入 lame duck 模式：所有链停止接收新区块，
+// 并阻塞直到当前区块与在途事件处理器完成。之后再取消 context 即可安全退出。
+func (mcw *MultiChainWatcher) BeginShutdown() {
+	mcw.closing.Store(true)
+	watchers, tronWatchers := mcw.chainWatchers()
+	var wg sync.WaitGroup
+
+	for _, watcher := range watchers {
+		wg.Add(1)
+		go func(w *ChainWatcher) {
+			defer wg.Done()
+			w.BeginShutdown()
+		}(watcher)
+	}
+
+	for _, tw := range tronWatchers {
+		wg.Add(1)
+		go func(w *TronWatcher) {
+			defer wg.Done()
+			w.BeginShutdown()
+		}(tw)
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		mcw.gate.drain()
+	}()
+
 	wg.Wait()
 }
 
+// Close 关闭所有链监听器，释放 RPC 连接。在 BeginShutdown 之后调用。
+func (mcw *MultiChainWatcher) Close() error {
+	watchers, tronWatchers := mcw.chainWatchers()
+	var errs []error
+	for _, w := range watchers {
+		errs = append(errs, w.Close())
+	}
+	for _, tw := range tronWatchers {
+		errs = append(errs, tw.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// isWatched 判断地址是否在指定链的监听列表中
+func (mcw *MultiChainWatcher) isWatched(chainID uint64, addr string) bool {
+	watchers, tronWatchers := mcw.chainWatchers()
+	if w, ok := watchers[chainID]; ok {
+		return w.isWatched(addr)
+	}
+	if tw, ok := tronWatchers[chainID]; ok {
+		return tw.isWatched(addr)
+	}
+	return false
+}
+
+// Health 返回每条链的健康状态
+func (mcw *MultiChainWatcher) Health() map[uint64]ChainHealth {
+	watchers, tronWatchers := mcw.chainWatchers()
+	health := make(map[uint64]ChainHealth, len(watchers)+len(tronWatchers))
+	for chainID, w := range watchers {
+		health[chainID] = w.Health()
+	}
+	for chainID, tw := range tronWatchers {
+		health[chainID] = tw.Health()
+	}
+	return health
+}
+
 // AddHandler 添加事件处理器 (applies to both EVM and TRON watchers)
 func (mcw *MultiChainWatcher) AddHandler(handler EventHandler) {
-	mcw.handlers = append(mcw.handlers, handler)
-	for _, watcher := range mcw.watchers {
-		watcher.handlers = append(watcher.handlers, handler)
+	mcw.dispatch.addHandler(handler)
+}
+
+// RegisterReorgHandler 注册重组处理器，每次检测到重组时调用 (用于失效已缓存的确认)
+func (mcw *MultiChainWatcher) RegisterReorgHandler(handler ReorgHandler) {
+	mcw.dispatch.addReorgHandler(handler)
+}
+
+// ReorgHistory 返回指定链近期检测到的重组，未知链返回 nil
+func (mcw *MultiChainWatcher) ReorgHistory(chainID uint64) []ReorgInfo {
+	watchers, tronWatchers := mcw.chainWatchers()
+	if w, ok := watchers[chainID]; ok {
+		return w.ReorgHistory()
 	}
-	for _, tw := range mcw.tronWatchers {
-		tw.handlers = append(tw.handlers, handler)
+	if tw, ok := tronWatchers[chainID]; ok {
+		return tw.ReorgHistory()
 	}
+	return nil
+}
+
+// AddTaggedHandler 注册只接收指定标签地址相关事件的处理器
+func (mcw *MultiChainWatcher) AddTaggedHandler(handler EventHandler, tags ...string) {
+	mcw.dispatch.addTaggedHandler(handler, tags...)
+}
+
+// TagAddress 为地址附加路由标签 (所有链生效)
+func (mcw *MultiChainWatcher) TagAddress(addr string, tags ...string) {
+	mcw.dispatch.tagAddress(addr, tags...)
+}
+
+// UntagAddress 移除地址的全部路由标签
+func (mcw *MultiChainWatcher) UntagAddress(addr string) {
+	mcw.dispatch.untagAddress(addr)
+}
+
+// SetDeadLetterHandler 设置重试耗尽后的死信处理器 (默认仅记录日志)
+func (mcw *MultiChainWatcher) SetDeadLetterHandler(handler DeadLetterHandler) {
+	mcw.dispatch.setDeadLetterHandler(handler)
 }
 
 // Start 启动单链监听
 func (w *ChainWatcher) Start(ctx context.Context) {
 	log.Info().Str("chain", w.chainName).Msg("Starting chain watcher")
+	ctx = w.closer.bind(ctx)
+
+	// 多端点时定期探测被隔离的端点，恢复后立即重新启用
+	go runEndpointHealthChecks(ctx, w.client, w.cfg.RPCHealthCheckInterval)
+
+	// 日志订阅模式: 订阅 Transfer 日志驱动区块处理，订阅中断期间退回轮询
+	if w.logSub != nil && !w.cfg.ConfirmedOnly {
+		go w.watchLogs(ctx)
+		return
+	}
 
-	// 优先使用 WebSocket 订阅
-	if w.wsClient != nil {
-		go w.subscribeNewBlocks(ctx)
+	// 优先使用 WebSocket 订阅 (仅处理已确认区块模式下只轮询，订阅推送的是链头)。
+	// 区块只由轮询循环按高度顺序处理，订阅推送的新块立即唤醒一次轮询
+	var heads chan struct{}
+	if w.wsClient != nil && !w.cfg.ConfirmedOnly {
+		heads = make(chan struct{}, 1)
+		go w.subscribeNewBlocks(ctx, heads)
 	}
 
 	// 同时使用轮询作为备份
-	go w.pollBlocks(ctx)
+	go w.pollBlocks(ctx, heads)
+}
+
+// BeginShutdown 停止接收新区块，等待当前区块与在途事件处理器完成
+func (w *ChainWatcher) BeginShutdown() {
+	w.gate.drain()
+	log.Info().Str("chain", w.chainName).Msg("Chain watcher drained")
+}
+
+// Close 停止监听循环并关闭 RPC/WebSocket 连接 (如运行时移除链)，可重复调用。
+// 需要等待当前区块处理完成时先调用 BeginShutdown。
+func (w *ChainWatcher) Close() error {
+	w.closer.close()
+	log.Info().Str("chain", w.chainName).Msg("Chain watcher closed")
+	return nil
 }
 
-// subscribeNewBlocks WebSocket 订阅新块
-func (w *ChainWatcher) subscribeNewBlocks(ctx context.Context) {
+// subscribeNewBlocks WebSocket 订阅新块，每个新块头唤醒一次轮询 (heads 已有待处理
+// 的唤醒时合并)。区块不在此处理: 与轮询循环并发处理会打乱重组检测所需的高度顺序
+func (w *ChainWatcher) subscribeNewBlocks(ctx context.Context, heads chan<- struct{}) {
 	headers := make(chan *types.Header)
 	sub, err := w.wsClient.SubscribeNewHead(ctx, headers)
 	if err != nil {
@@ -226,45 +685,162 @@ func (w *ChainWatcher) subscribeNewBlocks(ctx context.Context) {
 			log.Error().Err(err).Str("chain", w.chainName).Msg("WebSocket subscription error")
 			return
 		case header := <-headers:
-			w.processBlock(ctx, header.Number.Uint64())
+			w.headHeader.Store(header)
+			select {
+			case heads <- struct{}{}:
+			default:
+			}
 		}
 	}
 }
 
-// pollBlocks 轮询新块
-func (w *ChainWatcher) pollBlocks(ctx context.Context) {
-	ticker := time.NewTicker(12 * time.Second) // 每 12 秒检查一次
+// pollBlocks 轮询新块，heads 收到订阅推送的新块时立即轮询 (nil = 仅定时轮询)
+func (w *ChainWatcher) pollBlocks(ctx context.Context, heads <-chan struct{}) {
+	ticker := time.NewTicker(w.cfg.PollInterval) // 默认按链的出块时间
 	defer ticker.Stop()
 
-	var lastBlock uint64
+	// 从持久化检查点恢复，重新处理其后尚未确认的区块
+	lastBlock := w.checkpoints.resume(ctx)
 
 	for {
 		select {
 		case <-ctx.Done():
 			return
 		case <-ticker.C:
-			currentBlock, err := w.client.BlockNumber(ctx)
-			if err != nil {
-				log.Error().Err(err).Str("chain", w.chainName).Msg("Failed to get block number")
-				continue
-			}
+		case <-heads:
+		}
+		lastBlock = w.pollTick(ctx, lastBlock)
+	}
+}
 
-			if lastBlock == 0 {
-				lastBlock = currentBlock
-				continue
-			}
+// pollTick 执行一次轮询并推进检查点，单次轮询 panic 不终止循环，下次从原检查点重试
+func (w *ChainWatcher) pollTick(ctx context.Context, lastBlock uint64) uint64 {
+	defer recoverPanic(w.cfg.RecoverPanics, w.chainName, "poll")
+	lastBlock = w.poll(ctx, lastBlock)
+	w.checkpoint.Store(lastBlock)
+	w.checkpoints.advance(ctx, lastBlock, w.health.snapshot().Head)
+	return lastBlock
+}
 
-			// 处理新块
-			for block := lastBlock + 1; block <= currentBlock; block++ {
-				w.processBlock(ctx, block)
-			}
-			lastBlock = currentBlock
+// poll 执行一次轮询，返回已处理到的区块高度
+func (w *ChainWatcher) poll(ctx context.Context, lastBlock uint64) uint64 {
+	if w.paused.Load() {
+		return lastBlock
+	}
+	if !w.gate.enter() {
+		return lastBlock
+	}
+	defer w.gate.leave()
+
+	currentBlock, err := w.client.BlockNumber(ctx)
+	if err != nil {
+		log.Error().Err(err).Str("chain", w.chainName).Msg("Failed to get block number")
+		w.health.observeError(err)
+		return lastBlock
+	}
+	w.trackHead(currentBlock)
+
+	// 检查点推进的上限: 链头，或仅处理已确认区块模式下的确认边界
+	frontier := currentBlock
+	if w.cfg.ConfirmedOnly {
+		frontier = confirmedFrontier(currentBlock, w.confirmations.required())
+	}
+
+	if w.checkpoints.atHead(lastBlock) {
+		return frontier
+	}
+
+	lastBlock = w.processBlocks(ctx, lastBlock, frontier, currentBlock)
+	if !w.separateConfirmationChecks {
+		w.emitMilestones(currentBlock)
+	}
+	return lastBlock
+}
+
+// processBlocks 依次处理 lastBlock 之后直到 to 的区块，返回已处理到的区块高度
+// (lame duck 模式下完成当前块后不再继续)。调用方需已进入 gate
+func (w *ChainWatcher) processBlocks(ctx context.Context, lastBlock, to, head uint64) uint64 {
+	w.logRanges.plan(to)
+	for block := lastBlock + 1; block <= to; block++ {
+		if w.gate.isDraining() {
+			break
+		}
+		// 待确认事件队列已满: 停止推进检查点，等待里程碑释放
+		if w.milestones.full() {
+			log.Warn().Str("chain", w.chainName).Uint64("block", block).Msg("Pending confirmation queue full, pausing block processing")
+			break
 		}
+		w.processBlock(ctx, block, head)
+		blocksProcessed.WithLabelValues(w.chainName).Inc()
+		lastBlock = block
 	}
+	return lastBlock
 }
 
-// processBlock 处理单个区块
-func (w *ChainWatcher) processBlock(ctx context.Context, blockNumber uint64) {
+// trackHead 更新链头健康状态，停链或恢复时发出告警事件
+func (w *ChainWatcher) trackHead(head uint64) {
+	halted, resumed := w.health.observeHead(head)
+	if halted || resumed {
+		w.dispatch.dispatch(&w.gate, haltEvent(w.chainID, w.chainName, w.health.snapshot(), halted))
+	}
+}
+
+// Health 返回链头健康状态 (区分停链与 RPC 故障) 及近期重组记录
+func (w *ChainWatcher) Health() ChainHealth {
+	health := w.health.snapshot()
+	health.RecentReorgs = w.reorgs.recent()
+	health.Paused = w.paused.Load()
+	return health
+}
+
+// ReorgHistory 返回近期检测到的重组 (按时间先后)
+func (w *ChainWatcher) ReorgHistory() []ReorgInfo {
+	return w.reorgs.recent()
+}
+
+// emitMilestones 分发在 head 高度跨过确认里程碑的事件
+func (w *ChainWatcher) emitMilestones(head uint64) {
+	w.milestones.setRequired(w.confirmations.required())
+	for _, event := range w.milestones.advance(head) {
+		w.dispatch.dispatch(&w.gate, event)
+	}
+}
+
+// emitReorged 通知重组替换区块中仍在跟踪确认的事件，冲回这些区块的运行余额变动，并遗忘其中已检测的事件
+func (w *ChainWatcher) emitReorged(fromBlock uint64) {
+	w.dispatch.seen.forget(w.chainID, fromBlock)
+	for _, event := range w.milestones.orphan(fromBlock) {
+		w.dispatch.dispatch(&w.gate, event)
+	}
+	for _, event := range w.dispatch.balances.revert(w.chainID, fromBlock) {
+		w.dispatch.dispatch(&w.gate, event)
+	}
+}
+
+// parentHash 返回按高度查询规范链区块父哈希的函数，用于重组时回溯共同祖先
+func (w *ChainWatcher) parentHash(ctx context.Context) parentLookup {
+	return func(number uint64) (string, error) {
+		header, err := w.client.HeaderByNumber(ctx, new(big.Int).SetUint64(number))
+		if err != nil {
+			return "", err
+		}
+		return header.ParentHash.Hex(), nil
+	}
+}
+
+// blockHeader 返回指定高度的区块头，订阅已推送该高度时不再查询
+func (w *ChainWatcher) blockHeader(ctx context.Context, number uint64) (*types.Header, error) {
+	if header := w.headHeader.Load(); header != nil && header.Number != nil && header.Number.Uint64() == number {
+		return header, nil
+	}
+	return w.client.HeaderByNumber(ctx, new(big.Int).SetUint64(number))
+}
+
+// processBlock 处理单个区块 (head 为当前链上最新高度，用于计算确认数)
+func (w *ChainWatcher) processBlock(ctx context.Context, blockNumber uint64, head uint64) {
+	// 单个区块处理 panic 时记录并跳过该区块
+	defer recoverPanic(w.cfg.RecoverPanics, w.chainName, fmt.Sprintf("block %d", blockNumber))
+
 	w.mu.RLock()
 	addresses := make([]common.Address, 0, len(w.addresses))
 	for addr := range w.addresses {
@@ -272,7 +848,29 @@ func (w *ChainWatcher) processBlock(ctx context.Context, blockNumber uint64) {
 	}
 	w.mu.RUnlock()
 
-	if len(addresses) == 0 {
+	if len(addresses) == 0 && len(w.scopedTokens) == 0 {
+		return
+	}
+
+	// 区块头: 时间戳 (获取失败时退回本地时间) 与重组检测
+	timestamp := time.Now()
+	header, err := w.blockHeader(ctx, blockNumber)
+	if err != nil {
+		log.Warn().Err(err).Uint64("block", blockNumber).Str("chain", w.chainName).Msg("Failed to get block header")
+	} else {
+		timestamp = time.Unix(int64(header.Time), 0)
+		if reorg := w.reorgs.observe(blockNumber, header.Hash().Hex(), header.ParentHash.Hex(), w.parentHash(ctx)); reorg != nil {
+			w.confirmations.observe(*reorg)
+			w.dispatch.dispatchReorg(&w.gate, newReorgEvent(*reorg))
+			w.emitReorged(reorg.FromBlock)
+			// 预取的日志可能来自被替换的区块
+			w.logRanges.reset()
+		}
+	}
+
+	// 早于 MinEventTimestamp 的区块不发出事件 (回填时减少下游压力)
+	if timestamp.Before(w.cfg.MinEventTimestamp) {
+		log.Debug().Uint64("block", blockNumber).Str("chain", w.chainName).Msg("Block older than MinEventTimestamp, skipping")
 		return
 	}
 
@@ -280,49 +878,155 @@ func (w *ChainWatcher) processBlock(ctx context.Context, blockNumber uint64) {
 	query := ethereum.FilterQuery{
 		FromBlock: big.NewInt(int64(blockNumber)),
 		ToBlock:   big.NewInt(int64(blockNumber)),
-		Topics:    [][]common.Hash{{transferEventSig}},
+		Topics:    [][]common.Hash{w.logTopics()},
+	}
+	// capture unknown 模式需要区块内所有日志
+	if w.cfg.CaptureUnknownLogs {
+		query.Topics = nil
 	}
 
-	logs, err := w.client.FilterLogs(ctx, query)
+	// 预取的日志须来自当前区块头 (区块头获取失败时单独查询)
+	var blockHash common.Hash
+	if header != nil {
+		blockHash = header.Hash()
+	}
+	logs, err := w.blockLogs(ctx, blockNumber, blockHash, query)
+	if isSizeLimitError(err) {
+		// 响应超过大小限制: 改用按监听地址/代币过滤的窄查询
+		log.Warn().Err(err).Uint64("block", blockNumber).Str("chain", w.chainName).Msg("Logs response too large, retrying with narrowed queries")
+		logs, err = w.filterLogsNarrowed(ctx, blockNumber, addresses)
+	}
 	if err != nil {
 		log.Error().Err(err).Uint64("block", blockNumber).Str("chain", w.chainName).Msg("Failed to filter logs")
 		return
 	}
 
-	// 处理每个日志
+	// 处理每个日志 (同一交易的手续费只查询一次)
+	fees := make(map[common.Hash]*txFee)
+	matched := 0
 	for _, vLog := range logs {
-		w.processLog(ctx, vLog, addresses, blockNumber)
+		if w.processLog(ctx, vLog, addresses, head, timestamp, fees) {
+			matched++
+		}
+	}
+
+	// 监听地址发出的失败交易 (仍扣除 gas)
+	if w.cfg.IndexFailedTxs {
+		matched += w.processFailedTxs(ctx, blockNumber, head, addresses, timestamp)
+	}
+
+	// 区块级元数据事件
+	if w.cfg.EmitBlockEvents {
+		w.emitBlockProcessed(ctx, blockNumber, header, timestamp, matched)
 	}
 }
 
-// processLog 处理单个日志
-func (w *ChainWatcher) processLog(ctx context.Context, vLog types.Log, addresses []common.Address, currentBlock uint64) {
-	// 解析 Transfer 事件
-	if len(vLog.Topics) < 3 {
-		return
+// scopedEVMTokens 解析按代币监听模式的代币合约地址
+func scopedEVMTokens(tokens []string) map[common.Address]bool {
+	set := make(map[common.Address]bool, len(tokens))
+	for _, token := range tokens {
+		token = strings.TrimSpace(token)
+		if common.IsHexAddress(token) {
+			set[common.HexToAddress(token)] = true
+		}
+	}
+	return set
+}
+
+// confirmationsAt 计算区块在给定链头下的确认数 (链头落后时为 0)
+func confirmationsAt(head, blockNumber uint64) uint64 {
+	if head < blockNumber {
+		return 0
 	}
+	return head - blockNumber
+}
 
-	from := common.HexToAddress(vLog.Topics[1].Hex())
-	to := common.HexToAddress(vLog.Topics[2].Hex())
+// transferTopics 返回需要匹配的 Transfer topic0 列表
+func transferTopics(sigs []string) []common.Hash {
+	set := transferSigSet(sigs)
+	topics := make([]common.Hash, 0, len(set))
+	for sig := range set {
+		topics = append(topics, common.HexToHash(sig))
+	}
+	return topics
+}
 
-	// 检查是否与监听地址相关
-	isRelevant := false
+// logTopics 返回区块日志查询匹配的 topic0: Transfer 签名与 ERC-1155 转账签名
+func (w *ChainWatcher) logTopics() []common.Hash {
+	topics := make([]common.Hash, 0, len(w.transferTopics)+2)
+	topics = append(topics, w.transferTopics...)
+	return append(topics, erc1155Topics...)
+}
+
+// watchedSide 返回转账中被监听的一方 (to 优先作为分区地址)，均未监听时为空
+func watchedSide(from, to common.Address, addresses []common.Address) string {
+	var watched string
 	for _, addr := range addresses {
-		if from == addr || to == addr {
-			isRelevant = true
-			break
+		if to == addr {
+			return to.Hex()
+		}
+		if from == addr {
+			watched = from.Hex()
 		}
 	}
-	if !isRelevant {
-		return
+	return watched
+}
+
+// processLog 处理单个日志，返回是否发出了事件
+func (w *ChainWatcher) processLog(ctx context.Context, vLog types.Log, addresses []common.Address, currentBlock uint64, timestamp time.Time, fees map[common.Hash]*txFee) bool {
+	// 代币白名单: 跳过其他合约发出的日志
+	if len(w.watchedTokens) > 0 && !w.watchedTokens[vLog.Address] {
+		return false
+	}
+
+	// ERC-1155 TransferSingle/TransferBatch 使用独立的签名与数据布局
+	if len(vLog.Topics) > 0 && isERC1155Topic(vLog.Topics[0]) {
+		return w.processERC1155Log(ctx, vLog, addresses, currentBlock, timestamp, fees)
+	}
+
+	// 解析 Transfer 事件 (无法解析的日志在 capture unknown 模式下原样发出)
+	if len(vLog.Topics) < 3 || !w.isTransferTopic(vLog.Topics[0]) {
+		if w.cfg.CaptureUnknownLogs {
+			return w.processUnknownLog(vLog, currentBlock, timestamp)
+		}
+		return false
+	}
+
+	// ERC-721 与 ERC-20 共用 Transfer 签名，但 tokenId 为 indexed (第四个 topic)
+	var nft *erc721Transfer
+	if len(vLog.Topics) == 4 {
+		var err error
+		if nft, err = parseERC721Transfer(vLog.Topics, vLog.Data); err != nil {
+			log.Debug().Err(err).Str("chain", w.chainName).Str("tx", vLog.TxHash.Hex()).Msg("Skipping malformed Transfer log")
+			return false
+		}
+	}
+
+	from := common.HexToAddress(vLog.Topics[1].Hex())
+	to := common.HexToAddress(vLog.Topics[2].Hex())
+	if nft != nil {
+		from, to = nft.From, nft.To
+	}
+
+	// 检查是否与监听地址相关 (to 优先作为分区地址)
+	watched := watchedSide(from, to, addresses)
+	isRelevant := watched != ""
+	// 按代币监听模式: 该代币的所有转账都发出 (可配置丢弃与监听地址无关的)
+	if !isRelevant && (!w.scopedTokens[vLog.Address] || w.cfg.DropUnwatchedTransfers) {
+		return false
+	}
+	// from 与 to 均为零地址: 计为异常，默认丢弃
+	zeroToZero := from == common.Address(zeroAddress) && to == common.Address(zeroAddress)
+	if zeroToZero && dropZeroToZero(w.chainName, vLog.TxHash.Hex(), w.cfg.EmitZeroAddressTransfers) {
+		return false
 	}
 
 	// 解析金额
 	value := new(big.Int).SetBytes(vLog.Data)
 
 	// 检查确认数
-	confirmations := currentBlock - vLog.BlockNumber
-	confirmed := confirmations >= w.cfg.Confirmations
+	confirmations := confirmationsAt(currentBlock, vLog.BlockNumber)
+	confirmed := confirmations >= w.confirmations.required()
 
 	event := &ChainEvent{
 		ChainID:      w.chainID,
@@ -334,8 +1038,40 @@ func (w *ChainWatcher) processLog(ctx context.Context, vLog types.Log, addresses
 		ToAddress:    to.Hex(),
 		Value:        value.String(),
 		TokenAddress: vLog.Address.Hex(),
-		Timestamp:    time.Now(),
+		Timestamp:    timestamp,
 		Confirmed:    confirmed,
+
+		Confirmations: confirmations,
+
+		TouchesWatched: isRelevant,
+		WatchedAddress: watched,
+		EventID:        eventID(w.chainID, vLog.TxHash.Hex(), vLog.Index),
+		LogIndex:       vLog.Index,
+
+		ZeroAddressTransfer: zeroToZero,
+	}
+
+	if nft != nil {
+		event.EventType = EventTypeERC721Transfer
+		event.Value = ""
+		event.TokenID = nft.TokenID.String()
+	} else {
+		w.tokens.annotate(event, value)
+		event.ExceedsTotalSupply = w.exceedsTotalSupply(vLog.Address, value)
+	}
+
+	// 低于 MinTransferValue 的小额转账不发出，也不跟踪确认
+	if belowMinTransferValue(w.cfg.MinTransferValue, event) {
+		return false
+	}
+
+	event.FinalityStatus = w.milestones.finality(event)
+
+	if w.cfg.IncludeFeeInfo {
+		if fee := w.evmTxFee(ctx, vLog.TxHash, fees); fee != nil {
+			event.FeePaid = fee.paid
+			event.FeePayer = fee.payer
+		}
 	}
 
 	log.Info().
@@ -348,7 +1084,8 @@ func (w *ChainWatcher) processLog(ctx context.Context, vLog types.Log, addresses
 		Msg("Transfer event detected")
 
 	// 调用处理器
-	for _, handler := range w.handlers {
-		go handler(event)
+	if w.emit(event) {
+		w.milestones.track(event)
 	}
+	return true
 }
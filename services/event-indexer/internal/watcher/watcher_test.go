@@ -1,11 +1,20 @@
 package watcher
 
 import (
+	"context"
 	"encoding/hex"
+	"errors"
 	"math/big"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/protocol-bank/event-indexer/internal/config"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -118,7 +127,7 @@ func TestBlockRangeCalculation(t *testing.T) {
 
 func TestChainConfig(t *testing.T) {
 	tests := []struct {
-		chainID       int64
+		chainID       uint64
 		expectedName  string
 		expectedDelay time.Duration
 	}{
@@ -139,6 +148,80 @@ func TestChainConfig(t *testing.T) {
 	}
 }
 
+func TestChainConfig_UnknownChain(t *testing.T) {
+	info := getChainConfig(999999)
+	assert.Equal(t, "Chain 999999", info.Name)
+	assert.Equal(t, 12*time.Second, info.BlockTime)
+	assert.Greater(t, info.BlockTime, time.Duration(0))
+}
+
+func TestNetworkSymbol(t *testing.T) {
+	tests := []struct {
+		chainID uint64
+		symbol  string
+	}{
+		{1, "ETH"},
+		{137, "POLYGON"},
+		{42161, "ARBITRUM"},
+		{8453, "BASE"},
+		{10, "OPTIMISM"},
+		{56, "BSC"},
+		{11155111, "ETH_SEPOLIA"},
+		{17000, "ETH_HOLESKY"},
+		{728126428, "TRON"},
+		{3448148188, "TRON_NILE"},
+		{999999, "CHAIN_999999"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.symbol, func(t *testing.T) {
+			assert.Equal(t, tt.symbol, NetworkSymbol(tt.chainID))
+		})
+	}
+}
+
+func TestDispatch_SetsNetwork(t *testing.T) {
+	client := newFakeTronClient(200)
+	token, _ := testTronAddress(0xaa)
+	from, _ := testTronAddress(0x11)
+	to, toAddr := testTronAddress(0x22)
+	client.addTransfer(190, "b190", token, from, to, big.NewInt(1))
+
+	w := newTestTronWatcher(client)
+	w.AddTronAddress(toAddr)
+
+	var got []*ChainEvent
+	w.dispatch.addHandler(func(event *ChainEvent) error {
+		got = append(got, event)
+		return nil
+	})
+	collectTronTxs(t, w, 190, 200)
+
+	require.Len(t, got, 1)
+	assert.Equal(t, NetworkSymbol(w.chainID), got[0].Network)
+}
+
+func TestRegisterChainInfo(t *testing.T) {
+	const chainID = 424242
+	RegisterChainInfo(chainID, ChainInfo{Name: "Custom L2", BlockTime: time.Second})
+	t.Cleanup(func() {
+		chainInfoMu.Lock()
+		delete(chainInfos, chainID)
+		chainInfoMu.Unlock()
+	})
+
+	info := getChainConfig(chainID)
+	assert.Equal(t, "Custom L2", info.Name)
+	assert.Equal(t, time.Second, info.BlockTime)
+
+	// Zero block time falls back to the default
+	RegisterChainInfo(chainID, ChainInfo{Name: "Custom L2"})
+	assert.Equal(t, 12*time.Second, getChainConfig(chainID).BlockTime)
+
+	RegisterChainInfo(chainID, ChainInfo{Name: "Custom L2", Symbol: "CUSTOM"})
+	assert.Equal(t, "CUSTOM", NetworkSymbol(chainID))
+}
+
 func TestReorgDetection(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -175,16 +258,6 @@ type TransferEvent struct {
 	Amount *big.Int
 }
 
-type BlockRange struct {
-	From uint64
-	To   uint64
-}
-
-type ChainConfig struct {
-	Name      string
-	BlockTime time.Duration
-}
-
 func parseTransferEvent(topics []string, data string) (*TransferEvent, error) {
 	if len(topics) < 3 {
 		return nil, assert.AnError
@@ -203,34 +276,6 @@ func parseTransferEvent(topics []string, data string) (*TransferEvent, error) {
 	}, nil
 }
 
-func calculateBlockRanges(from, to, maxRange uint64) []BlockRange {
-	var ranges []BlockRange
-	for start := from; start <= to; start += maxRange {
-		end := start + maxRange - 1
-		if end > to {
-			end = to
-		}
-		ranges = append(ranges, BlockRange{From: start, To: end})
-	}
-	return ranges
-}
-
-func getChainConfig(chainID int64) ChainConfig {
-	configs := map[int64]ChainConfig{
-		1:     {Name: "Ethereum Mainnet", BlockTime: 12 * time.Second},
-		137:   {Name: "Polygon", BlockTime: 2 * time.Second},
-		42161: {Name: "Arbitrum One", BlockTime: 250 * time.Millisecond},
-		8453:  {Name: "Base", BlockTime: 2 * time.Second},
-		10:   {Name: "Optimism", BlockTime: 2 * time.Second},
-		56:    {Name: "BNB Chain", BlockTime: 3 * time.Second},
-	}
-	return configs[chainID]
-}
-
-func detectReorg(previousHash, currentParent string) bool {
-	return previousHash != currentParent
-}
-
 // ============================================
 // TRON Watcher Tests
 // ============================================
@@ -243,7 +288,7 @@ func TestHexTopicToTronAddress(t *testing.T) {
 		topic[i] = 0x11
 	}
 
-	addr := hexTopicToTronAddress(topic)
+	addr := hexTopicToTronAddress(topic, tronMainnetPrefix)
 	assert.NotEmpty(t, addr)
 	assert.Equal(t, byte('T'), addr[0])
 	assert.Equal(t, 34, len(addr))
@@ -330,3 +375,300 @@ type ChainConfigForTest struct {
 	Name string
 	Type string
 }
+
+// ============================================
+// EVM Watcher Tests
+// ============================================
+
+func TestChainWatcher_ConfirmationsField(t *testing.T) {
+	client := newFakeEVMClient(1000)
+	watched := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	client.addLog(testTransferLog(990, 0, common.HexToAddress("0x1111111111111111111111111111111111111111"), watched, big.NewInt(7)))
+
+	w := newTestChainWatcher(t, client)
+	w.AddAddress(watched)
+	events := collectEVMEvents(t, w, 990, 1000)
+
+	require.Len(t, events, 1)
+	assert.Equal(t, uint64(10), events[0].Confirmations)
+	assert.False(t, events[0].Confirmed, "10 < 12 required confirmations")
+}
+
+func TestTronWatcher_ConfirmationsField(t *testing.T) {
+	client := newFakeTronClient(200)
+	token, _ := testTronAddress(0xaa)
+	from, _ := testTronAddress(0x11)
+	to, toAddr := testTronAddress(0x22)
+	client.addTransfer(175, "f175", token, from, to, big.NewInt(1))
+
+	w := newTestTronWatcher(client)
+	w.AddTronAddress(toAddr)
+
+	var got *ChainEvent
+	w.dispatch.addHandler(func(event *ChainEvent) error {
+		got = event
+		return nil
+	})
+	w.gate.enter()
+	w.processBlock(context.Background(), 175, 200)
+	w.gate.leave()
+	w.gate.drain()
+
+	require.NotNil(t, got)
+	assert.Equal(t, uint64(25), got.Confirmations)
+	assert.True(t, got.Confirmed, "25 >= 19 required confirmations")
+}
+
+// fakeEVMClient is an in-memory evmRPC serving canned logs, receipts and
+// transactions.
+type fakeEVMClient struct {
+	mu       sync.Mutex
+	head     uint64
+	logs     map[uint64][]types.Log
+	receipts map[common.Hash]*types.Receipt
+	txs      map[common.Hash]*types.Transaction
+	senders  map[common.Hash]common.Address
+	headers  map[uint64]*types.Header
+	forks    map[uint64]byte
+	blockTxs map[uint64][]*types.Transaction
+
+	// maxLogs, if non-zero, fails FilterLogs queries returning more logs
+	maxLogs int
+
+	receiptCalls int
+}
+
+func newFakeEVMClient(head uint64) *fakeEVMClient {
+	return &fakeEVMClient{
+		head:     head,
+		logs:     make(map[uint64][]types.Log),
+		receipts: make(map[common.Hash]*types.Receipt),
+		txs:      make(map[common.Hash]*types.Transaction),
+		senders:  make(map[common.Hash]common.Address),
+		headers:  make(map[uint64]*types.Header),
+		forks:    make(map[uint64]byte),
+		blockTxs: make(map[uint64][]*types.Transaction),
+	}
+}
+
+// reorg replaces block n (and so every descendant) with a sibling block.
+func (f *fakeEVMClient) reorg(n uint64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.forks[n]++
+	for num := range f.headers {
+		if num >= n {
+			delete(f.headers, num)
+		}
+	}
+}
+
+// headerAt builds a hash-linked chain of headers; timestamps advance 12s
+// per block. Callers hold f.mu.
+func (f *fakeEVMClient) headerAt(n uint64) *types.Header {
+	if header, ok := f.headers[n]; ok {
+		return header
+	}
+	header := &types.Header{
+		Number: new(big.Int).SetUint64(n),
+		Time:   n * 12,
+		Extra:  []byte{f.forks[n]},
+	}
+	if n > 0 {
+		header.ParentHash = f.headerAt(n - 1).Hash()
+	}
+	f.headers[n] = header
+	return header
+}
+
+// addTx registers a transaction, its receipt and its sender.
+func (f *fakeEVMClient) addTx(hash common.Hash, tx *types.Transaction, receipt *types.Receipt, sender common.Address) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.txs[hash] = tx
+	f.receipts[hash] = receipt
+	f.senders[tx.Hash()] = sender
+	if receipt != nil && receipt.BlockNumber != nil {
+		f.blockTxs[receipt.BlockNumber.Uint64()] = append(f.blockTxs[receipt.BlockNumber.Uint64()], tx)
+	}
+}
+
+func (f *fakeEVMClient) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.receiptCalls++
+	receipt, ok := f.receipts[txHash]
+	if !ok {
+		return nil, ethereum.NotFound
+	}
+	return receipt, nil
+}
+
+func (f *fakeEVMClient) TransactionByHash(ctx context.Context, hash common.Hash) (*types.Transaction, bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	tx, ok := f.txs[hash]
+	if !ok {
+		return nil, false, ethereum.NotFound
+	}
+	return tx, false, nil
+}
+
+func (f *fakeEVMClient) TransactionSender(ctx context.Context, tx *types.Transaction, block common.Hash, index uint) (common.Address, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	sender, ok := f.senders[tx.Hash()]
+	if !ok {
+		return common.Address{}, ethereum.NotFound
+	}
+	return sender, nil
+}
+
+func (f *fakeEVMClient) addLog(l types.Log) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.logs[l.BlockNumber] = append(f.logs[l.BlockNumber], l)
+}
+
+func (f *fakeEVMClient) BlockNumber(ctx context.Context) (uint64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.head, nil
+}
+
+func (f *fakeEVMClient) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.headerAt(number.Uint64()), nil
+}
+
+func (f *fakeEVMClient) BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	header := f.headerAt(number.Uint64())
+	return types.NewBlockWithHeader(header).WithBody(types.Body{Transactions: f.blockTxs[number.Uint64()]}), nil
+}
+
+func (f *fakeEVMClient) TransactionCount(ctx context.Context, blockHash common.Hash) (uint, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for n, header := range f.headers {
+		if header.Hash() == blockHash {
+			return uint(len(f.blockTxs[n])), nil
+		}
+	}
+	return 0, ethereum.NotFound
+}
+
+func (f *fakeEVMClient) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var out []types.Log
+	for block := q.FromBlock.Uint64(); block <= q.ToBlock.Uint64(); block++ {
+		for _, l := range f.logs[block] {
+			if matchesFilter(q, l) {
+				l.BlockHash = f.headerAt(block).Hash()
+				out = append(out, l)
+			}
+		}
+	}
+	if f.maxLogs > 0 && len(out) > f.maxLogs {
+		return nil, errors.New("Log response size exceeded. You can make eth_getLogs requests with up to a 2K block range")
+	}
+	return out, nil
+}
+
+// matchesFilter applies the address and per-position topic criteria of q.
+func matchesFilter(q ethereum.FilterQuery, l types.Log) bool {
+	if len(q.Addresses) > 0 {
+		found := false
+		for _, addr := range q.Addresses {
+			found = found || addr == l.Address
+		}
+		if !found {
+			return false
+		}
+	}
+	for i, set := range q.Topics {
+		if len(set) == 0 {
+			continue
+		}
+		if len(l.Topics) <= i || !containsHash(set, l.Topics[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsHash(set []common.Hash, h common.Hash) bool {
+	for _, s := range set {
+		if s == h {
+			return true
+		}
+	}
+	return false
+}
+
+// newTestChainWatcher builds an Ethereum-like EVM watcher around a fake client.
+func newTestChainWatcher(t *testing.T, client evmRPC) *ChainWatcher {
+	t.Helper()
+	parsedABI, err := abi.JSON(strings.NewReader(erc20ABI))
+	require.NoError(t, err)
+	return newEVMWatcher(config.ChainConfig{
+		ChainID:       1,
+		Name:          "Ethereum Test",
+		Confirmations: 12,
+		Type:          "evm",
+	}, client, parsedABI)
+}
+
+// testTransferLog builds an ERC20 Transfer log emitted by a fixed token contract.
+func testTransferLog(block uint64, index uint, from, to common.Address, value *big.Int) types.Log {
+	return types.Log{
+		Address:     common.HexToAddress("0xdAC17F958D2ee523a2206206994597C13D831ec7"),
+		Topics:      []common.Hash{common.HexToHash(trc20TransferSig), common.BytesToHash(from.Bytes()), common.BytesToHash(to.Bytes())},
+		Data:        common.LeftPadBytes(value.Bytes(), 32),
+		BlockNumber: block,
+		TxHash:      common.BigToHash(new(big.Int).SetUint64(block*1000 + uint64(index))),
+		Index:       index,
+	}
+}
+
+// collectEVMEvents processes one block and returns the emitted events.
+func collectEVMEvents(t *testing.T, w *ChainWatcher, blockNumber, head uint64) []*ChainEvent {
+	t.Helper()
+
+	var mu sync.Mutex
+	var events []*ChainEvent
+	w.dispatch.addHandler(func(event *ChainEvent) error {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, event)
+		return nil
+	})
+
+	w.gate.enter()
+	w.processBlock(context.Background(), blockNumber, head)
+	w.gate.leave()
+	w.gate.inflight.Wait() // unlike drain, leaves the watcher usable
+	return events
+}
+
+func TestChainWatcher_MinEventTimestamp(t *testing.T) {
+	client := newFakeEVMClient(1000)
+	watched := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	from := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	client.addLog(testTransferLog(990, 0, from, watched, big.NewInt(1)))
+	client.addLog(testTransferLog(996, 0, from, watched, big.NewInt(2)))
+
+	w := newTestChainWatcher(t, client)
+	w.cfg.MinEventTimestamp = time.Unix(996*12, 0)
+	w.AddAddress(watched)
+
+	assert.Empty(t, collectEVMEvents(t, w, 990, 1000), "block 990 predates the cutoff")
+
+	events := collectEVMEvents(t, w, 996, 1000)
+	require.Len(t, events, 1)
+	assert.Equal(t, time.Unix(996*12, 0), events[0].Timestamp)
+}
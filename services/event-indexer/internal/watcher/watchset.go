@@ -0,0 +1,241 @@
+package watcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/rs/zerolog/log"
+)
+
+// WatchSetStore persists addresses added at runtime, one set per chain.
+// Adding and removing are idempotent.
+type WatchSetStore interface {
+	LoadWatchedAddresses(ctx context.Context, chainID uint64) ([]string, error)
+	AddWatchedAddress(ctx context.Context, chainID uint64, address string) error
+	RemoveWatchedAddress(ctx context.Context, chainID uint64, address string) error
+}
+
+// isEVMAddressFormat reports whether addr looks like a 0x-prefixed hex address.
+func isEVMAddressFormat(addr string) bool {
+	return len(addr) == 42 && addr[:2] == "0x"
+}
+
+// isTronAddressFormat reports whether addr looks like a TRON Base58 address.
+func isTronAddressFormat(addr string) bool {
+	return len(addr) == 34 && addr[0] == 'T'
+}
+
+// AddAddress watches addr on the given chains, or on every chain whose
+// address format it matches if none are given. Scoping an address keeps a
+// transfer on one chain from matching a deposit address meant for another.
+// It reports whether addr was newly added to any chain, false if every
+// chain already watched it. With a WatchSetStore the address is persisted
+// so it survives a restart.
+func (mcw *MultiChainWatcher) AddAddress(addr string, chainIDs ...uint64) bool {
+	addr = strings.TrimSpace(addr)
+	added, chains, err := mcw.addAddress(addr, chainIDs)
+	if err != nil {
+		log.Warn().Err(err).Msg("Rejected watched address")
+	}
+	mcw.persistWatchSet(addr, chains, true)
+	return added
+}
+
+// addAddress watches addr without persisting it, returning whether it was
+// newly added and the chains it applies to. An invalid TRON address is
+// rejected by every TRON chain, with the error returned.
+func (mcw *MultiChainWatcher) addAddress(addr string, chainIDs []uint64) (bool, []uint64, error) {
+	applies := func(chainID uint64) bool {
+		return len(chainIDs) == 0 || slices.Contains(chainIDs, chainID)
+	}
+
+	mcw.mu.Lock()
+	defer mcw.mu.Unlock()
+
+	added := false
+	var chains []uint64
+	var rejected error
+	switch {
+	case isEVMAddressFormat(addr):
+		for chainID, w := range mcw.watchers {
+			if applies(chainID) {
+				chains = append(chains, chainID)
+				if w.AddAddress(common.HexToAddress(addr)) {
+					added = true
+				}
+			}
+		}
+	case isTronAddressFormat(addr):
+		for chainID, tw := range mcw.tronWatchers {
+			if applies(chainID) {
+				isNew, err := tw.AddTronAddress(addr)
+				if err != nil {
+					rejected = err
+					continue
+				}
+				chains = append(chains, chainID)
+				if isNew {
+					added = true
+				}
+			}
+		}
+	}
+	// Chains still starting get it when they join
+	for chainID, p := range mcw.pending {
+		if applies(chainID) && pendingAddressMatches(p, addr) {
+			if isTronChain(p.cfg) {
+				if _, err := normalizeTronAddress(addr, tronAddressPrefix(p.cfg.AddressPrefix)); err != nil {
+					rejected = err
+					continue
+				}
+			}
+			chains = append(chains, chainID)
+			if !p.addresses[addr] {
+				p.addresses[addr] = true
+				added = true
+			}
+		}
+	}
+	return added, chains, rejected
+}
+
+// RemoveAddress stops watching addr on the given chains, or on all chains
+// if none are given, and drops it from the WatchSetStore. An address that is
+// also in the configured WatchedAddresses is watched again after a restart.
+func (mcw *MultiChainWatcher) RemoveAddress(addr string, chainIDs ...uint64) {
+	applies := func(chainID uint64) bool {
+		return len(chainIDs) == 0 || slices.Contains(chainIDs, chainID)
+	}
+
+	mcw.mu.Lock()
+	var chains []uint64
+	switch {
+	case isEVMAddressFormat(addr):
+		for chainID, w := range mcw.watchers {
+			if applies(chainID) {
+				w.RemoveAddress(common.HexToAddress(addr))
+				chains = append(chains, chainID)
+			}
+		}
+	case isTronAddressFormat(addr):
+		for chainID, tw := range mcw.tronWatchers {
+			if applies(chainID) {
+				tw.RemoveTronAddress(addr)
+				chains = append(chains, chainID)
+			}
+		}
+	}
+	for chainID, p := range mcw.pending {
+		if applies(chainID) {
+			delete(p.addresses, addr)
+			if pendingAddressMatches(p, addr) {
+				chains = append(chains, chainID)
+			}
+		}
+	}
+	mcw.mu.Unlock()
+
+	mcw.persistWatchSet(addr, chains, false)
+}
+
+// pendingAddressMatches reports whether addr's format fits the chain of p.
+func pendingAddressMatches(p *pendingChain, addr string) bool {
+	if isTronChain(p.cfg) {
+		return isTronAddressFormat(addr)
+	}
+	return isEVMAddressFormat(addr)
+}
+
+// watchConfiguredAddresses adds cfg's watched addresses, each on the chains
+// it is scoped to (all chains if unscoped). Configured addresses aren't
+// persisted. Invalid TRON addresses are returned as an error, so bad config
+// fails startup instead of never matching.
+func (mcw *MultiChainWatcher) watchConfiguredAddresses(addresses []string, scopes map[string][]uint64) error {
+	scoped := make(map[string][]uint64, len(scopes))
+	for addr, chainIDs := range scopes {
+		scoped[tagKey(addr)] = chainIDs
+	}
+	var errs []error
+	for _, addr := range addresses {
+		addr = strings.TrimSpace(addr)
+		if _, _, err := mcw.addAddress(addr, scoped[tagKey(addr)]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// SetWatchSetStore persists runtime address additions and removals to store,
+// and watches the addresses it already holds on top of the configured ones.
+// Must be called before Start.
+func (mcw *MultiChainWatcher) SetWatchSetStore(ctx context.Context, store WatchSetStore) error {
+	watchers, tronWatchers := mcw.chainWatchers()
+	chainIDs := make([]uint64, 0, len(watchers)+len(tronWatchers))
+	for chainID := range watchers {
+		chainIDs = append(chainIDs, chainID)
+	}
+	for chainID := range tronWatchers {
+		chainIDs = append(chainIDs, chainID)
+	}
+	for _, cfg := range mcw.pendingChains() {
+		chainIDs = append(chainIDs, cfg.ChainID)
+	}
+
+	for _, chainID := range chainIDs {
+		addresses, err := store.LoadWatchedAddresses(ctx, chainID)
+		if err != nil {
+			return fmt.Errorf("load watched addresses of chain %d: %w", chainID, err)
+		}
+		restored := 0
+		for _, addr := range addresses {
+			added, _, err := mcw.addAddress(addr, []uint64{chainID})
+			if err != nil {
+				log.Warn().Err(err).Uint64("chain_id", chainID).Msg("Skipping invalid persisted address")
+			}
+			if added {
+				restored++
+			}
+		}
+		if len(addresses) > 0 {
+			log.Info().Uint64("chain_id", chainID).Int("persisted", len(addresses)).Int("restored", restored).Msg("Loaded persisted watched addresses")
+		}
+	}
+
+	mcw.mu.Lock()
+	defer mcw.mu.Unlock()
+	mcw.watchSetStore = store
+	return nil
+}
+
+// persistWatchSet records addr being added to or removed from chains in the
+// WatchSetStore, if one is set. EVM addresses are stored checksummed so a
+// later removal matches regardless of case. Failures are logged: the
+// in-memory watch set already changed, only durability is lost.
+func (mcw *MultiChainWatcher) persistWatchSet(addr string, chains []uint64, add bool) {
+	mcw.mu.RLock()
+	store := mcw.watchSetStore
+	mcw.mu.RUnlock()
+	if store == nil || len(chains) == 0 {
+		return
+	}
+
+	if isEVMAddressFormat(addr) {
+		addr = common.HexToAddress(addr).Hex()
+	}
+	ctx := context.Background()
+	for _, chainID := range chains {
+		var err error
+		if add {
+			err = store.AddWatchedAddress(ctx, chainID, addr)
+		} else {
+			err = store.RemoveWatchedAddress(ctx, chainID, addr)
+		}
+		if err != nil {
+			log.Warn().Err(err).Uint64("chain_id", chainID).Str("address", addr).Bool("add", add).Msg("Failed to persist watched address")
+		}
+	}
+}
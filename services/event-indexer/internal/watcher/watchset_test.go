@@ -0,0 +1,176 @@
+package watcher
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/protocol-bank/event-indexer/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMultiChainWatcher_ChainScopedAddress(t *testing.T) {
+	deposit := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	other := common.HexToAddress("0x1111111111111111111111111111111111111111")
+
+	parsedABI, err := abi.JSON(strings.NewReader(erc20ABI))
+	require.NoError(t, err)
+
+	// The same transfer shows up on both chains
+	newWatcher := func(chainID uint64) *ChainWatcher {
+		client := newFakeEVMClient(110)
+		client.addLog(testTransferLog(100, 0, other, deposit, big.NewInt(5)))
+		return newEVMWatcher(config.ChainConfig{ChainID: chainID, Name: "Test", Confirmations: 12, Type: "evm"}, client, parsedABI)
+	}
+	ethereum, polygon := newWatcher(1), newWatcher(137)
+	mcw := &MultiChainWatcher{watchers: map[uint64]*ChainWatcher{1: ethereum, 137: polygon}}
+
+	mcw.AddAddress(deposit.Hex(), 1)
+
+	assert.Len(t, collectEVMEvents(t, ethereum, 100, 110), 1)
+	assert.Empty(t, collectEVMEvents(t, polygon, 100, 110), "not watched on chain 137")
+	assert.True(t, mcw.isWatched(1, deposit.Hex()))
+	assert.False(t, mcw.isWatched(137, deposit.Hex()))
+
+	t.Run("unscoped applies to every chain", func(t *testing.T) {
+		mcw.AddAddress(deposit.Hex())
+		assert.True(t, mcw.isWatched(137, deposit.Hex()))
+
+		mcw.RemoveAddress(deposit.Hex(), 137)
+		assert.True(t, mcw.isWatched(1, deposit.Hex()))
+		assert.False(t, mcw.isWatched(137, deposit.Hex()))
+	})
+
+	t.Run("configured scopes match case-insensitively", func(t *testing.T) {
+		mcw.RemoveAddress(deposit.Hex())
+		mcw.watchConfiguredAddresses([]string{deposit.Hex()}, map[string][]uint64{strings.ToLower(deposit.Hex()): {137}})
+		assert.False(t, mcw.isWatched(1, deposit.Hex()))
+		assert.True(t, mcw.isWatched(137, deposit.Hex()))
+	})
+}
+
+func TestAddAddress_ReportsNewlyAdded(t *testing.T) {
+	w := newTestChainWatcher(t, newFakeEVMClient(100))
+	addr := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	assert.True(t, w.AddAddress(addr))
+	assert.False(t, w.AddAddress(addr), "already watched")
+	w.RemoveAddress(addr)
+	assert.True(t, w.AddAddress(addr), "watched again after removal")
+
+	tw := newTestTronWatcher(newFakeTronClient(100))
+	_, tronAddr := testTronAddress(0x22)
+	added, err := tw.AddTronAddress(tronAddr)
+	require.NoError(t, err)
+	assert.True(t, added)
+	added, err = tw.AddTronAddress(tronAddr)
+	require.NoError(t, err)
+	assert.False(t, added)
+
+	mcw := &MultiChainWatcher{
+		watchers:     map[uint64]*ChainWatcher{1: w},
+		tronWatchers: map[uint64]*TronWatcher{728126428: tw},
+		pending: map[uint64]*pendingChain{
+			137: {cfg: config.ChainConfig{ChainID: 137, Type: "evm"}, addresses: map[string]bool{}},
+		},
+	}
+	other := common.HexToAddress("0x3333333333333333333333333333333333333333").Hex()
+	assert.True(t, mcw.AddAddress(other))
+	assert.False(t, mcw.AddAddress(other), "already watched on every chain, pending ones included")
+	assert.True(t, mcw.AddAddress(addr.Hex()), "new on the pending chain")
+	assert.False(t, mcw.AddAddress(tronAddr))
+}
+
+// memoryWatchSet is an in-memory WatchSetStore.
+type memoryWatchSet struct {
+	mu   sync.Mutex
+	sets map[uint64]map[string]bool
+}
+
+func (m *memoryWatchSet) LoadWatchedAddresses(_ context.Context, chainID uint64) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []string
+	for addr := range m.sets[chainID] {
+		out = append(out, addr)
+	}
+	return out, nil
+}
+
+func (m *memoryWatchSet) AddWatchedAddress(_ context.Context, chainID uint64, address string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.sets == nil {
+		m.sets = make(map[uint64]map[string]bool)
+	}
+	if m.sets[chainID] == nil {
+		m.sets[chainID] = make(map[string]bool)
+	}
+	m.sets[chainID][address] = true
+	return nil
+}
+
+func (m *memoryWatchSet) RemoveWatchedAddress(_ context.Context, chainID uint64, address string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.sets[chainID], address)
+	return nil
+}
+
+func TestMultiChainWatcher_PersistedWatchSet(t *testing.T) {
+	configured := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	runtime := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	removed := common.HexToAddress("0x3333333333333333333333333333333333333333")
+	_, tronAddr := testTronAddress(0x44)
+	store := &memoryWatchSet{}
+
+	// Each "process" builds fresh watchers and watches the configured address
+	start := func() *MultiChainWatcher {
+		mcw := &MultiChainWatcher{
+			watchers:     map[uint64]*ChainWatcher{1: newTestChainWatcher(t, newFakeEVMClient(100))},
+			tronWatchers: map[uint64]*TronWatcher{728126428: newTestTronWatcher(newFakeTronClient(100))},
+		}
+		mcw.watchConfiguredAddresses([]string{configured.Hex()}, nil)
+		require.NoError(t, mcw.SetWatchSetStore(context.Background(), store))
+		return mcw
+	}
+
+	mcw := start()
+	mcw.AddAddress(strings.ToLower(runtime.Hex()))
+	mcw.AddAddress(tronAddr)
+	mcw.AddAddress(removed.Hex())
+	mcw.RemoveAddress(strings.ToLower(removed.Hex()))
+	assert.Equal(t, map[string]bool{runtime.Hex(): true}, store.sets[1], "configured addresses aren't persisted, EVM ones are checksummed")
+
+	// Restart
+	mcw = start()
+	assert.True(t, mcw.isWatched(1, runtime.Hex()), "runtime addition survives the restart")
+	assert.True(t, mcw.isWatched(1, configured.Hex()), "merged with the configured addresses")
+	assert.True(t, mcw.isWatched(728126428, tronAddr))
+	assert.False(t, mcw.isWatched(1, removed.Hex()), "removal is persisted too")
+}
+
+func TestMultiChainWatcher_ConfiguredAddressValidation(t *testing.T) {
+	_, valid := testTronAddress(0x22)
+	const typo = "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6u"
+	tw := newTestTronWatcher(newFakeTronClient(100))
+	mcw := &MultiChainWatcher{
+		tronWatchers: map[uint64]*TronWatcher{728126428: tw},
+		pending: map[uint64]*pendingChain{
+			2494104990: {cfg: config.ChainConfig{ChainID: 2494104990, Type: "tron"}, addresses: map[string]bool{}},
+		},
+	}
+
+	err := mcw.watchConfiguredAddresses([]string{" " + valid, typo}, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), typo)
+	assert.NotContains(t, err.Error(), valid)
+
+	assert.True(t, tw.isWatched(valid), "valid addresses are still watched, trimmed")
+	assert.False(t, tw.isWatched(typo))
+	assert.Equal(t, map[string]bool{valid: true}, mcw.pending[2494104990].addresses, "chains still starting reject it too")
+}
@@ -41,6 +41,8 @@ func main() {
 	if err != nil {
 		log.Fatal().Err(err).Msg("Failed to initialize nonce manager")
 	}
+	nonceManager.SetRegressionThreshold(cfg.NonceRegressionThreshold)
+	nonceManager.SetGapThreshold(cfg.NonceGapThreshold)
 
 	// 队列消费者
 	queueConsumer, err := queue.NewConsumer(ctx, cfg.Redis)
@@ -54,6 +56,14 @@ func main() {
 		log.Fatal().Err(err).Msg("Failed to initialize payout service")
 	}
 
+	// 热钱包原生币余额监控 (配置了阈值的链)，余额过低时告警
+	for _, chainCfg := range cfg.Chains {
+		if chainCfg.LowBalanceThreshold != nil && len(chainCfg.HotWallets) > 0 {
+			go payoutService.MonitorBalances(ctx, service.LogAlert)
+			break
+		}
+	}
+
 	// 启动队列消费者
 	go queueConsumer.Start(ctx, payoutService.ProcessJob)
 
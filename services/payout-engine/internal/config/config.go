@@ -1,8 +1,12 @@
 package config
 
 import (
+	"fmt"
+	"math/big"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 )
 
 type Config struct {
@@ -14,6 +18,9 @@ type Config struct {
 	// TRON-specific
 	TronPrivateKey string // TRON Payout Signing Key (separate from EVM)
 	TRC20FeeLimit  int64  // Fee limit for TRC20 transfers (in SUN, default 100 TRX)
+	// Ceiling for retrying a TRC20 transfer that ran out of energy with a
+	// higher fee limit (in SUN); at or below TRC20FeeLimit there is no retry
+	TRC20MaxFeeLimit int64
 
 	// Database
 	Database DatabaseConfig
@@ -23,6 +30,25 @@ type Config struct {
 
 	// Blockchain
 	Chains map[uint64]ChainConfig
+
+	// Consecutive regressed onchain nonce reads before the cached nonce is
+	// reset automatically (0 = disabled)
+	NonceRegressionThreshold int
+	// Allocated-but-unconfirmed nonces per signer above which a stuck
+	// transaction is alerted on (0 = disabled)
+	NonceGapThreshold uint64
+
+	// Confirmations to wait for after broadcasting an EVM payout before the
+	// job succeeds and the nonce lease is released (0 = don't wait)
+	PayoutConfirmations uint64
+	// How long to wait for the receipt and confirmations
+	ReceiptTimeout time.Duration
+	// How often the receipt and chain head are polled
+	ReceiptPollInterval time.Duration
+
+	// How often hot wallet native balances are checked against
+	// LowBalanceThreshold
+	BalanceCheckInterval time.Duration
 }
 
 type DatabaseConfig struct {
@@ -34,6 +60,15 @@ type RedisConfig struct {
 	Password   string
 	DB         int
 	TLSEnabled bool // Enable TLS for production Redis
+
+	// Prefix for nonce and nonce lock keys, to share one Redis between
+	// deployments (e.g. "prod:")
+	NonceKeyPrefix string
+	// Expiry of the per-address nonce lock (0 = 30s)
+	NonceLockTTL time.Duration
+	// Keep extending a held nonce lock until it is released, for signing
+	// that can outlast NonceLockTTL
+	NonceLockRenewal bool
 }
 
 type ChainConfig struct {
@@ -44,6 +79,11 @@ type ChainConfig struct {
 	NativeToken string
 	Decimals    int
 	Type        string // "evm" or "tron"
+
+	// Hot wallets whose native balance is monitored, and the balance (in
+	// wei/SUN) below which an alert fires (nil = not monitored)
+	HotWallets          []string
+	LowBalanceThreshold *big.Int
 }
 
 func Load() (*Config, error) {
@@ -54,14 +94,27 @@ func Load() (*Config, error) {
 	if trc20FeeLimit <= 0 {
 		trc20FeeLimit = 100_000_000 // 100 TRX default
 	}
+	trc20MaxFeeLimit, _ := strconv.ParseInt(getEnv("TRC20_MAX_FEE_LIMIT", "0"), 10, 64)
+
+	nonceRegressionThreshold, _ := strconv.Atoi(getEnv("NONCE_REGRESSION_THRESHOLD", "0"))
+	nonceGapThreshold, _ := strconv.ParseUint(getEnv("NONCE_GAP_THRESHOLD", "0"), 10, 64)
+	payoutConfirmations, _ := strconv.ParseUint(getEnv("PAYOUT_CONFIRMATIONS", "0"), 10, 64)
 
 	cfg := &Config{
-		Environment:    getEnv("ENVIRONMENT", "development"),
-		GRPCPort:       port,
-		APISecret:      getEnv("API_SECRET", ""),
-		PrivateKey:     getEnv("PAYOUT_PRIVATE_KEY", ""),
-		TronPrivateKey: getEnv("TRON_PRIVATE_KEY", ""),
-		TRC20FeeLimit:  trc20FeeLimit,
+		Environment:      getEnv("ENVIRONMENT", "development"),
+		GRPCPort:         port,
+		APISecret:        getEnv("API_SECRET", ""),
+		PrivateKey:       getEnv("PAYOUT_PRIVATE_KEY", ""),
+		TronPrivateKey:   getEnv("TRON_PRIVATE_KEY", ""),
+		TRC20FeeLimit:    trc20FeeLimit,
+		TRC20MaxFeeLimit: trc20MaxFeeLimit,
+
+		NonceRegressionThreshold: nonceRegressionThreshold,
+		NonceGapThreshold:        nonceGapThreshold,
+		PayoutConfirmations:      payoutConfirmations,
+		ReceiptTimeout:           getEnvDuration("RECEIPT_TIMEOUT", 5*time.Minute),
+		ReceiptPollInterval:      getEnvDuration("RECEIPT_POLL_INTERVAL", 3*time.Second),
+		BalanceCheckInterval:     getEnvDuration("BALANCE_CHECK_INTERVAL", time.Minute),
 		Database: DatabaseConfig{
 			URL: getEnv("DATABASE_URL", ""),
 		},
@@ -70,6 +123,10 @@ func Load() (*Config, error) {
 			Password:   getEnv("REDIS_PASSWORD", ""),
 			DB:         redisDB,
 			TLSEnabled: getEnv("REDIS_TLS_ENABLED", "false") == "true",
+
+			NonceKeyPrefix:   getEnv("NONCE_KEY_PREFIX", ""),
+			NonceLockTTL:     getEnvDuration("NONCE_LOCK_TTL", 30*time.Second),
+			NonceLockRenewal: getEnv("NONCE_LOCK_RENEWAL", "false") == "true",
 		},
 		Chains: map[uint64]ChainConfig{
 			// ——— EVM Chains ———
@@ -140,6 +197,21 @@ func Load() (*Config, error) {
 		},
 	}
 
+	// Per-chain hot wallet balance monitoring
+	for chainID, chainCfg := range cfg.Chains {
+		for _, addr := range strings.Split(getEnv(fmt.Sprintf("HOT_WALLETS_%d", chainID), ""), ",") {
+			if addr = strings.TrimSpace(addr); addr != "" {
+				chainCfg.HotWallets = append(chainCfg.HotWallets, addr)
+			}
+		}
+		if value := getEnv(fmt.Sprintf("LOW_BALANCE_THRESHOLD_%d", chainID), ""); value != "" {
+			if threshold, ok := new(big.Int).SetString(value, 10); ok && threshold.Sign() > 0 {
+				chainCfg.LowBalanceThreshold = threshold
+			}
+		}
+		cfg.Chains[chainID] = chainCfg
+	}
+
 	return cfg, nil
 }
 
@@ -149,3 +221,12 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if d, err := time.ParseDuration(value); err == nil && d > 0 {
+			return d
+		}
+	}
+	return defaultValue
+}
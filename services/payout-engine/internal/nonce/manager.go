@@ -2,8 +2,12 @@ package nonce
 
 import (
 	"context"
+	"crypto/rand"
 	"crypto/tls"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"math/big"
 	"strings"
 	"sync"
 	"time"
@@ -15,16 +19,36 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
+// chainClient 是 Manager 依赖的链客户端方法子集 (*ethclient.Client 满足)
+type chainClient interface {
+	PendingNonceAt(ctx context.Context, account common.Address) (uint64, error)
+	NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error)
+}
+
 // Manager 管理多链多地址的 Nonce
 type Manager struct {
 	redis       *redis.Client
-	clients     map[uint64]*ethclient.Client
+	clients     map[uint64]chainClient
 	localNonces map[string]uint64 // key: chainID:address
 	mu          sync.RWMutex
 	lockTTL     time.Duration
+	keyPrefix   string // 多租户共用 Redis 时的键前缀，如 "prod:"
+	lockRenewal bool   // 持锁期间由 watchdog 自动续期 (签名耗时可能超过 lockTTL)
+
+	// 链上 nonce 回退检测: 连续 regressionThreshold 次读数低于
+	// 已观察到的最高值时自动重置缓存 (0 = 关闭)
+	regressionThreshold int
+	regressions         map[string]int // key: nonce key → 连续回退次数
+
+	// 缓存 nonce 领先链上已确认 nonce 超过 gapThreshold 时告警 (0 = 关闭)
+	gapThreshold uint64
 }
 
-// NewManager 创建 Nonce 管理器
+// defaultLockTTL 是未配置 NonceLockTTL 时 nonce 锁的过期时间
+const defaultLockTTL = 30 * time.Second
+
+// NewManager 创建 Nonce 管理器。键前缀与锁过期时间取自 cfg.NonceKeyPrefix /
+// cfg.NonceLockTTL
 func NewManager(ctx context.Context, cfg config.RedisConfig) (*Manager, error) {
 	var rdb *redis.Client
 	if strings.HasPrefix(cfg.URL, "redis://") || strings.HasPrefix(cfg.URL, "rediss://") {
@@ -52,14 +76,32 @@ func NewManager(ctx context.Context, cfg config.RedisConfig) (*Manager, error) {
 		return nil, fmt.Errorf("redis connection failed: %w", err)
 	}
 
+	lockTTL := cfg.NonceLockTTL
+	if lockTTL <= 0 {
+		lockTTL = defaultLockTTL
+	}
+
 	return &Manager{
 		redis:       rdb,
-		clients:     make(map[uint64]*ethclient.Client),
+		clients:     make(map[uint64]chainClient),
 		localNonces: make(map[string]uint64),
-		lockTTL:     30 * time.Second,
+		lockTTL:     lockTTL,
+		keyPrefix:   cfg.NonceKeyPrefix,
+		lockRenewal: cfg.NonceLockRenewal,
+		regressions: make(map[string]int),
 	}, nil
 }
 
+// nonceKey 返回地址缓存 nonce 的 Redis 键
+func (m *Manager) nonceKey(chainID uint64, address common.Address) string {
+	return fmt.Sprintf("%snonce:%d:%s", m.keyPrefix, chainID, address.Hex())
+}
+
+// lockKey 返回地址 nonce 锁的 Redis 键
+func (m *Manager) lockKey(chainID uint64, address common.Address) string {
+	return fmt.Sprintf("%slock:nonce:%d:%s", m.keyPrefix, chainID, address.Hex())
+}
+
 // AddChainClient 添加链客户端
 func (m *Manager) AddChainClient(chainID uint64, client *ethclient.Client) {
 	m.mu.Lock()
@@ -67,43 +109,123 @@ func (m *Manager) AddChainClient(chainID uint64, client *ethclient.Client) {
 	m.clients[chainID] = client
 }
 
+// SetRegressionThreshold 开启链上 nonce 回退检测：连续 threshold 次读到
+// 低于此前观察值的 pending nonce 时自动 ResetNonce 并告警 (0 = 关闭)
+func (m *Manager) SetRegressionThreshold(threshold int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.regressionThreshold = threshold
+}
+
+// SetGapThreshold 开启 nonce 缺口告警：缓存的下一个 nonce 领先链上已确认
+// nonce 超过 threshold 时说明有交易卡住，后续交易都在排队 (0 = 关闭)
+func (m *Manager) SetGapThreshold(threshold uint64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.gapThreshold = threshold
+}
+
+// SetLockRenewal 开启或关闭持锁期间的自动续期 (watchdog)
+func (m *Manager) SetLockRenewal(enabled bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lockRenewal = enabled
+}
+
+// SetLockTTL 设置 nonce 锁的过期时间。Redis 过期精度为毫秒，非正值或不足
+// 1ms 时与 NewManager 一样使用默认值 (否则锁永不过期或 watchdog 续期间隔为 0)
+func (m *Manager) SetLockTTL(ttl time.Duration) {
+	if ttl < time.Millisecond {
+		ttl = defaultLockTTL
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lockTTL = ttl
+}
+
 // GetNonce 获取下一个可用的 Nonce（带分布式锁）
 func (m *Manager) GetNonce(ctx context.Context, chainID uint64, address common.Address) (uint64, func(), error) {
-	key := fmt.Sprintf("nonce:%d:%s", chainID, address.Hex())
-	lockKey := fmt.Sprintf("lock:%s", key)
+	key := m.nonceKey(chainID, address)
+	lockKey := m.lockKey(chainID, address)
 
-	// 获取分布式锁
-	acquired, err := m.acquireLock(ctx, lockKey)
-	if err != nil {
-		return 0, nil, fmt.Errorf("failed to acquire lock: %w", err)
-	}
-	if !acquired {
-		return 0, nil, fmt.Errorf("nonce lock busy for %s on chain %d", address.Hex(), chainID)
-	}
+	// 获取分布式锁 (开启续期时持锁期间由 watchdog 自动延长 TTL)
+	m.mu.RLock()
+	renew := m.lockRenewal
+	m.mu.RUnlock()
 
-	releaseFn := func() {
-		m.releaseLock(ctx, lockKey)
+	var releaseFn func()
+	if renew {
+		release, err := m.acquireLockWithRenewal(ctx, lockKey)
+		if errors.Is(err, errLockBusy) {
+			return 0, nil, fmt.Errorf("nonce lock busy for %s on chain %d", address.Hex(), chainID)
+		}
+		if err != nil {
+			return 0, nil, fmt.Errorf("failed to acquire lock: %w", err)
+		}
+		releaseFn = release
+	} else {
+		lockToken, acquired, err := m.acquireLock(ctx, lockKey)
+		if err != nil {
+			return 0, nil, fmt.Errorf("failed to acquire lock: %w", err)
+		}
+		if !acquired {
+			return 0, nil, fmt.Errorf("nonce lock busy for %s on chain %d", address.Hex(), chainID)
+		}
+		releaseFn = func() {
+			m.releaseLock(ctx, lockKey, lockToken)
+		}
 	}
 
-	// 获取 Nonce
-	nonce, err := m.getNonceValue(ctx, chainID, address, key)
+	// 分配 Nonce (取值与预增加原子完成)
+	nonce, err := m.allocate(ctx, chainID, address, key)
 	if err != nil {
 		releaseFn()
 		return 0, nil, err
 	}
 
-	// 预增加 Nonce
-	m.incrementNonce(ctx, key)
-
 	return nonce, releaseFn, nil
 }
 
-// getNonceValue 获取 Nonce 值
-func (m *Manager) getNonceValue(ctx context.Context, chainID uint64, address common.Address, key string) (uint64, error) {
-	// 先检查 Redis 缓存
-	cachedNonce, err := m.redis.Get(ctx, key).Uint64()
+// allocateScript 原子地取出缓存的下一个 Nonce 并加一，未缓存时返回 nil
+var allocateScript = redis.NewScript(`
+local nonce = redis.call('GET', KEYS[1])
+if not nonce then
+	return false
+end
+redis.call('INCR', KEYS[1])
+return nonce
+`)
+
+// AllocateNonce 原子地分配地址的下一个 Nonce：返回本次应使用的值并将缓存加一。
+// 不依赖分布式锁，并发调用也不会拿到相同的 Nonce。未缓存时从链上 pending
+// nonce 初始化 (多个调用者同时初始化时只有一个生效)。与 GetNonce 一样做
+// 链上回退与缺口检测
+func (m *Manager) AllocateNonce(ctx context.Context, chainID uint64, address common.Address) (uint64, error) {
+	return m.allocate(ctx, chainID, address, m.nonceKey(chainID, address))
+}
+
+// allocate 是 GetNonce 与 AllocateNonce 共用的分配路径：先做链上回退与缺口检测，
+// 再原子地取值并加一
+func (m *Manager) allocate(ctx context.Context, chainID uint64, address common.Address, key string) (uint64, error) {
+	// 链上 nonce 回退检测 (可能触发自动重置)
+	m.checkRegression(ctx, chainID, address, key)
+
+	// 缺口检测 (仅告警，不影响分配)
+	m.mu.RLock()
+	checkGap := m.gapThreshold > 0
+	m.mu.RUnlock()
+	if checkGap {
+		if _, err := m.NonceGap(ctx, chainID, address); err != nil {
+			log.Warn().Err(err).Uint64("chain", chainID).Str("address", address.Hex()).Msg("Failed to check nonce gap")
+		}
+	}
+
+	nonce, err := allocateScript.Run(ctx, m.redis, []string{key}).Uint64()
 	if err == nil {
-		return cachedNonce, nil
+		return nonce, nil
+	}
+	if !errors.Is(err, redis.Nil) {
+		return 0, fmt.Errorf("failed to allocate nonce: %w", err)
 	}
 
 	// 从链上获取
@@ -120,52 +242,351 @@ func (m *Manager) getNonceValue(ctx context.Context, chainID uint64, address com
 		return 0, fmt.Errorf("failed to get onchain nonce: %w", err)
 	}
 
-	// 缓存到 Redis（10 分钟过期）
-	m.redis.Set(ctx, key, onchainNonce, 10*time.Minute)
+	// 缓存到 Redis（10 分钟过期），已被其他调用者初始化时保留其值
+	if err := m.redis.SetNX(ctx, key, onchainNonce, 10*time.Minute).Err(); err != nil {
+		return 0, fmt.Errorf("failed to cache onchain nonce: %w", err)
+	}
 
-	return onchainNonce, nil
+	nonce, err = allocateScript.Run(ctx, m.redis, []string{key}).Uint64()
+	if err != nil {
+		return 0, fmt.Errorf("failed to allocate nonce: %w", err)
+	}
+	return nonce, nil
 }
 
-// incrementNonce 增加 Nonce
-func (m *Manager) incrementNonce(ctx context.Context, key string) {
-	m.redis.Incr(ctx, key)
+// checkRegression 读取链上 pending nonce 并与已观察到的最高值比较。
+// 单次过期读数 (部分 RPC 提供商会返回陈旧数据) 只计数不处理，
+// 连续达到阈值才视为真实回退：重置缓存并告警。读数恢复一致时计数清零。
+func (m *Manager) checkRegression(ctx context.Context, chainID uint64, address common.Address, key string) {
+	m.mu.RLock()
+	threshold := m.regressionThreshold
+	client, ok := m.clients[chainID]
+	m.mu.RUnlock()
+	if threshold <= 0 || !ok {
+		return
+	}
+
+	onchainNonce, err := client.PendingNonceAt(ctx, address)
+	if err != nil {
+		log.Warn().Err(err).Uint64("chain", chainID).Str("address", address.Hex()).Msg("Failed to read onchain nonce for regression check")
+		return
+	}
+
+	m.mu.Lock()
+	observed, seen := m.localNonces[key]
+	if !seen || onchainNonce >= observed {
+		m.localNonces[key] = onchainNonce
+		delete(m.regressions, key)
+		m.mu.Unlock()
+		return
+	}
+	m.regressions[key]++
+	count := m.regressions[key]
+	if count < threshold {
+		m.mu.Unlock()
+		log.Warn().
+			Uint64("chain", chainID).
+			Str("address", address.Hex()).
+			Uint64("onchain", onchainNonce).
+			Uint64("observed", observed).
+			Int("count", count).
+			Msg("Onchain nonce below previously observed value, possibly stale read")
+		return
+	}
+	// 确认回退: 以新的链上值为基准重新开始
+	m.localNonces[key] = onchainNonce
+	delete(m.regressions, key)
+	m.mu.Unlock()
+
+	log.Error().
+		Uint64("chain", chainID).
+		Str("address", address.Hex()).
+		Uint64("onchain", onchainNonce).
+		Uint64("observed", observed).
+		Int("reads", count).
+		Msg("ALERT: onchain nonce regression confirmed, resetting cached nonce")
+	if err := m.ResetNonce(ctx, chainID, address); err != nil {
+		log.Error().Err(err).Uint64("chain", chainID).Str("address", address.Hex()).Msg("Failed to reset nonce after regression")
+	}
 }
 
 // ResetNonce 重置 Nonce（交易失败时使用）
 func (m *Manager) ResetNonce(ctx context.Context, chainID uint64, address common.Address) error {
-	key := fmt.Sprintf("nonce:%d:%s", chainID, address.Hex())
+	key := m.nonceKey(chainID, address)
 	return m.redis.Del(ctx, key).Err()
 }
 
-// acquireLock 获取分布式锁
-func (m *Manager) acquireLock(ctx context.Context, key string) (bool, error) {
+// reconcileScript 仅当缓存仍为对账时读到的值 (ARGV[1]) 时将其回退为链上值
+// ARGV[2] (compare-and-set)，期间被 AllocateNonce 分配过则不做修改
+var reconcileScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	redis.call('SET', KEYS[1], ARGV[2], 'PX', ARGV[3])
+	return 1
+end
+return 0
+`)
+
+// ReconcileNonce 将缓存的 Nonce 与链上 pending nonce 对账：交易被内存池丢弃后
+// 缓存会领先于链上，后续交易全部卡住。持有地址锁期间没有正在发送的交易，
+// 此时缓存高于链上 pending nonce (已包含内存池交易) 即说明存在被丢弃的 nonce，
+// 将缓存回退到链上值。缓存不存在或不高于链上值时不做修改。
+// AllocateNonce 不经过地址锁，回退以 compare-and-set 完成：读取链上值期间
+// 缓存已被分配时放弃本次对账，避免回退到正在使用的 nonce 之下
+func (m *Manager) ReconcileNonce(ctx context.Context, chainID uint64, address common.Address) error {
+	key := m.nonceKey(chainID, address)
+	lockKey := m.lockKey(chainID, address)
+
+	m.mu.RLock()
+	client, ok := m.clients[chainID]
+	m.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("no client for chain %d", chainID)
+	}
+
+	// 与 GetNonce 互斥
+	lockToken, acquired, err := m.acquireLock(ctx, lockKey)
+	if err != nil {
+		return fmt.Errorf("failed to acquire lock: %w", err)
+	}
+	if !acquired {
+		return fmt.Errorf("nonce lock busy for %s on chain %d", address.Hex(), chainID)
+	}
+	defer m.releaseLock(ctx, lockKey, lockToken)
+
+	cached, err := m.redis.Get(ctx, key).Uint64()
+	if errors.Is(err, redis.Nil) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read cached nonce: %w", err)
+	}
+
+	onchainNonce, err := client.PendingNonceAt(ctx, address)
+	if err != nil {
+		return fmt.Errorf("failed to get onchain nonce: %w", err)
+	}
+	if cached <= onchainNonce {
+		return nil
+	}
+
+	swapped, err := reconcileScript.Run(ctx, m.redis, []string{key}, cached, onchainNonce, (10 * time.Minute).Milliseconds()).Int()
+	if err != nil {
+		return fmt.Errorf("failed to reset cached nonce: %w", err)
+	}
+	if swapped == 0 {
+		log.Warn().
+			Uint64("chain", chainID).
+			Str("address", address.Hex()).
+			Uint64("cached", cached).
+			Msg("Cached nonce changed during reconcile, allocation in flight, skipping")
+		return nil
+	}
+	log.Warn().
+		Uint64("chain", chainID).
+		Str("address", address.Hex()).
+		Uint64("cached", cached).
+		Uint64("onchain", onchainNonce).
+		Uint64("delta", cached-onchainNonce).
+		Msg("Cached nonce ahead of chain, reconciled down to onchain pending nonce")
+	return nil
+}
+
+// PeekNonce 只读查看地址当前缓存的下一个 Nonce，不加锁、不修改缓存。
+// 未缓存时 cached 为 false (下次 GetNonce 将从链上获取)。
+// 供管理后台展示各签名地址的 nonce 状态；分配 nonce 请使用 GetNonce
+func (m *Manager) PeekNonce(ctx context.Context, chainID uint64, address common.Address) (nonce uint64, cached bool, err error) {
+	key := m.nonceKey(chainID, address)
+	nonce, err = m.redis.Get(ctx, key).Uint64()
+	if errors.Is(err, redis.Nil) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to read cached nonce: %w", err)
+	}
+	return nonce, true, nil
+}
+
+// NonceGap 返回地址缓存的下一个 Nonce 领先链上已确认 nonce (最新区块) 的数量，
+// 即已分配但尚未上链的交易数。未缓存或缓存不领先时为 0。
+// 缺口超过 SetGapThreshold 设置的阈值时告警：通常是某笔交易卡住，
+// 其后所有交易都在排队等待
+func (m *Manager) NonceGap(ctx context.Context, chainID uint64, address common.Address) (uint64, error) {
+	m.mu.RLock()
+	client, ok := m.clients[chainID]
+	threshold := m.gapThreshold
+	m.mu.RUnlock()
+	if !ok {
+		return 0, fmt.Errorf("no client for chain %d", chainID)
+	}
+
+	cached, isCached, err := m.PeekNonce(ctx, chainID, address)
+	if err != nil {
+		return 0, err
+	}
+	if !isCached {
+		return 0, nil
+	}
+
+	confirmed, err := client.NonceAt(ctx, address, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get confirmed nonce: %w", err)
+	}
+	if cached <= confirmed {
+		return 0, nil
+	}
+
+	gap := cached - confirmed
+	if threshold > 0 && gap > threshold {
+		log.Warn().
+			Uint64("chain", chainID).
+			Str("address", address.Hex()).
+			Uint64("cached", cached).
+			Uint64("confirmed", confirmed).
+			Uint64("gap", gap).
+			Uint64("threshold", threshold).
+			Msg("ALERT: nonce gap above threshold, a payout transaction may be stuck")
+	}
+	return gap, nil
+}
+
+// acquireLock 获取分布式锁。锁的值是本次生成的随机 token，成功时返回该
+// token，释放时需传回，避免锁过期后被其他 worker 重新获取时误删对方的锁
+func (m *Manager) acquireLock(ctx context.Context, key string) (token string, acquired bool, err error) {
+	m.mu.RLock()
+	ttl := m.lockTTL
+	m.mu.RUnlock()
+
+	token, err = newLockToken()
+	if err != nil {
+		return "", false, err
+	}
+
 	// 使用 SETNX 实现分布式锁
-	result, err := m.redis.SetNX(ctx, key, "1", m.lockTTL).Result()
+	result, err := m.redis.SetNX(ctx, key, token, ttl).Result()
 	if err != nil {
-		return false, err
+		return "", false, err
 	}
 
 	if !result {
 		// 等待并重试
 		for i := 0; i < 10; i++ {
 			time.Sleep(100 * time.Millisecond)
-			result, err = m.redis.SetNX(ctx, key, "1", m.lockTTL).Result()
+			result, err = m.redis.SetNX(ctx, key, token, ttl).Result()
 			if err != nil {
-				return false, err
+				return "", false, err
 			}
 			if result {
-				return true, nil
+				return token, true, nil
 			}
 		}
-		return false, nil
+		return "", false, nil
 	}
 
-	return true, nil
+	return token, true, nil
 }
 
-// releaseLock 释放分布式锁
-func (m *Manager) releaseLock(ctx context.Context, key string) {
-	if err := m.redis.Del(ctx, key).Err(); err != nil {
+// releaseScript 仅当锁仍由 token 持有时删除 (compare-and-delete)
+var releaseScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// releaseLock 释放 token 持有的分布式锁；锁已过期并被他人获取时不做修改
+func (m *Manager) releaseLock(ctx context.Context, key, token string) {
+	released, err := releaseScript.Run(ctx, m.redis, []string{key}, token).Int()
+	if err != nil {
 		log.Error().Err(err).Str("key", key).Msg("Failed to release lock")
+		return
+	}
+	if released == 0 {
+		log.Warn().Str("key", key).Msg("Lock expired before release, held by another worker or gone")
+	}
+}
+
+// errLockBusy 表示锁在重试期间一直被他人持有
+var errLockBusy = errors.New("lock busy")
+
+// lockRenewalDivisor: watchdog 每 lockTTL/lockRenewalDivisor 续期一次，
+// 单次续期失败后仍有充足时间重试
+const lockRenewalDivisor = 3
+
+// renewScript 仅当锁仍由 token 持有时延长其 TTL
+var renewScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// acquireLockWithRenewal 获取分布式锁并启动 watchdog：持锁期间每 lockTTL 的
+// 1/3 将 TTL 重置为 lockTTL，直到调用 release 或 ctx 结束。用于耗时可能超过
+// lockTTL 的操作 (如硬件钱包签名)，避免锁中途过期被其他 worker 获取。
+// 锁一直被占用时返回 errLockBusy
+func (m *Manager) acquireLockWithRenewal(ctx context.Context, key string) (release func(), err error) {
+	token, acquired, err := m.acquireLock(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if !acquired {
+		return nil, errLockBusy
+	}
+
+	m.mu.RLock()
+	ttl := m.lockTTL
+	m.mu.RUnlock()
+
+	watchCtx, cancel := context.WithCancel(ctx)
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		m.renewLock(watchCtx, key, token, ttl)
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			cancel()
+			<-stopped
+			// ctx 可能已取消，释放锁不应随之失败
+			m.releaseLock(context.WithoutCancel(ctx), key, token)
+		})
+	}, nil
+}
+
+// renewLock 定期延长 token 持有的锁，直到 ctx 结束或锁已不属于 token
+func (m *Manager) renewLock(ctx context.Context, key, token string, ttl time.Duration) {
+	ticker := time.NewTicker(ttl / lockRenewalDivisor)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		renewed, err := renewScript.Run(ctx, m.redis, []string{key}, token, ttl.Milliseconds()).Int()
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			log.Warn().Err(err).Str("key", key).Msg("Failed to renew lock, retrying")
+			continue
+		}
+		if renewed == 0 {
+			log.Error().Str("key", key).Msg("Lock lost before renewal, held by another worker or gone")
+			return
+		}
+	}
+}
+
+// newLockToken 生成锁持有者的随机 token
+func newLockToken() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", fmt.Errorf("failed to generate lock token: %w", err)
 	}
+	return hex.EncodeToString(b[:]), nil
 }
@@ -3,13 +3,13 @@ package nonce
 import (
 	"context"
 	"fmt"
+	"math/big"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/alicebob/miniredis/v2"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/go-redis/redis/v8"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -28,9 +28,10 @@ func newTestManager(t *testing.T) (*Manager, func()) {
 
 	m := &Manager{
 		redis:       client,
-		clients:     make(map[uint64]*ethclient.Client),
+		clients:     make(map[uint64]chainClient),
 		localNonces: make(map[string]uint64),
-		lockTTL:     30 * time.Second,
+		lockTTL:     defaultLockTTL,
+		regressions: make(map[string]int),
 	}
 
 	cleanup := func() {
@@ -67,6 +68,64 @@ func TestNonceManager_ResetNonce(t *testing.T) {
 	assert.ErrorIs(t, err, redis.Nil)
 }
 
+func TestNonceManager_PeekNonce(t *testing.T) {
+	nm, cleanup := newTestManager(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	addr := common.HexToAddress("0x1234567890123456789012345678901234567890")
+	chainID := uint64(1)
+	key := fmt.Sprintf("nonce:%d:%s", chainID, addr.Hex())
+	lockKey := fmt.Sprintf("lock:%s", key)
+
+	// Nothing cached: the next GetNonce would fetch from chain
+	nonce, cached, err := nm.PeekNonce(ctx, chainID, addr)
+	require.NoError(t, err)
+	assert.False(t, cached)
+	assert.Zero(t, nonce)
+
+	nm.redis.Set(ctx, key, 7, 10*time.Minute)
+	// Held by a signer: peeking doesn't wait for or take the lock
+	nm.redis.Set(ctx, lockKey, "1", time.Minute)
+
+	nonce, cached, err = nm.PeekNonce(ctx, chainID, addr)
+	require.NoError(t, err)
+	assert.True(t, cached)
+	assert.Equal(t, uint64(7), nonce)
+
+	// Nothing was modified
+	val, err := nm.redis.Get(ctx, key).Uint64()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(7), val)
+	ttl, err := nm.redis.TTL(ctx, lockKey).Result()
+	require.NoError(t, err)
+	assert.Positive(t, ttl, "the lock is untouched")
+}
+
+func TestNonceManager_KeyPrefix(t *testing.T) {
+	nm, cleanup := newTestManager(t)
+	defer cleanup()
+	nm.keyPrefix = "prod:"
+
+	ctx := context.Background()
+	addr := common.HexToAddress("0x1234567890123456789012345678901234567890")
+	nm.clients[1] = &scriptedClient{nonces: []uint64{4}}
+
+	nonce, release, err := nm.GetNonce(ctx, 1, addr)
+	require.NoError(t, err)
+	lockTTL, err := nm.redis.TTL(ctx, "prod:lock:nonce:1:"+addr.Hex()).Result()
+	require.NoError(t, err)
+	assert.Positive(t, lockTTL, "lock held under the prefix")
+	release()
+	assert.Equal(t, uint64(4), nonce)
+
+	val, err := nm.redis.Get(ctx, "prod:nonce:1:"+addr.Hex()).Uint64()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(5), val)
+	_, err = nm.redis.Get(ctx, "nonce:1:"+addr.Hex()).Result()
+	assert.ErrorIs(t, err, redis.Nil, "unprefixed keys untouched")
+}
+
 func TestNonceManager_IncrementNonce(t *testing.T) {
 	nm, cleanup := newTestManager(t)
 	defer cleanup()
@@ -79,10 +138,12 @@ func TestNonceManager_IncrementNonce(t *testing.T) {
 	// Seed initial value
 	nm.redis.Set(ctx, key, 0, 10*time.Minute)
 
-	// Increment 3 times
-	nm.incrementNonce(ctx, key)
-	nm.incrementNonce(ctx, key)
-	nm.incrementNonce(ctx, key)
+	// Allocate 3 times
+	for want := uint64(0); want < 3; want++ {
+		nonce, err := nm.AllocateNonce(ctx, chainID, addr)
+		require.NoError(t, err)
+		assert.Equal(t, want, nonce)
+	}
 
 	val, err := nm.redis.Get(ctx, key).Uint64()
 	require.NoError(t, err)
@@ -97,19 +158,48 @@ func TestNonceManager_AcquireReleaseLock(t *testing.T) {
 	lockKey := "lock:nonce:1:0x1234"
 
 	// Should acquire successfully
-	acquired, err := nm.acquireLock(ctx, lockKey)
+	token, acquired, err := nm.acquireLock(ctx, lockKey)
 	require.NoError(t, err)
 	assert.True(t, acquired)
+	assert.NotEmpty(t, token)
 
 	// Release
-	nm.releaseLock(ctx, lockKey)
+	nm.releaseLock(ctx, lockKey, token)
 
 	// Should be able to acquire again after release
-	acquired2, err := nm.acquireLock(ctx, lockKey)
+	token2, acquired2, err := nm.acquireLock(ctx, lockKey)
 	require.NoError(t, err)
 	assert.True(t, acquired2)
+	assert.NotEqual(t, token, token2, "each acquisition gets its own token")
+
+	nm.releaseLock(ctx, lockKey, token2)
+}
+
+func TestNonceManager_ReleaseOnlyOwnLock(t *testing.T) {
+	nm, cleanup := newTestManager(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	lockKey := "lock:nonce:1:0x1234"
+
+	// Worker A's lock expired and worker B took it over
+	stale, acquired, err := nm.acquireLock(ctx, lockKey)
+	require.NoError(t, err)
+	require.True(t, acquired)
+	nm.redis.Del(ctx, lockKey)
+	current, acquired, err := nm.acquireLock(ctx, lockKey)
+	require.NoError(t, err)
+	require.True(t, acquired)
+
+	// A's late release leaves B's lock alone
+	nm.releaseLock(ctx, lockKey, stale)
+	val, err := nm.redis.Get(ctx, lockKey).Result()
+	require.NoError(t, err)
+	assert.Equal(t, current, val)
 
-	nm.releaseLock(ctx, lockKey)
+	nm.releaseLock(ctx, lockKey, current)
+	_, err = nm.redis.Get(ctx, lockKey).Result()
+	assert.ErrorIs(t, err, redis.Nil)
 }
 
 func TestNonceManager_MultipleAddresses(t *testing.T) {
@@ -135,7 +225,8 @@ func TestNonceManager_MultipleAddresses(t *testing.T) {
 	assert.Equal(t, uint64(20), val2)
 
 	// Increment addr1, addr2 should be unchanged
-	nm.incrementNonce(ctx, key1)
+	_, err := nm.AllocateNonce(ctx, chainID, addr1)
+	require.NoError(t, err)
 	val1, _ = nm.redis.Get(ctx, key1).Uint64()
 	val2, _ = nm.redis.Get(ctx, key2).Uint64()
 
@@ -169,7 +260,8 @@ func TestNonceManager_ConcurrentIncrement(t *testing.T) {
 	defer cleanup()
 
 	ctx := context.Background()
-	key := "nonce:1:0xConcurrent"
+	addr := common.HexToAddress("0x3333333333333333333333333333333333333333")
+	key := fmt.Sprintf("nonce:1:%s", addr.Hex())
 	nm.redis.Set(ctx, key, 0, 10*time.Minute)
 
 	numGoroutines := 50
@@ -179,7 +271,8 @@ func TestNonceManager_ConcurrentIncrement(t *testing.T) {
 	for i := 0; i < numGoroutines; i++ {
 		go func() {
 			defer wg.Done()
-			nm.incrementNonce(ctx, key)
+			_, err := nm.AllocateNonce(ctx, 1, addr)
+			assert.NoError(t, err)
 		}()
 	}
 
@@ -189,3 +282,374 @@ func TestNonceManager_ConcurrentIncrement(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, uint64(numGoroutines), val)
 }
+
+// scriptedClient returns pending nonces from a fixed sequence, repeating the
+// last, and confirmed as the nonce at the latest block.
+type scriptedClient struct {
+	nonces    []uint64
+	calls     int
+	confirmed uint64
+}
+
+func (c *scriptedClient) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	n := c.nonces[min(c.calls, len(c.nonces)-1)]
+	c.calls++
+	return n, nil
+}
+
+func (c *scriptedClient) NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error) {
+	return c.confirmed, nil
+}
+
+func TestNonceManager_RegressionReset(t *testing.T) {
+	ctx := context.Background()
+	addr := common.HexToAddress("0x1234567890123456789012345678901234567890")
+	key := fmt.Sprintf("nonce:%d:%s", 1, addr.Hex())
+
+	allocate := func(t *testing.T, nm *Manager) uint64 {
+		t.Helper()
+		nonce, release, err := nm.GetNonce(ctx, 1, addr)
+		require.NoError(t, err)
+		release()
+		return nonce
+	}
+
+	t.Run("single stale read is ignored", func(t *testing.T) {
+		nm, cleanup := newTestManager(t)
+		defer cleanup()
+		nm.SetRegressionThreshold(2)
+		// seed (check + fetch), consistent, stale, consistent again
+		nm.clients[1] = &scriptedClient{nonces: []uint64{10, 10, 10, 4, 11}}
+
+		assert.Equal(t, uint64(10), allocate(t, nm), "seeded from chain")
+		assert.Equal(t, uint64(11), allocate(t, nm))
+		assert.Equal(t, uint64(12), allocate(t, nm), "one stale read doesn't reset")
+		assert.Equal(t, uint64(13), allocate(t, nm))
+		assert.Empty(t, nm.regressions, "consistent read clears the count")
+	})
+
+	t.Run("sustained regression resets", func(t *testing.T) {
+		nm, cleanup := newTestManager(t)
+		defer cleanup()
+		nm.SetRegressionThreshold(2)
+		// seed (check + fetch), consistent, then stuck at 4 (check + refetch)
+		nm.clients[1] = &scriptedClient{nonces: []uint64{10, 10, 10, 4, 4, 4}}
+
+		assert.Equal(t, uint64(10), allocate(t, nm))
+		assert.Equal(t, uint64(11), allocate(t, nm))
+		assert.Equal(t, uint64(12), allocate(t, nm), "first regressed read only counts")
+		assert.Equal(t, uint64(4), allocate(t, nm), "second regressed read resets to chain")
+
+		val, err := nm.redis.Get(ctx, key).Uint64()
+		require.NoError(t, err)
+		assert.Equal(t, uint64(5), val)
+	})
+
+	t.Run("sustained regression resets through AllocateNonce", func(t *testing.T) {
+		nm, cleanup := newTestManager(t)
+		defer cleanup()
+		nm.SetRegressionThreshold(2)
+		nm.clients[1] = &scriptedClient{nonces: []uint64{10, 10, 10, 4, 4, 4}}
+
+		allocateNonce := func() uint64 {
+			nonce, err := nm.AllocateNonce(ctx, 1, addr)
+			require.NoError(t, err)
+			return nonce
+		}
+		assert.Equal(t, uint64(10), allocateNonce())
+		assert.Equal(t, uint64(11), allocateNonce())
+		assert.Equal(t, uint64(12), allocateNonce(), "first regressed read only counts")
+		assert.Equal(t, uint64(4), allocateNonce(), "second regressed read resets to chain")
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		nm, cleanup := newTestManager(t)
+		defer cleanup()
+		client := &scriptedClient{nonces: []uint64{10, 4}}
+		nm.clients[1] = client
+
+		assert.Equal(t, uint64(10), allocate(t, nm))
+		assert.Equal(t, uint64(11), allocate(t, nm))
+		assert.Equal(t, 1, client.calls, "cached nonce served without chain reads")
+	})
+}
+
+func TestNonceManager_ReconcileNonce(t *testing.T) {
+	ctx := context.Background()
+	addr := common.HexToAddress("0x1234567890123456789012345678901234567890")
+	key := fmt.Sprintf("nonce:%d:%s", 1, addr.Hex())
+
+	cachedAfter := func(t *testing.T, nm *Manager, cached *uint64, onchain uint64) (uint64, bool) {
+		t.Helper()
+		nm.clients[1] = &scriptedClient{nonces: []uint64{onchain}}
+		if cached != nil {
+			nm.redis.Set(ctx, key, *cached, 10*time.Minute)
+		}
+		require.NoError(t, nm.ReconcileNonce(ctx, 1, addr))
+		nonce, ok, err := nm.PeekNonce(ctx, 1, addr)
+		require.NoError(t, err)
+		return nonce, ok
+	}
+	ptr := func(n uint64) *uint64 { return &n }
+
+	t.Run("dropped transactions reset the cache down", func(t *testing.T) {
+		nm, cleanup := newTestManager(t)
+		defer cleanup()
+		nonce, ok := cachedAfter(t, nm, ptr(15), 12)
+		assert.True(t, ok)
+		assert.Equal(t, uint64(12), nonce)
+
+		_, err := nm.redis.Get(ctx, "lock:"+key).Result()
+		assert.ErrorIs(t, err, redis.Nil, "lock released")
+	})
+
+	t.Run("cache in line or behind is kept", func(t *testing.T) {
+		nm, cleanup := newTestManager(t)
+		defer cleanup()
+		nonce, _ := cachedAfter(t, nm, ptr(12), 12)
+		assert.Equal(t, uint64(12), nonce)
+		nonce, _ = cachedAfter(t, nm, ptr(9), 12)
+		assert.Equal(t, uint64(9), nonce)
+	})
+
+	t.Run("nothing cached", func(t *testing.T) {
+		nm, cleanup := newTestManager(t)
+		defer cleanup()
+		_, ok := cachedAfter(t, nm, nil, 12)
+		assert.False(t, ok, "not seeded by reconciling")
+	})
+
+	t.Run("waits for the lock", func(t *testing.T) {
+		nm, cleanup := newTestManager(t)
+		defer cleanup()
+		nm.clients[1] = &scriptedClient{nonces: []uint64{12}}
+		nm.redis.Set(ctx, key, 15, 10*time.Minute)
+		nm.redis.Set(ctx, "lock:"+key, "1", time.Minute)
+
+		assert.Error(t, nm.ReconcileNonce(ctx, 1, addr), "a signer holds the lock")
+		nonce, _, err := nm.PeekNonce(ctx, 1, addr)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(15), nonce, "untouched while locked")
+	})
+
+	t.Run("unknown chain", func(t *testing.T) {
+		nm, cleanup := newTestManager(t)
+		defer cleanup()
+		assert.Error(t, nm.ReconcileNonce(ctx, 56, addr))
+	})
+
+	t.Run("allocation during the chain read is kept", func(t *testing.T) {
+		nm, cleanup := newTestManager(t)
+		defer cleanup()
+		nm.redis.Set(ctx, key, 15, 10*time.Minute)
+		nm.clients[1] = &allocatingClient{nonce: 12, allocate: func() {
+			_, err := nm.AllocateNonce(ctx, 1, addr)
+			require.NoError(t, err)
+		}}
+
+		require.NoError(t, nm.ReconcileNonce(ctx, 1, addr))
+		nonce, _, err := nm.PeekNonce(ctx, 1, addr)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(16), nonce, "nonce 15 is in use, not rolled back")
+	})
+}
+
+// allocatingClient runs allocate (a lock-free AllocateNonce caller racing the
+// reconcile) before answering the pending nonce read.
+type allocatingClient struct {
+	nonce    uint64
+	allocate func()
+}
+
+func (c *allocatingClient) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	c.allocate()
+	return c.nonce, nil
+}
+
+func (c *allocatingClient) NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error) {
+	return c.nonce, nil
+}
+
+func TestNonceManager_NonceGap(t *testing.T) {
+	ctx := context.Background()
+	addr := common.HexToAddress("0x1234567890123456789012345678901234567890")
+
+	nm, cleanup := newTestManager(t)
+	defer cleanup()
+	nm.SetGapThreshold(2)
+	client := &scriptedClient{nonces: []uint64{10}, confirmed: 10}
+	nm.clients[1] = client
+
+	gap, err := nm.NonceGap(ctx, 1, addr)
+	require.NoError(t, err)
+	assert.Zero(t, gap, "nothing allocated yet")
+
+	// Three payouts allocated, none mined: 10, 11, 12 are pending
+	for i := 0; i < 3; i++ {
+		_, release, err := nm.GetNonce(ctx, 1, addr)
+		require.NoError(t, err)
+		release()
+	}
+	gap, err = nm.NonceGap(ctx, 1, addr)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3), gap)
+
+	client.confirmed = 13
+	gap, err = nm.NonceGap(ctx, 1, addr)
+	require.NoError(t, err)
+	assert.Zero(t, gap, "all mined")
+
+	_, err = nm.NonceGap(ctx, 56, addr)
+	assert.Error(t, err, "unknown chain")
+}
+
+func TestNonceManager_AllocateNonce(t *testing.T) {
+	ctx := context.Background()
+	addr := common.HexToAddress("0x1234567890123456789012345678901234567890")
+
+	t.Run("seeds from chain then increments", func(t *testing.T) {
+		nm, cleanup := newTestManager(t)
+		defer cleanup()
+		client := &scriptedClient{nonces: []uint64{7}}
+		nm.clients[1] = client
+
+		for want := uint64(7); want < 10; want++ {
+			nonce, err := nm.AllocateNonce(ctx, 1, addr)
+			require.NoError(t, err)
+			assert.Equal(t, want, nonce)
+		}
+		assert.Equal(t, 1, client.calls, "chain read only to seed")
+
+		next, cached, err := nm.PeekNonce(ctx, 1, addr)
+		require.NoError(t, err)
+		assert.True(t, cached)
+		assert.Equal(t, uint64(10), next)
+	})
+
+	t.Run("concurrent callers never share a nonce", func(t *testing.T) {
+		nm, cleanup := newTestManager(t)
+		defer cleanup()
+		nm.redis.Set(ctx, fmt.Sprintf("nonce:%d:%s", 1, addr.Hex()), 100, 10*time.Minute)
+
+		const callers = 50
+		var mu sync.Mutex
+		seen := make(map[uint64]bool)
+		var wg sync.WaitGroup
+		for i := 0; i < callers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				nonce, err := nm.AllocateNonce(ctx, 1, addr)
+				assert.NoError(t, err)
+				mu.Lock()
+				defer mu.Unlock()
+				assert.False(t, seen[nonce], "nonce %d allocated twice", nonce)
+				seen[nonce] = true
+			}()
+		}
+		wg.Wait()
+		assert.Len(t, seen, callers)
+		for n := uint64(100); n < 100+callers; n++ {
+			assert.True(t, seen[n])
+		}
+	})
+
+	t.Run("unknown chain", func(t *testing.T) {
+		nm, cleanup := newTestManager(t)
+		defer cleanup()
+		_, err := nm.AllocateNonce(ctx, 56, addr)
+		assert.Error(t, err)
+	})
+}
+
+func TestNonceManager_LockRenewal(t *testing.T) {
+	ctx := context.Background()
+	lockKey := "lock:nonce:1:0x1234"
+
+	// miniredis doesn't expire keys on its own: move the TTL away from
+	// lockTTL by hand and watch for the watchdog to reset it
+	const drifted = time.Hour
+	renewed := func(nm *Manager) bool {
+		ttl, err := nm.redis.PTTL(ctx, lockKey).Result()
+		return err == nil && ttl > 0 && ttl <= nm.lockTTL
+	}
+
+	t.Run("extends while held", func(t *testing.T) {
+		nm, cleanup := newTestManager(t)
+		defer cleanup()
+		nm.SetLockTTL(60 * time.Millisecond)
+
+		release, err := nm.acquireLockWithRenewal(ctx, lockKey)
+		require.NoError(t, err)
+		nm.redis.PExpire(ctx, lockKey, drifted)
+		assert.Eventually(t, func() bool { return renewed(nm) }, time.Second, 5*time.Millisecond)
+
+		release()
+		release()
+		_, err = nm.redis.Get(ctx, lockKey).Result()
+		assert.ErrorIs(t, err, redis.Nil, "released")
+	})
+
+	t.Run("stops when ctx is done", func(t *testing.T) {
+		nm, cleanup := newTestManager(t)
+		defer cleanup()
+		nm.SetLockTTL(60 * time.Millisecond)
+
+		lockCtx, cancel := context.WithCancel(ctx)
+		release, err := nm.acquireLockWithRenewal(lockCtx, lockKey)
+		require.NoError(t, err)
+		cancel()
+		time.Sleep(10 * time.Millisecond)
+		nm.redis.PExpire(ctx, lockKey, drifted)
+		time.Sleep(100 * time.Millisecond)
+		assert.False(t, renewed(nm), "no renewal after cancel")
+
+		release()
+		_, err = nm.redis.Get(ctx, lockKey).Result()
+		assert.ErrorIs(t, err, redis.Nil, "release still works with a cancelled ctx")
+	})
+
+	t.Run("never extends a lock it lost", func(t *testing.T) {
+		nm, cleanup := newTestManager(t)
+		defer cleanup()
+		nm.SetLockTTL(60 * time.Millisecond)
+
+		release, err := nm.acquireLockWithRenewal(ctx, lockKey)
+		require.NoError(t, err)
+		defer release()
+		nm.redis.Set(ctx, lockKey, "other-worker", drifted)
+		time.Sleep(100 * time.Millisecond)
+		assert.False(t, renewed(nm))
+	})
+
+	t.Run("busy", func(t *testing.T) {
+		nm, cleanup := newTestManager(t)
+		defer cleanup()
+		nm.redis.Set(ctx, lockKey, "other-worker", time.Minute)
+
+		_, err := nm.acquireLockWithRenewal(ctx, lockKey)
+		assert.ErrorIs(t, err, errLockBusy)
+	})
+}
+
+func TestNonceManager_SetLockTTL(t *testing.T) {
+	ctx := context.Background()
+	nm, cleanup := newTestManager(t)
+	defer cleanup()
+
+	nm.SetLockTTL(time.Minute)
+	assert.Equal(t, time.Minute, nm.lockTTL)
+
+	for _, ttl := range []time.Duration{0, -time.Second, 2 * time.Nanosecond} {
+		nm.SetLockTTL(ttl)
+		assert.Equal(t, defaultLockTTL, nm.lockTTL, "ttl %v", ttl)
+	}
+
+	// The watchdog ticker can't panic on a defaulted TTL, and the lock expires
+	release, err := nm.acquireLockWithRenewal(ctx, "lock:nonce:1:0x1234")
+	require.NoError(t, err)
+	ttl, err := nm.redis.PTTL(ctx, "lock:nonce:1:0x1234").Result()
+	require.NoError(t, err)
+	assert.Positive(t, ttl)
+	release()
+}
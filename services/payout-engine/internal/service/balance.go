@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	troncore "github.com/fbsobreira/gotron-sdk/pkg/proto/core"
+	"github.com/rs/zerolog/log"
+)
+
+// LowBalanceAlert reports a hot wallet whose native balance fell below the
+// chain's LowBalanceThreshold. Payouts from it halt once it can't pay gas.
+type LowBalanceAlert struct {
+	ChainID     uint64
+	ChainName   string
+	Address     string
+	NativeToken string
+	Balance     *big.Int // wei/SUN
+	Threshold   *big.Int // wei/SUN
+}
+
+// AlertHook receives low balance alerts, e.g. to page ops.
+type AlertHook func(alert LowBalanceAlert)
+
+// LogAlert is the default AlertHook: it only logs the alert.
+func LogAlert(alert LowBalanceAlert) {
+	log.Error().
+		Uint64("chain_id", alert.ChainID).
+		Str("chain", alert.ChainName).
+		Str("address", alert.Address).
+		Str("balance", alert.Balance.String()).
+		Str("threshold", alert.Threshold.String()).
+		Str("token", alert.NativeToken).
+		Msg("ALERT: hot wallet native balance below threshold, top up before payouts fail")
+}
+
+// nativeBalanceClient is the subset of *ethclient.Client used to read
+// native balances.
+type nativeBalanceClient interface {
+	BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error)
+}
+
+// tronBalanceClient is the subset of *tronclient.GrpcClient used to read
+// TRX balances.
+type tronBalanceClient interface {
+	GetAccount(addr string) (*troncore.Account, error)
+}
+
+// MonitorBalances checks every configured hot wallet's native balance now
+// and then every BalanceCheckInterval until ctx is done, calling alert when
+// one drops below its chain's LowBalanceThreshold.
+func (s *PayoutService) MonitorBalances(ctx context.Context, alert AlertHook) {
+	ticker := time.NewTicker(s.cfg.BalanceCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		s.checkBalances(ctx, alert)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkBalances runs one pass over the hot wallets. A wallet alerts once
+// when it goes low and again only after it has been topped up and drained.
+func (s *PayoutService) checkBalances(ctx context.Context, alert AlertHook) {
+	if s.lowBalances == nil {
+		s.lowBalances = make(map[string]bool)
+	}
+
+	for chainID, chainCfg := range s.cfg.Chains {
+		if chainCfg.LowBalanceThreshold == nil {
+			continue
+		}
+		for _, addr := range chainCfg.HotWallets {
+			balance, err := s.nativeBalance(ctx, chainID, addr)
+			if err != nil {
+				log.Warn().Err(err).Uint64("chain_id", chainID).Str("address", addr).Msg("Failed to check hot wallet balance")
+				continue
+			}
+
+			key := fmt.Sprintf("%d:%s", chainID, addr)
+			if balance.Cmp(chainCfg.LowBalanceThreshold) >= 0 {
+				if s.lowBalances[key] {
+					log.Info().Uint64("chain_id", chainID).Str("address", addr).Str("balance", balance.String()).Msg("Hot wallet balance back above threshold")
+					delete(s.lowBalances, key)
+				}
+				continue
+			}
+			if s.lowBalances[key] {
+				continue
+			}
+			s.lowBalances[key] = true
+			alert(LowBalanceAlert{
+				ChainID:     chainID,
+				ChainName:   chainCfg.Name,
+				Address:     addr,
+				NativeToken: chainCfg.NativeToken,
+				Balance:     balance,
+				Threshold:   chainCfg.LowBalanceThreshold,
+			})
+		}
+	}
+}
+
+// nativeBalance returns addr's native balance on chainID in wei/SUN.
+func (s *PayoutService) nativeBalance(ctx context.Context, chainID uint64, addr string) (*big.Int, error) {
+	if client, ok := s.tronBalances[chainID]; ok {
+		account, err := client.GetAccount(addr)
+		if err != nil {
+			return nil, err
+		}
+		return big.NewInt(account.GetBalance()), nil
+	}
+	if client, ok := s.balances[chainID]; ok {
+		if !common.IsHexAddress(addr) {
+			return nil, fmt.Errorf("invalid address: %s", addr)
+		}
+		return client.BalanceAt(ctx, common.HexToAddress(addr), nil)
+	}
+	return nil, fmt.Errorf("unsupported chain: %d", chainID)
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	troncore "github.com/fbsobreira/gotron-sdk/pkg/proto/core"
+	"github.com/protocol-bank/payout-engine/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockBalanceClient mocks native balance lookups
+type MockBalanceClient struct {
+	mock.Mock
+}
+
+func (m *MockBalanceClient) BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error) {
+	args := m.Called(ctx, account, blockNumber)
+	balance, _ := args.Get(0).(*big.Int)
+	return balance, args.Error(1)
+}
+
+func (m *MockTronClient) GetAccount(addr string) (*troncore.Account, error) {
+	args := m.Called(addr)
+	account, _ := args.Get(0).(*troncore.Account)
+	return account, args.Error(1)
+}
+
+// ============================================
+// Balance Monitor Tests
+// ============================================
+
+func TestCheckBalances(t *testing.T) {
+	hot := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	funded := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	tronHot := "TJRabPrwbZy45sbavfcjinPJC18kjpRTv8"
+
+	evm := new(MockBalanceClient)
+	evm.On("BalanceAt", mock.Anything, funded, (*big.Int)(nil)).Return(big.NewInt(5e18), nil)
+	tron := new(MockTronClient)
+	tron.On("GetAccount", tronHot).Return(&troncore.Account{Balance: 10_000_000}, nil)
+
+	s := &PayoutService{
+		cfg: &config.Config{Chains: map[uint64]config.ChainConfig{
+			1:         {Name: "Ethereum", NativeToken: "ETH", HotWallets: []string{hot.Hex(), funded.Hex()}, LowBalanceThreshold: big.NewInt(1e17)},
+			728126428: {Name: "TRON Mainnet", NativeToken: "TRX", HotWallets: []string{tronHot}, LowBalanceThreshold: big.NewInt(100_000_000)},
+			137:       {Name: "Polygon", HotWallets: []string{hot.Hex()}}, // no threshold, not monitored
+		}},
+		balances:     map[uint64]nativeBalanceClient{1: evm, 137: evm},
+		tronBalances: map[uint64]tronBalanceClient{728126428: tron},
+	}
+
+	var alerts []LowBalanceAlert
+	alert := func(a LowBalanceAlert) { alerts = append(alerts, a) }
+
+	// Running low
+	low := evm.On("BalanceAt", mock.Anything, hot, (*big.Int)(nil)).Return(big.NewInt(5e16), nil)
+	s.checkBalances(context.Background(), alert)
+	require.Len(t, alerts, 2)
+	byChain := map[uint64]LowBalanceAlert{alerts[0].ChainID: alerts[0], alerts[1].ChainID: alerts[1]}
+	assert.Equal(t, LowBalanceAlert{
+		ChainID:     1,
+		ChainName:   "Ethereum",
+		Address:     hot.Hex(),
+		NativeToken: "ETH",
+		Balance:     big.NewInt(5e16),
+		Threshold:   big.NewInt(1e17),
+	}, byChain[1])
+	assert.Equal(t, tronHot, byChain[728126428].Address)
+	assert.Equal(t, big.NewInt(10_000_000), byChain[728126428].Balance)
+	evm.AssertNumberOfCalls(t, "BalanceAt", 2) // chain 137 has no threshold
+
+	// Still low: no repeat alert
+	s.checkBalances(context.Background(), alert)
+	assert.Len(t, alerts, 2)
+
+	// Topped up, then drained again
+	low.Unset()
+	topped := evm.On("BalanceAt", mock.Anything, hot, (*big.Int)(nil)).Return(big.NewInt(1e18), nil)
+	s.checkBalances(context.Background(), alert)
+	assert.Len(t, alerts, 2)
+	topped.Unset()
+	evm.On("BalanceAt", mock.Anything, hot, (*big.Int)(nil)).Return(big.NewInt(1), nil)
+	s.checkBalances(context.Background(), alert)
+	require.Len(t, alerts, 3)
+	assert.Equal(t, big.NewInt(1), alerts[2].Balance)
+
+	// A failed lookup doesn't alert
+	tron.ExpectedCalls = nil
+	tron.On("GetAccount", tronHot).Return(nil, errors.New("node unavailable"))
+	delete(s.lowBalances, "728126428:"+tronHot)
+	s.checkBalances(context.Background(), alert)
+	assert.Len(t, alerts, 3)
+}
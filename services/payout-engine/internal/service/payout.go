@@ -4,9 +4,11 @@ import (
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"math/big"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/ethereum/go-ethereum"
@@ -34,8 +36,14 @@ type PayoutService struct {
 	nonceManager *nonce.Manager
 	queue        *queue.Consumer
 	clients      map[uint64]*ethclient.Client
-	tronClients  map[uint64]*tronclient.GrpcClient
+	tronClients  map[uint64]tronPayoutClient
 	erc20ABI     abi.ABI
+	receipts     map[uint64]receiptClient // EVM receipt tracking, see WaitForReceipt
+
+	// Hot wallet balance monitoring, see MonitorBalances
+	balances     map[uint64]nativeBalanceClient
+	tronBalances map[uint64]tronBalanceClient
+	lowBalances  map[string]bool // chainID:address currently alerted as low
 }
 
 // NewPayoutService 创建支付服务
@@ -53,7 +61,10 @@ func NewPayoutService(
 
 	// 初始化链客户端
 	clients := make(map[uint64]*ethclient.Client)
-	tronClients := make(map[uint64]*tronclient.GrpcClient)
+	tronClients := make(map[uint64]tronPayoutClient)
+	receipts := make(map[uint64]receiptClient)
+	balances := make(map[uint64]nativeBalanceClient)
+	tronBalances := make(map[uint64]tronBalanceClient)
 
 	for chainID, chainCfg := range cfg.Chains {
 		if chainCfg.Type == "tron" {
@@ -63,6 +74,7 @@ func NewPayoutService(
 				continue
 			}
 			tronClients[chainID] = client
+			tronBalances[chainID] = client
 			log.Info().Uint64("chain_id", chainID).Str("name", chainCfg.Name).Msg("Connected to Tron chain")
 		} else {
 			client, err := ethclient.Dial(chainCfg.RPCURL)
@@ -71,6 +83,8 @@ func NewPayoutService(
 				continue
 			}
 			clients[chainID] = client
+			receipts[chainID] = client
+			balances[chainID] = client
 			nonceManager.AddChainClient(chainID, client)
 			log.Info().Uint64("chain_id", chainID).Str("name", chainCfg.Name).Msg("Connected to chain")
 		}
@@ -83,6 +97,9 @@ func NewPayoutService(
 		clients:      clients,
 		tronClients:  tronClients,
 		erc20ABI:     parsedABI,
+		receipts:     receipts,
+		balances:     balances,
+		tronBalances: tronBalances,
 	}, nil
 }
 
@@ -163,7 +180,9 @@ func (s *PayoutService) ProcessJob(ctx context.Context, job *queue.Job) (*queue.
 			Error:   fmt.Errorf("failed to get nonce: %w", err),
 		}, nil
 	}
-	defer releaseFn()
+	// 交易广播后即释放 (见下方)，其余返回路径由 defer 释放
+	release := sync.OnceFunc(releaseFn)
+	defer release()
 
 	// 构建交易
 	var tx *types.Transaction
@@ -210,12 +229,32 @@ func (s *PayoutService) ProcessJob(ctx context.Context, job *queue.Job) (*queue.
 		}, nil
 	}
 
+	// nonce 已被广播的交易占用，释放锁后再等待确认，同一签名地址的其他任务无需排队
+	release()
+
 	txHash := signedTx.Hash().Hex()
 	log.Info().
 		Str("job_id", job.ID).
 		Str("tx_hash", txHash).
 		Msg("Transaction sent successfully")
 
+	// 等待上链确认 (不持有 nonce 锁)。只有回滚的交易判为失败
+	// 并重试；超时的交易可能稍后上链，重试会重复支付，交由 event-indexer 对账
+	if s.cfg.PayoutConfirmations > 0 {
+		_, err := s.WaitForReceipt(ctx, job.ChainID, signedTx.Hash(), s.cfg.PayoutConfirmations)
+		if errors.Is(err, ErrTxReverted) {
+			return &queue.JobResult{
+				JobID:   job.ID,
+				Success: false,
+				TxHash:  txHash,
+				Error:   err,
+			}, nil
+		}
+		if err != nil {
+			log.Warn().Err(err).Str("job_id", job.ID).Str("tx_hash", txHash).Msg("Payout sent but not confirmed")
+		}
+	}
+
 	return &queue.JobResult{
 		JobID:   job.ID,
 		Success: true,
@@ -425,8 +464,9 @@ func isTronAddress(address string) bool {
 }
 
 // processTronJob handles TRX native and TRC20 token transfers on the TRON network.
-// Flow: validate → build tx → sign → broadcast → return tx hash.
-func (s *PayoutService) processTronJob(ctx context.Context, client *tronclient.GrpcClient, job *queue.Job) (*queue.JobResult, error) {
+// Flow: validate → build tx → sign → broadcast → return tx hash. TRC20
+// transfers that run out of energy are retried once with a higher fee limit.
+func (s *PayoutService) processTronJob(ctx context.Context, client tronPayoutClient, job *queue.Job) (*queue.JobResult, error) {
 	log.Info().
 		Str("job_id", job.ID).
 		Str("to", job.ToAddress).
@@ -466,6 +506,54 @@ func (s *PayoutService) processTronJob(ctx context.Context, client *tronclient.G
 		}, nil
 	}
 
+	// Native TRX transfers don't consume energy: sent once
+	if job.TokenAddress == "" {
+		txHash, err := s.sendTronTransaction(client, job, amount, privateKeyHex, 0)
+		if err != nil {
+			return &queue.JobResult{JobID: job.ID, Success: false, Error: err}, nil
+		}
+		return &queue.JobResult{JobID: job.ID, Success: true, TxHash: txHash}, nil
+	}
+
+	// TRC20: an out-of-energy revert is retried once with a higher fee limit
+	feeLimits := trc20FeeLimits(s.cfg.TRC20FeeLimit, s.cfg.TRC20MaxFeeLimit)
+	var txHash string
+	for _, feeLimit := range feeLimits {
+		var err error
+		txHash, err = s.sendTronTransaction(client, job, amount, privateKeyHex, feeLimit)
+		if err != nil {
+			return &queue.JobResult{JobID: job.ID, Success: false, Error: err}, nil
+		}
+		if len(feeLimits) == 1 {
+			// No higher fee limit to retry with: don't wait for the outcome
+			return &queue.JobResult{JobID: job.ID, Success: true, TxHash: txHash}, nil
+		}
+
+		err = s.waitForTronResult(ctx, client, txHash)
+		if !errors.Is(err, ErrTronOutOfEnergy) {
+			if err != nil {
+				return &queue.JobResult{JobID: job.ID, Success: false, TxHash: txHash, Error: err}, nil
+			}
+			return &queue.JobResult{JobID: job.ID, Success: true, TxHash: txHash}, nil
+		}
+		log.Warn().
+			Str("job_id", job.ID).
+			Str("tx_hash", txHash).
+			Int64("fee_limit", feeLimit).
+			Msg("TRC20 transfer ran out of energy")
+	}
+	return &queue.JobResult{
+		JobID:   job.ID,
+		Success: false,
+		TxHash:  txHash,
+		Error:   fmt.Errorf("%w: %s with fee limit %d SUN", ErrTronOutOfEnergy, txHash, feeLimits[len(feeLimits)-1]),
+	}, nil
+}
+
+// sendTronTransaction builds, signs and broadcasts a TRON transfer of
+// amount and returns its transaction hash. feeLimit (in SUN) applies to
+// TRC20 transfers only.
+func (s *PayoutService) sendTronTransaction(client tronPayoutClient, job *queue.Job, amount *big.Int, privateKeyHex string, feeLimit int64) (string, error) {
 	// Build transaction: native TRX or TRC20
 	var txExt *tronapi.TransactionExtention
 	var err error
@@ -475,63 +563,35 @@ func (s *PayoutService) processTronJob(ctx context.Context, client *tronclient.G
 		txExt, err = client.Transfer(job.FromAddress, job.ToAddress, amount.Int64())
 	} else {
 		// TRC20 token transfer (e.g. USDT, USDC)
-		feeLimit := s.cfg.TRC20FeeLimit
-		if feeLimit <= 0 {
-			feeLimit = 100_000_000 // 100 TRX default
-		}
 		txExt, err = client.TRC20Send(job.FromAddress, job.ToAddress, job.TokenAddress, amount, feeLimit)
 	}
 	if err != nil {
-		return &queue.JobResult{
-			JobID:   job.ID,
-			Success: false,
-			Error:   fmt.Errorf("failed to build TRON transaction: %w", err),
-		}, nil
+		return "", fmt.Errorf("failed to build TRON transaction: %w", err)
 	}
 
 	// Validate the node returned a valid transaction
 	if txExt == nil || txExt.GetTransaction() == nil {
-		return &queue.JobResult{
-			JobID:   job.ID,
-			Success: false,
-			Error:   fmt.Errorf("TRON node returned nil transaction"),
-		}, nil
+		return "", fmt.Errorf("TRON node returned nil transaction")
 	}
 	if txExt.GetResult() != nil && txExt.GetResult().GetCode() != tronapi.Return_SUCCESS {
-		return &queue.JobResult{
-			JobID:   job.ID,
-			Success: false,
-			Error:   fmt.Errorf("TRON node rejected transaction: %s", string(txExt.GetResult().GetMessage())),
-		}, nil
+		return "", fmt.Errorf("TRON node rejected transaction: %s", string(txExt.GetResult().GetMessage()))
 	}
 
 	// Sign the transaction
 	signedTx, err := s.signTronTransaction(txExt.GetTransaction(), txExt.GetTxid(), privateKeyHex)
 	if err != nil {
-		return &queue.JobResult{
-			JobID:   job.ID,
-			Success: false,
-			Error:   fmt.Errorf("failed to sign TRON transaction: %w", err),
-		}, nil
+		return "", fmt.Errorf("failed to sign TRON transaction: %w", err)
 	}
 
 	// Broadcast to the TRON network
 	broadcastResult, err := client.Broadcast(signedTx)
 	if err != nil {
-		return &queue.JobResult{
-			JobID:   job.ID,
-			Success: false,
-			Error:   fmt.Errorf("failed to broadcast TRON transaction: %w", err),
-		}, nil
+		return "", fmt.Errorf("failed to broadcast TRON transaction: %w", err)
 	}
 
 	// Check broadcast result
 	if !broadcastResult.GetResult() {
-		return &queue.JobResult{
-			JobID:   job.ID,
-			Success: false,
-			Error:   fmt.Errorf("TRON broadcast rejected (code=%v): %s", broadcastResult.GetCode(), string(broadcastResult.GetMessage())),
-		}, nil
+		return "", fmt.Errorf("TRON broadcast rejected (code=%v): %s", broadcastResult.GetCode(), string(broadcastResult.GetMessage()))
 	}
 
 	// Extract transaction hash
@@ -541,13 +601,10 @@ func (s *PayoutService) processTronJob(ctx context.Context, client *tronclient.G
 		Str("tx_hash", txHash).
 		Str("to", job.ToAddress).
 		Str("token", job.TokenSymbol).
+		Int64("fee_limit", feeLimit).
 		Msg("TRON transaction broadcast successfully")
 
-	return &queue.JobResult{
-		JobID:   job.ID,
-		Success: true,
-		TxHash:  txHash,
-	}, nil
+	return txHash, nil
 }
 
 // signTronTransaction signs a TRON transaction using ECDSA (secp256k1).
@@ -590,7 +647,7 @@ func (s *PayoutService) signTronTransaction(tx *troncore.Transaction, txID []byt
 
 // waitForTronConfirmation polls the TRON node for transaction confirmation.
 // Returns nil if confirmed, error on timeout or failure.
-func (s *PayoutService) waitForTronConfirmation(ctx context.Context, client *tronclient.GrpcClient, txHash string, timeout time.Duration) error {
+func (s *PayoutService) waitForTronConfirmation(ctx context.Context, client tronPayoutClient, txHash string, timeout time.Duration) error {
 	deadline := time.After(timeout)
 	ticker := time.NewTicker(3 * time.Second)
 	defer ticker.Stop()
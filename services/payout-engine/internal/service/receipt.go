@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	// ErrReceiptTimeout is returned when a transaction isn't mined and
+	// confirmed within the receipt timeout. It may still confirm later.
+	ErrReceiptTimeout = errors.New("timed out waiting for transaction receipt")
+	// ErrTxReverted is returned for a mined transaction whose execution failed.
+	ErrTxReverted = errors.New("transaction reverted")
+)
+
+// receiptClient is the subset of *ethclient.Client used to track receipts.
+type receiptClient interface {
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+	BlockNumber(ctx context.Context) (uint64, error)
+}
+
+// WaitForReceipt polls until txHash is mined with the given number of
+// confirmations and returns its receipt. A reverted transaction returns the
+// receipt with ErrTxReverted as soon as it's mined; running out of time
+// returns ErrReceiptTimeout. A reorg that drops the transaction sends it back
+// to waiting for a receipt.
+func (s *PayoutService) WaitForReceipt(ctx context.Context, chainID uint64, txHash common.Hash, confirmations uint64) (*types.Receipt, error) {
+	client, ok := s.receipts[chainID]
+	if !ok {
+		return nil, fmt.Errorf("unsupported chain: %d", chainID)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.cfg.ReceiptTimeout)
+	defer cancel()
+	ticker := time.NewTicker(s.cfg.ReceiptPollInterval)
+	defer ticker.Stop()
+
+	for {
+		receipt, err := client.TransactionReceipt(ctx, txHash)
+		switch {
+		case errors.Is(err, ethereum.NotFound):
+			log.Debug().Str("tx_hash", txHash.Hex()).Msg("Waiting for transaction to be mined...")
+		case err != nil:
+			log.Warn().Err(err).Str("tx_hash", txHash.Hex()).Msg("Failed to fetch transaction receipt")
+		case receipt.Status != types.ReceiptStatusSuccessful:
+			return receipt, fmt.Errorf("%w: %s in block %d", ErrTxReverted, txHash.Hex(), receipt.BlockNumber)
+		default:
+			head, err := client.BlockNumber(ctx)
+			if err != nil {
+				log.Warn().Err(err).Str("tx_hash", txHash.Hex()).Msg("Failed to fetch block number")
+				break
+			}
+			// The receipt's own block counts as the first confirmation
+			mined := receipt.BlockNumber.Uint64()
+			if head >= mined && head-mined+1 >= confirmations {
+				log.Info().
+					Str("tx_hash", txHash.Hex()).
+					Uint64("block", mined).
+					Uint64("confirmations", head-mined+1).
+					Msg("Transaction confirmed")
+				return receipt, nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return nil, fmt.Errorf("%w: %s", ErrReceiptTimeout, txHash.Hex())
+			}
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
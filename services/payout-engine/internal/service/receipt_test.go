@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/protocol-bank/payout-engine/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockReceiptClient mocks receipt and head lookups
+type MockReceiptClient struct {
+	mock.Mock
+}
+
+func (m *MockReceiptClient) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	args := m.Called(ctx, txHash)
+	receipt, _ := args.Get(0).(*types.Receipt)
+	return receipt, args.Error(1)
+}
+
+func (m *MockReceiptClient) BlockNumber(ctx context.Context) (uint64, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(uint64), args.Error(1)
+}
+
+func newReceiptTestService(client receiptClient, timeout time.Duration) *PayoutService {
+	return &PayoutService{
+		cfg: &config.Config{
+			ReceiptTimeout:      timeout,
+			ReceiptPollInterval: time.Millisecond,
+		},
+		receipts: map[uint64]receiptClient{1: client},
+	}
+}
+
+// ============================================
+// Receipt Polling Tests
+// ============================================
+
+func TestWaitForReceipt(t *testing.T) {
+	txHash := common.HexToHash("0xabc")
+	mined := &types.Receipt{Status: types.ReceiptStatusSuccessful, BlockNumber: big.NewInt(100)}
+
+	t.Run("pending then mined and confirmed", func(t *testing.T) {
+		client := new(MockReceiptClient)
+		client.On("TransactionReceipt", mock.Anything, txHash).Return(nil, ethereum.NotFound).Twice()
+		client.On("TransactionReceipt", mock.Anything, txHash).Return(mined, nil)
+		client.On("BlockNumber", mock.Anything).Return(uint64(100), nil).Once()
+		client.On("BlockNumber", mock.Anything).Return(uint64(102), nil)
+
+		s := newReceiptTestService(client, time.Second)
+		receipt, err := s.WaitForReceipt(context.Background(), 1, txHash, 3)
+		require.NoError(t, err)
+		assert.Equal(t, mined, receipt)
+		client.AssertNumberOfCalls(t, "TransactionReceipt", 4)
+		client.AssertNumberOfCalls(t, "BlockNumber", 2)
+	})
+
+	t.Run("reverted", func(t *testing.T) {
+		client := new(MockReceiptClient)
+		reverted := &types.Receipt{Status: types.ReceiptStatusFailed, BlockNumber: big.NewInt(100)}
+		client.On("TransactionReceipt", mock.Anything, txHash).Return(reverted, nil)
+
+		s := newReceiptTestService(client, time.Second)
+		receipt, err := s.WaitForReceipt(context.Background(), 1, txHash, 3)
+		assert.ErrorIs(t, err, ErrTxReverted)
+		assert.Equal(t, reverted, receipt)
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		client := new(MockReceiptClient)
+		client.On("TransactionReceipt", mock.Anything, txHash).Return(nil, ethereum.NotFound)
+
+		s := newReceiptTestService(client, 20*time.Millisecond)
+		_, err := s.WaitForReceipt(context.Background(), 1, txHash, 1)
+		assert.ErrorIs(t, err, ErrReceiptTimeout)
+	})
+
+	t.Run("unsupported chain", func(t *testing.T) {
+		s := newReceiptTestService(new(MockReceiptClient), time.Second)
+		_, err := s.WaitForReceipt(context.Background(), 56, txHash, 1)
+		assert.Error(t, err)
+	})
+}
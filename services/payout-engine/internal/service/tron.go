@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	tronapi "github.com/fbsobreira/gotron-sdk/pkg/proto/api"
+	troncore "github.com/fbsobreira/gotron-sdk/pkg/proto/core"
+	"github.com/rs/zerolog/log"
+)
+
+// ErrTronOutOfEnergy is returned for a TRC20 transfer that reverted because
+// its fee limit couldn't pay for the energy it needed.
+var ErrTronOutOfEnergy = errors.New("TRON transaction ran out of energy")
+
+// tronPayoutClient is the subset of *tronclient.GrpcClient used to send
+// TRON payouts.
+type tronPayoutClient interface {
+	Transfer(from, toAddress string, amount int64) (*tronapi.TransactionExtention, error)
+	TRC20Send(from, to, contract string, amount *big.Int, feeLimit int64) (*tronapi.TransactionExtention, error)
+	Broadcast(tx *troncore.Transaction) (*tronapi.Return, error)
+	GetTransactionInfoByID(id string) (*troncore.TransactionInfo, error)
+}
+
+// trc20FeeLimits returns the fee limits (in SUN) a TRC20 transfer is
+// attempted with: the base limit, then, if the ceiling allows, one retry at
+// twice the base limit capped at the ceiling.
+func trc20FeeLimits(base, ceiling int64) []int64 {
+	if base <= 0 {
+		base = 100_000_000 // 100 TRX default
+	}
+	if ceiling <= base {
+		return []int64{base}
+	}
+	return []int64{base, min(2*base, ceiling)}
+}
+
+// waitForTronResult polls until txHash is included in a block and returns
+// ErrTronOutOfEnergy or ErrTxReverted if its contract execution failed.
+// Running out of time returns nil: the transaction may still confirm, and
+// the event indexer will report it.
+func (s *PayoutService) waitForTronResult(ctx context.Context, client tronPayoutClient, txHash string) error {
+	ctx, cancel := context.WithTimeout(ctx, s.cfg.ReceiptTimeout)
+	defer cancel()
+	ticker := time.NewTicker(s.cfg.ReceiptPollInterval)
+	defer ticker.Stop()
+
+	for {
+		info, err := client.GetTransactionInfoByID(txHash)
+		switch {
+		case err != nil:
+			log.Debug().Err(err).Str("tx_hash", txHash).Msg("Waiting for TRON transaction result...")
+		case info.GetBlockNumber() > 0:
+			switch result := info.GetReceipt().GetResult(); result {
+			case troncore.Transaction_Result_DEFAULT, troncore.Transaction_Result_SUCCESS:
+				return nil
+			case troncore.Transaction_Result_OUT_OF_ENERGY:
+				return fmt.Errorf("%w: %s in block %d", ErrTronOutOfEnergy, txHash, info.GetBlockNumber())
+			default:
+				return fmt.Errorf("%w: %s in block %d (%s)", ErrTxReverted, txHash, info.GetBlockNumber(), result)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				log.Warn().Str("tx_hash", txHash).Msg("TRON result polling timed out")
+				return nil
+			}
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"encoding/hex"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	tronapi "github.com/fbsobreira/gotron-sdk/pkg/proto/api"
+	troncore "github.com/fbsobreira/gotron-sdk/pkg/proto/core"
+	"github.com/protocol-bank/payout-engine/internal/config"
+	"github.com/protocol-bank/payout-engine/internal/queue"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockTronClient mocks the TRON node calls of a payout
+type MockTronClient struct {
+	mock.Mock
+}
+
+func (m *MockTronClient) Transfer(from, toAddress string, amount int64) (*tronapi.TransactionExtention, error) {
+	args := m.Called(from, toAddress, amount)
+	txExt, _ := args.Get(0).(*tronapi.TransactionExtention)
+	return txExt, args.Error(1)
+}
+
+func (m *MockTronClient) TRC20Send(from, to, contract string, amount *big.Int, feeLimit int64) (*tronapi.TransactionExtention, error) {
+	args := m.Called(from, to, contract, amount, feeLimit)
+	txExt, _ := args.Get(0).(*tronapi.TransactionExtention)
+	return txExt, args.Error(1)
+}
+
+func (m *MockTronClient) Broadcast(tx *troncore.Transaction) (*tronapi.Return, error) {
+	args := m.Called(tx)
+	ret, _ := args.Get(0).(*tronapi.Return)
+	return ret, args.Error(1)
+}
+
+func (m *MockTronClient) GetTransactionInfoByID(id string) (*troncore.TransactionInfo, error) {
+	args := m.Called(id)
+	info, _ := args.Get(0).(*troncore.TransactionInfo)
+	return info, args.Error(1)
+}
+
+// testTronTx builds an unsigned transaction whose txid is filled with b.
+func testTronTx(b byte) *tronapi.TransactionExtention {
+	txID := make([]byte, 32)
+	for i := range txID {
+		txID[i] = b
+	}
+	return &tronapi.TransactionExtention{
+		Transaction: &troncore.Transaction{RawData: &troncore.TransactionRaw{}},
+		Txid:        txID,
+		Result:      &tronapi.Return{Result: true, Code: tronapi.Return_SUCCESS},
+	}
+}
+
+func newTronTestService(t *testing.T, feeLimit, maxFeeLimit int64) *PayoutService {
+	t.Helper()
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	return &PayoutService{
+		cfg: &config.Config{
+			TronPrivateKey:      hex.EncodeToString(crypto.FromECDSA(key)),
+			TRC20FeeLimit:       feeLimit,
+			TRC20MaxFeeLimit:    maxFeeLimit,
+			ReceiptTimeout:      time.Second,
+			ReceiptPollInterval: time.Millisecond,
+		},
+	}
+}
+
+// ============================================
+// TRC20 Fee Limit Retry Tests
+// ============================================
+
+func TestTRC20FeeLimits(t *testing.T) {
+	assert.Equal(t, []int64{100_000_000}, trc20FeeLimits(100_000_000, 0), "no ceiling, no retry")
+	assert.Equal(t, []int64{100_000_000}, trc20FeeLimits(100_000_000, 100_000_000))
+	assert.Equal(t, []int64{100_000_000, 200_000_000}, trc20FeeLimits(100_000_000, 500_000_000))
+	assert.Equal(t, []int64{100_000_000, 150_000_000}, trc20FeeLimits(100_000_000, 150_000_000), "capped at the ceiling")
+	assert.Equal(t, []int64{100_000_000}, trc20FeeLimits(0, 0), "default base")
+}
+
+func TestProcessTronJob_OutOfEnergyRetry(t *testing.T) {
+	job := &queue.Job{
+		ID:           "job-1",
+		ChainID:      728126428,
+		FromAddress:  "TLa2f6VPqDgRE67v1736s7bJ8Ray5wYjU7",
+		ToAddress:    "TSfcPbdVEBp7qr4XWg7yqkRXpNp4g1WXYZ",
+		Amount:       "1000000",
+		TokenAddress: "TR7NHqjeKQxGTCi8q8ZY4pL8otSzgjLj6t",
+		TokenSymbol:  "USDT",
+	}
+	first, retry := testTronTx(0x01), testTronTx(0x02)
+	firstHash, retryHash := hex.EncodeToString(first.Txid), hex.EncodeToString(retry.Txid)
+	outOfEnergy := &troncore.TransactionInfo{BlockNumber: 100, Receipt: &troncore.ResourceReceipt{Result: troncore.Transaction_Result_OUT_OF_ENERGY}}
+	succeeded := &troncore.TransactionInfo{BlockNumber: 101, Receipt: &troncore.ResourceReceipt{Result: troncore.Transaction_Result_SUCCESS}}
+	broadcastOK := &tronapi.Return{Result: true}
+
+	t.Run("retried with a higher fee limit", func(t *testing.T) {
+		client := new(MockTronClient)
+		client.On("TRC20Send", job.FromAddress, job.ToAddress, job.TokenAddress, mock.Anything, int64(100_000_000)).Return(first, nil).Once()
+		client.On("TRC20Send", job.FromAddress, job.ToAddress, job.TokenAddress, mock.Anything, int64(200_000_000)).Return(retry, nil).Once()
+		client.On("Broadcast", mock.Anything).Return(broadcastOK, nil)
+		client.On("GetTransactionInfoByID", firstHash).Return(nil, assert.AnError).Once() // not yet in a block
+		client.On("GetTransactionInfoByID", firstHash).Return(outOfEnergy, nil)
+		client.On("GetTransactionInfoByID", retryHash).Return(succeeded, nil)
+
+		s := newTronTestService(t, 100_000_000, 300_000_000)
+		result, err := s.processTronJob(context.Background(), client, job)
+		require.NoError(t, err)
+		assert.True(t, result.Success)
+		assert.NoError(t, result.Error)
+		assert.Equal(t, retryHash, result.TxHash)
+		client.AssertExpectations(t)
+	})
+
+	t.Run("fails when the retry runs out of energy too", func(t *testing.T) {
+		client := new(MockTronClient)
+		client.On("TRC20Send", job.FromAddress, job.ToAddress, job.TokenAddress, mock.Anything, int64(100_000_000)).Return(first, nil).Once()
+		client.On("TRC20Send", job.FromAddress, job.ToAddress, job.TokenAddress, mock.Anything, int64(150_000_000)).Return(retry, nil).Once()
+		client.On("Broadcast", mock.Anything).Return(broadcastOK, nil)
+		client.On("GetTransactionInfoByID", mock.Anything).Return(outOfEnergy, nil)
+
+		s := newTronTestService(t, 100_000_000, 150_000_000)
+		result, err := s.processTronJob(context.Background(), client, job)
+		require.NoError(t, err)
+		assert.False(t, result.Success)
+		assert.ErrorIs(t, result.Error, ErrTronOutOfEnergy)
+		assert.Equal(t, retryHash, result.TxHash)
+		client.AssertNumberOfCalls(t, "TRC20Send", 2)
+	})
+
+	t.Run("no retry without a higher ceiling", func(t *testing.T) {
+		client := new(MockTronClient)
+		client.On("TRC20Send", job.FromAddress, job.ToAddress, job.TokenAddress, mock.Anything, int64(100_000_000)).Return(first, nil).Once()
+		client.On("Broadcast", mock.Anything).Return(broadcastOK, nil)
+
+		s := newTronTestService(t, 100_000_000, 0)
+		result, err := s.processTronJob(context.Background(), client, job)
+		require.NoError(t, err)
+		assert.True(t, result.Success)
+		assert.Equal(t, firstHash, result.TxHash)
+		client.AssertNotCalled(t, "GetTransactionInfoByID", mock.Anything)
+	})
+}
@@ -20,6 +20,17 @@ protoc \
   --go-grpc_opt=paths=source_relative \
   "$PROTO_DIR"/*.proto
 
+# event-indexer 直接编译生成的 indexer 代码 (internal/pb)
+INDEXER_OUT_DIR="$PROTO_DIR/../event-indexer/internal/pb"
+INDEXER_PKG="Mindexer.proto=github.com/protocol-bank/event-indexer/internal/pb;pb"
+protoc \
+  --proto_path="$PROTO_DIR" \
+  --go_out="$INDEXER_OUT_DIR" \
+  --go_opt=paths=source_relative,"$INDEXER_PKG" \
+  --go-grpc_out="$INDEXER_OUT_DIR" \
+  --go-grpc_opt=paths=source_relative,"$INDEXER_PKG" \
+  "$PROTO_DIR"/indexer.proto
+
 # Generate TypeScript code (using ts-proto)
 protoc \
   --proto_path="$PROTO_DIR" \